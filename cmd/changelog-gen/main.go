@@ -0,0 +1,118 @@
+// Command changelog-gen maintains the dataset changelog embedded in the server
+// binary (src/template/data/changelog.json). Run it after refreshing the
+// embedded snapshot:
+//
+//	changelog-gen [-previous DIR] [-upstream COMMIT] <data-dir> <changelog.json>
+//
+// It hashes the templates under data-dir and, when that version is not already
+// the newest entry, appends a revision listing every added, removed and
+// modified template. -previous points at the snapshot the changelog last
+// recorded (for example a `git worktree` of the previous release) so modified
+// templates carry a unified diff; without it every template is recorded as
+// added, which is only correct for the first revision.
+//
+// Permalinks store template names as indexes into the changelog's Names, so
+// it refuses to write a changelog in which an already recorded name would
+// move.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/apimgr/gitignore/src/template"
+)
+
+func main() {
+	previous := flag.String("previous", "", "directory holding the previously recorded snapshot")
+	upstream := flag.String("upstream", "", "upstream commit the snapshot was taken from")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: changelog-gen [-previous DIR] [-upstream COMMIT] <data-dir> <changelog.json>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dataDir, out := flag.Arg(0), flag.Arg(1)
+
+	next, err := loadDir(dataDir)
+	if err != nil {
+		fail(err)
+	}
+	var prev []*template.Template
+	if *previous != "" {
+		if prev, err = loadDir(*previous); err != nil {
+			fail(err)
+		}
+	}
+
+	log := &template.Changelog{}
+	if data, err := os.ReadFile(out); err == nil {
+		if log, err = template.ParseChangelog(data); err != nil {
+			fail(err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		fail(err)
+	}
+
+	rev := template.NewRevision(prev, next, time.Now(), *upstream)
+	if latest := log.Latest(); latest != nil && latest.Version == rev.Version {
+		fmt.Printf("changelog-gen: dataset %s already recorded\n", rev.Version)
+		return
+	}
+	names := log.Names()
+	log.Revisions = append(log.Revisions, rev)
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		fail(err)
+	}
+	if err := checkNames(names, data); err != nil {
+		fail(err)
+	}
+	if err := os.WriteFile(out, append(data, '\n'), 0o644); err != nil {
+		fail(err)
+	}
+	fmt.Printf("changelog-gen: recorded %s (%d templates, %d changes)\n", rev.Version, rev.Count, len(rev.Changes))
+}
+
+// checkNames verifies that data, parsed the way the server loads it, still
+// lists names first and in the same order, so issued permalinks keep their
+// meaning. A revision dated before the ones already recorded would break it.
+func checkNames(names []string, data []byte) error {
+	log, err := template.ParseChangelog(data)
+	if err != nil {
+		return err
+	}
+	got := log.Names()
+	for i, name := range names {
+		if i >= len(got) || got[i] != name {
+			return fmt.Errorf("template %q would move from permalink index %d; recorded revisions must keep their order", name, i)
+		}
+	}
+	return nil
+}
+
+// loadDir loads a github/gitignore-shaped directory of templates.
+func loadDir(dir string) ([]*template.Template, error) {
+	m, err := template.New()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.LoadDir(dir, "snapshot"); err != nil {
+		return nil, err
+	}
+	return m.ListAll(), nil
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	os.Exit(1)
+}
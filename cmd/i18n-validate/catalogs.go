@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apimgr/gitignore/src/common/i18n"
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// validateCatalogs checks the template metadata catalogs in dir against the
+// embedded dataset and the English catalog. It prints how much of the dataset
+// the English catalog describes and each other language's coverage of the
+// English entries; languages below minCoverage percent of those entries are
+// reported as problems.
+func validateCatalogs(dir string, minCoverage float64) ([]string, error) {
+	catalogs, err := loadCatalogs(dir)
+	if err != nil {
+		return nil, err
+	}
+	base, ok := catalogs[baseLang]
+	if !ok {
+		return nil, fmt.Errorf("missing base catalog %s.json in %s", baseLang, dir)
+	}
+	tm, err := template.New()
+	if err != nil {
+		return nil, fmt.Errorf("loading dataset: %w", err)
+	}
+	names := map[string]bool{}
+	for _, name := range tm.List() {
+		names[name] = true
+	}
+	categories := map[string]bool{}
+	for _, c := range tm.GetCategories() {
+		categories[c] = true
+	}
+
+	var problems []string
+	for _, lang := range sortedCatalogKeys(catalogs) {
+		c := catalogs[lang]
+		for cat := range c.Categories {
+			if !categories[cat] {
+				problems = append(problems, fmt.Sprintf("catalog %s: orphaned category %q (not in dataset)", lang, cat))
+			}
+		}
+		for name := range c.Templates {
+			if !names[name] {
+				problems = append(problems, fmt.Sprintf("catalog %s: orphaned template %q (not in dataset)", lang, name))
+			}
+		}
+
+		entries := catalogEntries(c)
+		for key, val := range entries {
+			if strings.TrimSpace(val) == "" {
+				problems = append(problems, fmt.Sprintf("catalog %s: empty value for %q", lang, key))
+			}
+		}
+		if lang == baseLang {
+			fmt.Printf("catalog %s: %.1f%% dataset coverage (%d/%d templates, %d/%d categories)\n",
+				lang, percent(len(c.Templates), len(names)), len(c.Templates), len(names), len(c.Categories), len(categories))
+			continue
+		}
+		baseEntries := catalogEntries(base)
+		covered := 0
+		for key, val := range entries {
+			baseVal, ok := baseEntries[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("catalog %s: orphaned entry %q (not in %s)", lang, key, baseLang))
+				continue
+			}
+			covered++
+			if bv, tv := varSet(baseVal), varSet(val); !equalSets(bv, tv) {
+				problems = append(problems, fmt.Sprintf("catalog %s: %q interpolation vars %v differ from %s %v", lang, key, sortedSet(tv), baseLang, sortedSet(bv)))
+			}
+		}
+		pct := percent(covered, len(baseEntries))
+		fmt.Printf("catalog %s: %.1f%% coverage of %s (%d/%d entries)\n", lang, pct, baseLang, covered, len(baseEntries))
+		if pct < minCoverage {
+			problems = append(problems, fmt.Sprintf("catalog %s: coverage %.1f%% is below -min-coverage %.1f%%", lang, pct, minCoverage))
+		}
+	}
+	return problems, nil
+}
+
+// loadCatalogs reads every *.json catalog in dir, keyed by language code.
+func loadCatalogs(dir string) (map[string]i18n.Catalog, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]i18n.Catalog{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var c i18n.Catalog
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("catalog %s: invalid JSON: %w", e.Name(), err)
+		}
+		out[strings.TrimSuffix(e.Name(), ".json")] = c
+	}
+	return out, nil
+}
+
+// catalogEntries flattens a catalog into translatable strings keyed like
+// "categories.Global.name" and "templates.Go".
+func catalogEntries(c i18n.Catalog) map[string]string {
+	out := map[string]string{}
+	for cat, t := range c.Categories {
+		out["categories."+cat+".name"] = t.Name
+		out["categories."+cat+".description"] = t.Description
+	}
+	for name, d := range c.Templates {
+		out["templates."+name] = d
+	}
+	return out
+}
+
+// percent returns n as a percentage of total, or 100 when total is zero.
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(n) * 100 / float64(total)
+}
+
+func sortedCatalogKeys(m map[string]i18n.Catalog) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -2,17 +2,30 @@
 // AI.md PART 30 "Build-Time Validation". It loads every locales/*.json file in
 // the directory given as the first argument and enforces:
 //
-//   - All language files have identical key sets to en.json.
+//   - All language files have identical key sets to en.json. Plural keys
+//     are compared by message key and must carry exactly the CLDR plural
+//     forms of their language, plus an optional explicit "zero".
 //   - No empty string values.
-//   - All interpolation variables ({var}) match across languages.
+//   - All interpolation variables ({var}) match across languages. A plural
+//     form may leave out variables (Arabic "one" and "two" name no number),
+//     but may not add any.
 //   - No orphaned keys (keys in other languages not in en.json).
 //
+// Given a template metadata catalogs directory as the second argument, it
+// also checks every catalogs/*.json: entries must name a category or
+// template in the embedded dataset, and non-English catalogs may only
+// translate entries en.json has, with no empty values and matching
+// placeholders. It prints the share of dataset templates and categories the
+// English catalog describes and each other language's coverage of the English
+// catalog, and -min-coverage fails any language below that percentage.
+//
 // It exits non-zero and prints every violation when validation fails, so it
 // can gate CI and `make i18n-validate`.
 package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -20,6 +33,8 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/apimgr/gitignore/src/common/i18n"
 )
 
 // baseLang is the authoritative language every other locale is compared to.
@@ -29,11 +44,13 @@ const baseLang = "en"
 var varPattern = regexp.MustCompile(`\{[a-zA-Z0-9_]+\}`)
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintln(os.Stderr, "usage: i18n-validate <locales-dir>")
+	minCoverage := flag.Float64("min-coverage", 0, "fail when a catalog covers less than this percentage of the en catalog")
+	flag.Parse()
+	if flag.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "usage: i18n-validate [-min-coverage PCT] <locales-dir> [catalogs-dir]")
 		os.Exit(2)
 	}
-	dir := os.Args[1]
+	dir := flag.Arg(0)
 
 	locales, err := loadLocales(dir)
 	if err != nil {
@@ -56,6 +73,18 @@ func main() {
 		problems = append(problems, compareToBase(lang, base, locales[lang])...)
 		problems = append(problems, checkValues(lang, locales[lang])...)
 	}
+	for _, lang := range sortedKeys(locales) {
+		problems = append(problems, checkPluralForms(lang, locales[lang])...)
+	}
+
+	if flag.NArg() > 1 {
+		catalogProblems, err := validateCatalogs(flag.Arg(1), *minCoverage)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(2)
+		}
+		problems = append(problems, catalogProblems...)
+	}
 
 	if len(problems) > 0 {
 		sort.Strings(problems)
@@ -66,7 +95,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	fmt.Printf("i18n-validate: OK (%d languages, %d keys each)\n", len(locales), len(base))
+	fmt.Printf("i18n-validate: OK (%d languages, %d keys in %s)\n", len(locales), len(base), baseLang)
 }
 
 // loadLocales reads every *.json file in dir and returns a map of language code
@@ -117,10 +146,15 @@ func flatten(prefix string, node map[string]interface{}, out map[string]string)
 }
 
 // compareToBase reports missing keys, orphaned keys, and interpolation-variable
-// mismatches for lang relative to the base locale.
+// mismatches for lang relative to the base locale. Plural keys are compared
+// by message key; their forms are checked by checkPluralForms.
 func compareToBase(lang string, base, target map[string]string) []string {
 	var problems []string
+	basePlurals, targetPlurals := pluralKeys(base), pluralKeys(target)
 	for key, baseVal := range base {
+		if _, _, ok := i18n.SplitPluralKey(key); ok {
+			continue
+		}
 		targetVal, ok := target[key]
 		if !ok {
 			problems = append(problems, fmt.Sprintf("%s: missing key %q (present in %s)", lang, key, baseLang))
@@ -131,13 +165,76 @@ func compareToBase(lang string, base, target map[string]string) []string {
 		}
 	}
 	for key := range target {
+		if _, _, ok := i18n.SplitPluralKey(key); ok {
+			continue
+		}
 		if _, ok := base[key]; !ok {
 			problems = append(problems, fmt.Sprintf("%s: orphaned key %q (not in %s)", lang, key, baseLang))
 		}
 	}
+
+	for key := range basePlurals {
+		if targetPlurals[key] == nil {
+			problems = append(problems, fmt.Sprintf("%s: missing plural key %q (present in %s)", lang, key, baseLang))
+		}
+	}
+	for key, forms := range targetPlurals {
+		if basePlurals[key] == nil {
+			problems = append(problems, fmt.Sprintf("%s: orphaned plural key %q (not in %s)", lang, key, baseLang))
+			continue
+		}
+		bv := varSet(base[key+".other"])
+		for form := range forms {
+			tv := varSet(target[key+"."+form])
+			if form == "other" {
+				if !equalSets(bv, tv) {
+					problems = append(problems, fmt.Sprintf("%s: key %q interpolation vars %v differ from %s %v", lang, key+".other", sortedSet(tv), baseLang, sortedSet(bv)))
+				}
+			} else if extra := setDiff(tv, bv); len(extra) > 0 {
+				problems = append(problems, fmt.Sprintf("%s: key %q uses interpolation vars %v not in %s %q", lang, key+"."+form, extra, baseLang, key+".other"))
+			}
+		}
+	}
+	return problems
+}
+
+// checkPluralForms reports plural keys in lang that lack a form lang's CLDR
+// rules select, or that define a form they never select. "zero" is always
+// allowed as an explicit-zero override.
+func checkPluralForms(lang string, target map[string]string) []string {
+	var problems []string
+	want := i18n.PluralForms(lang)
+	for key, forms := range pluralKeys(target) {
+		for _, form := range want {
+			if !forms[form] {
+				problems = append(problems, fmt.Sprintf("%s: plural key %q is missing the %q form", lang, key, form))
+			}
+		}
+		for form := range forms {
+			if form != "zero" && !contains(want, form) {
+				problems = append(problems, fmt.Sprintf("%s: plural key %q has form %q, which %s never selects", lang, key, form, lang))
+			}
+		}
+	}
 	return problems
 }
 
+// pluralKeys groups the plural keys of a flattened locale by message key.
+func pluralKeys(flat map[string]string) map[string]map[string]bool {
+	out := map[string]map[string]bool{}
+	for key := range flat {
+		base, form, ok := i18n.SplitPluralKey(key)
+		if !ok {
+			continue
+		}
+		if out[base] == nil {
+			out[base] = map[string]bool{}
+		}
+		out[base][form] = true
+	}
+	return out
+}
+
 // checkValues reports any empty string value, which the spec forbids.
 func checkValues(lang string, target map[string]string) []string {
 	var problems []string
@@ -169,6 +266,27 @@ func equalSets(a, b map[string]bool) bool {
 	return true
 }
 
+// setDiff returns the members of a not in b, sorted.
+func setDiff(a, b map[string]bool) []string {
+	var out []string
+	for k := range a {
+		if !b[k] {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func contains(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
 func sortedSet(m map[string]bool) []string {
 	out := make([]string, 0, len(m))
 	for k := range m {
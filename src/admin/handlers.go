@@ -17,6 +17,14 @@ type Handler struct {
 	version   string
 	commit    string
 	buildDate string
+	extraAPI  []apiRoute
+}
+
+// apiRoute is an admin API endpoint contributed by another package.
+type apiRoute struct {
+	method  string
+	pattern string
+	handler http.HandlerFunc
 }
 
 // NewHandler creates a new admin handler
@@ -29,6 +37,12 @@ func NewHandler(username, password, apiToken string, sessionTimeout int, sslEnab
 	}
 }
 
+// HandleAPI adds an endpoint under /api/v1/admin behind the same bearer
+// token as the built-in ones. It must be called before RegisterRoutes.
+func (h *Handler) HandleAPI(method, pattern string, handler http.HandlerFunc) {
+	h.extraAPI = append(h.extraAPI, apiRoute{method: method, pattern: pattern, handler: handler})
+}
+
 // RegisterRoutes registers admin routes on the router
 func (h *Handler) RegisterRoutes(r chi.Router) {
 	// Admin web interface (session auth)
@@ -46,6 +60,9 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		r.Get("/config", h.requireToken(h.handleAPIGetConfig))
 		r.Put("/config", h.requireToken(h.handleAPIUpdateConfig))
 		r.Post("/reload", h.requireToken(h.handleAPIReload))
+		for _, route := range h.extraAPI {
+			r.Method(route.method, route.pattern, h.requireToken(route.handler))
+		}
 	})
 }
 
@@ -63,11 +80,17 @@ func (h *Handler) requireSession(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// Authorized reports whether r carries a valid admin API token, for public
+// endpoints that show operators more than anonymous clients.
+func (h *Handler) Authorized(r *http.Request) bool {
+	token := GetTokenFromRequest(r)
+	return token != "" && h.auth.ValidateAPIToken(token)
+}
+
 // Middleware for bearer token authentication
 func (h *Handler) requireToken(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		token := GetTokenFromRequest(r)
-		if token == "" || !h.auth.ValidateAPIToken(token) {
+		if !h.Authorized(r) {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusUnauthorized)
 			json.NewEncoder(w).Encode(map[string]string{"error": "Unauthorized"})
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Backend kinds, as set in cli.yml server.backend and server.fallbacks.
+const (
+	// BackendNative is this project's own /api/v1/* API.
+	BackendNative = "native"
+	// BackendGitignoreIO is any gitignore.io-compatible service: the public
+	// toptal one, or this server's /api/list compatibility routes.
+	BackendGitignoreIO = "gitignoreio"
+	// BackendGitHub is GitHub's /gitignore/templates REST API, on github.com
+	// (https://api.github.com) or GitHub Enterprise (https://HOST/api/v3).
+	BackendGitHub = "github"
+	// BackendAuto probes the server on first contact (see Detect).
+	BackendAuto = "auto"
+)
+
+// ValidBackend reports whether kind names a backend, "" meaning auto.
+func ValidBackend(kind string) bool {
+	switch kind {
+	case "", BackendAuto, BackendNative, BackendGitignoreIO, BackendGitHub:
+		return true
+	}
+	return false
+}
+
+// Backend is one template API the CLI can talk to. Every backend answers
+// List, Search, GetTemplate and Combine; the rest return *UnsupportedError
+// where the API has no equivalent.
+type Backend interface {
+	Kind() string
+	URL() string
+	List() ([]string, error)
+	Search(q string) ([]string, error)
+	Categories() ([]string, error)
+	CategoryTemplates(name string) ([]string, error)
+	GetTemplate(name string) (*Template, error)
+	Combine(names []string) (string, error)
+	Stats() (map[string]interface{}, error)
+	Healthz() error
+}
+
+// UnsupportedError is returned for an operation the server's API does not
+// offer, e.g. categories on gitignore.io.
+type UnsupportedError struct {
+	Op   string
+	Kind string
+	URL  string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s is not available from %s (%s API)", e.Op, e.URL, e.Kind)
+}
+
+// DetectError is returned when autodetection finds no template API it knows
+// at a server.
+type DetectError struct {
+	URL string
+}
+
+func (e *DetectError) Error() string {
+	return fmt.Sprintf("no supported template API found at %s: set server.backend in cli.yml", e.URL)
+}
+
+// Unreachable reports whether err means the server could not be reached, as
+// opposed to it answering with an error: a connection failure, or a gateway
+// in front of it reporting the server down.
+func Unreachable(err error) bool {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.Status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// BackendFor returns the backend of kind for the server at baseURL. BackendAuto
+// (or "") probes the server first.
+func (c *Client) BackendFor(baseURL, kind string) (Backend, error) {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	switch kind {
+	case "", BackendAuto:
+		detected, err := c.Detect(baseURL)
+		if err != nil {
+			return nil, err
+		}
+		if c.OnDetect != nil {
+			c.OnDetect(baseURL, detected)
+		}
+		return c.BackendFor(baseURL, detected)
+	case BackendNative:
+		return &Native{client: c, url: baseURL}, nil
+	case BackendGitignoreIO:
+		return &GitignoreIO{client: c, url: baseURL}, nil
+	case BackendGitHub:
+		return &GitHub{client: c, url: baseURL}, nil
+	}
+	return nil, fmt.Errorf("unknown backend %q: expected auto, native, gitignoreio or github", kind)
+}
+
+// Detect probes the server at baseURL and returns its backend kind, trying
+// in order this project's /api/autodiscover, GitHub's /gitignore/templates
+// and gitignore.io's /api/list. A server that cannot be reached fails with
+// its connection error rather than *DetectError, so failover moves on.
+func (c *Client) Detect(baseURL string) (string, error) {
+	body, err := c.request(baseURL, "/api/autodiscover", nil, nil, nil)
+	if err == nil {
+		var env struct {
+			Data struct {
+				APIBase string `json:"api_base"`
+			} `json:"data"`
+		}
+		if json.Unmarshal(body, &env) == nil && env.Data.APIBase != "" {
+			return BackendNative, nil
+		}
+	} else if Unreachable(err) {
+		return "", err
+	}
+
+	body, err = c.request(baseURL, "/gitignore/templates", nil, nil, githubHeader())
+	if err == nil {
+		var names []string
+		if json.Unmarshal(body, &names) == nil && len(names) > 0 {
+			return BackendGitHub, nil
+		}
+	} else if Unreachable(err) {
+		return "", err
+	}
+
+	body, err = c.request(baseURL, "/api/list", nil, nil, nil)
+	if err == nil && isKeyList(string(body)) {
+		return BackendGitignoreIO, nil
+	} else if err != nil && Unreachable(err) {
+		return "", err
+	}
+	return "", &DetectError{URL: baseURL}
+}
@@ -1,19 +1,26 @@
-// Package api implements the HTTP client used by gitignore-cli to talk to
-// this project's own /api/v1/* endpoints. All endpoints are public and
+// Package api implements the HTTP client used by gitignore-cli. It talks to
+// this project's own /api/v1/* endpoints, to any gitignore.io-compatible
+// service and to GitHub's gitignore API (see Backend), failing over between
+// the configured servers in order. This project's endpoints are public and
 // unauthenticated (see IDEA.md: "No user accounts, registration, or login
-// of any kind") so this client carries no token/auth machinery.
+// of any kind"); the only credential is an optional GITHUB_TOKEN for GitHub.
+// Requests go through the public SDK in src/sdk, which adds retries and
+// typed errors.
 package api
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/apimgr/gitignore/src/common/urlutil"
+	"github.com/apimgr/gitignore/src/sdk"
 )
 
 // ProjectName is compiled in via -ldflags and used only to build the
@@ -29,202 +36,272 @@ func UserAgent() string {
 	return fmt.Sprintf("%s-cli/%s", ProjectName, Version)
 }
 
-// Client talks to the gitignore API server.
+// Server is a template server to fail over to.
+type Server struct {
+	URL string
+	// Backend is the server's API kind (BackendNative, ...); "" or
+	// BackendAuto detects it.
+	Backend string
+}
+
+// Client answers template commands from the primary server, failing over to
+// each of Fallbacks in turn when a server cannot be reached or its API lacks
+// the operation. A server found unreachable is skipped for the rest of the
+// process. Any other error, such as an unknown template, is the answer.
 type Client struct {
-	BaseURL    string
+	// BaseURL is the primary server. Features only this project's server
+	// offers, such as the event stream, use it alone.
+	BaseURL string
+	// Backend is the primary server's API kind; "" or BackendAuto detects it.
+	Backend string
+	// Fallbacks are tried in order after the primary.
+	Fallbacks  []Server
 	HTTPClient *http.Client
 	// Lang is the resolved output language sent as Accept-Language so the
 	// server can localize error messages (AI.md PART 30). Empty means the
 	// server applies its own default.
 	Lang string
+	// OnDetect, when set, is told the kind detected for a server whose
+	// backend is auto, so the caller can remember it and skip the probe
+	// next time.
+	OnDetect func(url, kind string)
+
+	mu       sync.Mutex
+	backends map[Server]Backend
+	down     map[string]error
 }
 
-// New creates a Client for baseURL (trailing slash trimmed).
+// New creates a Client for this project's native API at baseURL (trailing
+// slash trimmed).
 func New(baseURL string) *Client {
 	return &Client{
 		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Backend:    BackendNative,
 		HTTPClient: &http.Client{Timeout: 30 * time.Second},
 	}
 }
 
-// Template mirrors the JSON shape of src/template.Template as served by
-// the API. Kept as a small client-side copy so this package has no
-// compile-time dependency on the server's internal packages.
-type Template struct {
-	Name        string   `json:"name"`
-	FileName    string   `json:"file_name"`
-	Category    string   `json:"category"`
-	Content     string   `json:"content,omitempty"`
-	Description string   `json:"description,omitempty"`
-	Tags        []string `json:"tags,omitempty"`
-	Size        int      `json:"size"`
-}
-
-// envelope mirrors the {"ok": true, "data": ...} JSON contract implemented
-// by src/server/response.go (APIResponse) for every JSON-negotiated
-// response.
-type envelope struct {
-	OK        bool            `json:"ok"`
-	Data      json.RawMessage `json:"data"`
-	Count     int             `json:"count,omitempty"`
-	Query     string          `json:"query,omitempty"`
-	Category  string          `json:"category,omitempty"`
-	Templates []string        `json:"templates,omitempty"`
-	Error     string          `json:"error,omitempty"`
-}
+// Template is a template as the API serves it.
+type Template = sdk.Template
 
 // APIError is returned for non-2xx HTTP responses; Status carries the HTTP
-// status code so callers can map it to a CLI exit code.
-type APIError struct {
-	Status  int
-	Message string
-}
+// status code so callers can map it to a CLI exit code, and Suggestions the
+// names closest to an unknown template.
+type APIError = sdk.Error
 
-func (e *APIError) Error() string {
-	return fmt.Sprintf("server returned %d: %s", e.Status, e.Message)
+// retryPolicy retries a request once: failover to the next server is the
+// CLI's main answer to a server that is down.
+var retryPolicy = sdk.RetryPolicy{
+	MaxAttempts:   2,
+	MinBackoff:    250 * time.Millisecond,
+	MaxBackoff:    time.Second,
+	MaxRetryAfter: 10 * time.Second,
 }
 
-func (c *Client) get(path string, pathParams, queryParams map[string]string) (*envelope, error) {
-	apiURL := urlutil.BuildAPIURL(c.BaseURL, path, pathParams, queryParams)
-	if apiURL == "" {
-		return nil, fmt.Errorf("invalid server URL: %s", c.BaseURL)
+// sdkClient returns the SDK client for the server at base, sharing the
+// client's HTTPClient and headers.
+func (c *Client) sdkClient(base string) *sdk.Client {
+	return &sdk.Client{
+		BaseURL:    base,
+		HTTPClient: c.HTTPClient,
+		UserAgent:  UserAgent(),
+		Lang:       c.Lang,
+		Retry:      retryPolicy,
 	}
+}
 
-	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+// request GETs path on the server at base and returns the body of a 2xx
+// response. Any other status becomes an *APIError carrying the server's
+// message: this project's "message" field, GitHub's "message", or the
+// plain-text body. header adds to the default request headers.
+func (c *Client) request(base, path string, pathParams, queryParams map[string]string, header http.Header) ([]byte, error) {
+	for key, value := range pathParams {
+		path = strings.ReplaceAll(path, "{"+key+"}", urlutil.EncodePathSegment(value))
 	}
-	req.Header.Set("User-Agent", UserAgent())
-	req.Header.Set("Accept", "application/json")
-	if c.Lang != "" {
-		req.Header.Set("Accept-Language", c.Lang)
+	query := url.Values{}
+	for key, value := range queryParams {
+		query.Set(key, value)
 	}
+	body, err := c.sdkClient(base).Fetch(context.Background(), path, query, header)
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return nil, fmt.Errorf("connecting to %s: %w", base, err)
+	}
+	return body, err
+}
 
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("connecting to %s: %w", c.BaseURL, err)
+// servers returns the primary and the fallbacks in order, without
+// duplicates. An empty primary is left out when fallbacks exist.
+func (c *Client) servers() []Server {
+	out := make([]Server, 0, 1+len(c.Fallbacks))
+	seen := make(map[Server]bool)
+	if c.BaseURL != "" || len(c.Fallbacks) == 0 {
+		primary := Server{URL: c.BaseURL, Backend: c.Backend}
+		out = append(out, primary)
+		seen[primary] = true
 	}
-	defer resp.Body.Close()
+	for _, s := range c.Fallbacks {
+		s.URL = strings.TrimSuffix(s.URL, "/")
+		if !seen[s] {
+			out = append(out, s)
+			seen[s] = true
+		}
+	}
+	return out
+}
 
-	body, err := io.ReadAll(resp.Body)
+// backend returns s's backend, detecting it on first use, or the error that
+// marked s unreachable earlier.
+func (c *Client) backend(s Server) (Backend, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err, ok := c.down[s.URL]; ok {
+		return nil, err
+	}
+	if b, ok := c.backends[s]; ok {
+		return b, nil
+	}
+	b, err := c.BackendFor(s.URL, s.Backend)
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
+		return nil, err
+	}
+	if c.backends == nil {
+		c.backends = make(map[Server]Backend)
 	}
+	c.backends[s] = b
+	return b, nil
+}
 
-	var env envelope
-	if len(body) > 0 {
-		_ = json.Unmarshal(body, &env)
+// markDown records that the server at url could not be reached.
+func (c *Client) markDown(url string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.down == nil {
+		c.down = make(map[string]error)
 	}
+	c.down[url] = err
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg := env.Error
-		if msg == "" {
-			msg = strings.TrimSpace(string(body))
+// failover runs fn against each server's backend in order until one
+// answers. Unreachable servers and those whose API lacks the operation are
+// passed over; any other error is returned at once. When every server is
+// passed over, a connection error wins over an unsupported operation so that
+// callers still fall back to cached templates.
+func (c *Client) failover(fn func(Backend) error) error {
+	var unreachable, skipped error
+	for _, s := range c.servers() {
+		b, err := c.backend(s)
+		if err == nil {
+			err = fn(b)
+		}
+		if err == nil {
+			return nil
 		}
-		if msg == "" {
-			msg = resp.Status
+		var unsupported *UnsupportedError
+		var undetected *DetectError
+		switch {
+		case Unreachable(err):
+			c.markDown(s.URL, err)
+			if unreachable == nil {
+				unreachable = err
+			}
+		case errors.As(err, &unsupported), errors.As(err, &undetected):
+			if skipped == nil {
+				skipped = err
+			}
+		default:
+			return err
 		}
-		return nil, &APIError{Status: resp.StatusCode, Message: msg}
 	}
-
-	return &env, nil
+	if unreachable != nil {
+		return unreachable
+	}
+	return skipped
 }
 
 // List returns all template names.
-func (c *Client) List() ([]string, error) {
-	env, err := c.get("/api/v1/list", nil, nil)
-	if err != nil {
-		return nil, err
-	}
-	var names []string
-	if err := json.Unmarshal(env.Data, &names); err != nil {
-		return nil, fmt.Errorf("decoding list response: %w", err)
-	}
-	return names, nil
+func (c *Client) List() (names []string, err error) {
+	err = c.failover(func(b Backend) error {
+		names, err = b.List()
+		return err
+	})
+	return names, err
 }
 
 // Search returns template names matching q.
-func (c *Client) Search(q string) ([]string, error) {
-	env, err := c.get("/api/v1/search", nil, map[string]string{"q": q})
-	if err != nil {
-		return nil, err
-	}
-	var names []string
-	if err := json.Unmarshal(env.Data, &names); err != nil {
-		return nil, fmt.Errorf("decoding search response: %w", err)
-	}
-	return names, nil
+func (c *Client) Search(q string) (names []string, err error) {
+	err = c.failover(func(b Backend) error {
+		names, err = b.Search(q)
+		return err
+	})
+	return names, err
 }
 
 // Categories returns all category names.
-func (c *Client) Categories() ([]string, error) {
-	env, err := c.get("/api/v1/categories", nil, nil)
-	if err != nil {
-		return nil, err
-	}
-	var cats []string
-	if err := json.Unmarshal(env.Data, &cats); err != nil {
-		return nil, fmt.Errorf("decoding categories response: %w", err)
-	}
-	return cats, nil
+func (c *Client) Categories() (cats []string, err error) {
+	err = c.failover(func(b Backend) error {
+		cats, err = b.Categories()
+		return err
+	})
+	return cats, err
 }
 
 // CategoryTemplates returns template names in the given category.
-func (c *Client) CategoryTemplates(name string) ([]string, error) {
-	env, err := c.get("/api/v1/categories/{name}", map[string]string{"name": name}, nil)
-	if err != nil {
-		return nil, err
-	}
-	var names []string
-	if err := json.Unmarshal(env.Data, &names); err != nil {
-		return nil, fmt.Errorf("decoding category response: %w", err)
-	}
-	return names, nil
+func (c *Client) CategoryTemplates(name string) (names []string, err error) {
+	err = c.failover(func(b Backend) error {
+		names, err = b.CategoryTemplates(name)
+		return err
+	})
+	return names, err
 }
 
 // GetTemplate fetches a single named template.
-func (c *Client) GetTemplate(name string) (*Template, error) {
-	env, err := c.get("/api/v1/templates/{name}", map[string]string{"name": name}, nil)
-	if err != nil {
-		return nil, err
-	}
-	var tmpl Template
-	if err := json.Unmarshal(env.Data, &tmpl); err != nil {
-		return nil, fmt.Errorf("decoding template response: %w", err)
-	}
-	return &tmpl, nil
+func (c *Client) GetTemplate(name string) (tmpl *Template, err error) {
+	err = c.failover(func(b Backend) error {
+		tmpl, err = b.GetTemplate(name)
+		return err
+	})
+	return tmpl, err
 }
 
 // Combine merges the named templates into one output, in request order.
-func (c *Client) Combine(names []string) (string, error) {
-	env, err := c.get("/api/v1/combine", nil, map[string]string{"templates": strings.Join(names, ",")})
-	if err != nil {
-		return "", err
-	}
-	var content string
-	if err := json.Unmarshal(env.Data, &content); err != nil {
-		return "", fmt.Errorf("decoding combine response: %w", err)
-	}
-	return content, nil
+func (c *Client) Combine(names []string) (content string, err error) {
+	err = c.failover(func(b Backend) error {
+		content, err = b.Combine(names)
+		return err
+	})
+	return content, err
 }
 
 // Stats returns server-reported template statistics.
-func (c *Client) Stats() (map[string]interface{}, error) {
-	env, err := c.get("/api/v1/stats", nil, nil)
-	if err != nil {
-		return nil, err
-	}
-	var stats map[string]interface{}
-	if err := json.Unmarshal(env.Data, &stats); err != nil {
-		return nil, fmt.Errorf("decoding stats response: %w", err)
-	}
-	return stats, nil
+func (c *Client) Stats() (stats map[string]interface{}, err error) {
+	err = c.failover(func(b Backend) error {
+		stats, err = b.Stats()
+		return err
+	})
+	return stats, err
+}
+
+// TemplatesArchive downloads the templates archive from the first native
+// server that answers (see Native.TemplatesArchive).
+func (c *Client) TemplatesArchive(etag string) (data []byte, newETag string, err error) {
+	err = c.failover(func(b Backend) error {
+		n, ok := b.(*Native)
+		if !ok {
+			return &UnsupportedError{Op: "templates archive", Kind: b.Kind(), URL: b.URL()}
+		}
+		data, newETag, err = n.TemplatesArchive(etag)
+		return err
+	})
+	return data, newETag, err
 }
 
-// Healthz checks server reachability; used by --status.
+// Healthz checks that some configured server is reachable; used by
+// --status.
 func (c *Client) Healthz() error {
-	_, err := c.get("/api/v1/healthz", nil, nil)
-	return err
+	return c.failover(func(b Backend) error {
+		return b.Healthz()
+	})
 }
 
 // StatsCount is a small helper for formatting numeric stats fields that may
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/apimgr/gitignore/src/common/urlutil"
+	"github.com/apimgr/gitignore/src/sdk"
+)
+
+// Event types sent on /api/v1/events that the CLI acts on.
+const (
+	// EventTemplatesUpdated announces a newly installed template dataset.
+	EventTemplatesUpdated = "templates.updated"
+	// EventResync is sent when a resumed stream missed events, so anything
+	// cached from before it must be treated as stale.
+	EventResync = "resync"
+)
+
+// Event is one message from the server's event stream.
+type Event struct {
+	// ID resumes the stream after this event when passed back to Events.
+	ID   string          `json:"-"`
+	Type string          `json:"type"`
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data"`
+}
+
+// InvalidatesTemplates reports whether e means locally cached templates may
+// no longer match the server's.
+func (e Event) InvalidatesTemplates() bool {
+	return e.Type == EventTemplatesUpdated || e.Type == EventResync
+}
+
+// Events follows /api/v1/events, calling fn for each event until ctx is
+// done, the server ends the stream, or fn returns an error. types narrows the
+// stream (nil means all public events) and lastEventID resumes after an
+// earlier event. It returns the ID of the last event delivered so a caller
+// can reconnect without missing any.
+func (c *Client) Events(ctx context.Context, lastEventID string, types []string, fn func(Event) error) (string, error) {
+	var query map[string]string
+	if len(types) > 0 {
+		query = map[string]string{"types": strings.Join(types, ",")}
+	}
+	apiURL := urlutil.BuildAPIURL(c.BaseURL, "/api/v1/events", nil, query)
+	if apiURL == "" {
+		return lastEventID, fmt.Errorf("invalid server URL: %s", c.BaseURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return lastEventID, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", UserAgent())
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	// The stream stays open indefinitely, so the client's request timeout
+	// must not apply.
+	hc := *c.HTTPClient
+	hc.Timeout = 0
+	resp, err := hc.Do(req)
+	if err != nil {
+		return lastEventID, fmt.Errorf("connecting to %s: %w", c.BaseURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return lastEventID, sdk.ParseError(resp, body)
+	}
+
+	var id string
+	var data []string
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			// A blank line dispatches the event gathered so far.
+			if len(data) > 0 {
+				var e Event
+				if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &e); err == nil {
+					e.ID = id
+					if err := fn(e); err != nil {
+						return lastEventID, err
+					}
+					if id != "" {
+						lastEventID = id
+					}
+				}
+			}
+			id, data = "", nil
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			id = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if err := sc.Err(); err != nil && ctx.Err() == nil {
+		return lastEventID, fmt.Errorf("reading events: %w", err)
+	}
+	return lastEventID, nil
+}
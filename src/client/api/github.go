@@ -0,0 +1,158 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// GitHub is GitHub's gitignore templates API, GET /gitignore/templates and
+// GET /gitignore/templates/{name}. Its base URL is https://api.github.com,
+// or https://HOST/api/v3 on GitHub Enterprise. Template names are
+// case-sensitive there; this backend matches them ignoring case like the
+// other backends. There are no categories or stats.
+type GitHub struct {
+	client *Client
+	url    string
+}
+
+// githubTemplate is the body of GET /gitignore/templates/{name}.
+type githubTemplate struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+}
+
+// githubHeader returns GitHub's recommended request headers, authenticating
+// with GITHUB_TOKEN when it is set to lift the anonymous rate limit.
+func githubHeader() http.Header {
+	h := http.Header{
+		"Accept":               {"application/vnd.github+json"},
+		"X-Github-Api-Version": {"2022-11-28"},
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		h.Set("Authorization", "Bearer "+token)
+	}
+	return h
+}
+
+// Kind returns BackendGitHub.
+func (g *GitHub) Kind() string { return BackendGitHub }
+
+// URL returns the API's base URL.
+func (g *GitHub) URL() string { return g.url }
+
+func (g *GitHub) unsupported(op string) error {
+	return &UnsupportedError{Op: op, Kind: BackendGitHub, URL: g.url}
+}
+
+// List returns every template name.
+func (g *GitHub) List() ([]string, error) {
+	body, err := g.client.request(g.url, "/gitignore/templates", nil, nil, githubHeader())
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if err := json.Unmarshal(body, &names); err != nil {
+		return nil, fmt.Errorf("decoding list response: %w", err)
+	}
+	return names, nil
+}
+
+// Search returns the names containing q, ignoring case; GitHub has no search
+// endpoint for templates.
+func (g *GitHub) Search(q string) ([]string, error) {
+	names, err := g.List()
+	if err != nil {
+		return nil, err
+	}
+	q = strings.ToLower(strings.TrimSpace(q))
+	var matches []string
+	for _, name := range names {
+		if strings.Contains(strings.ToLower(name), q) {
+			matches = append(matches, name)
+		}
+	}
+	return matches, nil
+}
+
+// Categories is unsupported: GitHub's API has no categories.
+func (g *GitHub) Categories() ([]string, error) {
+	return nil, g.unsupported("categories")
+}
+
+// CategoryTemplates is unsupported: GitHub's API has no categories.
+func (g *GitHub) CategoryTemplates(name string) ([]string, error) {
+	return nil, g.unsupported("categories")
+}
+
+// GetTemplate fetches a template, retrying with the listed spelling of the
+// name when GitHub does not know it as given.
+func (g *GitHub) GetTemplate(name string) (*Template, error) {
+	name = strings.TrimSpace(name)
+	tmpl, err := g.get(name)
+	var apiErr *APIError
+	if err == nil || !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound {
+		return tmpl, err
+	}
+	names, listErr := g.List()
+	if listErr != nil {
+		return nil, err
+	}
+	for _, listed := range names {
+		if listed != name && strings.EqualFold(listed, name) {
+			return g.get(listed)
+		}
+	}
+	return nil, &APIError{Status: http.StatusNotFound, Message: fmt.Sprintf("template not found: %s", name)}
+}
+
+func (g *GitHub) get(name string) (*Template, error) {
+	body, err := g.client.request(g.url, "/gitignore/templates/{name}", map[string]string{"name": name}, nil, githubHeader())
+	if err != nil {
+		return nil, err
+	}
+	var t githubTemplate
+	if err := json.Unmarshal(body, &t); err != nil {
+		return nil, fmt.Errorf("decoding template response: %w", err)
+	}
+	return &Template{
+		Name:     t.Name,
+		FileName: t.Name + ".gitignore",
+		Content:  t.Source,
+		Size:     len(t.Source),
+	}, nil
+}
+
+// Combine fetches each template and joins them in request order under
+// "### Name ###" headings, as gitignore.io lays out its combined files.
+func (g *GitHub) Combine(names []string) (string, error) {
+	var b strings.Builder
+	for i, name := range names {
+		tmpl, err := g.GetTemplate(name)
+		if err != nil {
+			return "", err
+		}
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "### %s ###\n%s", tmpl.Name, tmpl.Content)
+		if !strings.HasSuffix(tmpl.Content, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String(), nil
+}
+
+// Stats is unsupported: GitHub's API reports no statistics.
+func (g *GitHub) Stats() (map[string]interface{}, error) {
+	return nil, g.unsupported("stats")
+}
+
+// Healthz checks that the template list can be fetched.
+func (g *GitHub) Healthz() error {
+	_, err := g.List()
+	return err
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/apimgr/gitignore/src/common/urlutil"
+)
+
+// GitignoreIO is a gitignore.io-compatible API: GET /api/list for the
+// template keys and GET /api/{key,key,...} for the combined file. Templates
+// are named by their lowercase keys, and there are no categories or stats.
+type GitignoreIO struct {
+	client *Client
+	url    string
+}
+
+// gitignoreIOEntry is one template in /api/list?format=json.
+type gitignoreIOEntry struct {
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	FileName string `json:"fileName"`
+	Contents string `json:"contents"`
+}
+
+// gitignoreIOError starts the line gitignore.io writes in place of a
+// template it does not know.
+const gitignoreIOError = "#!! ERROR: "
+
+// Kind returns BackendGitignoreIO.
+func (g *GitignoreIO) Kind() string { return BackendGitignoreIO }
+
+// URL returns the server's base URL.
+func (g *GitignoreIO) URL() string { return g.url }
+
+func (g *GitignoreIO) unsupported(op string) error {
+	return &UnsupportedError{Op: op, Kind: BackendGitignoreIO, URL: g.url}
+}
+
+// List returns every template key.
+func (g *GitignoreIO) List() ([]string, error) {
+	body, err := g.client.request(g.url, "/api/list", nil, nil, textHeader())
+	if err != nil {
+		return nil, err
+	}
+	return splitKeys(string(body)), nil
+}
+
+// Search returns the keys containing q, ignoring case; gitignore.io has no
+// search endpoint of its own.
+func (g *GitignoreIO) Search(q string) ([]string, error) {
+	keys, err := g.List()
+	if err != nil {
+		return nil, err
+	}
+	q = strings.ToLower(strings.TrimSpace(q))
+	var matches []string
+	for _, key := range keys {
+		if strings.Contains(key, q) {
+			matches = append(matches, key)
+		}
+	}
+	return matches, nil
+}
+
+// Categories is unsupported: gitignore.io has no categories.
+func (g *GitignoreIO) Categories() ([]string, error) {
+	return nil, g.unsupported("categories")
+}
+
+// CategoryTemplates is unsupported: gitignore.io has no categories.
+func (g *GitignoreIO) CategoryTemplates(name string) ([]string, error) {
+	return nil, g.unsupported("categories")
+}
+
+// GetTemplate returns the template with key name, ignoring case.
+func (g *GitignoreIO) GetTemplate(name string) (*Template, error) {
+	body, err := g.client.request(g.url, "/api/list", nil, map[string]string{"format": "json"}, nil)
+	if err != nil {
+		return nil, err
+	}
+	var entries map[string]gitignoreIOEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("decoding list response: %w", err)
+	}
+	entry, ok := entries[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, &APIError{Status: http.StatusNotFound, Message: fmt.Sprintf("template not found: %s", name)}
+	}
+	return &Template{
+		Name:     entry.Name,
+		FileName: entry.FileName,
+		Content:  entry.Contents,
+		Size:     len(entry.Contents),
+	}, nil
+}
+
+// Combine returns the service's combined file for names. A name the service
+// does not know fails the whole request rather than leaving an error marker
+// in the output.
+func (g *GitignoreIO) Combine(names []string) (string, error) {
+	// Each key is escaped on its own: the commas between them must stay
+	// literal.
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = urlutil.EncodePathSegment(strings.ToLower(strings.TrimSpace(name)))
+	}
+	body, err := g.client.request(g.url, "/api/"+strings.Join(keys, ","), nil, nil, textHeader())
+	if err != nil {
+		// The service answers 404 when the first name is unknown, with the
+		// marker as the body.
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+			if msg := markedError(apiErr.Message); msg != "" {
+				apiErr.Message = msg
+			}
+		}
+		return "", err
+	}
+	if msg := markedError(string(body)); msg != "" {
+		return "", &APIError{Status: http.StatusNotFound, Message: msg}
+	}
+	return string(body), nil
+}
+
+// Stats is unsupported: gitignore.io reports no statistics.
+func (g *GitignoreIO) Stats() (map[string]interface{}, error) {
+	return nil, g.unsupported("stats")
+}
+
+// Healthz checks that the template list can be fetched.
+func (g *GitignoreIO) Healthz() error {
+	_, err := g.List()
+	return err
+}
+
+// textHeader asks for the plain-text rendering gitignore.io serves by
+// default.
+func textHeader() http.Header {
+	return http.Header{"Accept": {"text/plain"}}
+}
+
+// splitKeys parses /api/list, whose keys are separated by commas and, on
+// the public service, newlines.
+func splitKeys(body string) []string {
+	var keys []string
+	for _, line := range strings.Split(body, "\n") {
+		for _, key := range strings.Split(line, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// isKeyList reports whether body looks like an /api/list response rather
+// than, say, an HTML page some other server answers every path with.
+func isKeyList(body string) bool {
+	keys := splitKeys(body)
+	if len(keys) == 0 {
+		return false
+	}
+	for _, key := range keys {
+		if strings.ContainsAny(key, " \t<>{}\"") {
+			return false
+		}
+	}
+	return true
+}
+
+// markedError returns the message of the first gitignore.io error marker in
+// body, or "".
+func markedError(body string) string {
+	for _, line := range strings.Split(body, "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), gitignoreIOError); ok {
+			return strings.TrimSpace(strings.TrimSuffix(rest, "!!#"))
+		}
+	}
+	return ""
+}
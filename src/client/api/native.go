@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/apimgr/gitignore/src/sdk"
+)
+
+// Native is this project's own /api/v1/* API.
+type Native struct {
+	client *Client
+	url    string
+}
+
+// Kind returns BackendNative.
+func (n *Native) Kind() string { return BackendNative }
+
+// URL returns the server's base URL.
+func (n *Native) URL() string { return n.url }
+
+// sdk returns the SDK client for the server.
+func (n *Native) sdk() *sdk.Client {
+	return n.client.sdkClient(n.url)
+}
+
+// List returns all template names.
+func (n *Native) List() ([]string, error) {
+	return n.sdk().List(context.Background())
+}
+
+// Search returns template names matching q.
+func (n *Native) Search(q string) ([]string, error) {
+	results, err := n.sdk().Search(context.Background(), q)
+	return templateNames(results), err
+}
+
+// Categories returns all category names.
+func (n *Native) Categories() ([]string, error) {
+	return n.sdk().Categories(context.Background())
+}
+
+// CategoryTemplates returns template names in the given category.
+func (n *Native) CategoryTemplates(name string) ([]string, error) {
+	templates, err := n.sdk().Category(context.Background(), name)
+	return templateNames(templates), err
+}
+
+// templateNames returns the names of templates, in order.
+func templateNames(templates []Template) []string {
+	if templates == nil {
+		return nil
+	}
+	names := make([]string, len(templates))
+	for i, tmpl := range templates {
+		names[i] = tmpl.Name
+	}
+	return names
+}
+
+// GetTemplate fetches a single named template.
+func (n *Native) GetTemplate(name string) (*Template, error) {
+	return n.sdk().Template(context.Background(), name)
+}
+
+// Combine merges the named templates into one output, in request order.
+func (n *Native) Combine(names []string) (string, error) {
+	return n.sdk().Combine(context.Background(), names, nil)
+}
+
+// Stats returns server-reported template statistics.
+func (n *Native) Stats() (map[string]interface{}, error) {
+	stats, err := n.sdk().Stats(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	// Commands print the statistics as generic key/value pairs.
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("decoding stats response: %w", err)
+	}
+	return out, nil
+}
+
+// Healthz checks server reachability.
+func (n *Native) Healthz() error {
+	_, err := n.sdk().Health(context.Background())
+	return err
+}
+
+// TemplatesArchive downloads /api/v1/templates.tar.gz. When etag is set it is
+// sent as If-None-Match, and an unchanged dataset returns nil data with the
+// same etag. Otherwise it returns the archive and its new ETag.
+func (n *Native) TemplatesArchive(etag string) ([]byte, string, error) {
+	return n.sdk().TemplatesArchive(context.Background(), etag)
+}
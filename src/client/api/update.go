@@ -0,0 +1,34 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/apimgr/gitignore/src/sdk"
+)
+
+// CLIVersion is a gitignore-cli build the server publishes for one
+// platform in /api/autodiscover cli_versions.
+type CLIVersion = sdk.CLIVersion
+
+// CLIVersions returns the gitignore-cli builds the primary server
+// publishes, keyed "{os}-{arch}". A server that publishes none, including
+// every server that is not this project's, returns an empty map.
+func (c *Client) CLIVersions(ctx context.Context) (map[string]CLIVersion, error) {
+	info, err := c.sdkClient(c.BaseURL).Autodiscover(ctx)
+	var apiErr *APIError
+	if err != nil && (errors.As(err, &apiErr) || Unreachable(err)) {
+		return nil, err
+	}
+	if err != nil || info.CLIVersions == nil {
+		return map[string]CLIVersion{}, nil
+	}
+	for platform, v := range info.CLIVersions {
+		if !strings.Contains(v.URL, "://") {
+			v.URL = c.BaseURL + "/" + strings.TrimPrefix(v.URL, "/")
+			info.CLIVersions[platform] = v
+		}
+	}
+	return info.CLIVersions, nil
+}
@@ -0,0 +1,152 @@
+// Package audit finds files committed to a git repository that should not
+// be: tracked files its own .gitignore files, or the templates its contents
+// call for (go.mod calls for Go, .idea/ for JetBrains), would ignore. It
+// backs `gitignore-cli audit` and the pre-commit hook `gitignore-cli hook`
+// installs.
+package audit
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// Group is the tracked files one rule would ignore.
+type Group struct {
+	// Source is the .gitignore path or template name the rule comes from.
+	Source  string   `json:"source"`
+	Line    int      `json:"line"`
+	Pattern string   `json:"pattern"`
+	Paths   []string `json:"paths"`
+}
+
+// Report is the outcome of an audit.
+type Report struct {
+	Recommended []Recommendation `json:"recommended"`
+	// Missing are the recommended templates the root .gitignore does not
+	// include yet.
+	Missing []string `json:"missing"`
+	Groups  []Group  `json:"findings"`
+}
+
+// Files returns every reported path, sorted.
+func (r *Report) Files() []string {
+	var files []string
+	for _, g := range r.Groups {
+		files = append(files, g.Paths...)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Run audits tracked, the repository's tracked files relative to its root.
+// gitignores maps each of its .gitignore files, by path relative to the
+// root, to its content; templates maps each recommended template that could
+// be fetched to its content.
+//
+// A file the repository's own .gitignore files ignore is reported under the
+// rule responsible. Otherwise it is reported under the first recommended
+// template that would ignore it, unless the .gitignore files explicitly
+// keep it with a "!" rule.
+func Run(tracked []string, gitignores map[string]string, recs []Recommendation, templates map[string]string) *Report {
+	rep := &Report{Recommended: recs, Missing: []string{}, Groups: []Group{}}
+	if recs == nil {
+		rep.Recommended = []Recommendation{}
+	}
+
+	// Shallower .gitignore files first so deeper ones override them, as in
+	// git.
+	files := make([]string, 0, len(gitignores))
+	for p := range gitignores {
+		files = append(files, p)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		di, dj := strings.Count(files[i], "/"), strings.Count(files[j], "/")
+		if di != dj {
+			return di < dj
+		}
+		return files[i] < files[j]
+	})
+	var repoRules []*Rule
+	rank := make(map[string]int)
+	for _, p := range files {
+		base := path.Dir(p)
+		if base == "." {
+			base = ""
+		}
+		repoRules = append(repoRules, ParseRules(p, base, gitignores[p])...)
+		rank[p] = len(rank)
+	}
+
+	var templateRules [][]*Rule
+	for _, rec := range recs {
+		content, ok := templates[rec.Template]
+		if !ok {
+			continue
+		}
+		templateRules = append(templateRules, ParseRules(rec.Template, "", content))
+		rank[rec.Template] = len(rank)
+		if !includes(gitignores[".gitignore"], rec.Template, content) {
+			rep.Missing = append(rep.Missing, rec.Template)
+		}
+	}
+
+	groups := make(map[*Rule]*Group)
+	sorted := append([]string(nil), tracked...)
+	sort.Strings(sorted)
+	for _, f := range sorted {
+		r := Ignoring(repoRules, f)
+		if r != nil && r.Negated() {
+			continue
+		}
+		for _, rules := range templateRules {
+			if r != nil {
+				break
+			}
+			if t := Ignoring(rules, f); t != nil && !t.Negated() {
+				r = t
+			}
+		}
+		if r == nil {
+			continue
+		}
+		g, ok := groups[r]
+		if !ok {
+			g = &Group{Source: r.Source, Line: r.Line, Pattern: r.Pattern}
+			groups[r] = g
+		}
+		g.Paths = append(g.Paths, f)
+	}
+
+	for _, g := range groups {
+		rep.Groups = append(rep.Groups, *g)
+	}
+	sort.Slice(rep.Groups, func(i, j int) bool {
+		a, b := rep.Groups[i], rep.Groups[j]
+		if rank[a.Source] != rank[b.Source] {
+			return rank[a.Source] < rank[b.Source]
+		}
+		return a.Line < b.Line
+	})
+	return rep
+}
+
+// includes reports whether gitignore already carries the template name:
+// under a "### name ###" heading as combined files have, or rule for rule.
+func includes(gitignore, name, content string) bool {
+	lines := make(map[string]bool)
+	for _, line := range strings.Split(gitignore, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.EqualFold(line, "### "+name+" ###") {
+			return true
+		}
+		lines[line] = true
+	}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") && !lines[line] {
+			return false
+		}
+	}
+	return true
+}
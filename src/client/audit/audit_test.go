@@ -0,0 +1,169 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// TestAudit verifies that an audit finds committed files the
+// repository's .gitignore or its recommended templates would ignore, and
+// names the rule responsible.
+func TestAudit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	tm, err := template.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":                  "*.log\n!keep.log\n",
+		"go.mod":                      "module example\n",
+		"main.go":                     "package main\n",
+		"debug.log":                   "",
+		"keep.log":                    "",
+		"docs/.DS_Store":              "",
+		".idea/workspace.xml":         "",
+		"web/package.json":            "{}\n",
+		"web/node_modules/x/ix.js":    "",
+		"tools/gen/__pycache__/a.pyc": "",
+		"tools/gen/gen.py":            "",
+		"sub/.gitignore":              "out/\n",
+		"sub/out/result.txt":          "",
+	}
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("add", "-f", ".")
+
+	top, err := Root(filepath.Join(root, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracked, err := Tracked(top)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracked) != len(files) {
+		t.Fatalf("tracked %d files, want %d", len(tracked), len(files))
+	}
+
+	recs := Recommend(tracked)
+	var names []string
+	templates := make(map[string]string)
+	for _, rec := range recs {
+		names = append(names, rec.Template)
+		tmpl, err := tm.Get(rec.Template)
+		if err != nil {
+			t.Fatal(err)
+		}
+		templates[rec.Template] = tmpl.Content
+	}
+	if want := []string{"Go", "Node", "Python", "JetBrains", "macOS"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("recommended %v, want %v", names, want)
+	}
+
+	gitignores := map[string]string{".gitignore": files[".gitignore"], "sub/.gitignore": files["sub/.gitignore"]}
+	rep := Run(tracked, gitignores, recs, templates)
+	got := make(map[string]string)
+	for _, g := range rep.Groups {
+		for _, p := range g.Paths {
+			got[p] = g.Source
+		}
+	}
+	want := map[string]string{
+		"debug.log":                   ".gitignore",
+		"sub/out/result.txt":          "sub/.gitignore",
+		"web/node_modules/x/ix.js":    "Node",
+		"tools/gen/__pycache__/a.pyc": "Python",
+		".idea/workspace.xml":         "JetBrains",
+		"docs/.DS_Store":              "macOS",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings %v, want %v", got, want)
+	}
+	if rep.Groups[0].Source != ".gitignore" || rep.Groups[0].Line != 1 || rep.Groups[0].Pattern != "*.log" {
+		t.Errorf("first group = %+v, want .gitignore line 1", rep.Groups[0])
+	}
+	if !reflect.DeepEqual(rep.Missing, names) {
+		t.Errorf("missing %v, want %v", rep.Missing, names)
+	}
+
+	sarif, err := rep.SARIF("gitignore-cli", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []json.RawMessage `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(sarif, &log); err != nil || log.Version != "2.1.0" || len(log.Runs[0].Results) != len(want) {
+		t.Errorf("SARIF log = %s (%v)", sarif, err)
+	}
+
+	if err := RemoveCached(top, rep.Files()); err != nil {
+		t.Fatal(err)
+	}
+	if tracked, _ := Tracked(top); len(tracked) != len(files)-len(want) {
+		t.Errorf("%d files tracked after removal, want %d", len(tracked), len(files)-len(want))
+	}
+	if _, err := os.Stat(filepath.Join(root, "docs", ".DS_Store")); err != nil {
+		t.Errorf("removal deleted the file on disk: %v", err)
+	}
+}
+
+// TestParseRulesMatch verifies rules are read as template.ParseRule reads
+// them and match as git does.
+func TestParseRulesMatch(t *testing.T) {
+	rules := ParseRules(".gitignore", "", "*.log\n!keep.log\nbuild/\n/root.txt\n\\#hash\ntab\t\ntrail   \n[oops\n")
+	if len(rules) != 7 || rules[1].Pattern != "!keep.log" || !rules[1].Negated() || rules[6].Pattern != "trail" {
+		t.Fatalf("rules = %+v", rules)
+	}
+	for _, c := range []struct {
+		path  string
+		want  string
+		isDir bool
+	}{
+		{path: "a/debug.log", want: "*.log"},
+		{path: "a/keep.log", want: "!keep.log"},
+		{path: "a/build", want: "build/", isDir: true},
+		{path: "a/build", want: ""},
+		{path: "root.txt", want: "/root.txt"},
+		{path: "a/root.txt", want: ""},
+		{path: "#hash", want: `\#hash`},
+		{path: "tab\t", want: "tab\t"},
+		{path: "tab", want: ""},
+		{path: "trail", want: "trail"},
+	} {
+		got := ""
+		if r := last(rules, c.path, c.isDir); r != nil {
+			got = r.Pattern
+		}
+		if got != c.want {
+			t.Errorf("rule for %q (dir %v) = %q, want %q", c.path, c.isDir, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// git runs git in dir and returns its standard output.
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// Root returns the top directory of the git work tree containing dir.
+func Root(dir string) (string, error) {
+	out, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Tracked lists the files git tracks in the work tree at root, relative to
+// it.
+func Tracked(root string) ([]string, error) {
+	out, err := git(root, "ls-files", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// RemoveCached untracks paths, leaving them on disk, in batches that keep
+// the command line short.
+func RemoveCached(root string, paths []string) error {
+	const batch = 200
+	for len(paths) > 0 {
+		n := min(batch, len(paths))
+		if _, err := git(root, append([]string{"rm", "--cached", "--quiet", "--"}, paths[:n]...)...); err != nil {
+			return err
+		}
+		paths = paths[n:]
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// hookMarker identifies a pre-commit hook written by InstallHook.
+	hookMarker = "# Installed by gitignore-cli hook install"
+	// chainedHook is where InstallHook moves a pre-commit hook it finds, so
+	// the new hook can run it first and UninstallHook can put it back.
+	chainedHook = "pre-commit.pre-gitignore-cli"
+)
+
+// ErrHookNotInstalled is returned by UninstallHook when there is no
+// pre-commit hook.
+var ErrHookNotInstalled = errors.New("no pre-commit hook installed")
+
+// ForeignHookError is returned by UninstallHook for a pre-commit hook that
+// InstallHook did not write.
+type ForeignHookError struct {
+	Path string
+}
+
+func (e *ForeignHookError) Error() string {
+	return fmt.Sprintf("%s was not installed by gitignore-cli", e.Path)
+}
+
+// HookPath returns the pre-commit hook of the repository at root, honouring
+// core.hooksPath.
+func HookPath(root string) (string, error) {
+	out, err := git(root, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	dir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	return filepath.Join(dir, "pre-commit"), nil
+}
+
+// hookScript returns the pre-commit hook running `binary hook run`, after
+// any hook it replaced.
+func hookScript(binary string) string {
+	return `#!/bin/sh
+` + hookMarker + `; remove it with
+# gitignore-cli hook uninstall. It runs the pre-commit hook that was here
+# before, then blocks commits of files the configured templates would ignore.
+previous="$(dirname "$0")/` + chainedHook + `"
+if [ -x "$previous" ]; then
+	"$previous" "$@" || exit $?
+fi
+cli=` + shellQuote(binary) + `
+if [ ! -x "$cli" ]; then
+	cli=gitignore-cli
+fi
+exec "$cli" hook run
+`
+}
+
+// InstallHook installs the pre-commit hook of the repository at root,
+// running binary. A pre-commit hook already there is kept and chained: it
+// runs first, and a failure still blocks the commit. Reinstalling rewrites
+// the hook in place. It returns the hook's path and whether a previous hook
+// is chained.
+func InstallHook(root, binary string) (string, bool, error) {
+	path, err := HookPath(root)
+	if err != nil {
+		return "", false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", false, fmt.Errorf("create hooks directory: %w", err)
+	}
+	chained := filepath.Join(filepath.Dir(path), chainedHook)
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil && !strings.Contains(string(existing), hookMarker):
+		if _, statErr := os.Stat(chained); statErr == nil {
+			return "", false, fmt.Errorf("cannot chain %s: %s already exists", path, chained)
+		}
+		if err := os.Rename(path, chained); err != nil {
+			return "", false, fmt.Errorf("keep existing hook: %w", err)
+		}
+	case err != nil && !os.IsNotExist(err):
+		return "", false, fmt.Errorf("read %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(hookScript(binary)), 0o755); err != nil {
+		return "", false, fmt.Errorf("write %s: %w", path, err)
+	}
+	_, statErr := os.Stat(chained)
+	return path, statErr == nil, nil
+}
+
+// UninstallHook removes the pre-commit hook InstallHook wrote to the
+// repository at root, restoring any hook it chained. It returns the hook's
+// path and whether a previous hook was restored.
+func UninstallHook(root string) (string, bool, error) {
+	path, err := HookPath(root)
+	if err != nil {
+		return "", false, err
+	}
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return path, false, ErrHookNotInstalled
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("read %s: %w", path, err)
+	}
+	if !strings.Contains(string(existing), hookMarker) {
+		return path, false, &ForeignHookError{Path: path}
+	}
+	if err := os.Remove(path); err != nil {
+		return "", false, fmt.Errorf("remove %s: %w", path, err)
+	}
+	chained := filepath.Join(filepath.Dir(path), chainedHook)
+	if _, err := os.Stat(chained); err != nil {
+		return path, false, nil
+	}
+	if err := os.Rename(chained, path); err != nil {
+		return "", false, fmt.Errorf("restore previous hook: %w", err)
+	}
+	return path, true, nil
+}
+
+// Staged lists the files added, copied, renamed or modified in the index of
+// the repository at root, relative to it.
+func Staged(root string) ([]string, error) {
+	out, err := git(root, "diff", "--cached", "--name-only", "-z", "--diff-filter=ACMR")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// HeaderTemplates returns the templates named by the "# Templates:" header
+// lines combined .gitignore files start with, in order and without
+// duplicates.
+func HeaderTemplates(gitignore string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(gitignore, "\n") {
+		list, ok := strings.CutPrefix(strings.TrimSpace(line), "# Templates:")
+		if !ok {
+			continue
+		}
+		for _, name := range strings.Split(list, ",") {
+			if name = strings.TrimSpace(name); name != "" && !seen[name] {
+				names = append(names, name)
+				seen[name] = true
+			}
+		}
+	}
+	return names
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestHook verifies that the pre-commit hook chains a hook already
+// installed and restores it when uninstalled, and leaves foreign hooks
+// alone.
+func TestHook(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	if out, err := exec.Command("git", "-C", root, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	hooks := filepath.Join(root, ".git", "hooks")
+	if err := os.MkdirAll(hooks, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	previous := "#!/bin/sh\nexit 0\n"
+	if err := os.WriteFile(filepath.Join(hooks, "pre-commit"), []byte(previous), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := UninstallHook(root); !errors.As(err, new(*ForeignHookError)) {
+		t.Fatalf("uninstall of a foreign hook: err = %v, want ForeignHookError", err)
+	}
+
+	path, chained, err := InstallHook(root, "/usr/local/bin/gitignore-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !chained {
+		t.Error("existing hook not chained")
+	}
+	script, _ := os.ReadFile(path)
+	if !strings.Contains(string(script), "hook run") {
+		t.Errorf("hook script does not run the check:\n%s", script)
+	}
+	if _, chained, err = InstallHook(root, "/usr/local/bin/gitignore-cli"); err != nil || !chained {
+		t.Fatalf("reinstall: chained = %v, err = %v", chained, err)
+	}
+
+	if _, restored, err := UninstallHook(root); err != nil || !restored {
+		t.Fatalf("uninstall: restored = %v, err = %v", restored, err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != previous {
+		t.Errorf("restored hook = %q, want %q", got, previous)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := UninstallHook(root); !errors.Is(err, ErrHookNotInstalled) {
+		t.Errorf("uninstall without a hook: err = %v, want ErrHookNotInstalled", err)
+	}
+}
+
+// TestCLIHookHeaderTemplates verifies that the templates are read from the
+// "# Templates:" header of a generated .gitignore.
+func TestCLIHookHeaderTemplates(t *testing.T) {
+	content := "# Generated by gitignore\n# Templates: Go, macOS\n# Templates: Go,Node\n\n*.log\n"
+	if got, want := HeaderTemplates(content), []string{"Go", "macOS", "Node"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("HeaderTemplates = %v, want %v", got, want)
+	}
+	if got := HeaderTemplates("*.log\n"); got != nil {
+		t.Errorf("HeaderTemplates without a header = %v, want nil", got)
+	}
+}
@@ -0,0 +1,263 @@
+package audit
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// Rule is one pattern line of a .gitignore file or template.
+type Rule struct {
+	// Source is where the rule comes from: a .gitignore path relative to
+	// the repository root, or a template name.
+	Source string
+	// Line is the rule's 1-based line number in Source.
+	Line int
+	// Pattern is the rule as written, without unescaped trailing spaces.
+	Pattern string
+	// rule is the line as template.ParseRule reads it.
+	rule template.Rule
+	// base is the directory the rule is relative to, "" for the root.
+	base string
+	re   *regexp.Regexp
+}
+
+// Negated reports whether the rule re-includes what it matches ("!").
+func (r *Rule) Negated() bool { return r.rule.Negate }
+
+// ParseRules parses gitignore content from source whose patterns are
+// relative to the directory base ("" for the repository root). Lines are
+// read by template.ParseRule, as git reads them; lines git would reject
+// match nothing and are skipped.
+func ParseRules(source, base, content string) []*Rule {
+	var rules []*Rule
+	for i, line := range strings.Split(content, "\n") {
+		rule, ok, err := template.ParseRule(line, i+1)
+		if !ok || err != nil {
+			continue
+		}
+		expr, ok := patternRegexp(rule)
+		if !ok {
+			continue
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			continue
+		}
+		rules = append(rules, &Rule{Source: source, Line: rule.Line, Pattern: rule.Text(), rule: rule, base: base, re: re})
+	}
+	return rules
+}
+
+// patternRegexp translates a rule's glob into an anchored regexp. An
+// anchored rule is relative to its base; otherwise it matches a name at any
+// depth. ok is false when git's wildmatch would reject the pattern, which
+// then matches nothing.
+func patternRegexp(rule template.Rule) (expr string, ok bool) {
+	var b strings.Builder
+	b.WriteString("^")
+	if !rule.Anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	pattern := strings.TrimPrefix(rule.Pattern, "/")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/") && (i == 0 || pattern[i-1] == '/'):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case pattern[i:] == "**" && i > 0 && pattern[i-1] == '/':
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			class, n, ok := bracket(pattern[i:])
+			if !ok {
+				return "", false
+			}
+			b.WriteString(class)
+			i += n - 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String(), true
+}
+
+// posixClasses maps the character classes wildmatch knows to regexp class
+// members. Those that include "/" are spelled out without it.
+var posixClasses = map[string]string{
+	"alnum":  "[:alnum:]",
+	"alpha":  "[:alpha:]",
+	"blank":  "[:blank:]",
+	"cntrl":  "[:cntrl:]",
+	"digit":  "[:digit:]",
+	"graph":  `!-.0-~`,
+	"lower":  "[:lower:]",
+	"print":  ` -.0-~`,
+	"punct":  "!-.:-@\\[-`{-~",
+	"space":  "[:space:]",
+	"upper":  "[:upper:]",
+	"xdigit": "[:xdigit:]",
+}
+
+// bracket translates the bracket expression opening pattern into a regexp
+// class and returns the expression's length. The closing "]" is found as
+// git's wildmatch finds it: a "]" first in the set is a member, "[:name:]"
+// is a character class and a backslash escapes the next character. As in
+// git, the class never matches "/". ok is false for an unterminated
+// expression or an unknown class name.
+func bracket(pattern string) (class string, n int, ok bool) {
+	i := 1
+	negated := i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^')
+	if negated {
+		i++
+	}
+	var members []string
+	prev := rune(-1) // the last single member, which may start a range
+	for first := true; ; first = false {
+		if i >= len(pattern) {
+			return "", 0, false
+		}
+		if pattern[i] == ']' && !first {
+			break
+		}
+		c, size := utf8.DecodeRuneInString(pattern[i:])
+		switch {
+		case c == '\\':
+			i++
+			if i >= len(pattern) {
+				return "", 0, false
+			}
+			c, size = utf8.DecodeRuneInString(pattern[i:])
+			members = append(members, classRange(c, c)...)
+			prev = c
+		case c == '-' && prev >= 0 && i+1 < len(pattern) && pattern[i+1] != ']':
+			i++
+			hi, hiSize := utf8.DecodeRuneInString(pattern[i:])
+			if hi == '\\' {
+				i++
+				if i >= len(pattern) {
+					return "", 0, false
+				}
+				hi, hiSize = utf8.DecodeRuneInString(pattern[i:])
+			}
+			members = append(members, classRange(prev, hi)...)
+			prev, size = -1, hiSize
+		case c == '[' && strings.HasPrefix(pattern[i+1:], ":"):
+			end := strings.IndexByte(pattern[i+2:], ']')
+			if end < 0 {
+				return "", 0, false
+			}
+			end += i + 2
+			if end == i+2 || pattern[end-1] != ':' {
+				// No ":]", so the "[" is a plain member.
+				members = append(members, classRange('[', '[')...)
+				prev = '['
+				break
+			}
+			members = append(members, posixClasses[pattern[i+2:end-1]])
+			if members[len(members)-1] == "" {
+				return "", 0, false
+			}
+			prev, size = -1, end+1-i
+		default:
+			members = append(members, classRange(c, c)...)
+			prev = c
+		}
+		i += size
+	}
+	switch {
+	case negated:
+		return "[^" + strings.Join(members, "") + "/]", i + 1, true
+	case len(members) == 0:
+		// Only "/" was listed, which a bracket expression never matches.
+		return `[^\x00-\x{10FFFF}]`, i + 1, true
+	}
+	return "[" + strings.Join(members, "") + "]", i + 1, true
+}
+
+// classRange returns the regexp class members for the runes lo to hi,
+// leaving out "/". A range running backwards matches nothing, as in git.
+func classRange(lo, hi rune) []string {
+	switch {
+	case hi < lo:
+		return nil
+	case lo <= '/' && '/' <= hi:
+		return append(classRange(lo, '.'), classRange('0', hi)...)
+	case lo == hi:
+		return []string{classRune(lo)}
+	}
+	return []string{classRune(lo) + "-" + classRune(hi)}
+}
+
+// classRune spells r so that it stands for itself inside a regexp class.
+func classRune(r rune) string {
+	if r < utf8.RuneSelf && (r >= '0' && r <= '9' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z') {
+		return string(r)
+	}
+	return fmt.Sprintf(`\x{%x}`, r)
+}
+
+// Matches reports whether r matches p, a slash-separated path relative to
+// the repository root naming a directory when isDir is set.
+func (r *Rule) Matches(p string, isDir bool) bool {
+	if r.rule.DirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		if !strings.HasPrefix(p, r.base+"/") {
+			return false
+		}
+		p = strings.TrimPrefix(p, r.base+"/")
+	}
+	return r.re.MatchString(p)
+}
+
+// last returns the last of rules matching p, the one git obeys, or nil.
+func last(rules []*Rule, p string, isDir bool) *Rule {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].Matches(p, isDir) {
+			return rules[i]
+		}
+	}
+	return nil
+}
+
+// Ignoring returns the rule deciding whether rules ignore the file at p: a
+// plain rule means it is ignored, a negated one that it is explicitly kept,
+// and nil that no rule matches. As in git, a file inside an ignored
+// directory is ignored whatever later rules say about the file itself.
+func Ignoring(rules []*Rule, p string) *Rule {
+	if r := ExcludedDir(rules, p); r != nil {
+		return r
+	}
+	return last(rules, p, false)
+}
+
+// ExcludedDir returns the rule ignoring a directory above p, or nil. Git
+// does not look inside an ignored directory, so no rule can re-include a
+// path below it.
+func ExcludedDir(rules []*Rule, p string) *Rule {
+	if path.Dir(p) == "." {
+		return nil
+	}
+	dirs := strings.Split(path.Dir(p), "/")
+	for i := range dirs {
+		if r := last(rules, strings.Join(dirs[:i+1], "/"), true); r != nil && !r.rule.Negate {
+			return r
+		}
+	}
+	return nil
+}
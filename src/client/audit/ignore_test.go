@@ -0,0 +1,50 @@
+package audit
+
+import "testing"
+
+// TestPatternMatch verifies globs match paths as git's wildmatch matches
+// them, bracket expressions and "**" included.
+func TestPatternMatch(t *testing.T) {
+	for _, c := range []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "[]a]", path: "]", want: true},
+		{pattern: "[]a]", path: "a", want: true},
+		{pattern: "[]a]", path: "b"},
+		{pattern: "[!]a]", path: "b", want: true},
+		{pattern: "[!]a]", path: "]"},
+		{pattern: "[^]a]", path: "a"},
+		{pattern: "x[]]y", path: "x]y", want: true},
+		{pattern: "[[:alpha:]]", path: "q", want: true},
+		{pattern: "[[:alpha:]]", path: "7"},
+		{pattern: "[[:alpha:]]", path: "]"},
+		{pattern: "[[:digit:]x]", path: "5", want: true},
+		{pattern: "[[:digit:]x]", path: "x", want: true},
+		{pattern: "[[:digit:]x]", path: "y"},
+		{pattern: "[[:punct:]]", path: "-", want: true},
+		{pattern: "a[[:punct:]]b", path: "a/b"},
+		{pattern: "a[!x]b", path: "a/b"},
+		{pattern: "a[--0]b", path: "a/b"},
+		{pattern: "a[--0]b", path: "a.b", want: true},
+		{pattern: "[a-c]", path: "b", want: true},
+		{pattern: "[a-]", path: "-", want: true},
+		{pattern: "[\\]]", path: "]", want: true},
+		{pattern: "[[:nope:]]", path: "n"},
+		{pattern: "[[:alpha:]", path: "a"},
+		{pattern: "**/foo", path: "foo", want: true},
+		{pattern: "**/foo", path: "a/b/foo", want: true},
+		{pattern: "foo/**", path: "foo/a/b", want: true},
+		{pattern: "foo/**", path: "foo"},
+		{pattern: "a/**/b", path: "a/b", want: true},
+		{pattern: "a/**/b", path: "a/x/y/b", want: true},
+		{pattern: "a/**/b", path: "a/xb"},
+	} {
+		rules := ParseRules(".gitignore", "", c.pattern)
+		got := len(rules) == 1 && rules[0].Matches(c.path, false)
+		if got != c.want {
+			t.Errorf("%q matches %q = %v, want %v", c.pattern, c.path, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package audit
+
+import "strings"
+
+// markers maps a file or directory name to the template its presence calls
+// for. A leading "*" matches a suffix; a trailing "/" only a directory.
+// Templates come out in table order, languages first.
+var markers = []struct {
+	name, template string
+}{
+	{"go.mod", "Go"},
+	{"package.json", "Node"},
+	{"next.config.js", "Nextjs"},
+	{"pyproject.toml", "Python"},
+	{"requirements.txt", "Python"},
+	{"setup.py", "Python"},
+	{"Pipfile", "Python"},
+	{"*.py", "Python"},
+	{"*.ipynb", "JupyterNotebooks"},
+	{"Cargo.toml", "Rust"},
+	{"Gemfile", "Ruby"},
+	{"pom.xml", "Maven"},
+	{"build.gradle", "Gradle"},
+	{"build.gradle.kts", "Gradle"},
+	{"*.java", "Java"},
+	{"*.kt", "Kotlin"},
+	{"composer.json", "Composer"},
+	{"pubspec.yaml", "Dart"},
+	{"mix.exs", "Elixir"},
+	{"Package.swift", "Swift"},
+	{"*.xcodeproj/", "Xcode"},
+	{"*.sln", "VisualStudio"},
+	{"*.csproj", "VisualStudio"},
+	{"CMakeLists.txt", "CMake"},
+	{"*.tf", "Terraform"},
+	{"Vagrantfile", "Vagrant"},
+	{"build.zig", "Zig"},
+	{".idea/", "JetBrains"},
+	{".vscode/", "VisualStudioCode"},
+	{"*.sublime-project", "SublimeText"},
+	{"*.sublime-workspace", "SublimeText"},
+	{"*.swp", "Vim"},
+	{".DS_Store", "macOS"},
+	{"Thumbs.db", "Windows"},
+	{"desktop.ini", "Windows"},
+}
+
+// globalTemplates are the templates for operating systems and editors:
+// files a developer's machine leaves anywhere in a work tree, rather than
+// ones a package's contents produce.
+var globalTemplates = map[string]bool{
+	"JetBrains": true, "VisualStudioCode": true, "SublimeText": true, "Vim": true,
+	"macOS": true, "Windows": true, "Linux": true,
+}
+
+// Global reports whether template is for an operating system or editor.
+func Global(template string) bool {
+	return globalTemplates[template]
+}
+
+// Recommendation is a template the repository's contents call for.
+type Recommendation struct {
+	Template string `json:"template"`
+	// Marker is the first path found that calls for it.
+	Marker string `json:"marker"`
+}
+
+// Recommend returns the templates called for by the marker files and
+// directories among paths, each once, in the marker table's order.
+func Recommend(paths []string) []Recommendation {
+	found := make(map[string]string)
+	for _, p := range paths {
+		parts := strings.Split(p, "/")
+		for i, name := range parts {
+			isDir := i < len(parts)-1
+			for _, m := range markers {
+				if _, ok := found[m.template]; ok || !markerMatch(m.name, name, isDir) {
+					continue
+				}
+				found[m.template] = strings.Join(parts[:i+1], "/")
+				if isDir {
+					found[m.template] += "/"
+				}
+			}
+		}
+	}
+	var recs []Recommendation
+	seen := make(map[string]bool)
+	for _, m := range markers {
+		if marker, ok := found[m.template]; ok && !seen[m.template] {
+			recs = append(recs, Recommendation{Template: m.template, Marker: marker})
+			seen[m.template] = true
+		}
+	}
+	return recs
+}
+
+func markerMatch(marker, name string, isDir bool) bool {
+	if dir := strings.TrimSuffix(marker, "/"); dir != marker {
+		if !isDir {
+			return false
+		}
+		marker = dir
+	}
+	if suffix, ok := strings.CutPrefix(marker, "*"); ok {
+		return strings.HasSuffix(name, suffix) && name != suffix
+	}
+	return name == marker
+}
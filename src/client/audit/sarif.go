@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// sarifRuleID identifies audit findings in SARIF output.
+const sarifRuleID = "committed-ignored-file"
+
+// SARIF renders the report as a SARIF 2.1.0 log, one warning per file, for
+// CI code-scanning integrations. tool and version name the reporting
+// binary.
+func (r *Report) SARIF(tool, version string) ([]byte, error) {
+	type message struct {
+		Text string `json:"text"`
+	}
+	type location struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+		} `json:"physicalLocation"`
+	}
+	type result struct {
+		RuleID     string            `json:"ruleId"`
+		Level      string            `json:"level"`
+		Message    message           `json:"message"`
+		Locations  []location        `json:"locations"`
+		Properties map[string]string `json:"properties"`
+	}
+	type rule struct {
+		ID               string  `json:"id"`
+		ShortDescription message `json:"shortDescription"`
+	}
+	type driver struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Rules   []rule `json:"rules"`
+	}
+	type run struct {
+		Tool struct {
+			Driver driver `json:"driver"`
+		} `json:"tool"`
+		Results []result `json:"results"`
+	}
+
+	var out run
+	out.Tool.Driver = driver{
+		Name:    tool,
+		Version: version,
+		Rules: []rule{{
+			ID:               sarifRuleID,
+			ShortDescription: message{Text: "A committed file matches a .gitignore rule"},
+		}},
+	}
+	out.Results = []result{}
+	for _, g := range r.Groups {
+		for _, p := range g.Paths {
+			res := result{
+				RuleID:  sarifRuleID,
+				Level:   "warning",
+				Message: message{Text: fmt.Sprintf("%s is committed but ignored by %s line %d (%s)", p, g.Source, g.Line, g.Pattern)},
+				Properties: map[string]string{
+					"source":  g.Source,
+					"pattern": g.Pattern,
+				},
+			}
+			var loc location
+			loc.PhysicalLocation.ArtifactLocation.URI = p
+			res.Locations = []location{loc}
+			out.Results = append(out.Results, res)
+		}
+	}
+	return json.MarshalIndent(map[string]interface{}{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs":    []run{out},
+	}, "", "  ")
+}
@@ -0,0 +1,158 @@
+// Package cache keeps an offline copy of the server's template dataset so
+// gitignore-cli keeps working when the server is unreachable. The copy is
+// the server's /api/v1/templates.tar.gz archive stored verbatim, next to a
+// small metadata file recording its ETag and when it was last revalidated.
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/apimgr/gitignore/src/client/api"
+)
+
+// DefaultTTL is how long a revalidated copy counts as fresh when cli.yml sets
+// no cache.ttl.
+const DefaultTTL = 24 * time.Hour
+
+const (
+	archiveFile = "templates.tar.gz"
+	metaFile    = "templates.json"
+)
+
+// ErrEmpty is returned when nothing has been cached yet.
+var ErrEmpty = errors.New("no cached templates")
+
+// Meta describes the cached copy.
+type Meta struct {
+	// Server is the base URL the archive was last revalidated against.
+	Server string `json:"server"`
+	// ETag is the archive's ETag, the server's dataset version in quotes.
+	ETag string `json:"etag"`
+	// Fetched is when the server last confirmed or replaced the copy.
+	Fetched time.Time `json:"fetched"`
+	Count   int       `json:"count"`
+}
+
+// Store is the on-disk cache in Dir. A copy older than TTL is stale: it is
+// still served offline, with a warning, and revalidated on the next online
+// run. A zero TTL revalidates on every run.
+type Store struct {
+	Dir string
+	TTL time.Duration
+}
+
+// New returns the store kept in dir.
+func New(dir string, ttl time.Duration) *Store {
+	return &Store{Dir: dir, TTL: ttl}
+}
+
+// Meta reads the cache metadata, returning ErrEmpty when there is none.
+func (s *Store) Meta() (*Meta, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, metaFile))
+	if os.IsNotExist(err) {
+		return nil, ErrEmpty
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read cache metadata: %w", err)
+	}
+	var m Meta
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse cache metadata: %w", err)
+	}
+	return &m, nil
+}
+
+// Stale reports whether m is older than the store's TTL.
+func (s *Store) Stale(m *Meta) bool {
+	return time.Since(m.Fetched) >= s.TTL
+}
+
+// Load reads the cached dataset.
+func (s *Store) Load() (*Dataset, *Meta, error) {
+	m, err := s.Meta()
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(filepath.Join(s.Dir, archiveFile))
+	if os.IsNotExist(err) {
+		return nil, nil, ErrEmpty
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("open cached templates: %w", err)
+	}
+	defer f.Close()
+	d, err := ParseArchive(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cached templates: %w", err)
+	}
+	return d, m, nil
+}
+
+// Refresh revalidates the cache against c's server, downloading the archive
+// only when the server's dataset differs from the cached one. It reports
+// whether the cached templates changed.
+func (s *Store) Refresh(c *api.Client) (*Meta, bool, error) {
+	var etag string
+	if m, err := s.Meta(); err == nil {
+		if _, statErr := os.Stat(filepath.Join(s.Dir, archiveFile)); statErr == nil {
+			etag = m.ETag
+		}
+	}
+	data, newETag, err := c.TemplatesArchive(etag)
+	if err != nil {
+		return nil, false, err
+	}
+
+	m := &Meta{Server: c.BaseURL, ETag: newETag, Fetched: time.Now().UTC()}
+	changed := data != nil
+	if changed {
+		d, err := ParseArchive(bytes.NewReader(data))
+		if err != nil {
+			return nil, false, fmt.Errorf("templates archive from %s: %w", c.BaseURL, err)
+		}
+		m.Count = d.Count()
+		if err := s.write(archiveFile, data); err != nil {
+			return nil, false, err
+		}
+	} else if old, err := s.Meta(); err == nil {
+		m.Count = old.Count
+	}
+	body, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, false, err
+	}
+	if err := s.write(metaFile, body); err != nil {
+		return nil, false, err
+	}
+	return m, changed, nil
+}
+
+// write replaces name in the cache directory atomically, so an interrupted
+// refresh leaves the previous copy intact.
+func (s *Store) write(name string, data []byte) error {
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.Dir, name+".*")
+	if err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.Dir, name)); err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+	return nil
+}
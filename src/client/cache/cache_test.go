@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apimgr/gitignore/src/client/api"
+)
+
+// newArchive builds a templates.tar.gz the way the server does: flat entry
+// names with the category in a PAX record.
+func newArchive(t *testing.T, templates map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range templates {
+		hdr := &tar.Header{
+			Name:       name + ".gitignore",
+			Mode:       0o644,
+			Size:       int64(len(content)),
+			PAXRecords: map[string]string{categoryRecord: "Root"},
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// archiveServer serves archive under etag at /api/v1/templates.tar.gz,
+// answering a matching If-None-Match with 304, and counts full downloads.
+type archiveServer struct {
+	*httptest.Server
+	etag      atomic.Value
+	archive   atomic.Value
+	downloads atomic.Int32
+}
+
+func newArchiveServer(t *testing.T, etag string, archive []byte) *archiveServer {
+	t.Helper()
+	s := &archiveServer{}
+	s.set(etag, archive)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/templates.tar.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		etag := s.etag.Load().(string)
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		s.downloads.Add(1)
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Write(s.archive.Load().([]byte))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *archiveServer) set(etag string, archive []byte) {
+	s.etag.Store(etag)
+	s.archive.Store(archive)
+}
+
+// TestStore verifies that the cache reports ErrEmpty before the first
+// refresh, reuses its copy on 304, replaces it on 200, and keeps serving it,
+// stale, once the server is unreachable.
+func TestStore(t *testing.T) {
+	srv := newArchiveServer(t, `"v1"`, newArchive(t, map[string]string{
+		"Go":   "*.exe\n",
+		"Node": "node_modules/\n",
+	}))
+	c := api.New(srv.URL)
+	dir := t.TempDir()
+	s := New(dir, time.Hour)
+
+	if _, err := s.Meta(); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("Meta before refresh: err = %v, want ErrEmpty", err)
+	}
+	if _, _, err := s.Load(); !errors.Is(err, ErrEmpty) {
+		t.Fatalf("Load before refresh: err = %v, want ErrEmpty", err)
+	}
+
+	m, changed, err := s.Refresh(c)
+	if err != nil || !changed || m.ETag != `"v1"` || m.Count != 2 || m.Server != srv.URL {
+		t.Fatalf("first Refresh = %+v, %v, %v; want v1 with 2 templates", m, changed, err)
+	}
+	if s.Stale(m) {
+		t.Error("a just-refreshed copy is stale")
+	}
+
+	// An unchanged dataset is revalidated, not downloaded again.
+	m, changed, err = s.Refresh(c)
+	if err != nil || changed || m.ETag != `"v1"` || m.Count != 2 {
+		t.Fatalf("Refresh on 304 = %+v, %v, %v; want the v1 copy kept", m, changed, err)
+	}
+	if n := srv.downloads.Load(); n != 1 {
+		t.Errorf("archive downloaded %d times, want 1", n)
+	}
+
+	// A changed dataset replaces the copy.
+	srv.set(`"v2"`, newArchive(t, map[string]string{
+		"Go":   "*.exe\n*.test\n",
+		"Node": "node_modules/\n",
+		"Zig":  "zig-out/\n",
+	}))
+	m, changed, err = s.Refresh(c)
+	if err != nil || !changed || m.ETag != `"v2"` || m.Count != 3 {
+		t.Fatalf("Refresh on 200 = %+v, %v, %v; want v2 with 3 templates", m, changed, err)
+	}
+	d, _, err := s.Load()
+	if err != nil || d.Count() != 3 {
+		t.Fatalf("Load after replace: %v templates, %v; want 3", d, err)
+	}
+
+	// With the server gone, refreshing fails but the copy is still served,
+	// and once past its TTL it is reported stale.
+	srv.Close()
+	if _, _, err := s.Refresh(c); err == nil || !api.Unreachable(err) {
+		t.Fatalf("Refresh with the server down: err = %v, want unreachable", err)
+	}
+	stale := New(dir, 0)
+	d, m, err = stale.Load()
+	if err != nil || d.Count() != 3 || m.ETag != `"v2"` {
+		t.Fatalf("Load with the server down = %v, %+v, %v; want the v2 copy", d, m, err)
+	}
+	if !stale.Stale(m) {
+		t.Error("copy past its TTL is not stale")
+	}
+}
@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apimgr/gitignore/src/client/api"
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// categoryRecord is the PAX record in which the server names each archive
+// entry's category. Archives from servers predating it put every template in
+// "Root".
+const categoryRecord = "GITIGNORE.category"
+
+// pathRecord is the PAX record giving each archive entry's path in the
+// dataset. Without it, the path is derived from the category.
+const pathRecord = "GITIGNORE.path"
+
+// NotFoundError is returned for a template or category the dataset lacks.
+type NotFoundError struct {
+	Err error
+}
+
+func (e *NotFoundError) Error() string { return e.Err.Error() }
+
+func (e *NotFoundError) Unwrap() error { return e.Err }
+
+// Dataset answers CLI commands without the server. It wraps the server's own
+// template.Manager, so lookups, search, combine and the per-template
+// description and tags match the server byte for byte. Only template.New
+// links the server's embedded dataset into a binary, and nothing here calls
+// it.
+type Dataset struct {
+	m *template.Manager
+}
+
+// NewDataset returns a dataset answering from m.
+func NewDataset(m *template.Manager) *Dataset {
+	return &Dataset{m: m}
+}
+
+// ParseArchive reads a templates.tar.gz archive.
+func ParseArchive(r io.Reader) (*Dataset, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("decompress archive: %w", err)
+	}
+	defer gz.Close()
+
+	var templates []*template.Template
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read archive: %w", err)
+		}
+		fileName := filepath.Base(hdr.Name)
+		if hdr.Typeflag != tar.TypeReg || !strings.HasSuffix(fileName, ".gitignore") {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", hdr.Name, err)
+		}
+		path := hdr.PAXRecords[pathRecord]
+		if path == "" {
+			path = fileName
+			if category := hdr.PAXRecords[categoryRecord]; category != "" && category != "Root" {
+				path = category + "/" + fileName
+			}
+		}
+		templates = append(templates, template.NewTemplate(path, string(content)))
+	}
+	if len(templates) == 0 {
+		return nil, errors.New("archive contains no templates")
+	}
+	return NewDataset(template.FromTemplates(templates, template.DatasetVersion(templates))), nil
+}
+
+// Count returns the number of templates.
+func (d *Dataset) Count() int {
+	return d.m.Count()
+}
+
+// Version returns the dataset's content hash, the version the server
+// reports for the same templates.
+func (d *Dataset) Version() string {
+	return d.m.Version()
+}
+
+// List returns every template name.
+func (d *Dataset) List() []string {
+	return d.m.List()
+}
+
+// Search returns the names of the templates matching query.
+func (d *Dataset) Search(query string) []string {
+	return names(d.m.Search(query))
+}
+
+// Categories returns every category name.
+func (d *Dataset) Categories() []string {
+	return d.m.GetCategories()
+}
+
+// CategoryTemplates returns the names of the templates in category.
+func (d *Dataset) CategoryTemplates(category string) ([]string, error) {
+	templates := d.m.GetByCategory(category)
+	if len(templates) == 0 {
+		return nil, &NotFoundError{Err: fmt.Errorf("category not found: %s", category)}
+	}
+	return names(templates), nil
+}
+
+// Get returns a template by name or dataset path.
+func (d *Dataset) Get(name string) (*api.Template, error) {
+	tmpl, err := d.m.Get(name)
+	if err != nil {
+		return nil, &NotFoundError{Err: err}
+	}
+	out := api.Template(*tmpl)
+	return &out, nil
+}
+
+// Suggest returns up to limit template names close to name, best first.
+func (d *Dataset) Suggest(name string, limit int) []string {
+	return d.m.Suggest(name, limit)
+}
+
+// Combine merges the named templates in order, trimming the names as the
+// server's combine endpoint does.
+func (d *Dataset) Combine(names []string) (string, error) {
+	trimmed := make([]string, len(names))
+	for i, name := range names {
+		trimmed[i] = strings.TrimSpace(name)
+	}
+	content, err := d.m.Combine(trimmed)
+	if err != nil {
+		return "", &NotFoundError{Err: err}
+	}
+	return content, nil
+}
+
+// Stats returns the template statistics in the shape the API client decodes
+// them, so both print identically.
+func (d *Dataset) Stats() (map[string]interface{}, error) {
+	body, err := json.Marshal(d.m.Stats())
+	if err != nil {
+		return nil, err
+	}
+	var stats map[string]interface{}
+	if err := json.Unmarshal(body, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// names returns the names of templates, sorted.
+func names(templates []*template.Template) []string {
+	out := make([]string, len(templates))
+	for i, tmpl := range templates {
+		out[i] = tmpl.Name
+	}
+	sort.Strings(out)
+	return out
+}
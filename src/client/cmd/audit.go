@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apimgr/gitignore/src/client/api"
+	"github.com/apimgr/gitignore/src/client/audit"
+	"github.com/apimgr/gitignore/src/client/cache"
+	"github.com/apimgr/gitignore/src/client/output"
+)
+
+// CmdAudit implements `gitignore-cli audit [--fix | --print-fix] [--sarif]
+// [DIR]`: it reports the files committed to the repository at DIR that its
+// .gitignore, or the templates its contents call for, would ignore. It exits
+// non-zero when there are any, so it can gate CI, unless --fix resolved
+// them.
+func CmdAudit(c *api.Client, p *output.Printer, format string, args []string) int {
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fix := fs.Bool("fix", false, "Untrack the files and append the missing templates")
+	printFix := fs.Bool("print-fix", false, "Print the commands --fix would run")
+	sarif := fs.Bool("sarif", false, "Write a SARIF 2.1.0 report")
+	if err := fs.Parse(args); err != nil {
+		p.Error("audit: %v", err)
+		return output.ExitUsage
+	}
+	if *fix && *printFix {
+		p.Error("%s", tr(c, "cli.audit_flags"))
+		return output.ExitUsage
+	}
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+
+	root, err := audit.Root(dir)
+	if err != nil {
+		p.Error("%s", tr(c, "cli.audit_not_repo", "path", dir, "error", err.Error()))
+		return output.ExitGeneral
+	}
+	tracked, err := audit.Tracked(root)
+	if err != nil {
+		p.Error("%v", err)
+		return output.ExitGeneral
+	}
+
+	// Marker directories such as .idea/ usually are not tracked, so the
+	// work tree's top level is searched as well.
+	markerPaths := append([]string(nil), tracked...)
+	if entries, err := os.ReadDir(root); err == nil {
+		for _, e := range entries {
+			if e.IsDir() {
+				markerPaths = append(markerPaths, e.Name()+"/")
+			} else {
+				markerPaths = append(markerPaths, e.Name())
+			}
+		}
+	}
+	recs := audit.Recommend(markerPaths)
+
+	gitignores := make(map[string]string)
+	for _, f := range append(tracked, ".gitignore") {
+		if filepath.Base(f) != ".gitignore" {
+			continue
+		}
+		if data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(f))); err == nil {
+			gitignores[f] = string(data)
+		}
+	}
+
+	templates, err := auditTemplates(c, p, recs)
+	if err != nil {
+		return handleAPIError(c, err, p)
+	}
+	rep := audit.Run(tracked, gitignores, recs, templates)
+
+	switch {
+	case *sarif:
+		data, err := rep.SARIF(BinaryName, api.Version)
+		if err != nil {
+			p.Error("%v", err)
+			return output.ExitGeneral
+		}
+		fmt.Println(string(data))
+	case format == "json":
+		enc, _ := json.MarshalIndent(rep, "", "  ")
+		fmt.Println(string(enc))
+	case format == "table":
+		var rows [][]string
+		for _, g := range rep.Groups {
+			for _, f := range g.Paths {
+				rows = append(rows, []string{fmt.Sprintf("%s:%d %s", g.Source, g.Line, g.Pattern), f})
+			}
+		}
+		fmt.Print(output.FormatTable([]string{tr(c, "cli.header_rule"), tr(c, "cli.header_file")}, rows))
+	default:
+		printAuditText(c, p, rep)
+	}
+
+	files := rep.Files()
+	switch {
+	case *printFix:
+		if len(files) > 0 {
+			fmt.Println("git rm --cached -- " + shellJoin(files))
+		}
+		if len(rep.Missing) > 0 {
+			fmt.Printf("%s %s >> .gitignore\n", BinaryName, shellJoin(rep.Missing))
+		}
+	case *fix:
+		return auditFix(c, p, root, files, rep.Missing)
+	}
+	if len(files) > 0 {
+		return output.ExitGeneral
+	}
+	return output.ExitSuccess
+}
+
+// auditTemplates fetches the recommended templates' content. A template the
+// server does not have is skipped with a warning.
+func auditTemplates(c *api.Client, p *output.Printer, recs []audit.Recommendation) (map[string]string, error) {
+	templates := make(map[string]string)
+	if len(recs) == 0 {
+		return templates, nil
+	}
+	var skipped []audit.Recommendation
+	fetch := func(get func(string) (*api.Template, error)) error {
+		clear(templates)
+		skipped = skipped[:0]
+		for _, rec := range recs {
+			tmpl, err := get(rec.Template)
+			var apiErr *api.APIError
+			var notFound *cache.NotFoundError
+			switch {
+			case err == nil:
+				templates[rec.Template] = tmpl.Content
+			case errors.As(err, &notFound), errors.As(err, &apiErr) && apiErr.Status == 404:
+				skipped = append(skipped, rec)
+			default:
+				return err
+			}
+		}
+		return nil
+	}
+	err := withCache(c, p, func() error {
+		return fetch(c.GetTemplate)
+	}, func(d *cache.Dataset) error {
+		return fetch(d.Get)
+	})
+	for _, rec := range skipped {
+		p.Warn("%s", tr(c, "cli.audit_template_skipped", "template", rec.Template, "marker", rec.Marker))
+	}
+	return templates, err
+}
+
+// printAuditText prints the findings grouped by the rule responsible, then
+// a summary.
+func printAuditText(c *api.Client, p *output.Printer, rep *audit.Report) {
+	for _, g := range rep.Groups {
+		fmt.Printf("%s %s\n", p.Bold(fmt.Sprintf("%s:%d", g.Source, g.Line)), p.Cyan(g.Pattern))
+		for _, f := range g.Paths {
+			fmt.Println("  " + f)
+		}
+	}
+	if files := rep.Files(); len(files) > 0 {
+		fmt.Println()
+		fmt.Println(p.Yellow(tr(c, "cli.audit_summary", "count", len(files))))
+	} else {
+		fmt.Println(p.Green(tr(c, "cli.audit_clean")))
+	}
+	if len(rep.Missing) > 0 {
+		fmt.Println(tr(c, "cli.audit_missing", "templates", strings.Join(rep.Missing, ", ")))
+	}
+}
+
+// auditFix untracks files and appends the missing templates to the root
+// .gitignore, reporting each step on stderr.
+func auditFix(c *api.Client, p *output.Printer, root string, files, missing []string) int {
+	if len(files) > 0 {
+		if err := audit.RemoveCached(root, files); err != nil {
+			p.Error("%v", err)
+			return output.ExitGeneral
+		}
+		p.Info("%s", tr(c, "cli.audit_untracked", "count", len(files)))
+	}
+	if len(missing) == 0 {
+		return output.ExitSuccess
+	}
+	var content string
+	err := withCache(c, p, func() (err error) {
+		content, err = c.Combine(missing)
+		return err
+	}, func(d *cache.Dataset) (err error) {
+		content, err = d.Combine(missing)
+		return err
+	})
+	if err != nil {
+		return handleAPIError(c, err, p)
+	}
+	path := filepath.Join(root, ".gitignore")
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		p.Error("%v", err)
+		return output.ExitGeneral
+	}
+	if len(existing) > 0 {
+		prefix := "\n"
+		if !strings.HasSuffix(string(existing), "\n") {
+			prefix = "\n\n"
+		}
+		content = prefix + content
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		p.Error("%v", err)
+		return output.ExitGeneral
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		p.Error("%v", err)
+		return output.ExitGeneral
+	}
+	if err := f.Close(); err != nil {
+		p.Error("%v", err)
+		return output.ExitGeneral
+	}
+	p.Info("%s", tr(c, "cli.audit_appended", "templates", strings.Join(missing, ", "), "file", path))
+	return output.ExitSuccess
+}
+
+// shellJoin quotes each of words for a POSIX shell where needed.
+func shellJoin(words []string) string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		if w != "" && strings.IndexFunc(w, func(r rune) bool {
+			return !(r == '/' || r == '.' || r == '-' || r == '_' || r == '+' ||
+				(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'))
+		}) < 0 {
+			quoted[i] = w
+		} else {
+			quoted[i] = "'" + strings.ReplaceAll(w, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
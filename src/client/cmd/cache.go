@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"errors"
+	"time"
+
+	"github.com/apimgr/gitignore/src/client/api"
+	"github.com/apimgr/gitignore/src/client/cache"
+	"github.com/apimgr/gitignore/src/client/output"
+)
+
+// Cache is the offline template cache, set once at startup. Commands fall
+// back to it when the server is unreachable. Nil disables the fallback.
+var Cache *cache.Store
+
+// Embedded is the dataset compiled into embedded builds (build tag
+// "embedded"), nil otherwise. With no server configured every command is
+// answered from it; otherwise it is the last resort when neither the server
+// nor the cache can answer.
+var Embedded *cache.Dataset
+
+// Offline answers every command locally, never contacting the server
+// (--offline).
+var Offline bool
+
+// withCache runs live against the server, or local against a local dataset
+// when offline, when no server is configured in an embedded build, or when
+// no configured server can be reached or offers the command. After a successful live run it revalidates a
+// stale cache so it is current the next time the network is gone.
+func withCache(c *api.Client, p *output.Printer, live func() error, local func(*cache.Dataset) error) error {
+	if c.BaseURL == "" && Embedded != nil {
+		return local(Embedded)
+	}
+	if Offline {
+		d, err := localDataset(c, p, nil)
+		if err != nil {
+			return err
+		}
+		return local(d)
+	}
+
+	err := live()
+	if err == nil {
+		if Cache != nil {
+			if m, metaErr := Cache.Meta(); metaErr != nil || Cache.Stale(m) {
+				// Best effort: the command already succeeded.
+				_, _, _ = Cache.Refresh(c)
+			}
+		}
+		return nil
+	}
+	var unsupported *api.UnsupportedError
+	if !api.Unreachable(err) && !errors.As(err, &unsupported) {
+		return err
+	}
+	d, localErr := localDataset(c, p, err)
+	if localErr != nil {
+		return err
+	}
+	return local(d)
+}
+
+// localDataset returns the dataset to answer from without the server: the
+// cache, or failing that the embedded dataset. cause is the error that made
+// the server unusable, nil under --offline; it is reported as a warning
+// before the fallback is used.
+func localDataset(c *api.Client, p *output.Printer, cause error) (*cache.Dataset, error) {
+	err := cache.ErrEmpty
+	if Cache != nil {
+		var d *cache.Dataset
+		var m *cache.Meta
+		if d, m, err = Cache.Load(); err == nil {
+			if cause != nil {
+				p.Warn("%s", tr(c, "cli.cache_fallback", "error", cause.Error(), "age", cacheAge(m)))
+			}
+			if Cache.Stale(m) {
+				p.Warn("%s", tr(c, "cli.cache_stale", "age", cacheAge(m), "ttl", Cache.TTL.String()))
+			}
+			return d, nil
+		}
+	}
+	if Embedded != nil {
+		if cause != nil {
+			p.Warn("%s", tr(c, "cli.embedded_fallback", "error", cause.Error(), "version", Embedded.Version()))
+		}
+		return Embedded, nil
+	}
+	return nil, err
+}
+
+// cacheAge is how long ago the cache was last revalidated, to the minute.
+func cacheAge(m *cache.Meta) string {
+	return time.Since(m.Fetched).Round(time.Minute).String()
+}
+
+// CmdRefresh implements --refresh: it revalidates the template cache against
+// the server and reports the outcome on stderr, leaving stdout to whatever
+// command follows.
+func CmdRefresh(c *api.Client, p *output.Printer) int {
+	m, changed, err := Cache.Refresh(c)
+	if err != nil {
+		return handleAPIError(c, err, p)
+	}
+	key := "cli.cache_current"
+	if changed {
+		key = "cli.cache_updated"
+	}
+	p.Info("%s", tr(c, key, "count", m.Count, "etag", m.ETag))
+	return output.ExitSuccess
+}
@@ -2,15 +2,24 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"sort"
 	"strings"
 
 	"github.com/apimgr/gitignore/src/client/api"
+	"github.com/apimgr/gitignore/src/client/cache"
 	"github.com/apimgr/gitignore/src/client/output"
+	"github.com/apimgr/gitignore/src/common/i18n"
 )
 
+// tr translates a CLI message into the client's output language (--lang,
+// then cli.yml, then the environment).
+func tr(c *api.Client, key string, args ...interface{}) string {
+	return i18n.TranslateFormat(c.Lang, key, args...)
+}
+
 // printNames renders a []string per the requested --output format.
 func printNames(names []string, format string, p *output.Printer, tableHeader string) int {
 	switch format {
@@ -33,55 +42,92 @@ func printNames(names []string, format string, p *output.Printer, tableHeader st
 
 // CmdList implements `gitignore-cli list`.
 func CmdList(c *api.Client, p *output.Printer, format string) int {
-	names, err := c.List()
+	var names []string
+	err := withCache(c, p, func() (err error) {
+		names, err = c.List()
+		return err
+	}, func(d *cache.Dataset) error {
+		names = d.List()
+		return nil
+	})
 	if err != nil {
-		return handleAPIError(err, p)
+		return handleAPIError(c, err, p)
 	}
 	sort.Strings(names)
-	return printNames(names, format, p, "Template")
+	return printNames(names, format, p, tr(c, "cli.header_template"))
 }
 
 // CmdSearch implements `gitignore-cli search QUERY`.
 func CmdSearch(c *api.Client, p *output.Printer, format, query string) int {
 	if strings.TrimSpace(query) == "" {
-		p.Error("search requires a query, e.g. %s search golang", binaryName())
+		p.Error("%s", tr(c, "cli.search_requires_query", "example", binaryName()+" search golang"))
 		return output.ExitUsage
 	}
-	names, err := c.Search(query)
+	var names []string
+	err := withCache(c, p, func() (err error) {
+		names, err = c.Search(query)
+		return err
+	}, func(d *cache.Dataset) error {
+		names = d.Search(query)
+		return nil
+	})
 	if err != nil {
-		return handleAPIError(err, p)
+		return handleAPIError(c, err, p)
 	}
-	return printNames(names, format, p, "Template")
+	sort.Strings(names)
+	return printNames(names, format, p, tr(c, "cli.header_template"))
 }
 
 // CmdCategories implements `gitignore-cli categories`.
 func CmdCategories(c *api.Client, p *output.Printer, format string) int {
-	cats, err := c.Categories()
+	var cats []string
+	err := withCache(c, p, func() (err error) {
+		cats, err = c.Categories()
+		return err
+	}, func(d *cache.Dataset) error {
+		cats = d.Categories()
+		return nil
+	})
 	if err != nil {
-		return handleAPIError(err, p)
+		return handleAPIError(c, err, p)
 	}
 	sort.Strings(cats)
-	return printNames(cats, format, p, "Category")
+	return printNames(cats, format, p, tr(c, "cli.header_category"))
 }
 
 // CmdCategory implements `gitignore-cli category NAME`.
 func CmdCategory(c *api.Client, p *output.Printer, format, name string) int {
 	if strings.TrimSpace(name) == "" {
-		p.Error("category requires a name, e.g. %s category editors", binaryName())
+		p.Error("%s", tr(c, "cli.category_requires_name", "example", binaryName()+" category Global"))
 		return output.ExitUsage
 	}
-	names, err := c.CategoryTemplates(name)
+	var names []string
+	err := withCache(c, p, func() (err error) {
+		names, err = c.CategoryTemplates(name)
+		return err
+	}, func(d *cache.Dataset) (err error) {
+		names, err = d.CategoryTemplates(name)
+		return err
+	})
 	if err != nil {
-		return handleAPIError(err, p)
+		return handleAPIError(c, err, p)
 	}
-	return printNames(names, format, p, "Template")
+	sort.Strings(names)
+	return printNames(names, format, p, tr(c, "cli.header_template"))
 }
 
 // CmdStats implements `gitignore-cli stats`.
 func CmdStats(c *api.Client, p *output.Printer, format string) int {
-	stats, err := c.Stats()
+	var stats map[string]interface{}
+	err := withCache(c, p, func() (err error) {
+		stats, err = c.Stats()
+		return err
+	}, func(d *cache.Dataset) (err error) {
+		stats, err = d.Stats()
+		return err
+	})
 	if err != nil {
-		return handleAPIError(err, p)
+		return handleAPIError(c, err, p)
 	}
 	if format == "json" {
 		enc, _ := json.MarshalIndent(stats, "", "  ")
@@ -102,12 +148,19 @@ func CmdStats(c *api.Client, p *output.Printer, format string) int {
 // CmdGetTemplate implements `gitignore-cli get NAME` / `template NAME`.
 func CmdGetTemplate(c *api.Client, p *output.Printer, format, name string) int {
 	if strings.TrimSpace(name) == "" {
-		p.Error("get requires a template name, e.g. %s get Go", binaryName())
+		p.Error("%s", tr(c, "cli.get_requires_name", "command", "get", "example", binaryName()+" get Go"))
 		return output.ExitUsage
 	}
-	tmpl, err := c.GetTemplate(name)
+	var tmpl *api.Template
+	err := withCache(c, p, func() (err error) {
+		tmpl, err = c.GetTemplate(name)
+		return err
+	}, func(d *cache.Dataset) (err error) {
+		tmpl, err = d.Get(name)
+		return err
+	})
 	if err != nil {
-		return handleAPIError(err, p)
+		return handleAPIError(c, err, p)
 	}
 	if format == "json" {
 		enc, _ := json.MarshalIndent(tmpl, "", "  ")
@@ -126,12 +179,19 @@ func CmdGetTemplate(c *api.Client, p *output.Printer, format, name string) int {
 // "gitignore-cli Go Node > .gitignore".
 func CmdCombine(c *api.Client, p *output.Printer, format string, names []string) int {
 	if len(names) == 0 {
-		p.Error("combine requires one or more template names, e.g. %s Go Node", binaryName())
+		p.Error("%s", tr(c, "cli.combine_requires_names", "example", binaryName()+" Go Node"))
 		return output.ExitUsage
 	}
-	content, err := c.Combine(names)
+	var content string
+	err := withCache(c, p, func() (err error) {
+		content, err = c.Combine(names)
+		return err
+	}, func(d *cache.Dataset) (err error) {
+		content, err = d.Combine(names)
+		return err
+	})
 	if err != nil {
-		return handleAPIError(err, p)
+		return handleAPIError(c, err, p)
 	}
 	if format == "json" {
 		enc, _ := json.MarshalIndent(map[string]interface{}{
@@ -149,15 +209,38 @@ func CmdCombine(c *api.Client, p *output.Printer, format string, names []string)
 }
 
 // handleAPIError maps an API/connection error to a CLI exit code and prints
-// a PART-32-style actionable message.
-func handleAPIError(err error, p *output.Printer) int {
+// a PART-32-style actionable message. Server messages arrive already
+// localized (the client sends Accept-Language).
+func handleAPIError(c *api.Client, err error, p *output.Printer) int {
+	var notFound *cache.NotFoundError
+	if errors.As(err, &notFound) {
+		p.Error("%s", tr(c, "cli.not_found", "message", notFound.Error()))
+		return output.ExitNotFound
+	}
+	if errors.Is(err, cache.ErrEmpty) {
+		p.Error("%s", tr(c, "cli.cache_empty"))
+		return output.ExitConnection
+	}
+	var unsupported *api.UnsupportedError
+	if errors.As(err, &unsupported) {
+		p.Error("%s", tr(c, "cli.unsupported", "operation", unsupported.Op, "server", unsupported.URL, "backend", unsupported.Kind))
+		return output.ExitGeneral
+	}
+	var undetected *api.DetectError
+	if errors.As(err, &undetected) {
+		p.Error("%s", tr(c, "cli.undetected", "server", undetected.URL))
+		return output.ExitConfig
+	}
 	if apiErr, ok := err.(*api.APIError); ok {
 		switch apiErr.Status {
 		case 404:
-			p.Error("resource not found: %s", apiErr.Message)
+			p.Error("%s", tr(c, "cli.not_found", "message", apiErr.Message))
+			if len(apiErr.Suggestions) > 0 {
+				fmt.Fprintln(os.Stderr, "  "+tr(c, "cli.did_you_mean", "names", strings.Join(apiErr.Suggestions, ", ")))
+			}
 			return output.ExitNotFound
 		case 401, 403:
-			p.Error("authentication failed: %s", apiErr.Message)
+			p.Error("%s", tr(c, "cli.auth_failed", "message", apiErr.Message))
 			return output.ExitAuth
 		default:
 			p.Error("%s", apiErr.Message)
@@ -165,8 +248,8 @@ func handleAPIError(err error, p *output.Printer) int {
 		}
 	}
 	p.Error("%s", err.Error())
-	fmt.Fprintln(os.Stderr, "  Check your network connection and server address.")
-	fmt.Fprintln(os.Stderr, "  Use --server to specify a different server.")
+	fmt.Fprintln(os.Stderr, "  "+tr(c, "cli.check_network"))
+	fmt.Fprintln(os.Stderr, "  "+tr(c, "cli.use_server_flag"))
 	return output.ExitConnection
 }
 
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apimgr/gitignore/src/client/api"
+	"github.com/apimgr/gitignore/src/client/audit"
+	"github.com/apimgr/gitignore/src/client/config"
+	"github.com/apimgr/gitignore/src/client/output"
+)
+
+// HookTemplates is cli.yml's hook.templates, set once at startup: the
+// templates the pre-commit hook checks when the repository names none.
+var HookTemplates []string
+
+// CmdHook implements `gitignore-cli hook install|uninstall|run [FILE...]`.
+// Only run needs templates; install and uninstall never contact the server.
+func CmdHook(c *api.Client, p *output.Printer, args []string) int {
+	if len(args) == 0 {
+		p.Error("%s", tr(c, "cli.hook_usage", "example", binaryName()+" hook install"))
+		return output.ExitUsage
+	}
+	root, err := audit.Root(".")
+	if err != nil {
+		p.Error("%s", tr(c, "cli.audit_not_repo", "path", ".", "error", err.Error()))
+		return output.ExitGeneral
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "install":
+		binary, err := os.Executable()
+		if err != nil {
+			binary = BinaryName
+		}
+		path, chained, err := audit.InstallHook(root, binary)
+		if err != nil {
+			p.Error("%v", err)
+			return output.ExitGeneral
+		}
+		p.Info("%s", tr(c, "cli.hook_installed", "path", path))
+		if chained {
+			p.Info("%s", tr(c, "cli.hook_chained"))
+		}
+		return output.ExitSuccess
+	case "uninstall":
+		path, restored, err := audit.UninstallHook(root)
+		var foreign *audit.ForeignHookError
+		switch {
+		case errors.Is(err, audit.ErrHookNotInstalled):
+			p.Error("%s", tr(c, "cli.hook_not_installed", "path", path))
+			return output.ExitNotFound
+		case errors.As(err, &foreign):
+			p.Error("%s", tr(c, "cli.hook_foreign", "path", foreign.Path))
+			return output.ExitGeneral
+		case err != nil:
+			p.Error("%v", err)
+			return output.ExitGeneral
+		}
+		p.Info("%s", tr(c, "cli.hook_uninstalled", "path", path))
+		if restored {
+			p.Info("%s", tr(c, "cli.hook_restored"))
+		}
+		return output.ExitSuccess
+	case "run":
+		return hookRun(c, p, root, args[1:])
+	}
+	p.Error("%s", tr(c, "cli.hook_usage", "example", binaryName()+" hook install"))
+	return output.ExitUsage
+}
+
+// hookRun checks files, or with none given the staged files, against the
+// repository's .gitignore files and its hook templates, and fails when any
+// would be ignored. Files come as arguments from the pre-commit framework.
+// Templates that cannot be loaded are warned about and skipped: an
+// unreachable server must not stop every commit.
+func hookRun(c *api.Client, p *output.Printer, root string, files []string) int {
+	if len(files) == 0 {
+		staged, err := audit.Staged(root)
+		if err != nil {
+			p.Error("%v", err)
+			return output.ExitGeneral
+		}
+		files = staged
+	}
+	if len(files) == 0 {
+		return output.ExitSuccess
+	}
+
+	gitignores := make(map[string]string)
+	tracked, _ := audit.Tracked(root)
+	for _, f := range append(append(tracked, files...), ".gitignore") {
+		if filepath.Base(f) != ".gitignore" {
+			continue
+		}
+		if data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(f))); err == nil {
+			gitignores[f] = string(data)
+		}
+	}
+
+	names, source, err := hookTemplates(root, gitignores[".gitignore"])
+	if err != nil {
+		p.Warn("%v", err)
+	}
+	recs := make([]audit.Recommendation, len(names))
+	for i, name := range names {
+		recs[i] = audit.Recommendation{Template: name, Marker: source}
+	}
+	templates, err := auditTemplates(c, p, recs)
+	if err != nil {
+		p.Warn("%s", tr(c, "cli.hook_unavailable", "error", err.Error()))
+	}
+
+	rep := audit.Run(files, gitignores, recs, templates)
+	blocked := rep.Files()
+	if len(blocked) == 0 {
+		return output.ExitSuccess
+	}
+	p.Error("%s", tr(c, "cli.hook_blocked", "count", len(blocked)))
+	for _, g := range rep.Groups {
+		fmt.Fprintf(os.Stderr, "%s %s\n", p.Bold(fmt.Sprintf("%s:%d", g.Source, g.Line)), p.Cyan(g.Pattern))
+		for _, f := range g.Paths {
+			fmt.Fprintln(os.Stderr, "  "+f)
+		}
+	}
+	fmt.Fprintln(os.Stderr, tr(c, "cli.hook_bypass"))
+	return output.ExitGeneral
+}
+
+// hookTemplates returns the templates the hook checks and where they are
+// configured: the repository's .gitignore-cli.yml, else the "# Templates:"
+// header of its generated .gitignore, else cli.yml.
+func hookTemplates(root, gitignore string) ([]string, string, error) {
+	repo, err := config.LoadRepoConfig(root)
+	if repo != nil && len(repo.Hook.Templates) > 0 {
+		return repo.Hook.Templates, config.RepoConfigFile, err
+	}
+	if names := audit.HeaderTemplates(gitignore); len(names) > 0 {
+		return names, ".gitignore", err
+	}
+	return HookTemplates, "cli.yml", err
+}
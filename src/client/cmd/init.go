@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+
+	"github.com/apimgr/gitignore/src/client/api"
+	"github.com/apimgr/gitignore/src/client/audit"
+	"github.com/apimgr/gitignore/src/client/monorepo"
+	"github.com/apimgr/gitignore/src/client/output"
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// CmdInit implements `gitignore-cli init [--recursive] [--dry-run] [--yes]
+// [DIR]`: it detects the templates the project at DIR calls for and writes
+// its .gitignore. With --recursive every package directory below DIR gets
+// its own .gitignore for its language templates, and the root one keeps the
+// operating-system and editor templates and the rules all packages share.
+// The plan is shown as a tree with diffs and written once confirmed.
+// Rerunning updates the generated part of each file in place.
+func CmdInit(c *api.Client, p *output.Printer, format string, args []string) int {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	recursive := fs.Bool("recursive", false, "Plan a .gitignore per package directory")
+	dryRun := fs.Bool("dry-run", false, "Show the plan without writing it")
+	yes := fs.Bool("yes", false, "Write the plan without asking")
+	if err := fs.Parse(args); err != nil {
+		p.Error("init: %v", err)
+		return output.ExitUsage
+	}
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	root, err := audit.Root(dir)
+	if err != nil {
+		// Not a git repository yet: plan for the directory itself.
+		if root, err = filepath.Abs(dir); err != nil {
+			p.Error("%v", err)
+			return output.ExitGeneral
+		}
+	}
+
+	pkgs, editors, err := monorepo.Detect(root, *recursive)
+	if err != nil {
+		p.Error("%v", err)
+		return output.ExitGeneral
+	}
+	if *recursive && len(pkgs) == 0 {
+		p.Warn("%s", tr(c, "cli.init_no_packages", "path", root, "manifests", strings.Join(monorepo.Manifests, ", ")))
+	}
+
+	// Every template once, in the order the files list them.
+	var recs []audit.Recommendation
+	var global []string
+	seen := make(map[string]bool)
+	add := func(rec audit.Recommendation) {
+		if !seen[rec.Template] {
+			recs = append(recs, rec)
+			seen[rec.Template] = true
+		}
+	}
+	for _, name := range monorepo.DefaultGlobal {
+		add(audit.Recommendation{Template: name, Marker: ".gitignore"})
+		global = append(global, name)
+	}
+	for _, rec := range editors {
+		add(rec)
+		global = append(global, rec.Template)
+	}
+	for _, pkg := range pkgs {
+		for _, rec := range pkg.Templates {
+			add(rec)
+		}
+	}
+	contents, err := auditTemplates(c, p, recs)
+	if err != nil {
+		return handleAPIError(c, err, p)
+	}
+
+	files, err := monorepo.Plan(root, pkgs, global, contents)
+	if err != nil {
+		p.Error("%v", err)
+		return output.ExitGeneral
+	}
+	var changed []*monorepo.File
+	for _, f := range files {
+		if f.Changed() {
+			changed = append(changed, f)
+		}
+	}
+
+	if format == "json" {
+		type planned struct {
+			*monorepo.File
+			Changed bool   `json:"changed"`
+			Diff    string `json:"diff,omitempty"`
+		}
+		out := struct {
+			Root     string             `json:"root"`
+			Packages []monorepo.Package `json:"packages"`
+			Files    []planned          `json:"files"`
+		}{Root: root, Packages: pkgs, Files: []planned{}}
+		if out.Packages == nil {
+			out.Packages = []monorepo.Package{}
+		}
+		for _, f := range files {
+			out.Files = append(out.Files, planned{File: f, Changed: f.Changed(), Diff: initDiff(f)})
+		}
+		enc, _ := json.MarshalIndent(out, "", "  ")
+		fmt.Println(string(enc))
+	} else {
+		fmt.Print(monorepo.Tree(files, func(f *monorepo.File) string {
+			return initLabel(c, p, f)
+		}))
+		for _, f := range changed {
+			fmt.Println()
+			for _, line := range strings.Split(strings.TrimSuffix(initDiff(f), "\n"), "\n") {
+				switch {
+				case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+					fmt.Println(p.Bold(line))
+				case strings.HasPrefix(line, "+"):
+					fmt.Println(p.Green(line))
+				case strings.HasPrefix(line, "-"):
+					fmt.Println(p.Red(line))
+				case strings.HasPrefix(line, "@@"):
+					fmt.Println(p.Cyan(line))
+				default:
+					fmt.Println(line)
+				}
+			}
+		}
+	}
+
+	if len(changed) == 0 {
+		p.Info("%s", tr(c, "cli.init_up_to_date"))
+		return output.ExitSuccess
+	}
+	if *dryRun {
+		return output.ExitSuccess
+	}
+	if !*yes {
+		if format == "json" || !isatty.IsTerminal(os.Stdin.Fd()) {
+			p.Error("%s", tr(c, "cli.init_needs_yes"))
+			return output.ExitGeneral
+		}
+		fmt.Fprint(os.Stderr, tr(c, "cli.init_confirm", "count", len(changed))+" ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			p.Info("%s", tr(c, "cli.init_aborted"))
+			return output.ExitSuccess
+		}
+	}
+	for _, f := range changed {
+		if err := f.Write(root); err != nil {
+			p.Error("%v", err)
+			return output.ExitGeneral
+		}
+		p.Info("%s", tr(c, "cli.init_written", "path", f.Path))
+	}
+	return output.ExitSuccess
+}
+
+// initLabel describes a planned file in the plan tree: whether it is new,
+// updated or unchanged, its templates, and the rules hoisted into it.
+func initLabel(c *api.Client, p *output.Printer, f *monorepo.File) string {
+	var status string
+	switch {
+	case !f.Exists:
+		status = p.Green(tr(c, "cli.init_new"))
+	case f.Changed():
+		status = p.Yellow(tr(c, "cli.init_updated"))
+	default:
+		status = tr(c, "cli.init_unchanged")
+	}
+	label := status + "  " + strings.Join(f.Templates, ", ")
+	if len(f.Shared) > 0 {
+		label += " " + tr(c, "cli.init_shared", "count", len(f.Shared))
+	}
+	return label
+}
+
+// initDiff returns the unified diff a planned file's write would make.
+func initDiff(f *monorepo.File) string {
+	from := "a/" + f.Path
+	if !f.Exists {
+		from = "/dev/null"
+	}
+	return template.UnifiedDiff(from, "b/"+f.Path, f.Old, f.New)
+}
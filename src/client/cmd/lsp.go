@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+
+	"github.com/apimgr/gitignore/src/client/api"
+	"github.com/apimgr/gitignore/src/client/cache"
+	"github.com/apimgr/gitignore/src/client/lsp"
+	"github.com/apimgr/gitignore/src/client/output"
+)
+
+// CmdLSP implements `gitignore-cli lsp`: a language server for .gitignore
+// files speaking LSP over stdin/stdout. Its templates are the server's,
+// through the cache, so diagnostics and completions follow the server's
+// dataset; without a server it answers from the cache or the embedded
+// dataset. Messages go to stderr, which editors keep as the server's log.
+func CmdLSP(c *api.Client, p *output.Printer, args []string) int {
+	if len(args) > 0 && args[0] != "--stdio" {
+		p.Error("%s", tr(c, "cli.lsp_usage", "example", binaryName()+" lsp"))
+		return output.ExitUsage
+	}
+	d, err := lspDataset(c, p)
+	if err != nil {
+		p.Error("%s", tr(c, "lsp.no_dataset", "error", err.Error()))
+		return output.ExitGeneral
+	}
+	p.Info("%s", tr(c, "lsp.started", "count", d.Count(), "version", d.Version()))
+
+	err = lsp.New(d, c.Lang, api.Version).Run(os.Stdin, os.Stdout)
+	switch {
+	case errors.Is(err, lsp.ErrNoShutdown):
+		return output.ExitGeneral
+	case err != nil:
+		p.Error("%v", err)
+		return output.ExitGeneral
+	}
+	return output.ExitSuccess
+}
+
+// lspDataset returns the templates the language server answers from: the
+// server's, refreshed into the cache when there is one, else the local
+// dataset withCache would fall back to.
+func lspDataset(c *api.Client, p *output.Printer) (*cache.Dataset, error) {
+	if c.BaseURL == "" && Embedded != nil {
+		return Embedded, nil
+	}
+	if !Offline && c.BaseURL != "" {
+		if Cache != nil {
+			if _, _, err := Cache.Refresh(c); err != nil {
+				return localDataset(c, p, err)
+			}
+			if d, _, err := Cache.Load(); err == nil {
+				return d, nil
+			}
+		}
+		data, _, err := c.TemplatesArchive("")
+		if err != nil {
+			return localDataset(c, p, err)
+		}
+		return cache.ParseArchive(bytes.NewReader(data))
+	}
+	return localDataset(c, p, nil)
+}
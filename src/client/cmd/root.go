@@ -24,7 +24,16 @@ var BinaryName = "gitignore-cli"
 // template-name list for the combine smart-detection path.
 var knownCommands = map[string]bool{
 	"list": true, "search": true, "categories": true, "category": true,
-	"stats": true, "get": true, "template": true, "combine": true, "help": true,
+	"stats": true, "get": true, "template": true, "combine": true, "audit": true,
+	"hook": true, "init": true, "lsp": true, "help": true,
+}
+
+// ServerOptional reports whether args run a command that works with no
+// server configured: hook installs without one, and its check falls back to
+// cached templates and the repository's own .gitignore; lsp answers from
+// the cache.
+func ServerOptional(args []string) bool {
+	return len(args) > 0 && (strings.EqualFold(args[0], "hook") || strings.EqualFold(args[0], "lsp"))
 }
 
 // Dispatch routes positional args (post-flag-parsing) to the matching
@@ -52,7 +61,7 @@ func Dispatch(c *api.Client, p *output.Printer, format string, args []string) in
 		return CmdCategories(c, p, format)
 	case "category":
 		if len(rest) == 0 {
-			p.Error("category requires a name")
+			p.Error("%s", tr(c, "cli.category_requires_name", "example", binaryName()+" category Global"))
 			return output.ExitUsage
 		}
 		return CmdCategory(c, p, format, rest[0])
@@ -60,12 +69,20 @@ func Dispatch(c *api.Client, p *output.Printer, format string, args []string) in
 		return CmdStats(c, p, format)
 	case "get", "template":
 		if len(rest) == 0 {
-			p.Error("%s requires a template name", first)
+			p.Error("%s", tr(c, "cli.get_requires_name", "command", first, "example", binaryName()+" "+first+" Go"))
 			return output.ExitUsage
 		}
 		return CmdGetTemplate(c, p, format, rest[0])
 	case "combine":
 		return CmdCombine(c, p, format, rest)
+	case "audit":
+		return CmdAudit(c, p, format, rest)
+	case "hook":
+		return CmdHook(c, p, rest)
+	case "init":
+		return CmdInit(c, p, format, rest)
+	case "lsp":
+		return CmdLSP(c, p, rest)
 	case "help":
 		PrintHelp("dev")
 		return output.ExitSuccess
@@ -121,6 +138,9 @@ func printInteractiveHelp() {
 	fmt.Println("  get NAME             Print a single template")
 	fmt.Println("  combine NAME NAME.. Merge templates (or just: NAME NAME..)")
 	fmt.Println("  stats                Show server template statistics")
+	fmt.Println("  audit [DIR]          Find committed files that should be ignored")
+	fmt.Println("  hook install         Block commits of files that should be ignored")
+	fmt.Println("  init [--recursive]   Write the .gitignore files the project calls for")
 	fmt.Println("  quit                 Exit interactive mode")
 }
 
@@ -139,6 +159,16 @@ func PrintHelp(version string) {
 	fmt.Println("  get NAME | template NAME       Print a single template")
 	fmt.Println("  combine NAME...                Merge templates (default when args are bare names)")
 	fmt.Println("  stats                         Show server template statistics")
+	fmt.Println("  audit [--fix|--print-fix] [--sarif] [DIR]")
+	fmt.Println("                                Find committed files the .gitignore or recommended")
+	fmt.Println("                                templates would ignore; exits 1 if any")
+	fmt.Println("  hook install|uninstall|run    Manage the pre-commit hook that blocks commits of")
+	fmt.Println("                                files the configured templates would ignore")
+	fmt.Println("  init [--recursive] [--dry-run] [--yes] [DIR]")
+	fmt.Println("                                Detect the project's templates and write its .gitignore;")
+	fmt.Println("                                --recursive writes one per package directory")
+	fmt.Println("  lsp                           Run the .gitignore language server on stdin/stdout")
+	fmt.Println("                                for editors (VS Code, Neovim, Helix)")
 	fmt.Println()
 	fmt.Println("Flags:")
 	fmt.Println("-h, --help                             - Show help")
@@ -146,6 +176,8 @@ func PrintHelp(version string) {
 	fmt.Println("--shell completions [SHELL]            - Print shell completions (auto-detect if SHELL omitted)")
 	fmt.Println("--shell init [SHELL]                   - Print shell init command (auto-detect if SHELL omitted)")
 	fmt.Println("--shell help                           - Show shell integration help")
+	fmt.Println("--update [check|yes]                   - Check for or install a newer release (default: yes)")
+	fmt.Println("--update branch [stable|beta|daily]    - Show or set the update channel")
 	fmt.Println()
 	fmt.Println("--server URL                           - Server URL (default: from cli.yml)")
 	fmt.Println("--config NAME                          - Config profile name (default: cli)")
@@ -153,6 +185,8 @@ func PrintHelp(version string) {
 	fmt.Println("--debug                                - Debug output")
 	fmt.Println("--color {auto|yes|no}                  - Color output (default: auto)")
 	fmt.Println("--lang CODE                            - Language for output (default: auto)")
+	fmt.Println("--offline                              - Use cached templates only; never contact the server")
+	fmt.Println("--refresh                              - Revalidate the template cache first (alone: just refresh)")
 	fmt.Println()
 	fmt.Println("Shells: bash, zsh, fish, sh, dash, ksh, powershell, pwsh")
 	fmt.Println()
@@ -161,12 +195,21 @@ func PrintHelp(version string) {
 	fmt.Printf("  %s Go Node > .gitignore\n", BinaryName)
 	fmt.Printf("  %s search python\n", BinaryName)
 	fmt.Printf("  %s get Go --output json\n", BinaryName)
+	fmt.Printf("  %s audit --print-fix\n", BinaryName)
+	fmt.Printf("  %s init --recursive --dry-run\n", BinaryName)
 }
 
-// PrintVersion prints --version output. Extended server info is appended
-// when reachable (best-effort; a failed probe is silent).
+// PrintVersion prints --version output. The source line tells embedded
+// builds, which carry their own dataset, from server-only ones. Extended
+// server info is appended when reachable (best-effort; a failed probe is
+// silent).
 func PrintVersion(version, commit, buildDate string, c *api.Client) {
 	fmt.Printf("%s %s (%s) built %s\n", BinaryName, version, commit, buildDate)
+	if Embedded != nil {
+		fmt.Printf("Source: embedded (dataset %s, %d templates)\n", Embedded.Version(), Embedded.Count())
+	} else {
+		fmt.Println("Source: server")
+	}
 	if c == nil {
 		return
 	}
@@ -7,7 +7,7 @@ import (
 )
 
 // commandWords lists the CLI's subcommands for shell completion generation.
-var commandWords = []string{"list", "search", "categories", "category", "stats", "get", "template", "combine", "help"}
+var commandWords = []string{"list", "search", "categories", "category", "stats", "get", "template", "combine", "audit", "hook", "init", "lsp", "help"}
 
 // DetectShell extracts a shell name from $SHELL (e.g. "/bin/zsh" -> "zsh"),
 // defaulting to "bash" when unset.
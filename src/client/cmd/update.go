@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+
+	"github.com/apimgr/gitignore/src/client/api"
+	"github.com/apimgr/gitignore/src/client/config"
+	"github.com/apimgr/gitignore/src/client/output"
+	clipath "github.com/apimgr/gitignore/src/client/path"
+	"github.com/apimgr/gitignore/src/updater"
+)
+
+// UpdateRepo is the GitHub repository gitignore-cli releases come from when
+// the server publishes no build for this platform.
+const UpdateRepo = "apimgr/gitignore"
+
+// UpdateAPIBase is the GitHub API root update checks query; empty means
+// api.github.com.
+var UpdateAPIBase string
+
+// UpdateExecutable is the binary --update replaces; empty means the running
+// one.
+var UpdateExecutable string
+
+// updateCheckInterval is how often the background check runs at most.
+const updateCheckInterval = 24 * time.Hour
+
+// CmdUpdate implements `gitignore-cli --update [check|yes|branch [CHANNEL]]`
+// (AI.md PART 32 "CLI Auto-Update"). check reports whether a newer release
+// exists, yes (the default) downloads it, verifies its SHA256 and atomically
+// replaces the binary, and branch shows or sets the release channel in
+// cli.yml. Releases come from the server's cli_versions, so clients follow
+// the server they talk to; beta and daily channels, and servers that
+// publish none or a build GitHub's release does not list, fall back to
+// GitHub.
+func CmdUpdate(c *api.Client, p *output.Printer, cfg *config.Config, cfgPath string, args []string) int {
+	sub := "yes"
+	if len(args) > 0 {
+		sub = strings.ToLower(args[0])
+	}
+	switch sub {
+	case "check", "yes":
+		if Embedded != nil {
+			p.Error("%s", tr(c, "cli.update_embedded"))
+			return output.ExitGeneral
+		}
+	case "branch":
+		if len(args) < 2 {
+			p.Info("%s", tr(c, "cli.update_channel", "channel", cfg.Update.Channel))
+			return output.ExitSuccess
+		}
+		if !config.ValidChannel(args[1]) {
+			p.Error("%s", tr(c, "cli.update_usage"))
+			return output.ExitUsage
+		}
+		cfg.Update.Channel = args[1]
+		if err := config.Save(cfgPath, cfg); err != nil {
+			p.Error("%v", err)
+			return output.ExitConfig
+		}
+		p.Info("%s", tr(c, "cli.update_channel_set", "channel", args[1]))
+		return output.ExitSuccess
+	default:
+		p.Error("%s", tr(c, "cli.update_usage"))
+		return output.ExitUsage
+	}
+
+	p.Info("%s", tr(c, "cli.update_current", "version", api.Version))
+	p.Info("%s", tr(c, "cli.update_channel", "channel", cfg.Update.Channel))
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+	defer cancel()
+	u, rel, err := findUpdate(ctx, c, cfg.Update.Channel)
+	if err != nil {
+		p.Error("%s", tr(c, "cli.update_check_failed", "error", err.Error()))
+		return output.ExitGeneral
+	}
+	if rel == nil {
+		p.Info("%s", tr(c, "cli.update_latest"))
+		return output.ExitSuccess
+	}
+	if sub == "check" {
+		p.Info("%s", tr(c, "cli.update_available", "version", rel.TagName, "command", binaryName()+" --update yes"))
+		return output.ExitSuccess
+	}
+	p.Info("%s", tr(c, "cli.update_downloading", "version", rel.TagName))
+	return installUpdate(c, p, u, rel)
+}
+
+// installUpdate installs rel and reports the outcome.
+func installUpdate(c *api.Client, p *output.Printer, u *updater.Updater, rel *updater.Release) int {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+	defer cancel()
+	if err := u.Install(ctx, rel); err != nil {
+		if errors.Is(err, fs.ErrPermission) {
+			path := UpdateExecutable
+			if path == "" {
+				path, _ = os.Executable()
+			}
+			p.Error("%s", tr(c, "cli.update_permission", "path", path))
+		} else {
+			p.Error("%s", tr(c, "cli.update_failed", "error", err.Error()))
+		}
+		return output.ExitGeneral
+	}
+	p.Info("%s", tr(c, "cli.update_installed", "version", rel.TagName))
+	return output.ExitSuccess
+}
+
+// findUpdate returns the release to update to on channel, or nil when the
+// running version is current. On the stable channel the build the primary
+// server publishes for this platform wins, provided its SHA256 is the one
+// in the checksums.txt of GitHub's release of that version: the server only
+// says where to download, so a hostile or compromised server cannot hand
+// out a binary of its own. Otherwise GitHub's newest release on the channel
+// does.
+func findUpdate(ctx context.Context, c *api.Client, channel string) (*updater.Updater, *updater.Release, error) {
+	u := updater.New(updater.Config{
+		Repo:           UpdateRepo,
+		BinaryName:     api.ProjectName + "-cli",
+		CurrentVersion: api.Version,
+		Branch:         channel,
+		APIBaseURL:     UpdateAPIBase,
+		UserAgent:      api.UserAgent(),
+		Executable:     UpdateExecutable,
+	})
+	stable := channel == "" || channel == "stable"
+	if stable && c.BaseURL != "" {
+		versions, err := c.CLIVersions(ctx)
+		if v, ok := versions[updater.Platform()]; err == nil && ok {
+			if !updater.Newer(v.Version, api.Version) {
+				return u, nil, nil
+			}
+			if rel := verifiedServerRelease(ctx, u, v); rel != nil {
+				return u, rel, nil
+			}
+		}
+	}
+	rel, err := u.Check(ctx)
+	if err != nil || rel == nil {
+		return u, nil, err
+	}
+	// Stable tags are versions, so never go back to an older one.
+	if stable && !updater.Newer(rel.TagName, api.Version) {
+		return u, nil, nil
+	}
+	return u, rel, nil
+}
+
+// verifiedServerRelease returns the server's build v as a release to install,
+// or nil when GitHub's release of v.Version does not list v.SHA256 for this
+// platform's asset.
+func verifiedServerRelease(ctx context.Context, u *updater.Updater, v api.CLIVersion) *updater.Release {
+	tag := "v" + strings.TrimPrefix(v.Version, "v")
+	rel, err := u.ReleaseByTag(ctx, tag)
+	if err != nil || rel == nil {
+		return nil
+	}
+	sums, err := u.Checksums(ctx, rel)
+	if err != nil {
+		return nil
+	}
+	asset := u.AssetName()
+	if sum := sums[asset]; sum == "" || !strings.EqualFold(sum, v.SHA256) {
+		return nil
+	}
+	return &updater.Release{
+		TagName:   tag,
+		Assets:    []updater.Asset{{Name: asset, BrowserDownloadURL: v.URL}},
+		Checksums: map[string]string{asset: sums[asset]},
+	}
+}
+
+// StartUpdateCheck starts the background update check cli.yml's
+// update.auto opts into, at most once a day and never for development or
+// embedded builds. It runs alongside the command; the returned function,
+// called once the command is done, waits briefly for the result and then
+// reports the newer release on stderr or, when update.auto is yes, installs
+// it. A check still running by then is dropped and retried on the next run.
+func StartUpdateCheck(c *api.Client, p *output.Printer, cfg *config.Config) func() {
+	mode := cfg.Update.AutoMode()
+	if mode == config.UpdateOff || api.Version == "dev" || Embedded != nil || !updateCheckDue() {
+		return func() {}
+	}
+	type result struct {
+		u   *updater.Updater
+		rel *updater.Release
+	}
+	found := make(chan result, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	go func() {
+		u, rel, err := findUpdate(ctx, c, cfg.Update.Channel)
+		if err == nil {
+			recordUpdateCheck()
+		}
+		found <- result{u, rel}
+	}()
+	return func() {
+		defer cancel()
+		var r result
+		select {
+		case r = <-found:
+		case <-time.After(2 * time.Second):
+			return
+		}
+		if r.rel == nil {
+			return
+		}
+		if mode == config.UpdateInstall {
+			installUpdate(c, p, r.u, r.rel)
+			return
+		}
+		if isatty.IsTerminal(os.Stderr.Fd()) {
+			p.Info("%s", tr(c, "cli.update_available", "version", r.rel.TagName, "command", binaryName()+" --update yes"))
+		}
+	}
+}
+
+// updateCheckFile records when the background check last succeeded.
+func updateCheckFile() string {
+	return filepath.Join(clipath.CacheDir(), "update-check")
+}
+
+// updateCheckDue reports whether a day has passed since the last background
+// check.
+func updateCheckDue() bool {
+	info, err := os.Stat(updateCheckFile())
+	return err != nil || time.Since(info.ModTime()) >= updateCheckInterval
+}
+
+// recordUpdateCheck marks the background check done for today.
+func recordUpdateCheck() {
+	_ = os.WriteFile(updateCheckFile(), []byte(time.Now().UTC().Format(time.RFC3339)+"\n"), 0o600)
+}
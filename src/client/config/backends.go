@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	clipath "github.com/apimgr/gitignore/src/client/path"
+)
+
+// ResolveBackend returns the API kind for the server at url: kind as
+// configured in cli.yml, or when that is empty or "auto" the kind detected
+// on an earlier run, else "" to detect it now.
+func ResolveBackend(kind, url string, detected map[string]string) string {
+	if kind == "" || kind == "auto" {
+		if remembered, ok := detected[strings.TrimSuffix(url, "/")]; ok {
+			return remembered
+		}
+	}
+	return kind
+}
+
+// LoadDetectedBackends returns the API kind detected for each server URL on
+// earlier runs. A missing or unreadable file is treated as empty.
+func LoadDetectedBackends() map[string]string {
+	detected := make(map[string]string)
+	if data, err := os.ReadFile(clipath.DetectedBackendsFile()); err == nil {
+		_ = yaml.Unmarshal(data, &detected)
+	}
+	return detected
+}
+
+// SaveDetectedBackend remembers kind as the API of the server at url.
+func SaveDetectedBackend(url, kind string) error {
+	detected := LoadDetectedBackends()
+	detected[strings.TrimSuffix(url, "/")] = kind
+	data, err := yaml.Marshal(detected)
+	if err != nil {
+		return fmt.Errorf("marshal detected backends: %w", err)
+	}
+	if err := os.WriteFile(clipath.DetectedBackendsFile(), data, 0o600); err != nil {
+		return fmt.Errorf("write detected backends: %w", err)
+	}
+	return nil
+}
@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
@@ -19,18 +21,115 @@ import (
 
 // ServerConfig holds server connection settings.
 type ServerConfig struct {
-	Primary   string `yaml:"primary,omitempty"`
-	VerifySSL string `yaml:"verify_ssl,omitempty"`
+	Primary string `yaml:"primary,omitempty"`
+	// Backend is the primary server's API: native (this project's server),
+	// gitignoreio, github, or auto (the default) to detect it.
+	Backend string `yaml:"backend,omitempty"`
+	// Fallbacks are tried in order when the primary cannot be reached or
+	// does not offer a command.
+	Fallbacks []FallbackServer `yaml:"fallbacks,omitempty"`
+	VerifySSL string           `yaml:"verify_ssl,omitempty"`
 }
 
-// UpdateConfig holds CLI self-update preferences (checked but not acted on
-// today — this project's server does not yet expose /api/autodiscover
-// cli_versions; the field is preserved for forward compatibility).
+// FallbackServer is one entry of server.fallbacks.
+type FallbackServer struct {
+	URL     string `yaml:"url"`
+	Backend string `yaml:"backend,omitempty"`
+}
+
+// UpdateConfig holds CLI self-update preferences. Auto opts into a
+// background check at most once a day: "check" reports a newer release on
+// stderr, "yes" installs it; "no" (the default) leaves updating to
+// `--update`. Channel is the release channel: stable, beta or daily.
 type UpdateConfig struct {
 	Auto    string `yaml:"auto,omitempty"`
 	Channel string `yaml:"channel,omitempty"`
 }
 
+// Background update modes, as resolved by UpdateConfig.AutoMode.
+const (
+	UpdateOff     = "no"
+	UpdateCheck   = "check"
+	UpdateInstall = "yes"
+)
+
+// AutoMode resolves Auto to UpdateOff, UpdateCheck or UpdateInstall: any
+// truthy value installs, "check" only reports, and anything else is off.
+func (u UpdateConfig) AutoMode() string {
+	switch {
+	case strings.EqualFold(strings.TrimSpace(u.Auto), UpdateCheck):
+		return UpdateCheck
+	case IsTruthy(u.Auto):
+		return UpdateInstall
+	}
+	return UpdateOff
+}
+
+// ValidChannel reports whether channel is a release channel.
+func ValidChannel(channel string) bool {
+	return channel == "stable" || channel == "beta" || channel == "daily"
+}
+
+// CacheConfig controls the offline template cache.
+type CacheConfig struct {
+	// TTL is how long a revalidated cache counts as fresh, as a Go duration
+	// ("24h", "30m"). "0" revalidates on every run.
+	TTL string `yaml:"ttl,omitempty"`
+}
+
+// HookConfig configures the pre-commit hook (gitignore-cli hook).
+type HookConfig struct {
+	// Templates are the templates whose rules staged files are checked
+	// against.
+	Templates []string `yaml:"templates,omitempty"`
+}
+
+// MaxRecent is how many recent compositions tui.recent keeps.
+const MaxRecent = 10
+
+// TUIConfig holds what the TUI composer remembers between runs.
+type TUIConfig struct {
+	// Favorites are listed first in the composer.
+	Favorites []string `yaml:"favorites,omitempty"`
+	// Recent are the compositions last saved, newest first.
+	Recent [][]string `yaml:"recent,omitempty"`
+}
+
+// IsFavorite reports whether name is a favorite template.
+func (t *TUIConfig) IsFavorite(name string) bool {
+	for _, f := range t.Favorites {
+		if strings.EqualFold(f, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToggleFavorite adds name to the favorites, or removes it if already
+// there, and reports whether it is now a favorite.
+func (t *TUIConfig) ToggleFavorite(name string) bool {
+	for i, f := range t.Favorites {
+		if strings.EqualFold(f, name) {
+			t.Favorites = append(t.Favorites[:i], t.Favorites[i+1:]...)
+			return false
+		}
+	}
+	t.Favorites = append(t.Favorites, name)
+	return true
+}
+
+// AddRecent records names as the newest composition, moving an identical
+// one to the front and dropping the oldest beyond MaxRecent.
+func (t *TUIConfig) AddRecent(names []string) {
+	recent := [][]string{append([]string(nil), names...)}
+	for _, r := range t.Recent {
+		if !slices.Equal(r, names) && len(recent) < MaxRecent {
+			recent = append(recent, r)
+		}
+	}
+	t.Recent = recent
+}
+
 // Config is the full cli.yml document.
 type Config struct {
 	Server ServerConfig `yaml:"server"`
@@ -38,6 +137,9 @@ type Config struct {
 	Color  string       `yaml:"color,omitempty"`
 	Lang   string       `yaml:"lang,omitempty"`
 	Update UpdateConfig `yaml:"update,omitempty"`
+	Cache  CacheConfig  `yaml:"cache,omitempty"`
+	Hook   HookConfig   `yaml:"hook,omitempty"`
+	TUI    TUIConfig    `yaml:"tui,omitempty"`
 }
 
 // Default returns a fresh Config with sane zero-value defaults.
@@ -46,6 +148,7 @@ func Default() *Config {
 		Output: "text",
 		Color:  "auto",
 		Update: UpdateConfig{Auto: "no", Channel: "stable"},
+		Cache:  CacheConfig{TTL: "24h"},
 	}
 }
 
@@ -132,6 +235,18 @@ func IsTruthy(s string) bool { return srvconfig.IsTruthy(s) }
 // IsFalsy re-exports the server's IsFalsy helper for CLI flag/env parsing.
 func IsFalsy(s string) bool { return srvconfig.IsFalsy(s) }
 
+// ResolveCacheTTL parses cache.ttl, defaulting to defaultTTL when unset.
+func ResolveCacheTTL(cfg *Config, defaultTTL time.Duration) (time.Duration, error) {
+	if cfg == nil || cfg.Cache.TTL == "" {
+		return defaultTTL, nil
+	}
+	ttl, err := time.ParseDuration(cfg.Cache.TTL)
+	if err != nil || ttl < 0 {
+		return 0, fmt.Errorf("invalid cache.ttl %q: expected a duration such as 24h or 30m", cfg.Cache.TTL)
+	}
+	return ttl, nil
+}
+
 // ResolveServer determines the server base URL using the priority order
 // from AI.md PART 32 "Server Address Resolution":
 //  1. --server flag (explicit)
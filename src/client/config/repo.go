@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RepoConfigFile is the repository-local configuration, committed at the
+// root of the repository it describes.
+const RepoConfigFile = ".gitignore-cli.yml"
+
+// RepoConfig is the RepoConfigFile document. It carries the settings that
+// belong to a repository rather than to a user.
+type RepoConfig struct {
+	Hook HookConfig `yaml:"hook,omitempty"`
+}
+
+// LoadRepoConfig reads RepoConfigFile from the repository at root, returning
+// nil when there is none.
+func LoadRepoConfig(root string) (*RepoConfig, error) {
+	path := filepath.Join(root, RepoConfigFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	var cfg RepoConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,20 @@
+//go:build embedded
+
+package main
+
+import (
+	"github.com/apimgr/gitignore/src/client/cache"
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// The embedded build links the server's template.Manager together with the
+// dataset compiled into src/template, so every command works with no server.
+func init() {
+	embeddedDataset = func() (*cache.Dataset, error) {
+		tm, err := template.New()
+		if err != nil {
+			return nil, err
+		}
+		return cache.NewDataset(tm), nil
+	}
+}
@@ -0,0 +1,409 @@
+package lsp
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/apimgr/gitignore/src/client/audit"
+	"github.com/apimgr/gitignore/src/client/cache"
+	"github.com/apimgr/gitignore/src/common/i18n"
+)
+
+// Diagnostic codes.
+const (
+	codeDuplicate          = "duplicate"
+	codeNegationExcluded   = "negation-excluded-dir"
+	codeNegationUnmatched  = "negation-unmatched"
+	codeEmptyPattern       = "empty-pattern"
+	codeTrailingBackslash  = "trailing-backslash"
+	codeBackslashSeparator = "backslash-separator"
+	codeUnclosedBracket    = "unclosed-bracket"
+	codeTrailingSpaces     = "trailing-spaces"
+	codeInvalidPattern     = "invalid-pattern"
+	codeUnknownTemplate    = "unknown-template"
+	codeMissingSection     = "missing-section"
+)
+
+// headerPrefix starts the line listing a generated file's templates, as
+// combine and init write it.
+const headerPrefix = "# Templates:"
+
+// sectionTitle matches a "### Name ###" section line.
+var sectionTitle = regexp.MustCompile(`^###\s+(.+?)\s+###\s*$`)
+
+// index maps the dataset's rules to the templates that have them.
+type index struct {
+	names    []string
+	category map[string]string
+	rules    map[string][]string
+	// common are the rules most templates share, most shared first: the
+	// patterns completion offers.
+	common []string
+}
+
+// maxCommon caps the patterns offered by completion.
+const maxCommon = 200
+
+// newIndex indexes the rules of every template in d.
+func newIndex(d *cache.Dataset) *index {
+	ix := &index{names: d.List(), category: make(map[string]string), rules: make(map[string][]string)}
+	sort.Slice(ix.names, func(i, j int) bool {
+		return strings.ToLower(ix.names[i]) < strings.ToLower(ix.names[j])
+	})
+	for _, name := range ix.names {
+		tmpl, err := d.Get(name)
+		if err != nil {
+			continue
+		}
+		ix.category[name] = tmpl.Category
+		seen := make(map[string]bool)
+		for _, line := range strings.Split(tmpl.Content, "\n") {
+			if rule := strings.TrimSpace(line); rule != "" && !strings.HasPrefix(rule, "#") && !seen[rule] {
+				ix.rules[rule] = append(ix.rules[rule], name)
+				seen[rule] = true
+			}
+		}
+	}
+	for rule, names := range ix.rules {
+		if len(names) > 1 {
+			ix.common = append(ix.common, rule)
+		}
+	}
+	sort.Slice(ix.common, func(i, j int) bool {
+		a, b := ix.common[i], ix.common[j]
+		if len(ix.rules[a]) != len(ix.rules[b]) {
+			return len(ix.rules[a]) > len(ix.rules[b])
+		}
+		return a < b
+	})
+	if len(ix.common) > maxCommon {
+		ix.common = ix.common[:maxCommon]
+	}
+	return ix
+}
+
+// document is an analyzed .gitignore.
+type document struct {
+	text  string
+	lines []string
+	// headers are the names on "# Templates:" lines, sections the
+	// "### Name ###" lines.
+	headers  []headerName
+	sections []section
+	findings []finding
+}
+
+// headerName is one template name in a "# Templates:" line, at bytes
+// [start, end) of the line.
+type headerName struct {
+	line, start, end int
+	name             string
+}
+
+// section is a "### Title ###" line.
+type section struct {
+	line  int
+	title string
+}
+
+// finding is a diagnostic with the fixes offered for it.
+type finding struct {
+	Diagnostic
+	fixes []fix
+}
+
+// fix is a quick fix editing the document.
+type fix struct {
+	title     string
+	edits     []TextEdit
+	preferred bool
+}
+
+// tr translates key into the server's language.
+func (s *Server) tr(key string, args ...interface{}) string {
+	return i18n.TranslateFormat(s.lang, key, args...)
+}
+
+// analyze parses text and finds its problems.
+func (s *Server) analyze(text string) *document {
+	doc := &document{text: text, lines: strings.Split(text, "\n")}
+	for i, line := range doc.lines {
+		doc.lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	for n, line := range doc.lines {
+		if m := sectionTitle.FindStringSubmatch(line); m != nil {
+			doc.sections = append(doc.sections, section{line: n, title: m[1]})
+		}
+		doc.headers = append(doc.headers, parseHeader(n, line)...)
+	}
+	s.checkPatterns(doc)
+	s.checkDuplicates(doc)
+	s.checkNegations(doc)
+	s.checkHeader(doc)
+	sort.SliceStable(doc.findings, func(i, j int) bool {
+		return doc.findings[i].Range.Start.Line < doc.findings[j].Range.Start.Line
+	})
+	return doc
+}
+
+// parseHeader returns the template names on line n when it is a
+// "# Templates:" line.
+func parseHeader(n int, line string) []headerName {
+	i := strings.Index(line, headerPrefix)
+	if i < 0 || strings.TrimSpace(line[:i]) != "" {
+		return nil
+	}
+	var names []headerName
+	start := i + len(headerPrefix)
+	for _, token := range strings.Split(line[start:], ",") {
+		name := strings.TrimSpace(token)
+		if name != "" {
+			off := start + strings.Index(token, name)
+			names = append(names, headerName{line: n, start: off, end: off + len(name), name: name})
+		}
+		start += len(token) + 1
+	}
+	return names
+}
+
+// add records a finding on bytes [start, end) of line n.
+func (doc *document) add(n, start, end, severity int, code, message string, fixes ...fix) {
+	doc.findings = append(doc.findings, finding{
+		Diagnostic: Diagnostic{
+			Range:    lineRange(n, doc.lines[n], start, end),
+			Severity: severity,
+			Code:     code,
+			Source:   source,
+			Message:  message,
+		},
+		fixes: fixes,
+	})
+}
+
+// deleteLine is the edit removing line n.
+func (doc *document) deleteLine(n int) TextEdit {
+	if n+1 < len(doc.lines) {
+		return TextEdit{Range: Range{Start: Position{Line: n}, End: Position{Line: n + 1}}}
+	}
+	if n == 0 {
+		return TextEdit{Range: lineRange(0, doc.lines[0], 0, len(doc.lines[0]))}
+	}
+	return TextEdit{Range: Range{
+		Start: Position{Line: n - 1, Character: utf16Len(doc.lines[n-1])},
+		End:   Position{Line: n, Character: utf16Len(doc.lines[n])},
+	}}
+}
+
+// rulePattern returns the pattern on line as git reads it, without
+// unescaped trailing spaces, or "" for blank and comment lines.
+func rulePattern(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if strings.HasPrefix(line, "#") {
+		return ""
+	}
+	return line
+}
+
+// checkPatterns reports patterns git rejects, never matches or reads
+// differently from how they look.
+func (s *Server) checkPatterns(doc *document) {
+	parsed := make(map[int]bool)
+	for _, r := range audit.ParseRules("", "", doc.text) {
+		parsed[r.Line-1] = true
+	}
+	for n, line := range doc.lines {
+		p := rulePattern(line)
+		if p == "" {
+			continue
+		}
+		if len(p) < len(line) {
+			doc.add(n, len(p), len(line), SeverityHint, codeTrailingSpaces, s.tr("lsp.trailing_spaces"))
+		}
+		body := strings.TrimPrefix(p, "!")
+		if strings.Trim(body, "/") == "" {
+			doc.add(n, 0, len(p), SeverityWarning, codeEmptyPattern, s.tr("lsp.empty_pattern"))
+			continue
+		}
+		if trailing := len(p) - len(strings.TrimRight(p, `\`)); trailing%2 == 1 {
+			doc.add(n, len(p)-1, len(p), SeverityError, codeTrailingBackslash, s.tr("lsp.trailing_backslash"))
+			continue
+		}
+		problem := false
+		for i := 0; i < len(p); i++ {
+			switch c := p[i]; {
+			case c == '\\' && i+1 < len(p):
+				if next := p[i+1]; isAlnum(next) && !problem {
+					doc.add(n, i, i+2, SeverityWarning, codeBackslashSeparator, s.tr("lsp.backslash_separator"))
+					problem = true
+				}
+				i++
+			case c == '[' && strings.IndexByte(p[i+1:], ']') < 0:
+				doc.add(n, i, i+1, SeverityWarning, codeUnclosedBracket, s.tr("lsp.unclosed_bracket"))
+				problem = true
+				i = len(p)
+			}
+		}
+		if !problem && !parsed[n] {
+			doc.add(n, 0, len(p), SeverityError, codeInvalidPattern, s.tr("lsp.invalid_pattern"))
+		}
+	}
+}
+
+// isAlnum reports whether c is an ASCII letter or digit.
+func isAlnum(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// checkDuplicates reports rules repeating an earlier one with nothing in
+// between reversing it: git would apply the same rule twice.
+func (s *Server) checkDuplicates(doc *document) {
+	type prev struct {
+		line   int
+		negate bool
+	}
+	last := make(map[string]prev)
+	for n, line := range doc.lines {
+		p := rulePattern(line)
+		if p == "" {
+			continue
+		}
+		body, negate := strings.CutPrefix(p, "!")
+		if before, ok := last[body]; ok && before.negate == negate {
+			doc.add(n, 0, len(line), SeverityWarning, codeDuplicate,
+				s.tr("lsp.duplicate", "line", before.line+1),
+				fix{title: s.tr("lsp.remove_duplicate"), edits: []TextEdit{doc.deleteLine(n)}, preferred: true})
+			continue
+		}
+		last[body] = prev{line: n, negate: negate}
+	}
+}
+
+// checkNegations reports "!" rules that cannot re-include anything: those
+// below a directory an earlier rule ignores, which git never looks inside,
+// and those no earlier rule ignores in the first place.
+func (s *Server) checkNegations(doc *document) {
+	rules := audit.ParseRules("", "", doc.text)
+	for i, r := range rules {
+		if !r.Negated() {
+			continue
+		}
+		isDir := strings.HasSuffix(r.Pattern, "/")
+		sample := samplePath(r.Pattern)
+		if sample == "" || !r.Matches(sample, isDir) {
+			continue
+		}
+		n := r.Line - 1
+		if dir := audit.ExcludedDir(rules[:i], sample); dir != nil {
+			doc.add(n, 0, len(r.Pattern), SeverityWarning, codeNegationExcluded,
+				s.tr("lsp.negation_excluded_dir", "line", dir.Line, "pattern", dir.Pattern))
+			continue
+		}
+		var match *audit.Rule
+		for j := i - 1; j >= 0 && match == nil; j-- {
+			if rules[j].Matches(sample, isDir) {
+				match = rules[j]
+			}
+		}
+		if match == nil || match.Negated() {
+			doc.add(n, 0, len(r.Pattern), SeverityHint, codeNegationUnmatched, s.tr("lsp.negation_unmatched"))
+		}
+	}
+}
+
+// samplePath returns a path pattern matches, made by filling its
+// wildcards in, or "" when it has none simple enough. Callers check the
+// result against the rule.
+func samplePath(pattern string) string {
+	pattern = strings.Trim(strings.TrimPrefix(pattern, "!"), "/")
+	segments := strings.Split(pattern, "/")
+	var out []string
+	for i, seg := range segments {
+		if seg == "**" {
+			if i == len(segments)-1 {
+				out = append(out, "x")
+			}
+			continue
+		}
+		var b strings.Builder
+		for j := 0; j < len(seg); j++ {
+			switch c := seg[j]; c {
+			case '*', '?':
+				b.WriteByte('x')
+			case '[':
+				end := strings.IndexByte(seg[j+1:], ']')
+				class := seg[j+1 : j+1+max(end, 0)]
+				if end <= 0 || class[0] == '!' || class[0] == '^' || class[0] == '\\' {
+					return ""
+				}
+				b.WriteByte(class[0])
+				j += end + 1
+			case '\\':
+				if j+1 < len(seg) {
+					j++
+					b.WriteByte(seg[j])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		out = append(out, b.String())
+	}
+	return strings.Join(out, "/")
+}
+
+// checkHeader reports "# Templates:" names the dataset does not know,
+// offering the closest ones, and known templates whose section is missing.
+func (s *Server) checkHeader(doc *document) {
+	for _, h := range doc.headers {
+		tmpl, err := s.data.Get(h.name)
+		if err != nil {
+			suggestions := s.data.Suggest(h.name, 3)
+			if len(suggestions) == 0 {
+				doc.add(h.line, h.start, h.end, SeverityError, codeUnknownTemplate, s.tr("lsp.unknown_template", "name", h.name))
+				continue
+			}
+			fixes := make([]fix, len(suggestions))
+			for i, name := range suggestions {
+				fixes[i] = fix{
+					title:     s.tr("lsp.replace_with", "name", name),
+					edits:     []TextEdit{{Range: lineRange(h.line, doc.lines[h.line], h.start, h.end), NewText: name}},
+					preferred: i == 0,
+				}
+			}
+			doc.add(h.line, h.start, h.end, SeverityError, codeUnknownTemplate,
+				s.tr("lsp.unknown_template_suggest", "name", h.name, "names", strings.Join(suggestions, ", ")), fixes...)
+			continue
+		}
+		if doc.section(tmpl.Name) < 0 {
+			doc.add(h.line, h.start, h.end, SeverityInformation, codeMissingSection,
+				s.tr("lsp.missing_section", "name", tmpl.Name),
+				fix{title: s.tr("lsp.insert_template", "name", tmpl.Name), edits: []TextEdit{s.insertTemplate(doc, tmpl.Name, tmpl.Content)}})
+		}
+	}
+}
+
+// section returns the line of the section titled name, ignoring case, or
+// -1.
+func (doc *document) section(name string) int {
+	for _, sec := range doc.sections {
+		if strings.EqualFold(sec.title, name) {
+			return sec.line
+		}
+	}
+	return -1
+}
+
+// sectionAt returns the title of the section holding line n, or "".
+func (doc *document) sectionAt(n int) string {
+	title := ""
+	for _, sec := range doc.sections {
+		if sec.line > n {
+			break
+		}
+		title = sec.title
+	}
+	return title
+}
@@ -0,0 +1,330 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/apimgr/gitignore/src/client/monorepo"
+	"github.com/apimgr/gitignore/src/common/i18n"
+)
+
+// maxHoverTemplates caps the template names a hover lists.
+const maxHoverTemplates = 10
+
+// hover describes the template under pos on a header or section line, or
+// what the rule under pos matches and which templates have it.
+func (s *Server) hover(text string, pos Position) *Hover {
+	doc := s.analyze(text)
+	if pos.Line < 0 || pos.Line >= len(doc.lines) {
+		return nil
+	}
+	line := doc.lines[pos.Line]
+	col := byteOffset(line, pos.Character)
+	for _, h := range doc.headers {
+		if h.line == pos.Line && col >= h.start && col <= h.end {
+			r := lineRange(h.line, line, h.start, h.end)
+			return s.templateHover(h.name, &r)
+		}
+	}
+	if m := sectionTitle.FindStringSubmatchIndex(line); m != nil {
+		r := lineRange(pos.Line, line, m[2], m[3])
+		return s.templateHover(line[m[2]:m[3]], &r)
+	}
+	p := rulePattern(line)
+	if p == "" {
+		return nil
+	}
+	body, negate := strings.CutPrefix(p, "!")
+	dirOnly := strings.HasSuffix(body, "/")
+	body = strings.TrimRight(body, "/")
+	anchored := strings.Contains(body, "/")
+	key := "lsp.hover_ignore_anywhere"
+	switch {
+	case negate && anchored:
+		key = "lsp.hover_include_anchored"
+	case negate:
+		key = "lsp.hover_include_anywhere"
+	case anchored:
+		key = "lsp.hover_ignore_anchored"
+	}
+	parts := []string{s.tr(key, "pattern", strings.TrimPrefix(body, "/"))}
+	if dirOnly {
+		parts = append(parts, s.tr("lsp.hover_dir_only"))
+	}
+	if strings.ContainsAny(body, "*?[") {
+		parts = append(parts, s.tr("lsp.hover_wildcards"))
+	}
+	if title := doc.sectionAt(pos.Line); title != "" {
+		if tmpl, err := s.data.Get(title); err == nil {
+			parts = append(parts, s.tr("lsp.hover_section", "name", tmpl.Name))
+		}
+	}
+	if names := s.index.rules[strings.TrimSpace(line)]; len(names) > 0 {
+		list := strings.Join(names[:min(len(names), maxHoverTemplates)], ", ")
+		if len(names) > maxHoverTemplates {
+			list += ", …"
+		}
+		parts = append(parts, s.tr("lsp.hover_templates", "count", i18n.TranslatePlural(s.lang, "plurals.templates", len(names)), "names", list))
+	}
+	r := lineRange(pos.Line, line, 0, len(p))
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: strings.Join(parts, "\n\n")}, Range: &r}
+}
+
+// templateHover describes the named template: its name, category and
+// description. Unknown names get no hover.
+func (s *Server) templateHover(name string, r *Range) *Hover {
+	tmpl, err := s.data.Get(name)
+	if err != nil {
+		return nil
+	}
+	value := "**" + tmpl.Name + "** — " + i18n.CategoryName(s.lang, tmpl.Category)
+	if desc := i18n.TemplateDescription(s.lang, tmpl.Name, tmpl.Description); desc != "" {
+		value += "\n\n" + desc
+	}
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: value}, Range: r}
+}
+
+// complete offers template names on "# Templates:" and section lines, and
+// the dataset's common patterns on rule lines.
+func (s *Server) complete(text string, pos Position) []CompletionItem {
+	doc := s.analyze(text)
+	if pos.Line < 0 || pos.Line >= len(doc.lines) {
+		return []CompletionItem{}
+	}
+	line := doc.lines[pos.Line]
+	col := byteOffset(line, pos.Character)
+	before := line[:col]
+
+	if i := strings.Index(before, headerPrefix); i >= 0 && strings.TrimSpace(before[:i]) == "" {
+		start := max(i+len(headerPrefix), strings.LastIndex(before, ",")+1)
+		for start < col && before[start] == ' ' {
+			start++
+		}
+		listed := make(map[string]bool)
+		for _, h := range doc.headers {
+			if h.line == pos.Line && h.start != start {
+				listed[strings.ToLower(h.name)] = true
+			}
+		}
+		return s.nameItems(lineRange(pos.Line, line, start, col), listed, "")
+	}
+	if rest, ok := strings.CutPrefix(before, "### "); ok && !strings.Contains(rest, "###") {
+		suffix := ""
+		if !strings.HasSuffix(strings.TrimSpace(line), "###") || strings.TrimSpace(line) == "###" {
+			suffix = " ###"
+		}
+		return s.nameItems(lineRange(pos.Line, line, 4, col), nil, suffix)
+	}
+	if strings.HasPrefix(strings.TrimSpace(before), "#") {
+		return []CompletionItem{}
+	}
+
+	start := len(before) - len(strings.TrimLeft(before, " \t"))
+	if strings.HasPrefix(before[start:], "!") {
+		start++
+	}
+	r := lineRange(pos.Line, line, start, col)
+	items := make([]CompletionItem, len(s.index.common))
+	for i, rule := range s.index.common {
+		items[i] = CompletionItem{
+			Label:    rule,
+			Kind:     completionKindText,
+			Detail:   i18n.TranslatePlural(s.lang, "plurals.templates", len(s.index.rules[rule])),
+			SortText: sortKey(i),
+			TextEdit: &TextEdit{Range: r, NewText: rule},
+		}
+	}
+	return items
+}
+
+// nameItems are completions of every template name not in listed,
+// replacing r and followed by suffix.
+func (s *Server) nameItems(r Range, listed map[string]bool, suffix string) []CompletionItem {
+	items := []CompletionItem{}
+	for _, name := range s.index.names {
+		if listed[strings.ToLower(name)] {
+			continue
+		}
+		items = append(items, CompletionItem{
+			Label:    name,
+			Kind:     completionKindModule,
+			Detail:   i18n.CategoryName(s.lang, s.index.category[name]),
+			TextEdit: &TextEdit{Range: r, NewText: name + suffix},
+		})
+	}
+	return items
+}
+
+// sortKey orders completion items by rank.
+func sortKey(i int) string {
+	const digits = "0123456789"
+	return string([]byte{digits[i/100%10], digits[i/10%10], digits[i%10]})
+}
+
+// codeActionParams are the textDocument/codeAction parameters used.
+type codeActionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+	Context      struct {
+		Only []string `json:"only"`
+	} `json:"context"`
+}
+
+// codeActions returns the quick fixes for the diagnostics on the lines of
+// p.Range and, for files with a "# Templates:" header, the source action
+// regenerating the file from it.
+func (s *Server) codeActions(p codeActionParams) []CodeAction {
+	uri := p.TextDocument.URI
+	text := s.docs[uri]
+	doc := s.analyze(text)
+	edit := func(edits ...TextEdit) *WorkspaceEdit {
+		return &WorkspaceEdit{Changes: map[string][]TextEdit{uri: edits}}
+	}
+	actions := []CodeAction{}
+	for _, f := range doc.findings {
+		if f.Range.End.Line < p.Range.Start.Line || f.Range.Start.Line > p.Range.End.Line {
+			continue
+		}
+		for _, fx := range f.fixes {
+			actions = append(actions, CodeAction{
+				Title:       fx.title,
+				Kind:        kindQuickFix,
+				Diagnostics: []Diagnostic{f.Diagnostic},
+				IsPreferred: fx.preferred,
+				Edit:        edit(fx.edits...),
+			})
+		}
+	}
+	if regenerated, ok := s.regenerate(doc); ok && regenerated != text {
+		last := len(doc.lines) - 1
+		actions = append(actions, CodeAction{
+			Title: s.tr("lsp.regenerate"),
+			Kind:  kindSource,
+			Edit: edit(TextEdit{
+				Range:   Range{End: Position{Line: last, Character: utf16Len(doc.lines[last])}},
+				NewText: regenerated,
+			}),
+		})
+	}
+	if len(p.Context.Only) == 0 {
+		return actions
+	}
+	var wanted []CodeAction
+	for _, a := range actions {
+		for _, kind := range p.Context.Only {
+			if a.Kind == kind || strings.HasPrefix(a.Kind, kind+".") {
+				wanted = append(wanted, a)
+				break
+			}
+		}
+	}
+	if wanted == nil {
+		wanted = []CodeAction{}
+	}
+	return wanted
+}
+
+// insertTemplate is the edit adding the named template's section with the
+// rules the document does not have yet: inside the block init generated
+// when there is one, else at the end.
+func (s *Server) insertTemplate(doc *document, name, content string) TextEdit {
+	have := make(map[string]bool)
+	for _, line := range doc.lines {
+		have[strings.TrimSpace(line)] = true
+	}
+	var body []string
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r", ""), "\n") {
+		rule := strings.TrimSpace(line)
+		if rule != "" && !strings.HasPrefix(rule, "#") && have[rule] {
+			continue
+		}
+		if rule == "" && (len(body) == 0 || body[len(body)-1] == "") {
+			continue
+		}
+		body = append(body, line)
+	}
+	for len(body) > 0 && body[len(body)-1] == "" {
+		body = body[:len(body)-1]
+	}
+	section := "### " + name + " ###\n" + strings.Join(body, "\n") + "\n"
+	if n, ok := monorepo.BlockEnd(doc.text); ok {
+		return TextEdit{Range: Range{Start: Position{Line: n}, End: Position{Line: n}}, NewText: "\n" + section}
+	}
+	last := len(doc.lines) - 1
+	end := Position{Line: last, Character: utf16Len(doc.lines[last])}
+	prefix := "\n"
+	switch {
+	case strings.TrimSpace(doc.text) == "":
+		prefix = ""
+	case doc.lines[last] != "":
+		prefix = "\n\n"
+	}
+	return TextEdit{Range: Range{Start: end, End: end}, NewText: prefix + section}
+}
+
+// regenerate rebuilds the file from the templates its "# Templates:"
+// header lists, keeping what the user wrote: a block init generated is
+// regenerated in place; otherwise the lines before the first section and
+// the sections of no listed template are kept around a fresh combine.
+// It reports false when the header names no known template.
+func (s *Server) regenerate(doc *document) (string, bool) {
+	var names []string
+	contents := make(map[string]string)
+	for _, h := range doc.headers {
+		tmpl, err := s.data.Get(h.name)
+		if err != nil {
+			continue
+		}
+		if _, ok := contents[tmpl.Name]; !ok {
+			names = append(names, tmpl.Name)
+			contents[tmpl.Name] = tmpl.Content
+		}
+	}
+	if len(names) == 0 {
+		return "", false
+	}
+	if _, ok := monorepo.BlockEnd(doc.text); ok {
+		return monorepo.Regenerate(doc.text, names, contents), true
+	}
+	combined, err := s.data.Combine(names)
+	if err != nil {
+		return "", false
+	}
+
+	first := len(doc.lines)
+	if len(doc.sections) > 0 {
+		first = doc.sections[0].line
+	}
+	var preamble []string
+	for _, line := range doc.lines[:first] {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "# Combined .gitignore" || strings.HasPrefix(trimmed, "# Generated:") || strings.HasPrefix(trimmed, headerPrefix) {
+			continue
+		}
+		preamble = append(preamble, line)
+	}
+	var b strings.Builder
+	if kept := strings.Trim(strings.Join(preamble, "\n"), "\n"); kept != "" {
+		b.WriteString(kept + "\n\n")
+	}
+	b.WriteString(strings.TrimRight(combined, "\n") + "\n")
+	for i, sec := range doc.sections {
+		if _, listed := contents[s.canonical(sec.title)]; listed {
+			continue
+		}
+		end := len(doc.lines)
+		if i+1 < len(doc.sections) {
+			end = doc.sections[i+1].line
+		}
+		b.WriteString("\n" + strings.Trim(strings.Join(doc.lines[sec.line:end], "\n"), "\n") + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n", true
+}
+
+// canonical returns the dataset's spelling of the template name, or name
+// itself when it is no template.
+func (s *Server) canonical(name string) string {
+	if tmpl, err := s.data.Get(name); err == nil {
+		return tmpl.Name
+	}
+	return name
+}
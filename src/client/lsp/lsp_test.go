@@ -0,0 +1,300 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/apimgr/gitignore/src/client/cache"
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// lspClient drives a language server over pipes as an editor would.
+type lspClient struct {
+	t      *testing.T
+	w      io.Writer
+	r      *bufio.Reader
+	id     int
+	diags  map[string][]Diagnostic
+	closed chan error
+}
+
+// lspMessage is any message from the server.
+type lspMessage struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code int `json:"code"`
+	} `json:"error"`
+}
+
+func (c *lspClient) send(v interface{}) {
+	c.t.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// notify sends a notification and reads the diagnostics it publishes, if
+// any are expected.
+func (c *lspClient) notify(method string, params interface{}, publishes bool) {
+	c.t.Helper()
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+	if publishes {
+		if msg := c.read(); msg.Method != "textDocument/publishDiagnostics" {
+			c.t.Fatalf("after %s: got %+v, want diagnostics", method, msg)
+		}
+	}
+}
+
+// call sends a request and decodes its result into result, returning the
+// error code of an error response.
+func (c *lspClient) call(method string, params, result interface{}) int {
+	c.t.Helper()
+	c.id++
+	c.send(map[string]interface{}{"jsonrpc": "2.0", "id": c.id, "method": method, "params": params})
+	msg := c.read()
+	if msg.ID == nil || *msg.ID != c.id {
+		c.t.Fatalf("%s: got %+v, want the response to request %d", method, msg, c.id)
+	}
+	if msg.Error != nil {
+		return msg.Error.Code
+	}
+	if result != nil {
+		if err := json.Unmarshal(msg.Result, result); err != nil {
+			c.t.Fatalf("%s result %s: %v", method, msg.Result, err)
+		}
+	}
+	return 0
+}
+
+func (c *lspClient) read() *lspMessage {
+	c.t.Helper()
+	header, err := textproto.NewReader(c.r).ReadMIMEHeader()
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	n, _ := strconv.Atoi(header.Get("Content-Length"))
+	body := make([]byte, n)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		c.t.Fatal(err)
+	}
+	var msg lspMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		c.t.Fatalf("%s: %v", body, err)
+	}
+	if msg.Method == "textDocument/publishDiagnostics" {
+		var p struct {
+			URI         string       `json:"uri"`
+			Diagnostics []Diagnostic `json:"diagnostics"`
+		}
+		json.Unmarshal(msg.Params, &p)
+		c.diags[p.URI] = p.Diagnostics
+	}
+	return &msg
+}
+
+// TestServer verifies the language server end to end: diagnostics for
+// duplicates, ineffective negations, invalid patterns and the "# Templates:"
+// header, the quick fixes and regeneration they offer, hovers, completions,
+// and the shutdown handshake.
+func TestServer(t *testing.T) {
+	tm, err := template.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &lspClient{t: t, w: inW, r: bufio.NewReader(outR), diags: make(map[string][]Diagnostic), closed: make(chan error, 1)}
+	go func() {
+		c.closed <- New(cache.NewDataset(tm), "en", "test").Run(inR, outW)
+		outW.Close()
+	}()
+
+	pos := func(line, char int) map[string]interface{} {
+		return map[string]interface{}{
+			"textDocument": map[string]string{"uri": "file:///repo/.gitignore"},
+			"position":     map[string]int{"line": line, "character": char},
+		}
+	}
+	if code := c.call("textDocument/hover", pos(0, 0), nil); code != -32002 {
+		t.Errorf("hover before initialize: code %d, want -32002", code)
+	}
+	var init struct {
+		Capabilities struct {
+			HoverProvider bool `json:"hoverProvider"`
+		} `json:"capabilities"`
+	}
+	if c.call("initialize", map[string]interface{}{"processId": nil}, &init); !init.Capabilities.HoverProvider {
+		t.Errorf("initialize = %+v, want a hover provider", init)
+	}
+	c.notify("initialized", map[string]interface{}{}, false)
+
+	const uri = "file:///repo/.gitignore"
+	text := strings.Join([]string{
+		"# Combined .gitignore",
+		"# Generated: Go, Nodee, Python",
+		"# Templates: Go, Nodee, Python",
+		"",
+		"### Go ###",
+		"*.exe",
+		"*.exe",
+		`build\output`,
+		"vendor/",
+		"!vendor/keep.txt",
+		"!*.keep",
+		"",
+		"### Custom ###",
+		"secret.env",
+		"",
+	}, "\n")
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "ignore", "version": 1, "text": text},
+	}, true)
+	found := make(map[string]int)
+	for _, d := range c.diags[uri] {
+		found[d.Code] = d.Range.Start.Line
+	}
+	want := map[string]int{
+		"unknown-template":      2,
+		"missing-section":       2,
+		"duplicate":             6,
+		"backslash-separator":   7,
+		"negation-excluded-dir": 9,
+		"negation-unmatched":    10,
+	}
+	for code, line := range want {
+		if got, ok := found[code]; !ok || got != line {
+			t.Errorf("diagnostic %s on line %d, found %v", code, line, found)
+		}
+	}
+	if len(found) != len(want) {
+		t.Errorf("diagnostics = %+v, want only %v", c.diags[uri], want)
+	}
+
+	var actions []CodeAction
+	c.call("textDocument/codeAction", map[string]interface{}{
+		"textDocument": map[string]string{"uri": uri},
+		"range":        map[string]interface{}{"start": map[string]int{"line": 2}, "end": map[string]int{"line": 2}},
+		"context":      map[string]interface{}{"diagnostics": []interface{}{}},
+	}, &actions)
+	titles := make(map[string]CodeAction)
+	for _, a := range actions {
+		titles[a.Title] = a
+	}
+	if a, ok := titles["Replace with Node"]; !ok || !a.IsPreferred || a.Edit.Changes[uri][0].NewText != "Node" {
+		t.Errorf("no preferred fix replacing Nodee with Node in %v", actions)
+	}
+	if a, ok := titles["Insert template Python"]; !ok || !strings.HasPrefix(a.Edit.Changes[uri][0].NewText, "\n### Python ###\n") {
+		t.Errorf("no action inserting the Python section in %v", actions)
+	}
+	regen, ok := titles["Regenerate from header"]
+	if !ok || regen.Kind != "source" {
+		t.Fatalf("no regenerate action in %v", actions)
+	}
+	out := regen.Edit.Changes[uri][0].NewText
+	for _, s := range []string{"# Templates: Go, Python\n", "### Go ###\n", "### Python ###\n", "\n\n### Custom ###\nsecret.env\n"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("regenerated file lacks %q:\n%s", s, out)
+		}
+	}
+	if strings.Contains(out, "Nodee") || strings.Contains(out, "\n\n\n### Custom") || strings.Contains(out, `build\output`) {
+		t.Errorf("regenerated file kept the unknown template or the old Go section, or spaced the custom one:\n%s", out)
+	}
+
+	c.call("textDocument/codeAction", map[string]interface{}{
+		"textDocument": map[string]string{"uri": uri},
+		"range":        map[string]interface{}{"start": map[string]int{"line": 6}, "end": map[string]int{"line": 6}},
+		"context":      map[string]interface{}{"diagnostics": []interface{}{}, "only": []string{"quickfix"}},
+	}, &actions)
+	if len(actions) != 1 || actions[0].Title != "Remove duplicate line" ||
+		actions[0].Edit.Changes[uri][0].Range != (Range{Start: Position{Line: 6}, End: Position{Line: 7}}) {
+		t.Errorf("quick fixes on the duplicate = %+v", actions)
+	}
+
+	// In a block init generated, sections go inside the block and
+	// regenerating leaves the rest of the file alone.
+	const blockURI = "file:///repo/services/api/.gitignore"
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": blockURI, "languageId": "ignore", "version": 1, "text": strings.Join([]string{
+			"local/",
+			"# >>> gitignore-cli init: generated, rerun init to update >>>",
+			"# Templates: Go, Python",
+			"",
+			"### Go ###",
+			"*.exe",
+			"# <<< gitignore-cli init <<<",
+			"",
+		}, "\n")},
+	}, true)
+	c.call("textDocument/codeAction", map[string]interface{}{
+		"textDocument": map[string]string{"uri": blockURI},
+		"range":        map[string]interface{}{"start": map[string]int{"line": 2}, "end": map[string]int{"line": 2}},
+		"context":      map[string]interface{}{"diagnostics": []interface{}{}},
+	}, &actions)
+	clear(titles)
+	for _, a := range actions {
+		titles[a.Title] = a
+	}
+	if a, ok := titles["Insert template Python"]; !ok || a.Edit.Changes[blockURI][0].Range.Start.Line != 6 {
+		t.Errorf("Python section not inserted before the block's end marker: %v", actions)
+	}
+	out = titles["Regenerate from header"].Edit.Changes[blockURI][0].NewText
+	if !strings.HasPrefix(out, "local/\n") || !strings.Contains(out, "### Python ###") || !strings.HasSuffix(out, "# <<< gitignore-cli init <<<\n") {
+		t.Errorf("regenerated block:\n%s", out)
+	}
+
+	var hover Hover
+	c.call("textDocument/hover", pos(5, 1), &hover)
+	if !strings.Contains(hover.Contents.Value, "at any depth") || !strings.Contains(hover.Contents.Value, "From the Go template.") {
+		t.Errorf("hover on *.exe = %q", hover.Contents.Value)
+	}
+	c.call("textDocument/hover", pos(2, 26), &hover)
+	if !strings.HasPrefix(hover.Contents.Value, "**Python**") {
+		t.Errorf("hover on Python = %q", hover.Contents.Value)
+	}
+
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+		"contentChanges": []map[string]string{{"text": "# Templates: Go, Py\n\n"}},
+	}, true)
+	var items []CompletionItem
+	c.call("textDocument/completion", pos(0, 19), &items)
+	var labels []string
+	for _, item := range items {
+		labels = append(labels, item.Label)
+	}
+	if !slices.Contains(labels, "Python") || slices.Contains(labels, "Go") {
+		t.Errorf("header completions = %d items, want Python and not Go", len(labels))
+	}
+	for _, item := range items {
+		if item.Label == "Python" && (item.TextEdit == nil || item.TextEdit.Range.Start.Character != 17) {
+			t.Errorf("Python completion replaces %+v, want the typed Py", item.TextEdit)
+		}
+	}
+	c.call("textDocument/completion", pos(1, 0), &items)
+	if len(items) == 0 || items[0].Label != "*.log" {
+		t.Errorf("pattern completions start with %+v, want *.log", items[:min(len(items), 1)])
+	}
+
+	if code := c.call("workspace/symbol", map[string]string{"query": ""}, nil); code != -32601 {
+		t.Errorf("unknown method: code %d, want -32601", code)
+	}
+	c.call("shutdown", nil, nil)
+	c.notify("exit", nil, false)
+	if err := <-c.closed; err != nil {
+		t.Errorf("Run after shutdown and exit = %v, want nil", err)
+	}
+}
@@ -0,0 +1,209 @@
+// Package lsp implements `gitignore-cli lsp`: a Language Server Protocol
+// server over stdio for .gitignore-family files. Diagnostics, hovers,
+// completions and code actions are answered from a template dataset, the
+// same one the other commands use, so editors follow the server's templates
+// instead of a list bundled with an extension.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/apimgr/gitignore/src/client/cache"
+)
+
+// source is the Diagnostic.Source of every diagnostic.
+const source = "gitignore-cli"
+
+// ErrNoShutdown is returned by Run when the editor sent exit without
+// shutdown first, or closed the stream; the process should exit with 1.
+var ErrNoShutdown = errors.New("lsp: exit without shutdown")
+
+// Server is a language server for one editor session. Requests are handled
+// one at a time, in order.
+type Server struct {
+	data    *cache.Dataset
+	index   *index
+	lang    string
+	version string
+
+	conn        *conn
+	docs        map[string]string
+	initialized bool
+	shutdown    bool
+}
+
+// New returns a server answering from d, with messages in lang. version is
+// reported to the editor as the server's version.
+func New(d *cache.Dataset, lang, version string) *Server {
+	return &Server{
+		data:    d,
+		index:   newIndex(d),
+		lang:    lang,
+		version: version,
+		docs:    make(map[string]string),
+	}
+}
+
+// Run serves the requests read from r, writing to w, until the editor
+// sends exit. It returns nil after an orderly shutdown, ErrNoShutdown when
+// the editor went away without one, or the error that broke the stream.
+func (s *Server) Run(r io.Reader, w io.Writer) error {
+	s.conn = &conn{r: bufio.NewReader(r), w: w}
+	for {
+		req, err := s.conn.read()
+		switch {
+		case errors.Is(err, errBadMessage):
+			if err := s.reply(nil, nil, &rpcError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		case errors.Is(err, io.EOF):
+			if s.shutdown {
+				return nil
+			}
+			return ErrNoShutdown
+		case err != nil:
+			return err
+		}
+		if req.Method == "exit" {
+			if s.shutdown {
+				return nil
+			}
+			return ErrNoShutdown
+		}
+		result, rpcErr := s.handle(req)
+		if req.ID == nil {
+			continue
+		}
+		if err := s.reply(req.ID, result, rpcErr); err != nil {
+			return err
+		}
+	}
+}
+
+// reply answers the request with id.
+func (s *Server) reply(id *json.RawMessage, result interface{}, rpcErr *rpcError) error {
+	resp := &response{JSONRPC: "2.0", ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		body, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		resp.Result = body
+	}
+	return s.conn.write(resp)
+}
+
+// notify sends the editor a notification.
+func (s *Server) notify(method string, params interface{}) error {
+	return s.conn.write(&notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// handle runs one request or notification. Notifications the server does
+// not handle, such as $/cancelRequest, are dropped; unknown requests are
+// answered with MethodNotFound.
+func (s *Server) handle(req *request) (interface{}, *rpcError) {
+	if req.Method == "initialize" {
+		s.initialized = true
+		return s.capabilities(), nil
+	}
+	if !s.initialized {
+		return nil, &rpcError{Code: codeServerNotInitialized, Message: "server not initialized"}
+	}
+	var err error
+	switch req.Method {
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var p struct {
+			TextDocument textDocumentItem `json:"textDocument"`
+		}
+		if err = json.Unmarshal(req.Params, &p); err == nil {
+			s.docs[p.TextDocument.URI] = p.TextDocument.Text
+			err = s.publish(p.TextDocument.URI)
+		}
+	case "textDocument/didChange":
+		var p struct {
+			TextDocument   textDocumentIdentifier `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		// Documents sync in full: the last change is the whole text.
+		if err = json.Unmarshal(req.Params, &p); err == nil && len(p.ContentChanges) > 0 {
+			s.docs[p.TextDocument.URI] = p.ContentChanges[len(p.ContentChanges)-1].Text
+			err = s.publish(p.TextDocument.URI)
+		}
+	case "textDocument/didClose":
+		var p struct {
+			TextDocument textDocumentIdentifier `json:"textDocument"`
+		}
+		if err = json.Unmarshal(req.Params, &p); err == nil {
+			delete(s.docs, p.TextDocument.URI)
+			err = s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+				"uri":         p.TextDocument.URI,
+				"diagnostics": []Diagnostic{},
+			})
+		}
+	case "textDocument/hover":
+		var p positionParams
+		if err = json.Unmarshal(req.Params, &p); err == nil {
+			return s.hover(s.docs[p.TextDocument.URI], p.Position), nil
+		}
+	case "textDocument/completion":
+		var p positionParams
+		if err = json.Unmarshal(req.Params, &p); err == nil {
+			return s.complete(s.docs[p.TextDocument.URI], p.Position), nil
+		}
+	case "textDocument/codeAction":
+		var p codeActionParams
+		if err = json.Unmarshal(req.Params, &p); err == nil {
+			return s.codeActions(p), nil
+		}
+	default:
+		if req.ID != nil {
+			return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+		}
+	}
+	if err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil, nil
+}
+
+// capabilities is the initialize result.
+func (s *Server) capabilities() interface{} {
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			// Full document sync.
+			"textDocumentSync": map[string]interface{}{"openClose": true, "change": 1},
+			"hoverProvider":    true,
+			"completionProvider": map[string]interface{}{
+				"triggerCharacters": []string{":", ",", " "},
+			},
+			"codeActionProvider": map[string]interface{}{
+				"codeActionKinds": []string{kindQuickFix, kindSource},
+			},
+		},
+		"serverInfo": map[string]string{"name": source, "version": s.version},
+	}
+}
+
+// publish sends the diagnostics of the document at uri.
+func (s *Server) publish(uri string) error {
+	doc := s.analyze(s.docs[uri])
+	diags := make([]Diagnostic, len(doc.findings))
+	for i, f := range doc.findings {
+		diags[i] = f.Diagnostic
+	}
+	return s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+		"uri":         uri,
+		"diagnostics": diags,
+	})
+}
@@ -1,9 +1,15 @@
 // Command gitignore-cli is the CLI client for the gitignore template server.
 // It talks to the server's public, unauthenticated /api/v1/* endpoints
-// (see IDEA.md: "No user accounts, registration, or login of any kind") to
-// list/search/combine .gitignore templates from the shell, e.g.:
+// (see IDEA.md: "No user accounts, registration, or login of any kind"), or
+// to a gitignore.io-compatible service or GitHub's gitignore API, to
+// list/search/combine .gitignore templates from the shell, failing over to
+// the configured fallback servers and then to a cached copy of the dataset
+// when the server is unreachable, e.g.:
 //
 //	gitignore-cli Go Node > .gitignore
+//
+// Built with -tags embedded it also carries the server's dataset and works
+// with no server at all.
 package main
 
 import (
@@ -11,8 +17,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/apimgr/gitignore/src/client/api"
+	"github.com/apimgr/gitignore/src/client/cache"
 	"github.com/apimgr/gitignore/src/client/cmd"
 	"github.com/apimgr/gitignore/src/client/config"
 	"github.com/apimgr/gitignore/src/client/output"
@@ -22,6 +30,10 @@ import (
 	"github.com/apimgr/gitignore/src/common/i18n"
 )
 
+// embeddedDataset loads the dataset compiled into embedded builds; it is nil
+// in the default build, which needs a server or a cache (see embedded.go).
+var embeddedDataset func() (*cache.Dataset, error)
+
 // Version information (set by build flags via -ldflags -X).
 var (
 	Version   = "dev"
@@ -47,6 +59,9 @@ func main() {
 	configFlag := flag.String("config", "", "Config profile name")
 	outputFlag := flag.String("output", "", "Output format: text, json, table")
 	shellFlag := flag.Bool("shell", false, "Shell integration: --shell {completions|init|help} [SHELL]")
+	offlineFlag := flag.Bool("offline", false, "Use cached templates only; never contact the server")
+	refreshFlag := flag.Bool("refresh", false, "Revalidate the template cache before running the command")
+	updateFlag := flag.Bool("update", false, "Self-update: --update {check|yes|branch} [CHANNEL]")
 
 	flag.Usage = func() { cmd.PrintHelp(Version) }
 	flag.Parse()
@@ -121,13 +136,52 @@ func main() {
 		os.Exit(output.ExitUsage)
 	}
 
+	if *offlineFlag && *refreshFlag {
+		printer.Error("--offline and --refresh cannot be combined")
+		os.Exit(output.ExitUsage)
+	}
+	cacheTTL, err := config.ResolveCacheTTL(cfg, cache.DefaultTTL)
+	if err != nil {
+		printer.Error("%v", err)
+		os.Exit(output.ExitConfig)
+	}
+	cmd.Cache = cache.New(clipath.TemplateCacheDir(), cacheTTL)
+	cmd.Offline = *offlineFlag
+	cmd.HookTemplates = cfg.Hook.Templates
+	if embeddedDataset != nil {
+		d, err := embeddedDataset()
+		if err != nil {
+			printer.Error("loading embedded templates: %v", err)
+			os.Exit(output.ExitGeneral)
+		}
+		cmd.Embedded = d
+	}
+
 	if *debugFlag {
-		fmt.Fprintf(os.Stderr, "debug: config=%s server-flag=%q output=%s color=%s\n",
-			cfgPath, *serverFlag, format, *colorFlag)
+		fmt.Fprintf(os.Stderr, "debug: config=%s server-flag=%q output=%s color=%s offline=%t cache-ttl=%s\n",
+			cfgPath, *serverFlag, format, *colorFlag, *offlineFlag, cacheTTL)
 	}
 
 	serverURL, err := config.ResolveServer(*serverFlag, cfg)
-	if err != nil {
+
+	// --update works without a configured server: releases then come from
+	// GitHub instead of the server's cli_versions.
+	if *updateFlag {
+		client := api.New("")
+		if err == nil && !*offlineFlag {
+			if client, err = newClient(serverURL, cfg); err != nil {
+				printer.Error("%v", err)
+				os.Exit(output.ExitConfig)
+			}
+		}
+		client.Lang = i18n.ResolveCLILang(*langFlag, cfg.Lang)
+		os.Exit(cmd.CmdUpdate(client, printer, cfg, cfgPath, args))
+	}
+
+	// --offline never contacts the server and embedded builds answer
+	// commands from their own dataset, so neither needs one configured;
+	// nor does the pre-commit hook.
+	if err != nil && !*offlineFlag && (cmd.Embedded == nil || len(args) == 0) && !cmd.ServerOptional(args) {
 		if *showVersion {
 			// --version must still work without a configured server.
 			cmd.PrintVersion(Version, CommitID, BuildDate, nil)
@@ -144,21 +198,39 @@ func main() {
 		os.Exit(output.ExitConfig)
 	}
 
-	client := api.New(serverURL)
+	client, err := newClient(serverURL, cfg)
+	if err != nil {
+		printer.Error("%v", err)
+		os.Exit(output.ExitConfig)
+	}
 	// Resolve the output language (--lang → config → LC_ALL/LANG → en) and
 	// advertise it so the server localizes error messages (AI.md PART 30).
 	client.Lang = i18n.ResolveCLILang(*langFlag, cfg.Lang)
 
 	if *showVersion {
+		if *offlineFlag || serverURL == "" {
+			client = nil
+		}
 		cmd.PrintVersion(Version, CommitID, BuildDate, client)
 		os.Exit(output.ExitSuccess)
 	}
 
+	if *refreshFlag {
+		// --refresh alone just refreshes; with a command it runs first.
+		if code := cmd.CmdRefresh(client, printer); code != output.ExitSuccess || len(args) == 0 {
+			os.Exit(code)
+		}
+	}
+
 	// Mode detection (AI.md PART 32 "Automatic Mode Detection"): no
 	// subcommand + an interactive terminal launches the bubbletea TUI by
 	// default; explicit commands and non-interactive/piped/dumb terminals
 	// always use the plain CLI path. There is no --tui/--cli flag.
 	if len(args) == 0 {
+		if *offlineFlag {
+			printer.Error("--offline needs a command; interactive mode requires a server")
+			os.Exit(output.ExitUsage)
+		}
 		if isTUIEligible() {
 			os.Exit(runInteractive(client, cfg, cfgPath))
 		}
@@ -166,7 +238,47 @@ func main() {
 		os.Exit(output.ExitUsage)
 	}
 
-	os.Exit(cmd.Dispatch(client, printer, format, args))
+	// The opt-in background update check runs alongside the command; the
+	// TUI is left alone so nothing writes over its screen.
+	checked := func() {}
+	if !*offlineFlag {
+		checked = cmd.StartUpdateCheck(client, printer, cfg)
+	}
+	code := cmd.Dispatch(client, printer, format, args)
+	checked()
+	os.Exit(code)
+}
+
+// newClient returns the client for serverURL failing over to the profile's
+// server.fallbacks, each speaking its configured API or the one detected on
+// an earlier run. Servers still on auto are probed on first use and the
+// result remembered.
+func newClient(serverURL string, cfg *config.Config) (*api.Client, error) {
+	if !api.ValidBackend(cfg.Server.Backend) {
+		return nil, fmt.Errorf("invalid server.backend %q: expected auto, native, gitignoreio or github", cfg.Server.Backend)
+	}
+	detected := config.LoadDetectedBackends()
+	client := api.New(serverURL)
+	// server.backend describes server.primary, not a --server override.
+	if serverURL == strings.TrimSuffix(cfg.Server.Primary, "/") {
+		client.Backend = config.ResolveBackend(cfg.Server.Backend, serverURL, detected)
+	} else {
+		client.Backend = config.ResolveBackend("", serverURL, detected)
+	}
+	for _, f := range cfg.Server.Fallbacks {
+		if !config.IsValidServerURL(f.URL) {
+			return nil, fmt.Errorf("invalid server.fallbacks url %q", f.URL)
+		}
+		if !api.ValidBackend(f.Backend) {
+			return nil, fmt.Errorf("invalid backend %q for fallback %s: expected auto, native, gitignoreio or github", f.Backend, f.URL)
+		}
+		client.Fallbacks = append(client.Fallbacks, api.Server{URL: f.URL, Backend: config.ResolveBackend(f.Backend, f.URL, detected)})
+	}
+	client.OnDetect = func(url, kind string) {
+		// Best effort: failing to remember only means probing again.
+		_ = config.SaveDetectedBackend(url, kind)
+	}
+	return client, nil
 }
 
 // isTUIEligible reports whether the current process environment qualifies
@@ -0,0 +1,122 @@
+// Package monorepo plans the .gitignore files of a repository holding
+// several packages: one root .gitignore for operating-system and editor
+// templates plus the rules every package shares, and one .gitignore per
+// package directory for its language templates, so a Go service's rules do
+// not reach into the Node frontend next to it. It backs
+// `gitignore-cli init --recursive`.
+package monorepo
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/apimgr/gitignore/src/client/audit"
+)
+
+// Manifests are the files that make a directory a package.
+var Manifests = []string{"go.mod", "package.json", "pyproject.toml", "Cargo.toml"}
+
+// DefaultGlobal are the operating-system templates every root .gitignore
+// gets: their files come from contributors' machines, so nothing in the
+// tree announces them.
+var DefaultGlobal = []string{"macOS", "Windows", "Linux"}
+
+// skipDirs are directories never searched for packages: dependencies,
+// build output and version-control metadata hold manifests of their own.
+var skipDirs = map[string]bool{
+	".git": true, "node_modules": true, "vendor": true, "target": true,
+	".venv": true, "venv": true, "__pycache__": true, "dist": true,
+}
+
+// Package is a directory holding one of the Manifests.
+type Package struct {
+	// Dir is the directory relative to the root, slash-separated; "" is the
+	// root itself.
+	Dir string `json:"dir"`
+	// Templates are the language templates its contents call for.
+	Templates []audit.Recommendation `json:"templates"`
+}
+
+// Detect walks the work tree at root and returns its packages, sorted by
+// directory, and the editor templates anything in the tree calls for.
+// Nested git repositories are left to their own .gitignore files. With
+// recursive false only root itself is examined, and it is returned as a
+// package even without a manifest.
+func Detect(root string, recursive bool) ([]Package, []audit.Recommendation, error) {
+	var pkgs []Package
+	var all []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			rel = ""
+		} else if skipDirs[d.Name()] || !recursive {
+			return fs.SkipDir
+		} else if _, err := os.Stat(filepath.Join(p, ".git")); err == nil {
+			return fs.SkipDir
+		}
+
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(entries))
+		isPackage := rel == "" && !recursive
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() {
+				name += "/"
+			}
+			names = append(names, name)
+			all = append(all, joinSlash(rel, name))
+			for _, m := range Manifests {
+				if !e.IsDir() && e.Name() == m {
+					isPackage = true
+				}
+			}
+		}
+		if isPackage {
+			pkg := Package{Dir: rel, Templates: []audit.Recommendation{}}
+			for _, rec := range audit.Recommend(names) {
+				if !audit.Global(rec.Template) {
+					rec.Marker = joinSlash(rel, rec.Marker)
+					pkg.Templates = append(pkg.Templates, rec)
+				}
+			}
+			pkgs = append(pkgs, pkg)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Dir < pkgs[j].Dir })
+
+	var global []audit.Recommendation
+	for _, rec := range audit.Recommend(all) {
+		if audit.Global(rec.Template) {
+			global = append(global, rec)
+		}
+	}
+	return pkgs, global, nil
+}
+
+// joinSlash joins dir and name like path.Join but keeps the trailing slash
+// that marks name as a directory.
+func joinSlash(dir, name string) string {
+	if dir == "" {
+		return name
+	}
+	return dir + "/" + name
+}
@@ -0,0 +1,109 @@
+package monorepo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestPlanRecursive verifies that a recursive init plan gives each package
+// its own language rules, hoists the rules every package shares to the
+// root, and updates only its generated block on a rerun.
+func TestPlanRecursive(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"services/api/go.mod":       "module api\n",
+		"web/package.json":          "{}\n",
+		"web/node_modules/x/go.mod": "module x\n",
+		"tools/pyproject.toml":      "[project]\n",
+		".idea/workspace.xml":       "",
+		"web/.gitignore":            "# kept\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkgs, editors, err := Detect(root, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dirs []string
+	for _, pkg := range pkgs {
+		dirs = append(dirs, pkg.Dir+"="+pkg.Templates[0].Template)
+	}
+	if want := []string{"services/api=Go", "tools=Python", "web=Node"}; !reflect.DeepEqual(dirs, want) {
+		t.Errorf("packages = %v, want %v", dirs, want)
+	}
+	if len(editors) != 1 || editors[0].Template != "JetBrains" {
+		t.Errorf("editor templates = %v, want JetBrains", editors)
+	}
+
+	contents := map[string]string{
+		"macOS":     ".DS_Store\n",
+		"JetBrains": ".idea/\n",
+		"Go":        "# Binaries\n*.exe\n.env\n/bin/\n",
+		"Node":      "node_modules/\n.env\n*.log\n",
+		"Python":    "__pycache__/\n.env\n*.log\n",
+	}
+	plan := func() []*File {
+		t.Helper()
+		planned, err := Plan(root, pkgs, []string{"macOS", "Linux", "JetBrains"}, contents)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return planned
+	}
+	planned := plan()
+	var paths []string
+	for _, f := range planned {
+		paths = append(paths, f.Path)
+	}
+	if want := []string{".gitignore", "services/api/.gitignore", "tools/.gitignore", "web/.gitignore"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("planned files = %v, want %v", paths, want)
+	}
+	rootFile := planned[0]
+	if !reflect.DeepEqual(rootFile.Templates, []string{"macOS", "JetBrains"}) {
+		t.Errorf("root templates = %v, want the available global ones", rootFile.Templates)
+	}
+	if !reflect.DeepEqual(rootFile.Shared, []string{".env"}) {
+		t.Errorf("shared rules = %v, want [.env]", rootFile.Shared)
+	}
+	for _, f := range planned[1:] {
+		if strings.Contains(f.New, ".env\n") {
+			t.Errorf("%s still has the hoisted rule:\n%s", f.Path, f.New)
+		}
+	}
+	if got := planned[1].New; !strings.Contains(got, "# Templates: Go\n") || !strings.Contains(got, "/bin/\n") {
+		t.Errorf("services/api/.gitignore = %q, want the Go rules", got)
+	}
+	web := planned[3]
+	if !web.Exists || !strings.HasPrefix(web.New, "# kept\n\n") || strings.Contains(web.New, "__pycache__") {
+		t.Errorf("web/.gitignore = %q, want its own content then the Node rules", web.New)
+	}
+
+	for _, f := range planned {
+		if err := f.Write(root); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range plan() {
+		if f.Changed() {
+			t.Errorf("%s changed on a rerun:\n%s", f.Path, f.New)
+		}
+	}
+
+	contents["Node"] += "dist/\n"
+	for _, f := range plan() {
+		if f.Path == "web/.gitignore" && (!f.Changed() || strings.Count(f.New, "# kept") != 1 || !strings.Contains(f.New, "dist/\n")) {
+			t.Errorf("web/.gitignore not updated in place:\n%s", f.New)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package monorepo
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// blockStart and blockEnd delimit the part of a .gitignore init generates.
+// Rerunning init replaces what lies between them and keeps everything else.
+const (
+	blockStart = "# >>> gitignore-cli init: generated, rerun init to update >>>"
+	blockEnd   = "# <<< gitignore-cli init <<<"
+)
+
+// File is one planned .gitignore.
+type File struct {
+	// Path is the file relative to the root, slash-separated.
+	Path      string   `json:"path"`
+	Templates []string `json:"templates"`
+	// Shared are the rules hoisted into the root .gitignore because every
+	// package's templates have them.
+	Shared []string `json:"shared,omitempty"`
+	Exists bool     `json:"exists"`
+	Old    string   `json:"-"`
+	New    string   `json:"-"`
+}
+
+// Changed reports whether writing the file would change anything.
+func (f *File) Changed() bool {
+	return !f.Exists || f.Old != f.New
+}
+
+// Write writes the planned content to the work tree at root.
+func (f *File) Write(root string) error {
+	return os.WriteFile(filepath.Join(root, filepath.FromSlash(f.Path)), []byte(f.New), 0o644)
+}
+
+// Plan lays out the .gitignore files for pkgs in the work tree at root.
+// The root file gets the global templates, the root package's own
+// templates, and the rules common to the templates of every other package
+// when there are at least two; each other package gets its language
+// templates minus the rules the root file already applies everywhere.
+// contents maps template names to their content; templates missing from it
+// are left out. A package left with no templates gets no file. The root
+// file comes first, the rest sorted by path.
+func Plan(root string, pkgs []Package, global []string, contents map[string]string) ([]*File, error) {
+	rootNames := append([]string(nil), global...)
+	var others []Package
+	for _, pkg := range pkgs {
+		if pkg.Dir == "" {
+			for _, rec := range pkg.Templates {
+				rootNames = append(rootNames, rec.Template)
+			}
+			continue
+		}
+		others = append(others, pkg)
+	}
+	rootNames = available(rootNames, contents)
+
+	// Rules the root file applies everywhere need not be repeated below it.
+	drop := make(map[string]bool)
+	for _, name := range rootNames {
+		for _, rule := range rules(contents[name]) {
+			if floating(rule) {
+				drop[rule] = true
+			}
+		}
+	}
+
+	var shared []string
+	if len(others) >= 2 {
+		count := make(map[string]int)
+		var order []string
+		for _, pkg := range others {
+			seen := make(map[string]bool)
+			for _, name := range available(templateNames(pkg), contents) {
+				for _, rule := range rules(contents[name]) {
+					if !floating(rule) || seen[rule] {
+						continue
+					}
+					seen[rule] = true
+					if count[rule] == 0 {
+						order = append(order, rule)
+					}
+					count[rule]++
+				}
+			}
+		}
+		for _, rule := range order {
+			if count[rule] == len(others) && !drop[rule] {
+				shared = append(shared, rule)
+			}
+		}
+	}
+
+	rootFile, err := planFile(root, ".gitignore", rootNames, render(rootNames, contents, nil, shared))
+	if err != nil {
+		return nil, err
+	}
+	rootFile.Shared = shared
+	files := []*File{rootFile}
+	for _, rule := range shared {
+		drop[rule] = true
+	}
+	for _, pkg := range others {
+		names := available(templateNames(pkg), contents)
+		if len(names) == 0 {
+			continue
+		}
+		f, err := planFile(root, path.Join(pkg.Dir, ".gitignore"), names, render(names, contents, drop, nil))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	sort.SliceStable(files[1:], func(i, j int) bool { return files[1+i].Path < files[1+j].Path })
+	return files, nil
+}
+
+// planFile reads the current file at rel and merges block into it.
+func planFile(root, rel string, names []string, block string) (*File, error) {
+	f := &File{Path: rel, Templates: names}
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+	switch {
+	case err == nil:
+		f.Exists = true
+		f.Old = string(data)
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+	f.New = merge(f.Old, block)
+	return f, nil
+}
+
+// render builds the generated block: a "# Templates:" header the
+// pre-commit hook reads, a "### name ###" section per template without the
+// dropped rules or ones an earlier section has, then the shared rules.
+func render(names []string, contents map[string]string, drop map[string]bool, shared []string) string {
+	var b strings.Builder
+	b.WriteString(blockStart + "\n")
+	b.WriteString("# Templates: " + strings.Join(names, ", ") + "\n")
+	written := make(map[string]bool)
+	section := func(title string, lines []string) {
+		var body []string
+		for _, line := range lines {
+			rule := strings.TrimSpace(line)
+			isRule := rule != "" && !strings.HasPrefix(rule, "#")
+			if isRule && (drop[rule] || written[rule]) {
+				continue
+			}
+			if rule == "" && (len(body) == 0 || body[len(body)-1] == "") {
+				continue
+			}
+			if isRule {
+				written[rule] = true
+			}
+			if rule == "" {
+				line = ""
+			}
+			body = append(body, line)
+		}
+		for len(body) > 0 && body[len(body)-1] == "" {
+			body = body[:len(body)-1]
+		}
+		b.WriteString("\n### " + title + " ###\n")
+		for _, line := range body {
+			b.WriteString(line + "\n")
+		}
+	}
+	for _, name := range names {
+		section(name, strings.Split(contents[name], "\n"))
+	}
+	if len(shared) > 0 {
+		section("Shared", append([]string{"# Rules every package's templates have."}, shared...))
+	}
+	b.WriteString(blockEnd + "\n")
+	return b.String()
+}
+
+// merge replaces the generated block in old with block, or appends block
+// when old has none.
+func merge(old, block string) string {
+	if i := strings.Index(old, blockStart); i >= 0 {
+		if j := strings.Index(old[i:], blockEnd); j >= 0 {
+			end := i + j + len(blockEnd)
+			if end < len(old) && old[end] == '\n' {
+				end++
+			}
+			return old[:i] + block + old[end:]
+		}
+	}
+	if old == "" {
+		return block
+	}
+	if !strings.HasSuffix(old, "\n") {
+		old += "\n"
+	}
+	return old + "\n" + block
+}
+
+// Regenerate rebuilds the block init generated in content for names,
+// keeping the rules it hoisted into the block's Shared section and
+// everything outside the block; content without a block gets one appended.
+// contents maps template names to their content; templates missing from it
+// are left out.
+func Regenerate(content string, names []string, contents map[string]string) string {
+	var shared []string
+	if i := strings.Index(content, blockStart); i >= 0 {
+		block := content[i:]
+		if j := strings.Index(block, blockEnd); j >= 0 {
+			block = block[:j]
+		}
+		if k := strings.Index(block, "\n### Shared ###\n"); k >= 0 {
+			shared = rules(block[k:])
+		}
+	}
+	return merge(content, render(available(names, contents), contents, nil, shared))
+}
+
+// BlockEnd returns the 0-based line number of the end marker of the block
+// init generated in content, where rules added to the block belong.
+func BlockEnd(content string) (line int, ok bool) {
+	i := strings.Index(content, blockStart)
+	if i < 0 {
+		return 0, false
+	}
+	j := strings.Index(content[i:], blockEnd)
+	if j < 0 {
+		return 0, false
+	}
+	return strings.Count(content[:i+j], "\n"), true
+}
+
+// rules returns the rule lines of a template, trimmed.
+func rules(content string) []string {
+	var out []string
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// floating reports whether rule matches at any depth below the .gitignore
+// holding it, so moving it to a parent directory's file only widens it.
+// Negations stay put: their meaning depends on the rules before them.
+func floating(rule string) bool {
+	return !strings.HasPrefix(rule, "!") && !strings.Contains(strings.TrimSuffix(rule, "/"), "/")
+}
+
+// templateNames returns the names of pkg's templates.
+func templateNames(pkg Package) []string {
+	names := make([]string, len(pkg.Templates))
+	for i, rec := range pkg.Templates {
+		names[i] = rec.Template
+	}
+	return names
+}
+
+// available returns names without duplicates or templates missing from
+// contents.
+func available(names []string, contents map[string]string) []string {
+	out := []string{}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if _, ok := contents[name]; ok && !seen[name] {
+			out = append(out, name)
+			seen[name] = true
+		}
+	}
+	return out
+}
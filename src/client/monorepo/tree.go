@@ -0,0 +1,56 @@
+package monorepo
+
+import (
+	"sort"
+	"strings"
+)
+
+// Tree draws files as a directory tree rooted at ".", with label(f)
+// appended to each file's line.
+func Tree(files []*File, label func(*File) string) string {
+	type node struct {
+		children map[string]*node
+		file     *File
+	}
+	top := &node{children: map[string]*node{}}
+	for _, f := range files {
+		n := top
+		for _, part := range strings.Split(f.Path, "/") {
+			child, ok := n.children[part]
+			if !ok {
+				child = &node{children: map[string]*node{}}
+				n.children[part] = child
+			}
+			n = child
+		}
+		n.file = f
+	}
+
+	var b strings.Builder
+	b.WriteString(".\n")
+	var walk func(n *node, indent string)
+	walk = func(n *node, indent string) {
+		names := make([]string, 0, len(n.children))
+		for name := range n.children {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for i, name := range names {
+			child := n.children[name]
+			branch, next := "├── ", "│   "
+			if i == len(names)-1 {
+				branch, next = "└── ", "    "
+			}
+			b.WriteString(indent + branch + name)
+			if child.file != nil {
+				b.WriteString("  " + label(child.file))
+			} else {
+				b.WriteString("/")
+			}
+			b.WriteString("\n")
+			walk(child, indent+next)
+		}
+	}
+	walk(top, "")
+	return b.String()
+}
@@ -80,6 +80,12 @@ func (p *Printer) Warn(format string, args ...interface{}) {
 	fmt.Fprintln(os.Stderr, p.Yellow("Warning: ")+fmt.Sprintf(format, args...))
 }
 
+// Info prints a formatted status message to stderr, keeping stdout for
+// command output.
+func (p *Printer) Info(format string, args ...interface{}) {
+	fmt.Fprintln(os.Stderr, fmt.Sprintf(format, args...))
+}
+
 // FormatTable renders rows as a simple aligned, box-drawn table.
 func FormatTable(headers []string, rows [][]string) string {
 	widths := make([]int, len(headers))
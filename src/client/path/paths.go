@@ -42,6 +42,13 @@ func CacheDir() string {
 	return filepath.Join(home, ".cache", orgName, projectName)
 }
 
+// TemplateCacheDir returns the directory holding the offline copy of the
+// server's template dataset. It lives under DataDir rather than CacheDir:
+// the CLI depends on it when offline, so cache cleaners must not remove it.
+func TemplateCacheDir() string {
+	return filepath.Join(DataDir(), "templates")
+}
+
 // LogDir returns the CLI log directory.
 func LogDir() string {
 	if runtime.GOOS == "windows" {
@@ -67,6 +74,12 @@ func ConfigFile(name string) string {
 	return filepath.Join(ConfigDir(), name)
 }
 
+// DetectedBackendsFile returns the file remembering each server's
+// autodetected API. It is a cache: losing it only costs a probe.
+func DetectedBackendsFile() string {
+	return filepath.Join(CacheDir(), "backends.yml")
+}
+
 // LogFile returns the CLI log file path.
 func LogFile() string {
 	return filepath.Join(LogDir(), "cli.log")
@@ -25,16 +25,17 @@ const (
 	screenView
 	screenStats
 	screenMessage
+	screenComposer
+	screenComposerSave
 )
 
 // nameListMode distinguishes what a screenNames listing is used for, so a
-// single list widget can serve list/search/categories/combine.
+// single list widget can serve list/search/categories.
 type nameListMode int
 
 const (
 	nameModeBrowse nameListMode = iota
 	nameModeCategoryDrill
-	nameModeCombinePick
 )
 
 type (
@@ -51,11 +52,6 @@ type (
 		stats map[string]interface{}
 		err   error
 	}
-	combineLoadedMsg struct {
-		content string
-		names   []string
-		err     error
-	}
 	configSavedMsg struct{ err error }
 )
 
@@ -78,7 +74,8 @@ type Model struct {
 	names list.Model
 
 	nameMode nameListMode
-	selected map[string]bool
+
+	composer *composer
 
 	input textinput.Model
 
@@ -117,7 +114,6 @@ func New(client *api.Client, cfg *config.Config, cfgPath string) Model {
 		menu:     menuList,
 		names:    namesList,
 		input:    ti,
-		selected: map[string]bool{},
 		screen:   screenMenu,
 	}
 
@@ -145,6 +141,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.names.SetSize(msg.Width, msg.Height-headerHeight)
 		m.viewport.Width = msg.Width
 		m.viewport.Height = msg.Height - headerHeight
+		m.resizeComposer()
 		return m, nil
 
 	case tea.KeyMsg:
@@ -192,17 +189,38 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.screen = screenStats
 		return m, nil
 
-	case combineLoadedMsg:
+	case composerLoadedMsg:
 		m.errMsg = ""
 		if msg.err != nil {
 			m.errMsg = msg.err.Error()
-			m.screen = screenNames
+			m.screen = screenMenu
 			return m, nil
 		}
-		m.viewName = strings.Join(msg.names, " + ")
-		m.viewport = viewport.New(m.width, m.height-4)
-		m.viewport.SetContent(msg.content)
-		m.screen = screenView
+		return m.openComposer(msg.names), nil
+
+	case previewLoadedMsg:
+		if m.composer != nil {
+			m.composer.previewFor(msg)
+		}
+		return m, nil
+
+	case composerSavedMsg:
+		if msg.err != nil {
+			m.errMsg = msg.err.Error()
+			m.screen = screenComposer
+			return m, nil
+		}
+		m.message = "Wrote ./" + gitignoreFile + "."
+		if msg.merged {
+			m.message = "Merged into ./" + gitignoreFile + "."
+		}
+		m.screen = screenMenu
+		return m, nil
+
+	case prefsSavedMsg:
+		if msg.err != nil {
+			m.errMsg = msg.err.Error()
+		}
 		return m, nil
 
 	case configSavedMsg:
@@ -222,8 +240,6 @@ func namesTitle(mode nameListMode) string {
 	switch mode {
 	case nameModeCategoryDrill:
 		return "Category templates (enter to view)"
-	case nameModeCombinePick:
-		return "Combine — space to select, enter to combine"
 	default:
 		return "Templates (enter to view)"
 	}
@@ -246,6 +262,10 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.updateSearchInput(msg)
 	case screenView:
 		return m.updateView(msg)
+	case screenComposer:
+		return m.updateComposer(msg)
+	case screenComposerSave:
+		return m.updateComposerSave(msg)
 	case screenStats, screenMessage:
 		if msg.String() == "esc" || msg.String() == "q" || msg.String() == "enter" {
 			m.screen = screenMenu
@@ -266,6 +286,7 @@ func (m Model) updateSetup(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		m.cfg.Server.Primary = strings.TrimSuffix(url, "/")
 		m.client.BaseURL = m.cfg.Server.Primary
+		m.client.Backend = m.cfg.Server.Backend
 		cfg, path := m.cfg, m.cfgPath
 		return m, func() tea.Msg { return configSavedMsg{err: config.Save(path, cfg)} }
 	case "esc", "ctrl+q":
@@ -308,11 +329,7 @@ func (m Model) dispatchMenu(id string) (tea.Model, tea.Cmd) {
 			return namesLoadedMsg{names: names, mode: nameModeCategoryDrill, err: err}
 		}
 	case "combine":
-		m.selected = map[string]bool{}
-		return m, func() tea.Msg {
-			names, err := client.List()
-			return namesLoadedMsg{names: names, mode: nameModeCombinePick, err: err}
-		}
+		return m, loadComposer(client)
 	case "stats":
 		return m, func() tea.Msg {
 			stats, err := client.Stats()
@@ -368,14 +385,6 @@ func (m Model) updateNames(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.screen = screenMenu
 		m.errMsg = ""
 		return m, nil
-	case " ":
-		if m.nameMode == nameModeCombinePick {
-			if item, ok := m.names.SelectedItem().(nameItem); ok {
-				name := string(item)
-				m.selected[name] = !m.selected[name]
-			}
-			return m, nil
-		}
 	case "enter":
 		item, ok := m.names.SelectedItem().(nameItem)
 		if !ok {
@@ -389,21 +398,6 @@ func (m Model) updateNames(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				names, err := client.CategoryTemplates(name)
 				return namesLoadedMsg{names: names, mode: nameModeBrowse, err: err}
 			}
-		case nameModeCombinePick:
-			var picked []string
-			for n, on := range m.selected {
-				if on {
-					picked = append(picked, n)
-				}
-			}
-			if len(picked) == 0 {
-				picked = []string{name}
-			}
-			sort.Strings(picked)
-			return m, func() tea.Msg {
-				content, err := client.Combine(picked)
-				return combineLoadedMsg{content: content, names: picked, err: err}
-			}
 		default:
 			return m, func() tea.Msg {
 				tmpl, err := client.GetTemplate(name)
@@ -443,9 +437,6 @@ func (m Model) View() string {
 		body = m.menu.View()
 	case screenNames:
 		body = m.names.View()
-		if m.nameMode == nameModeCombinePick {
-			body += "\n" + m.styles.Help.Render(fmt.Sprintf("selected: %d", len(selectedNames(m.selected))))
-		}
 	case screenSearchInput:
 		body = m.styles.Title.Render("Search templates") + "\n\n" +
 			m.input.View() + "\n\n" +
@@ -455,6 +446,10 @@ func (m Model) View() string {
 			m.styles.Help.Render("esc: back")
 	case screenStats:
 		body = m.renderStats()
+	case screenComposer:
+		body = m.composerView()
+	case screenComposerSave:
+		body = m.composerSaveView()
 	}
 
 	if m.errMsg != "" {
@@ -483,16 +478,6 @@ func (m Model) renderStats() string {
 	return b.String()
 }
 
-func selectedNames(sel map[string]bool) []string {
-	var out []string
-	for n, on := range sel {
-		if on {
-			out = append(out, n)
-		}
-	}
-	return out
-}
-
 // Run starts the bubbletea program and returns once the user quits.
 func Run(client *api.Client, cfg *config.Config, cfgPath string) error {
 	p := tea.NewProgram(New(client, cfg, cfgPath), tea.WithAltScreen())
@@ -0,0 +1,564 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/apimgr/gitignore/src/client/api"
+	"github.com/apimgr/gitignore/src/client/audit"
+	"github.com/apimgr/gitignore/src/client/config"
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// gitignoreFile is where the composer saves, relative to the working
+// directory.
+const gitignoreFile = ".gitignore"
+
+type (
+	composerLoadedMsg struct {
+		names []string
+		err   error
+	}
+	// previewLoadedMsg answers preview request seq; replies to requests
+	// superseded by a later selection change are dropped.
+	previewLoadedMsg struct {
+		seq     int
+		content string
+		err     error
+	}
+	composerSavedMsg struct {
+		merged bool
+		err    error
+	}
+	prefsSavedMsg struct{ err error }
+)
+
+// composer is the state of the compose screen: a filterable template list
+// on the left, the templates picked in composition order, and a live
+// preview of their combination on the right.
+type composer struct {
+	list        list.Model
+	picked      []string
+	suggestions []audit.Recommendation
+	// recent is the index of the tui.recent entry "r" loads next.
+	recent int
+
+	preview    viewport.Model
+	seq        int
+	content    string
+	ready      bool
+	previewErr string
+
+	// The save dialog.
+	merge  bool
+	exists bool
+	old    string
+	new    string
+	diff   viewport.Model
+}
+
+// position returns name's index in the composition, or -1.
+func (c *composer) position(name string) int {
+	for i, p := range c.picked {
+		if p == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// toggle adds name to the end of the composition, or removes it.
+func (c *composer) toggle(name string) {
+	if i := c.position(name); i >= 0 {
+		c.picked = append(c.picked[:i], c.picked[i+1:]...)
+		return
+	}
+	c.picked = append(c.picked, name)
+}
+
+// move shifts name by delta places within the composition and reports
+// whether it moved.
+func (c *composer) move(name string, delta int) bool {
+	i := c.position(name)
+	j := i + delta
+	if i < 0 || j < 0 || j >= len(c.picked) {
+		return false
+	}
+	c.picked[i], c.picked[j] = c.picked[j], c.picked[i]
+	return true
+}
+
+// pickDelegate renders a composer list row: the template's place in the
+// composition, a star for favorites, and its name.
+type pickDelegate struct {
+	c        *composer
+	favorite func(string) bool
+	styles   Styles
+}
+
+func (d pickDelegate) Height() int                             { return 1 }
+func (d pickDelegate) Spacing() int                            { return 0 }
+func (d pickDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (d pickDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	name := string(item.(nameItem))
+	box := "[ ]"
+	if i := d.c.position(name); i >= 0 {
+		box = fmt.Sprintf("[%d]", i+1)
+	}
+	star := "  "
+	if d.favorite(name) {
+		star = "★ "
+	}
+	if index == m.Index() {
+		fmt.Fprint(w, d.styles.Selected.Render("> "+box+" "+star+name))
+		return
+	}
+	fmt.Fprint(w, "  "+box+" "+star+name)
+}
+
+// openComposer builds the compose screen over every template name.
+func (m Model) openComposer(names []string) Model {
+	c := &composer{preview: viewport.New(0, 0), diff: viewport.New(0, 0)}
+	c.list = list.New(nil, pickDelegate{c: c, favorite: m.isFavorite, styles: m.styles}, 0, 0)
+	c.list.Title = "Compose"
+	c.list.SetShowStatusBar(false)
+	c.list.SetShowHelp(false)
+	m.composer = c
+	m.sortComposer(names)
+	c.suggestions = suggest(names)
+	c.preview.SetContent(m.styles.Muted.Render("Select templates with space to preview them here."))
+	m.resizeComposer()
+	m.screen = screenComposer
+	return m
+}
+
+// sortComposer lists names with favorites first, keeping the cursor on the
+// same template.
+func (m Model) sortComposer(names []string) {
+	c := m.composer
+	current, _ := c.list.SelectedItem().(nameItem)
+	sort.SliceStable(names, func(i, j int) bool {
+		fi, fj := m.isFavorite(names[i]), m.isFavorite(names[j])
+		if fi != fj {
+			return fi
+		}
+		return strings.ToLower(names[i]) < strings.ToLower(names[j])
+	})
+	items := make([]list.Item, len(names))
+	for i, n := range names {
+		items[i] = nameItem(n)
+	}
+	c.list.SetItems(items)
+	for i, n := range names {
+		if n == string(current) {
+			c.list.Select(i)
+		}
+	}
+}
+
+// resizeComposer splits the screen between the list and the preview.
+func (m Model) resizeComposer() {
+	c := m.composer
+	if c == nil {
+		return
+	}
+	left := max(m.width*2/5, 30)
+	body := max(m.height-5, 3)
+	c.list.SetSize(left, body)
+	c.preview.Width = max(m.width-left-4, 10)
+	c.preview.Height = max(body-2, 1)
+	c.diff.Width = m.width
+	c.diff.Height = max(m.height-6, 1)
+}
+
+// suggest returns the templates the marker files in the working directory
+// call for, spelled as the server lists them.
+func suggest(names []string) []audit.Recommendation {
+	entries, err := os.ReadDir(".")
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, e := range entries {
+		if e.IsDir() {
+			paths = append(paths, e.Name()+"/")
+		} else {
+			paths = append(paths, e.Name())
+		}
+	}
+	known := make(map[string]string, len(names))
+	for _, n := range names {
+		known[strings.ToLower(n)] = n
+	}
+	var out []audit.Recommendation
+	for _, rec := range audit.Recommend(paths) {
+		if name, ok := known[strings.ToLower(rec.Template)]; ok {
+			rec.Template = name
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+func (m Model) isFavorite(name string) bool {
+	return m.cfg != nil && m.cfg.TUI.IsFavorite(name)
+}
+
+// refreshPreview requests the combination of the current selection.
+func (m Model) refreshPreview() tea.Cmd {
+	c := m.composer
+	c.seq++
+	c.ready = false
+	if len(c.picked) == 0 {
+		c.content = ""
+		c.preview.SetContent(m.styles.Muted.Render("Select templates with space to preview them here."))
+		return nil
+	}
+	seq, picked, client := c.seq, append([]string(nil), c.picked...), m.client
+	return func() tea.Msg {
+		content, err := client.Combine(picked)
+		return previewLoadedMsg{seq: seq, content: content, err: err}
+	}
+}
+
+// savePrefs writes the favorites and recent compositions to cli.yml.
+func (m Model) savePrefs() tea.Cmd {
+	if m.cfg == nil || m.cfgPath == "" {
+		return nil
+	}
+	cfg, path := m.cfg, m.cfgPath
+	return func() tea.Msg { return prefsSavedMsg{err: config.Save(path, cfg)} }
+}
+
+func (m Model) updateComposer(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := m.composer
+	if c.list.SettingFilter() {
+		var cmd tea.Cmd
+		c.list, cmd = c.list.Update(msg)
+		return m, cmd
+	}
+	current, _ := c.list.SelectedItem().(nameItem)
+	name := string(current)
+
+	switch msg.String() {
+	case "esc", "q":
+		if msg.String() == "esc" && c.list.IsFiltered() {
+			break
+		}
+		m.screen = screenMenu
+		m.errMsg = ""
+		return m, nil
+	case " ":
+		if name != "" {
+			c.toggle(name)
+			return m, m.refreshPreview()
+		}
+		return m, nil
+	case "K", "shift+up":
+		if c.move(name, -1) {
+			return m, m.refreshPreview()
+		}
+		return m, nil
+	case "J", "shift+down":
+		if c.move(name, 1) {
+			return m, m.refreshPreview()
+		}
+		return m, nil
+	case "*":
+		if name == "" || m.cfg == nil {
+			return m, nil
+		}
+		m.cfg.TUI.ToggleFavorite(name)
+		names := make([]string, 0, len(c.list.Items()))
+		for _, it := range c.list.Items() {
+			names = append(names, string(it.(nameItem)))
+		}
+		m.sortComposer(names)
+		return m, m.savePrefs()
+	case "a":
+		added := false
+		for _, rec := range c.suggestions {
+			if c.position(rec.Template) < 0 {
+				c.picked = append(c.picked, rec.Template)
+				added = true
+			}
+		}
+		if added {
+			return m, m.refreshPreview()
+		}
+		return m, nil
+	case "r":
+		if m.cfg == nil || len(m.cfg.TUI.Recent) == 0 {
+			return m, nil
+		}
+		c.recent %= len(m.cfg.TUI.Recent)
+		c.picked = m.knownNames(m.cfg.TUI.Recent[c.recent])
+		c.recent++
+		return m, m.refreshPreview()
+	case "ctrl+u":
+		c.preview.HalfPageUp()
+		return m, nil
+	case "ctrl+d":
+		c.preview.HalfPageDown()
+		return m, nil
+	case "enter", "ctrl+s":
+		if len(c.picked) == 0 || !c.ready {
+			return m, nil
+		}
+		return m.openSave()
+	}
+	var cmd tea.Cmd
+	c.list, cmd = c.list.Update(msg)
+	return m, cmd
+}
+
+// knownNames returns names the list has, spelled as it does.
+func (m Model) knownNames(names []string) []string {
+	known := make(map[string]string)
+	for _, it := range m.composer.list.Items() {
+		n := string(it.(nameItem))
+		known[strings.ToLower(n)] = n
+	}
+	var out []string
+	for _, n := range names {
+		if k, ok := known[strings.ToLower(n)]; ok {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// openSave shows the save dialog: the diff writing or merging the preview
+// into ./.gitignore would make. Merging is the default for an existing file.
+func (m Model) openSave() (tea.Model, tea.Cmd) {
+	c := m.composer
+	data, err := os.ReadFile(gitignoreFile)
+	switch {
+	case err == nil:
+		c.exists, c.old, c.merge = true, string(data), true
+	case os.IsNotExist(err):
+		c.exists, c.old, c.merge = false, "", false
+	default:
+		m.errMsg = err.Error()
+		return m, nil
+	}
+	m.errMsg = ""
+	m.renderSaveDiff()
+	m.screen = screenComposerSave
+	return m, nil
+}
+
+// renderSaveDiff computes the planned file and shows its diff.
+func (m Model) renderSaveDiff() {
+	c := m.composer
+	c.new = c.content
+	if c.merge {
+		c.new = mergeGitignore(c.old, c.content)
+	}
+	from := "a/" + gitignoreFile
+	if !c.exists {
+		from = "/dev/null"
+	}
+	diff := template.UnifiedDiff(from, "b/"+gitignoreFile, c.old, c.new)
+	if diff == "" {
+		c.diff.SetContent(m.styles.Muted.Render("No changes: " + gitignoreFile + " already has these rules."))
+		return
+	}
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			lines[i] = m.styles.InputLabel.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = m.styles.Success.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = m.styles.Error.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = m.styles.Status.Render(line)
+		}
+	}
+	c.diff.SetContent(strings.Join(lines, "\n"))
+	c.diff.GotoTop()
+}
+
+func (m Model) updateComposerSave(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := m.composer
+	switch msg.String() {
+	case "esc", "n", "q":
+		m.screen = screenComposer
+		return m, nil
+	case "tab", "m":
+		if c.exists {
+			c.merge = !c.merge
+			m.renderSaveDiff()
+		}
+		return m, nil
+	case "y", "enter":
+		if c.exists && c.new == c.old {
+			m.screen = screenComposer
+			return m, nil
+		}
+		content, merged := c.new, c.merge
+		if m.cfg != nil {
+			m.cfg.TUI.AddRecent(c.picked)
+		}
+		save := m.savePrefs()
+		return m, func() tea.Msg {
+			if err := os.WriteFile(gitignoreFile, []byte(content), 0o644); err != nil {
+				return composerSavedMsg{err: err}
+			}
+			if save != nil {
+				if msg, ok := save().(prefsSavedMsg); ok && msg.err != nil {
+					return composerSavedMsg{merged: merged, err: fmt.Errorf("saved %s, but not cli.yml: %w", gitignoreFile, msg.err)}
+				}
+			}
+			return composerSavedMsg{merged: merged}
+		}
+	}
+	var cmd tea.Cmd
+	c.diff, cmd = c.diff.Update(msg)
+	return m, cmd
+}
+
+func (m Model) composerView() string {
+	c := m.composer
+	left := c.list.View()
+	var right string
+	if c.previewErr != "" {
+		right = m.styles.Error.Render("Preview failed: " + c.previewErr)
+	} else {
+		right = c.preview.View()
+	}
+	right = m.styles.Border.Width(c.preview.Width).Height(c.preview.Height).Render(right)
+
+	order := m.styles.Muted.Render("nothing selected")
+	if len(c.picked) > 0 {
+		order = strings.Join(c.picked, " › ")
+	}
+	lines := []string{
+		lipgloss.JoinHorizontal(lipgloss.Top, left, " ", right),
+		m.styles.InputLabel.Render("Order: ") + order,
+	}
+	var extra []string
+	if len(c.suggestions) > 0 {
+		var s []string
+		for _, rec := range c.suggestions {
+			s = append(s, fmt.Sprintf("%s (%s)", rec.Template, rec.Marker))
+		}
+		extra = append(extra, m.styles.InputLabel.Render("Suggested: ")+strings.Join(s, ", ")+m.styles.Help.Render("  a: add"))
+	}
+	if m.cfg != nil && len(m.cfg.TUI.Recent) > 0 {
+		next := m.cfg.TUI.Recent[c.recent%len(m.cfg.TUI.Recent)]
+		extra = append(extra, m.styles.InputLabel.Render("Recent: ")+strings.Join(next, " + ")+m.styles.Help.Render("  r: load"))
+	}
+	if len(extra) > 0 {
+		lines = append(lines, strings.Join(extra, "   "))
+	}
+	lines = append(lines, m.styles.Help.Render("space: select  ·  K/J: move  ·  /: filter  ·  *: favorite  ·  ctrl+u/d: scroll preview  ·  enter: save  ·  esc: back"))
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) composerSaveView() string {
+	c := m.composer
+	action := "Write"
+	if c.merge {
+		action = "Merge into"
+	}
+	help := "y: confirm  ·  esc: back"
+	if c.exists {
+		help = "y: confirm  ·  tab: write/merge  ·  esc: back"
+	}
+	return m.styles.Title.Render(fmt.Sprintf("%s ./%s — %s", action, gitignoreFile, strings.Join(c.picked, " + "))) + "\n" +
+		c.diff.View() + "\n" +
+		m.styles.Help.Render(help)
+}
+
+// mergeGitignore appends to existing the sections of the combined file
+// whose rules existing lacks, each without the rules it already has. The
+// combined file's header is left out and existing is kept as it is.
+func mergeGitignore(existing, combined string) string {
+	if strings.TrimSpace(existing) == "" {
+		return combined
+	}
+	have := make(map[string]bool)
+	for _, line := range strings.Split(existing, "\n") {
+		have[strings.TrimSpace(line)] = true
+	}
+
+	var out strings.Builder
+	var section []string
+	fresh := false
+	flush := func() {
+		for len(section) > 0 && strings.TrimSpace(section[len(section)-1]) == "" {
+			section = section[:len(section)-1]
+		}
+		if fresh {
+			out.WriteString("\n" + strings.Join(section, "\n") + "\n")
+		}
+		section, fresh = nil, false
+	}
+	inSection := false
+	for _, line := range strings.Split(combined, "\n") {
+		rule := strings.TrimSpace(line)
+		if strings.HasPrefix(rule, "### ") && strings.HasSuffix(rule, " ###") {
+			flush()
+			inSection = true
+			if have[rule] {
+				// The template is already there under its own heading.
+				continue
+			}
+			section = []string{line}
+			continue
+		}
+		if !inSection || section == nil {
+			continue
+		}
+		if rule != "" && !strings.HasPrefix(rule, "#") {
+			if have[rule] {
+				continue
+			}
+			fresh = true
+		}
+		section = append(section, line)
+	}
+	flush()
+
+	if out.Len() == 0 {
+		return existing
+	}
+	if !strings.HasSuffix(existing, "\n") {
+		existing += "\n"
+	}
+	return existing + out.String()
+}
+
+// previewFor applies a preview reply to the composer, ignoring stale ones.
+func (c *composer) previewFor(msg previewLoadedMsg) {
+	if msg.seq != c.seq {
+		return
+	}
+	c.previewErr = ""
+	if msg.err != nil {
+		c.previewErr = msg.err.Error()
+		return
+	}
+	c.content, c.ready = msg.content, true
+	c.preview.SetContent(msg.content)
+}
+
+// loadComposer fetches the template names the composer lists.
+func loadComposer(client *api.Client) tea.Cmd {
+	return func() tea.Msg {
+		names, err := client.List()
+		return composerLoadedMsg{names: names, err: err}
+	}
+}
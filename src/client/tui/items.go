@@ -22,7 +22,7 @@ var mainMenuItems = []menuItem{
 	{id: "list", title: "List templates", desc: "Browse every available template"},
 	{id: "search", title: "Search templates", desc: "Search by name, tag, or keyword"},
 	{id: "categories", title: "Browse categories", desc: "List templates grouped by category"},
-	{id: "combine", title: "Combine templates", desc: "Pick multiple templates and merge them"},
+	{id: "combine", title: "Compose .gitignore", desc: "Pick templates with a live preview and save to ./.gitignore"},
 	{id: "stats", title: "Server stats", desc: "Show server-reported template statistics"},
 	{id: "settings", title: "Settings", desc: "Change the configured server URL"},
 	{id: "quit", title: "Quit", desc: "Exit gitignore-cli"},
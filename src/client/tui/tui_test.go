@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/apimgr/gitignore/src/client/api"
+	clicfg "github.com/apimgr/gitignore/src/client/config"
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// newStubServer serves the list and combine endpoints the composer uses
+// from the embedded dataset, in the API's response envelope.
+func newStubServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	tm, err := template.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply := func(w http.ResponseWriter, data interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "data": data})
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/list", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, tm.List())
+	})
+	mux.HandleFunc("/api/v1/combine", func(w http.ResponseWriter, r *http.Request) {
+		content, err := tm.Combine(strings.Split(r.URL.Query().Get("templates"), ","))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		reply(w, content)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// TestComposer drives the composer: it suggests templates from the working
+// directory's marker files, previews their combination, merges it into an
+// existing ./.gitignore, and remembers favorites and the composition in
+// cli.yml.
+func TestComposer(t *testing.T) {
+	srv := newStubServer(t)
+
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile("go.mod", []byte("module example\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(".gitignore", []byte("# mine\n*.test\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := api.New(srv.URL)
+	cfg := clicfg.Default()
+	cfg.Server.Primary = srv.URL
+	cfgPath := filepath.Join(dir, "cli.yml")
+
+	var m tea.Model = New(client, cfg, cfgPath)
+	// send delivers msg and then every message its commands produce.
+	var send func(msg tea.Msg)
+	send = func(msg tea.Msg) {
+		var cmd tea.Cmd
+		m, cmd = m.Update(msg)
+		if cmd == nil {
+			return
+		}
+		switch next := cmd().(type) {
+		case tea.BatchMsg, nil:
+		default:
+			send(next)
+		}
+	}
+	key := func(k string) tea.KeyMsg {
+		switch k {
+		case "down":
+			return tea.KeyMsg{Type: tea.KeyDown}
+		case "enter":
+			return tea.KeyMsg{Type: tea.KeyEnter}
+		case " ":
+			return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+		}
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+	}
+
+	send(tea.WindowSizeMsg{Width: 160, Height: 40})
+	for _, k := range []string{"down", "down", "down", "enter"} {
+		send(key(k))
+	}
+	if view := m.View(); !strings.Contains(view, "Suggested: Go (go.mod)") {
+		t.Fatalf("composer does not suggest Go:\n%s", view)
+	}
+
+	send(key("*"))
+	if len(cfg.TUI.Favorites) != 1 {
+		t.Errorf("favorites = %v, want the template under the cursor", cfg.TUI.Favorites)
+	}
+	send(key("a"))
+	if view := m.View(); !strings.Contains(view, "Order: Go") || !strings.Contains(view, "### Go ###") {
+		t.Fatalf("composer does not preview Go:\n%s", view)
+	}
+
+	send(key("enter"))
+	if view := m.View(); !strings.Contains(view, "Merge into ./.gitignore") || !strings.Contains(view, "+### Go ###") {
+		t.Fatalf("save dialog does not show the merge diff:\n%s", view)
+	}
+	send(key("y"))
+
+	data, err := os.ReadFile(".gitignore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	if !strings.HasPrefix(got, "# mine\n*.test\n\n### Go ###\n") || strings.Count(got, "*.test") != 1 {
+		t.Errorf("merged .gitignore = %q, want the old rules then the new Go ones", got)
+	}
+	if strings.Contains(got, "# Combined .gitignore") {
+		t.Errorf("merged .gitignore has the combined header:\n%s", got)
+	}
+
+	saved, _, err := clicfg.Load(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(saved.TUI.Recent, [][]string{{"Go"}}) || len(saved.TUI.Favorites) != 1 {
+		t.Errorf("cli.yml tui = %+v, want the favorite and the Go composition", saved.TUI)
+	}
+}
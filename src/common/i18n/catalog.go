@@ -0,0 +1,95 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"strings"
+)
+
+// catalogFS embeds the template metadata catalogs: localized category names
+// and descriptions, and template descriptions. They are kept apart from the
+// UI locales because they are keyed by dataset names rather than message
+// keys, and coverage is allowed to be partial.
+//
+//go:embed catalogs/*.json
+var catalogFS embed.FS
+
+// Catalog is one language's template metadata translations. The English
+// catalog is the source translators work from: its template descriptions
+// replace the upstream comment lines, which are often just a heading.
+type Catalog struct {
+	Categories map[string]CategoryText `json:"categories"`
+	Templates  map[string]string       `json:"templates"`
+}
+
+// CategoryText is a category's display name and description.
+type CategoryText struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// catalogs holds every embedded catalog by lowercase language code. Built at
+// init and never mutated.
+var catalogs = map[string]Catalog{}
+
+func init() {
+	entries, err := catalogFS.ReadDir("catalogs")
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := catalogFS.ReadFile("catalogs/" + e.Name())
+		if err != nil {
+			continue
+		}
+		var c Catalog
+		if err := json.Unmarshal(data, &c); err != nil {
+			continue
+		}
+		catalogs[normalize(strings.TrimSuffix(e.Name(), ".json"))] = c
+	}
+}
+
+// CatalogFor returns the embedded catalog for lang exactly, without
+// fallback. The bool is false when lang has no catalog.
+func CatalogFor(lang string) (Catalog, bool) {
+	c, ok := catalogs[normalize(lang)]
+	return c, ok
+}
+
+// CategoryName returns category's display name in lang, following
+// Fallbacks; a category no catalog knows is shown as its dataset name.
+func CategoryName(lang, category string) string {
+	for _, code := range Fallbacks(lang) {
+		if t, ok := catalogs[code].Categories[category]; ok && t.Name != "" {
+			return t.Name
+		}
+	}
+	return category
+}
+
+// CategoryDescription returns category's description in lang, following
+// Fallbacks, or "" when no catalog describes it.
+func CategoryDescription(lang, category string) string {
+	for _, code := range Fallbacks(lang) {
+		if t, ok := catalogs[code].Categories[category]; ok && t.Description != "" {
+			return t.Description
+		}
+	}
+	return ""
+}
+
+// TemplateDescription returns the description of the named template in lang,
+// following Fallbacks, or fallback (the description taken from the template
+// itself) when no catalog has one.
+func TemplateDescription(lang, name, fallback string) string {
+	for _, code := range Fallbacks(lang) {
+		if d, ok := catalogs[code].Templates[name]; ok && d != "" {
+			return d
+		}
+	}
+	return fallback
+}
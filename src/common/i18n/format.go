@@ -0,0 +1,43 @@
+package i18n
+
+import (
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// FormatNumber formats n with lang's CLDR digit grouping and numbering
+// system: 1,234 in English, 1.234 in German, 1 234 in French, ١٬٢٣٤ in
+// Arabic. An unsupported or malformed lang formats as English.
+func FormatNumber(lang string, n int) string {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		tag = language.English
+	}
+	return message.NewPrinter(tag).Sprintf("%d", n)
+}
+
+// Fallbacks returns the lookup chain for lang: lang itself, its CLDR parent
+// locales (de-ch → de), then English. Template metadata catalogs are
+// consulted in this order, so a regional catalog only needs the entries that
+// differ from its parent.
+func Fallbacks(lang string) []string {
+	lang = normalize(lang)
+	chain := []string{}
+	add := func(code string) {
+		for _, c := range chain {
+			if c == code {
+				return
+			}
+		}
+		chain = append(chain, code)
+	}
+	if tag, err := language.Parse(lang); err == nil {
+		for ; tag != language.Und; tag = tag.Parent() {
+			add(strings.ToLower(tag.String()))
+		}
+	}
+	add(DefaultLang)
+	return chain
+}
@@ -189,7 +189,7 @@ func TranslateFormat(lang, key string, args ...interface{}) string {
 
 // TranslatePlural selects the CLDR plural form for count under lang's rules
 // and returns the interpolated string, replacing {count} with the number
-// (AI.md PART 30 "Plural Rules").
+// formatted for lang (AI.md PART 30 "Plural Rules").
 func TranslatePlural(lang, key string, count int) string {
 	lang = normalize(lang)
 	if !IsSupported(lang) {
@@ -200,10 +200,10 @@ func TranslatePlural(lang, key string, count int) string {
 	// map 0 to "other" (e.g. English). Falls back to the English zero form.
 	if count == 0 {
 		if val, ok := translations[lang][key+".zero"]; ok {
-			return interpolate(val, map[string]string{"count": strconv.Itoa(count)})
+			return interpolate(val, map[string]string{"count": FormatNumber(lang, count)})
 		}
 		if val, ok := translations[DefaultLang][key+".zero"]; ok {
-			return interpolate(val, map[string]string{"count": strconv.Itoa(count)})
+			return interpolate(val, map[string]string{"count": FormatNumber(lang, count)})
 		}
 	}
 	form := pluralForm(lang, count)
@@ -220,7 +220,49 @@ func TranslatePlural(lang, key string, count int) string {
 	if !ok {
 		return key
 	}
-	return interpolate(val, map[string]string{"count": strconv.Itoa(count)})
+	return interpolate(val, map[string]string{"count": FormatNumber(lang, count)})
+}
+
+// pluralFormOrder is the CLDR order of the plural categories.
+var pluralFormOrder = []string{"zero", "one", "two", "few", "many", "other"}
+
+// PluralForms returns the plural categories lang's CLDR rules select for
+// whole numbers, in CLDR order. A plural key in lang needs exactly these
+// forms, plus an optional explicit "zero" (see TranslatePlural). English
+// needs one and other; Japanese and Chinese only other; Arabic all six.
+func PluralForms(lang string) []string {
+	lang = normalize(lang)
+	seen := map[string]bool{}
+	// Every integer rule in CLDR is decided by the last two or three digits,
+	// or by exact millions (French "many"), so this range covers them all.
+	for n := 0; n <= 1000; n++ {
+		seen[pluralForm(lang, n)] = true
+	}
+	seen[pluralForm(lang, 1000000)] = true
+	out := make([]string, 0, len(seen))
+	for _, f := range pluralFormOrder {
+		if seen[f] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// SplitPluralKey splits a flattened plural key such as "plurals.items.one"
+// into its message key and plural form. ok is false when the last segment
+// is not a CLDR plural category.
+func SplitPluralKey(key string) (base, form string, ok bool) {
+	i := strings.LastIndexByte(key, '.')
+	if i < 0 {
+		return "", "", false
+	}
+	base, form = key[:i], key[i+1:]
+	for _, f := range pluralFormOrder {
+		if f == form {
+			return base, form, true
+		}
+	}
+	return "", "", false
 }
 
 // pluralForm returns the CLDR plural category ("zero","one","two","few",
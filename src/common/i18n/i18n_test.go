@@ -3,6 +3,7 @@ package i18n
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -23,32 +24,69 @@ func TestSupportedLanguages(t *testing.T) {
 }
 
 // TestKeySetsIdentical enforces the build-time invariant that every language
-// carries exactly the same keys as English (AI.md PART 30).
+// carries exactly the same keys as English (AI.md PART 30). Plural keys are
+// compared by message key, and each must carry exactly the plural forms its
+// language's CLDR rules select, plus an optional explicit "zero".
 func TestKeySetsIdentical(t *testing.T) {
-	base := map[string]bool{}
-	for _, k := range Keys("en") {
-		base[k] = true
-	}
+	base, basePlurals := splitKeys(Keys("en"))
 	if len(base) == 0 {
 		t.Fatal("english locale has no keys")
 	}
 	for _, lang := range SupportedLanguages() {
-		if lang == "en" {
-			continue
+		keys, plurals := splitKeys(Keys(lang))
+		if lang != "en" {
+			for k := range keys {
+				if !base[k] {
+					t.Errorf("%s: orphaned key %q not in en", lang, k)
+				}
+			}
+			for k := range base {
+				if !keys[k] {
+					t.Errorf("%s: missing key %q present in en", lang, k)
+				}
+			}
+			for k := range basePlurals {
+				if plurals[k] == nil {
+					t.Errorf("%s: missing plural key %q present in en", lang, k)
+				}
+			}
 		}
-		got := map[string]bool{}
-		for _, k := range Keys(lang) {
-			got[k] = true
-			if !base[k] {
-				t.Errorf("%s: orphaned key %q not in en", lang, k)
+		want := PluralForms(lang)
+		for k, forms := range plurals {
+			if basePlurals[k] == nil {
+				t.Errorf("%s: orphaned plural key %q not in en", lang, k)
+				continue
+			}
+			for _, f := range want {
+				if !forms[f] {
+					t.Errorf("%s: plural key %q is missing the %q form", lang, k, f)
+				}
+			}
+			for f := range forms {
+				if f != "zero" && !contains(want, f) {
+					t.Errorf("%s: plural key %q has form %q, which %s never selects", lang, k, f, lang)
+				}
 			}
 		}
-		for k := range base {
-			if !got[k] {
-				t.Errorf("%s: missing key %q present in en", lang, k)
+	}
+}
+
+// splitKeys separates flattened keys into plain keys and plural message keys
+// with the forms each defines.
+func splitKeys(keys []string) (map[string]bool, map[string]map[string]bool) {
+	plain := map[string]bool{}
+	plurals := map[string]map[string]bool{}
+	for _, k := range keys {
+		if base, form, ok := SplitPluralKey(k); ok {
+			if plurals[base] == nil {
+				plurals[base] = map[string]bool{}
 			}
+			plurals[base][form] = true
+			continue
 		}
+		plain[k] = true
 	}
+	return plain, plurals
 }
 
 // TestNoEmptyValues enforces the spec's "no empty string values" rule.
@@ -101,6 +139,87 @@ func TestTranslatePlural(t *testing.T) {
 	}
 }
 
+func TestTranslatePluralCLDR(t *testing.T) {
+	cases := []struct {
+		lang  string
+		count int
+		want  string
+	}{
+		{"en", 1234, "1,234 templates"},
+		{"de", 1, "1 Vorlage"},
+		{"de", 1234, "1.234 Vorlagen"},
+		{"ja", 1, "1 件のテンプレート"},
+		{"ar", 2, "قالبان"},
+		{"ar", 3, "٣ قوالب"},
+		{"ar", 11, "١١ قالبًا"},
+		{"ar", 100, "١٠٠ قالب"},
+	}
+	for _, c := range cases {
+		if got := TranslatePlural(c.lang, "plurals.templates", c.count); got != c.want {
+			t.Errorf("%s %d = %q, want %q", c.lang, c.count, got, c.want)
+		}
+	}
+}
+
+func TestPluralForms(t *testing.T) {
+	cases := map[string]string{
+		"en": "one other",
+		"fr": "one other",
+		"ja": "other",
+		"ar": "zero one two few many other",
+	}
+	for lang, want := range cases {
+		if got := strings.Join(PluralForms(lang), " "); got != want {
+			t.Errorf("PluralForms(%s) = %q, want %q", lang, got, want)
+		}
+	}
+	if base, form, ok := SplitPluralKey("plurals.items.few"); !ok || base != "plurals.items" || form != "few" {
+		t.Errorf("SplitPluralKey = %q %q %v", base, form, ok)
+	}
+	if _, _, ok := SplitPluralKey("common.save"); ok {
+		t.Error("common.save is not a plural key")
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	cases := map[string]string{"en": "1,234,567", "de": "1.234.567", "de-CH": "1’234’567", "xx-invalid-": "1,234,567"}
+	for lang, want := range cases {
+		if got := FormatNumber(lang, 1234567); got != want {
+			t.Errorf("FormatNumber(%s) = %q, want %q", lang, got, want)
+		}
+	}
+}
+
+func TestFallbacks(t *testing.T) {
+	if got := strings.Join(Fallbacks("de-CH"), " "); got != "de-ch de en" {
+		t.Errorf("Fallbacks(de-CH) = %q", got)
+	}
+	if got := strings.Join(Fallbacks("en"), " "); got != "en" {
+		t.Errorf("Fallbacks(en) = %q", got)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	for _, lang := range SupportedLanguages() {
+		if _, ok := CatalogFor(lang); !ok {
+			t.Errorf("no metadata catalog for %s", lang)
+		}
+	}
+	if got := CategoryName("de-CH", "Root"); got != "Sprachen & Frameworks" {
+		t.Errorf("de-CH Root = %q, want the de name", got)
+	}
+	if got := CategoryName("ja", "NoSuchCategory"); got != "NoSuchCategory" {
+		t.Errorf("unknown category = %q, want the dataset name", got)
+	}
+	en := TemplateDescription("en", "Go", "")
+	if en == "" || TemplateDescription("xx", "Go", "") != en {
+		t.Error("unsupported language should fall back to the en description")
+	}
+	if got := TemplateDescription("ja", "NoSuchTemplate", "upstream"); got != "upstream" {
+		t.Errorf("uncatalogued template = %q, want the fallback", got)
+	}
+}
+
 func TestDirection(t *testing.T) {
 	if Direction("ar") != "rtl" {
 		t.Errorf("Arabic direction = %q, want rtl", Direction("ar"))
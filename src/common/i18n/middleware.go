@@ -75,3 +75,8 @@ func T(r *http.Request, key string) string {
 func TF(r *http.Request, key string, args ...interface{}) string {
 	return TranslateFormat(LangFromContext(r.Context()), key, args...)
 }
+
+// TP is a request-scoped plural translation helper: TP(r, "plurals.templates", n).
+func TP(r *http.Request, key string, count int) string {
+	return TranslatePlural(LangFromContext(r.Context()), key, count)
+}
@@ -22,7 +22,12 @@ func BuildAPIURL(baseURL, path string, pathParams map[string]string, queryParams
 		placeholder := "{" + key + "}"
 		encodedPath = strings.ReplaceAll(encodedPath, placeholder, url.PathEscape(value))
 	}
-	u.Path = strings.TrimSuffix(u.Path, "/") + encodedPath
+	// Set the escaped form as RawPath so the encoded parameters are sent as
+	// they are rather than escaped a second time.
+	u.RawPath = strings.TrimSuffix(u.EscapedPath(), "/") + encodedPath
+	if u.Path, err = url.PathUnescape(u.RawPath); err != nil {
+		return ""
+	}
 
 	if len(queryParams) > 0 {
 		q := u.Query()
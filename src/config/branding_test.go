@@ -0,0 +1,35 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestBrandingYAMLRoundTrip(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Server.Branding.Theme.Dark = map[string]string{"accent": "#d9480f", "bg": "rgb(10, 10, 10)"}
+	cfg.Server.Branding.FooterLinks = []FooterLink{{Label: `Support "desk"`, URL: "https://support.example.com/"}}
+	cfg.Server.Branding.Announcement = AnnouncementConfig{Message: "Maintenance: Sat 02:00 UTC", Level: "warning"}
+
+	var got Config
+	if err := yaml.Unmarshal([]byte(generateConfigYAML(cfg)), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Server.Branding.Theme.Dark, cfg.Server.Branding.Theme.Dark) {
+		t.Errorf("theme.dark = %v", got.Server.Branding.Theme.Dark)
+	}
+	if len(got.Server.Branding.Theme.Light) != 0 {
+		t.Errorf("theme.light = %v, want empty", got.Server.Branding.Theme.Light)
+	}
+	if !reflect.DeepEqual(got.Server.Branding.FooterLinks, cfg.Server.Branding.FooterLinks) {
+		t.Errorf("footer_links = %v", got.Server.Branding.FooterLinks)
+	}
+	if got.Server.Branding.Announcement != cfg.Server.Branding.Announcement {
+		t.Errorf("announcement = %+v", got.Server.Branding.Announcement)
+	}
+	if got.Server.SEO.Keywords == nil {
+		t.Error("seo block lost after branding injection")
+	}
+}
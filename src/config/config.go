@@ -6,6 +6,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -51,6 +52,59 @@ type ServerConfig struct {
 	Notifications NotificationsConfig `yaml:"notifications"`
 	Update      UpdateConfig     `yaml:"update"`
 	Healthz     HealthzConfig    `yaml:"healthz"`
+	Templates   TemplatesConfig  `yaml:"templates"`
+	Events      EventsConfig     `yaml:"events"`
+}
+
+// TemplatesConfig controls where the template dataset comes from. The
+// github/gitignore snapshot embedded at build time is always available; Sync
+// optionally replaces it with a newer revision pulled by the scheduler.
+type TemplatesConfig struct {
+	Sync TemplateSyncConfig `yaml:"sync"`
+}
+
+// TemplateSyncConfig drives the template_sync scheduler task. URL is any git
+// URL, including file:// mirrors on air-gapped networks. Pin, when set, is a
+// tag or commit the dataset is held at instead of following Branch.
+type TemplateSyncConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	URL     string `yaml:"url"`
+	Branch  string `yaml:"branch"`
+	Pin     string `yaml:"pin"`
+}
+
+// DefaultTemplatesConfig returns the default template dataset settings: sync
+// off, pointed at the upstream github/gitignore repository.
+func DefaultTemplatesConfig() TemplatesConfig {
+	return TemplatesConfig{
+		Sync: TemplateSyncConfig{
+			Enabled: false,
+			URL:     "https://github.com/github/gitignore.git",
+			Branch:  "main",
+			Pin:     "",
+		},
+	}
+}
+
+// EventsConfig controls the /api/v1/events Server-Sent Events stream. Buffer
+// bounds how many recent events a reconnecting client can resume from with
+// Last-Event-ID; Heartbeat is the comment-ping interval in seconds that keeps
+// proxies from closing idle streams. Scheduler outcomes are operator-only
+// unless the task ID is listed in PublicTasks.
+type EventsConfig struct {
+	Buffer      int      `yaml:"buffer"`
+	Heartbeat   int      `yaml:"heartbeat"`
+	PublicTasks []string `yaml:"public_tasks"`
+}
+
+// DefaultEventsConfig returns the default event stream settings: a 256-event
+// resume buffer, 25-second heartbeats and template_sync outcomes public.
+func DefaultEventsConfig() EventsConfig {
+	return EventsConfig{
+		Buffer:      256,
+		Heartbeat:   25,
+		PublicTasks: []string{"template_sync"},
+	}
 }
 
 // HealthzConfig controls the optional root /healthz alias (AI.md PART 13). The
@@ -312,11 +366,38 @@ type I18nConfig struct {
 	CookieMaxAge       string   `yaml:"cookie_max_age"`
 }
 
-// BrandingConfig contains display/branding settings
+// BrandingConfig contains display/branding settings. The logo, favicon and
+// Markdown page overrides are files in the config dir (branding/ and pages/),
+// not settings, so they can be replaced without editing this file.
 type BrandingConfig struct {
-	Title       string `yaml:"title"`
-	Tagline     string `yaml:"tagline"`
-	Description string `yaml:"description"`
+	Title        string             `yaml:"title"`
+	Tagline      string             `yaml:"tagline"`
+	Description  string             `yaml:"description"`
+	Theme        ThemeTokens        `yaml:"theme"`
+	FooterLinks  []FooterLink       `yaml:"footer_links"`
+	Announcement AnnouncementConfig `yaml:"announcement"`
+}
+
+// ThemeTokens overrides the stylesheet's CSS custom properties (for example
+// "accent" for --accent) per color scheme. The dark set also applies to the
+// auto theme unless the visitor's system prefers light.
+type ThemeTokens struct {
+	Dark  map[string]string `yaml:"dark"`
+	Light map[string]string `yaml:"light"`
+}
+
+// FooterLink is an extra link rendered after the standard footer links.
+type FooterLink struct {
+	Label string `yaml:"label"`
+	URL   string `yaml:"url"`
+}
+
+// AnnouncementConfig is a site-wide banner shown above every page's content
+// while Message is non-empty. Level is "info" (default) or "warning".
+type AnnouncementConfig struct {
+	Message string `yaml:"message"`
+	Link    string `yaml:"link"`
+	Level   string `yaml:"level"`
 }
 
 // SEOConfig contains SEO-related settings
@@ -366,11 +447,35 @@ type ScheduleConfig struct {
 	CatchUpWindow string `yaml:"catch_up_window"`
 }
 
-// RateLimitConfig contains rate limiting settings
+// RateLimitConfig contains rate limiting settings. Each client gets a token
+// bucket of Requests tokens refilled over Window seconds; requests spend their
+// route class's cost.
 type RateLimitConfig struct {
-	Enabled  bool `yaml:"enabled"`
-	Requests int  `yaml:"requests"`
-	Window   int  `yaml:"window"`
+	Enabled    bool              `yaml:"enabled"`
+	Requests   int               `yaml:"requests"`
+	Window     int               `yaml:"window"`
+	MaxClients int               `yaml:"max_clients"`
+	MaxStreams int               `yaml:"max_streams"`
+	Costs      RateLimitCosts    `yaml:"costs"`
+	Exempt     []string          `yaml:"exempt"`
+	Policies   []RateLimitPolicy `yaml:"policies"`
+}
+
+// RateLimitCosts is the token cost of one request per route class.
+type RateLimitCosts struct {
+	Default int `yaml:"default"`
+	Search  int `yaml:"search"`
+	Combine int `yaml:"combine"`
+	Archive int `yaml:"archive"`
+}
+
+// RateLimitPolicy gives clients in CIDRs their own bucket size and window in
+// place of the default limits.
+type RateLimitPolicy struct {
+	Name     string   `yaml:"name"`
+	CIDRs    []string `yaml:"cidrs"`
+	Requests int      `yaml:"requests"`
+	Window   int      `yaml:"window"`
 }
 
 // MetricsConfig controls the Prometheus /metrics endpoint (AI.md PART 20).
@@ -431,6 +536,9 @@ type CleanupConfig struct {
 	DiskThreshold    int `yaml:"disk_threshold"`
 	LogRetentionDays int `yaml:"log_retention_days"`
 	BackupKeepCount  int `yaml:"backup_keep_count"`
+	// UsageRetentionDays is how many days of usage analytics the
+	// usage_cleanup task keeps in server.db.
+	UsageRetentionDays int `yaml:"usage_retention_days"`
 }
 
 // NotifyConfig contains notification settings for maintenance events
@@ -529,9 +637,19 @@ func DefaultConfig() *Config {
 				CatchUpWindow: "1h",
 			},
 			RateLimit: RateLimitConfig{
-				Enabled:  true,
-				Requests: 120,
-				Window:   60,
+				Enabled:    true,
+				Requests:   120,
+				Window:     60,
+				MaxClients: 10000,
+				MaxStreams: 4,
+				Costs: RateLimitCosts{
+					Default: 1,
+					Search:  2,
+					Combine: 3,
+					Archive: 20,
+				},
+				Exempt:   []string{},
+				Policies: []RateLimitPolicy{},
 			},
 			Metrics: MetricsConfig{
 				Enabled:         true,
@@ -559,9 +677,10 @@ func DefaultConfig() *Config {
 					MaxAttempts:   0,
 				},
 				Cleanup: CleanupConfig{
-					DiskThreshold:    90,
-					LogRetentionDays: 7,
-					BackupKeepCount:  5,
+					DiskThreshold:      90,
+					LogRetentionDays:   7,
+					BackupKeepCount:    5,
+					UsageRetentionDays: 90,
 				},
 				Notify: NotifyConfig{
 					OnEnter: true,
@@ -584,6 +703,8 @@ func DefaultConfig() *Config {
 			Tor:           DefaultTorConfig(),
 			GeoIP:         DefaultGeoIPConfig(),
 			Notifications: DefaultNotificationsConfig(),
+			Templates:     DefaultTemplatesConfig(),
+			Events:        DefaultEventsConfig(),
 		},
 		Web: WebConfig{
 			UI: WebUIConfig{
@@ -801,14 +922,6 @@ server:
     # run missed tasks on restart if within this window
     catch_up_window: %s
 
-  # Rate limiting
-  rate_limit:
-    enabled: %t
-    # requests per window
-    requests: %d
-    # seconds
-    window: %d
-
   # Database
   database:
 %s
@@ -831,6 +944,8 @@ server:
       disk_threshold: %d
       log_retention_days: %d
       backup_keep_count: %d
+      # days of usage analytics (/api/v1/stats/popular) to keep
+      usage_retention_days: %d
     notify:
       on_enter: %t
       on_exit: %t
@@ -932,9 +1047,6 @@ web:
 		cfg.Server.Schedule.Enabled,
 		cfg.Server.Schedule.Timezone,
 		cfg.Server.Schedule.CatchUpWindow,
-		cfg.Server.RateLimit.Enabled,
-		cfg.Server.RateLimit.Requests,
-		cfg.Server.RateLimit.Window,
 		dbSection,
 		cfg.Server.Logging.AccessFormat,
 		cfg.Server.Logging.Level,
@@ -944,6 +1056,7 @@ web:
 		cfg.Server.Maintenance.Cleanup.DiskThreshold,
 		cfg.Server.Maintenance.Cleanup.LogRetentionDays,
 		cfg.Server.Maintenance.Cleanup.BackupKeepCount,
+		cfg.Server.Maintenance.Cleanup.UsageRetentionDays,
 		cfg.Server.Maintenance.Notify.OnEnter,
 		cfg.Server.Maintenance.Notify.OnExit,
 		cfg.Server.I18n.Enabled,
@@ -972,9 +1085,174 @@ web:
 		cfg.Web.CORS,
 	)
 
-	// The notification block lives under server:, so inject it just before the
-	// update block rather than reflowing the large Sprintf argument list.
-	return strings.Replace(base, "  update:", generateNotificationsYAML(cfg)+"  update:", 1)
+	// The notification and rate-limit blocks live under server:, so inject them
+	// at their anchors rather than reflowing the large Sprintf argument list.
+	base = strings.Replace(base, "  # Database\n", generateRateLimitYAML(cfg)+"  # Database\n", 1)
+	base = strings.Replace(base, "  seo:\n", generateBrandingYAML(cfg)+"  seo:\n", 1)
+	return strings.Replace(base, "  update:",
+		generateNotificationsYAML(cfg)+generateTemplatesYAML(cfg)+generateEventsYAML(cfg)+"  update:", 1)
+}
+
+// generateBrandingYAML renders the theme, footer and announcement settings
+// that follow server.branding.description.
+func generateBrandingYAML(cfg *Config) string {
+	b := cfg.Server.Branding
+	var sb strings.Builder
+	sb.WriteString(`    # CSS token overrides by scheme, e.g. accent: "#d9480f" sets --accent.
+    # Logo and favicon: {config_dir}/branding/logo.{svg,png,webp,jpg} and
+    # favicon.{ico,png,svg}. Markdown in {config_dir}/pages/{about,privacy,
+    # terms,contact}.md replaces the built-in page. All reload on SIGHUP.
+    theme:
+`)
+	for _, scheme := range []struct {
+		name   string
+		tokens map[string]string
+	}{{"dark", b.Theme.Dark}, {"light", b.Theme.Light}} {
+		if len(scheme.tokens) == 0 {
+			fmt.Fprintf(&sb, "      %s: {}\n", scheme.name)
+			continue
+		}
+		fmt.Fprintf(&sb, "      %s:\n", scheme.name)
+		keys := make([]string, 0, len(scheme.tokens))
+		for k := range scheme.tokens {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&sb, "        %s: %s\n", k, strconv.Quote(scheme.tokens[k]))
+		}
+	}
+	sb.WriteString("    # Extra footer links: - {label: \"Support\", url: \"https://...\"}\n")
+	if len(b.FooterLinks) == 0 {
+		sb.WriteString("    footer_links: []\n")
+	} else {
+		sb.WriteString("    footer_links:\n")
+		for _, l := range b.FooterLinks {
+			fmt.Fprintf(&sb, "      - label: %s\n        url: %s\n", strconv.Quote(l.Label), strconv.Quote(l.URL))
+		}
+	}
+	fmt.Fprintf(&sb, `    # Site-wide banner (empty message = hidden); level: info or warning
+    announcement:
+      message: %s
+      link: %s
+      level: %s
+`, strconv.Quote(b.Announcement.Message), strconv.Quote(b.Announcement.Link), strconv.Quote(b.Announcement.Level))
+	return sb.String()
+}
+
+// generateRateLimitYAML renders the server.rate_limit block.
+func generateRateLimitYAML(cfg *Config) string {
+	rl := cfg.Server.RateLimit
+	var b strings.Builder
+	fmt.Fprintf(&b, `  # Rate limiting: a per-client token bucket holding "requests" tokens that
+  # refills completely over "window" seconds. Each request spends its route's
+  # cost, so bursts up to the bucket size are allowed.
+  rate_limit:
+    enabled: %t
+    requests: %d
+    # seconds
+    window: %d
+    # clients tracked at once; the least recently seen are evicted first
+    max_clients: %d
+    # concurrent /api/v1/events streams per client
+    max_streams: %d
+    # tokens per request by route class
+    costs:
+      default: %d
+      search: %d
+      combine: %d
+      archive: %d
+`,
+		rl.Enabled,
+		rl.Requests,
+		rl.Window,
+		rl.MaxClients,
+		rl.MaxStreams,
+		rl.Costs.Default,
+		rl.Costs.Search,
+		rl.Costs.Combine,
+		rl.Costs.Archive,
+	)
+	b.WriteString("    # IPs/CIDRs never limited (health checkers, internal monitoring)\n")
+	if len(rl.Exempt) == 0 {
+		b.WriteString("    exempt: []\n")
+	} else {
+		b.WriteString("    exempt:\n")
+		for _, cidr := range rl.Exempt {
+			fmt.Fprintf(&b, "      - \"%s\"\n", cidr)
+		}
+	}
+	b.WriteString("    # Separate limits for matching clients, e.g. CI runners behind one NAT:\n")
+	b.WriteString("    #   - name: ci\n    #     cidrs: [\"203.0.113.0/28\"]\n    #     requests: 2000\n    #     window: 60\n")
+	if len(rl.Policies) == 0 {
+		b.WriteString("    policies: []\n")
+	} else {
+		b.WriteString("    policies:\n")
+		for _, p := range rl.Policies {
+			quoted := make([]string, len(p.CIDRs))
+			for i, c := range p.CIDRs {
+				quoted[i] = `"` + c + `"`
+			}
+			fmt.Fprintf(&b, "      - name: %s\n        cidrs: [%s]\n        requests: %d\n        window: %d\n",
+				p.Name, strings.Join(quoted, ", "), p.Requests, p.Window)
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// generateTemplatesYAML renders the server.templates block that configures the
+// template_sync scheduler task.
+func generateTemplatesYAML(cfg *Config) string {
+	t := cfg.Server.Templates.Sync
+	return fmt.Sprintf(`  # Template dataset (github/gitignore). The snapshot embedded at build time is
+  # used until a sync succeeds; synced datasets are staged under
+  # {data_dir}/templates and survive restarts.
+  templates:
+    sync:
+      # Pull upstream on the template_sync scheduler task
+      enabled: %t
+      # Any git URL, including file:// mirrors for air-gapped networks
+      url: "%s"
+      # Branch to follow when no pin is set
+      branch: "%s"
+      # Release pin: a tag or commit to hold the dataset at (empty = follow branch)
+      pin: "%s"
+
+`,
+		t.Enabled,
+		t.URL,
+		t.Branch,
+		t.Pin,
+	)
+}
+
+// generateEventsYAML renders the server.events block.
+func generateEventsYAML(cfg *Config) string {
+	e := cfg.Server.Events
+	var b strings.Builder
+	fmt.Fprintf(&b, `  # Server-Sent Events at /api/v1/events
+  events:
+    # recent events kept for Last-Event-ID resume
+    buffer: %d
+    # seconds between keep-alive pings
+    heartbeat: %d
+    # scheduler task IDs whose outcomes are published to everyone; other
+    # task outcomes reach only admin-token streams
+`,
+		e.Buffer,
+		e.Heartbeat,
+	)
+	if len(e.PublicTasks) == 0 {
+		b.WriteString("    public_tasks: []\n")
+	} else {
+		b.WriteString("    public_tasks:\n")
+		for _, id := range e.PublicTasks {
+			fmt.Fprintf(&b, "      - %s\n", id)
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
 }
 
 // generateNotificationsYAML renders the server.notifications block (AI.md PART
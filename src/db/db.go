@@ -215,6 +215,36 @@ CREATE TABLE IF NOT EXISTS server_scheduler_state (
     enabled     INTEGER NOT NULL DEFAULT 1
 );
 
+CREATE TABLE IF NOT EXISTS server_template_revisions (
+    version     TEXT PRIMARY KEY,
+    synced_at   DATETIME NOT NULL,
+    data        TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS server_usage_fetches (
+    day         TEXT NOT NULL,
+    template    TEXT NOT NULL,
+    source      TEXT NOT NULL,
+    count       INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, template, source)
+);
+
+CREATE TABLE IF NOT EXISTS server_usage_pairs (
+    day         TEXT NOT NULL,
+    template_a  TEXT NOT NULL,
+    template_b  TEXT NOT NULL,
+    count       INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, template_a, template_b)
+);
+
+CREATE TABLE IF NOT EXISTS server_usage_misses (
+    day         TEXT NOT NULL,
+    name        TEXT NOT NULL,
+    source      TEXT NOT NULL,
+    count       INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, name, source)
+);
+
 CREATE TABLE IF NOT EXISTS server_nodes (
     id          TEXT PRIMARY KEY,
     address     TEXT NOT NULL,
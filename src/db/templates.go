@@ -0,0 +1,65 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// TemplateRevision is one dataset revision installed by the template_sync
+// task. Version is the dataset's content hash and Data the revision as the
+// changelog serves it, in JSON, so it merges into the embedded changelog
+// unchanged.
+type TemplateRevision struct {
+	Version  string
+	SyncedAt time.Time
+	Data     []byte
+}
+
+// RecordTemplateRevision stores the revision a sync installed. Syncing back to
+// a dataset seen before replaces its row, keeping one row per version.
+func RecordTemplateRevision(r TemplateRevision) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	ctx, cancel := writeCtx()
+	defer cancel()
+
+	_, err := conn.ExecContext(ctx, `
+INSERT OR REPLACE INTO server_template_revisions (version, synced_at, data)
+VALUES (?, ?, ?)`, r.Version, nullTimeText(r.SyncedAt), string(r.Data))
+	return err
+}
+
+// ListTemplateRevisions returns every synced revision, oldest first.
+func ListTemplateRevisions() ([]TemplateRevision, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	ctx, cancel := readCtx()
+	defer cancel()
+
+	rows, err := conn.QueryContext(ctx, `
+SELECT version, synced_at, data
+FROM server_template_revisions
+ORDER BY synced_at, version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revs []TemplateRevision
+	for rows.Next() {
+		var (
+			r    TemplateRevision
+			at   sql.NullString
+			data string
+		)
+		if err := rows.Scan(&r.Version, &at, &data); err != nil {
+			return nil, err
+		}
+		r.SyncedAt = parseTimeText(at)
+		r.Data = []byte(data)
+		revs = append(revs, r)
+	}
+	return revs, rows.Err()
+}
@@ -0,0 +1,288 @@
+package db
+
+// Template usage analytics. Counts are aggregated in memory by the server and
+// flushed here as per-day totals; no request-level rows, IPs or user agents are
+// ever stored.
+
+// UsageDayLayout is the format of the day column in the usage tables.
+const UsageDayLayout = "2006-01-02"
+
+// UsageCount is one per-day counter keyed by template (or unknown name) and
+// the route family it was requested through.
+type UsageCount struct {
+	Day    string
+	Name   string
+	Source string
+	Count  int64
+}
+
+// PairCount is one per-day co-occurrence counter for two templates requested
+// together; A sorts before B.
+type PairCount struct {
+	Day   string
+	A, B  string
+	Count int64
+}
+
+// UsageBatch is one flush of in-memory counters.
+type UsageBatch struct {
+	Fetches []UsageCount
+	Pairs   []PairCount
+	Misses  []UsageCount
+}
+
+// Empty reports whether the batch has nothing to write.
+func (b UsageBatch) Empty() bool {
+	return len(b.Fetches) == 0 && len(b.Pairs) == 0 && len(b.Misses) == 0
+}
+
+// TemplateUsage is a template's fetch total over a window, split by source.
+type TemplateUsage struct {
+	Template string `json:"template"`
+	Total    int64  `json:"total"`
+	Native   int64  `json:"native"`
+	Compat   int64  `json:"compat"`
+}
+
+// NameCount is a name with its count over a window.
+type NameCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// DayCount is a total for one day.
+type DayCount struct {
+	Day   string `json:"day"`
+	Count int64  `json:"count"`
+}
+
+// PairUsage is how often two templates were requested together over a window.
+type PairUsage struct {
+	Templates [2]string `json:"templates"`
+	Count     int64     `json:"count"`
+}
+
+// RecordUsage adds a batch to the stored per-day counters in one transaction.
+func RecordUsage(b UsageBatch) error {
+	if b.Empty() {
+		return nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	ctx, cancel := writeCtx()
+	defer cancel()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, c := range b.Fetches {
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO server_usage_fetches (day, template, source, count) VALUES (?, ?, ?, ?)
+ON CONFLICT(day, template, source) DO UPDATE SET count = count + excluded.count`,
+			c.Day, c.Name, c.Source, c.Count); err != nil {
+			return err
+		}
+	}
+	for _, p := range b.Pairs {
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO server_usage_pairs (day, template_a, template_b, count) VALUES (?, ?, ?, ?)
+ON CONFLICT(day, template_a, template_b) DO UPDATE SET count = count + excluded.count`,
+			p.Day, p.A, p.B, p.Count); err != nil {
+			return err
+		}
+	}
+	for _, c := range b.Misses {
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO server_usage_misses (day, name, source, count) VALUES (?, ?, ?, ?)
+ON CONFLICT(day, name, source) DO UPDATE SET count = count + excluded.count`,
+			c.Day, c.Name, c.Source, c.Count); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// PruneUsage deletes the usage counters of days before beforeDay from every
+// usage table in one transaction and returns the number of rows removed.
+func PruneUsage(beforeDay string) (int64, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	ctx, cancel := writeCtx()
+	defer cancel()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var removed int64
+	for _, table := range []string{"server_usage_fetches", "server_usage_pairs", "server_usage_misses"} {
+		res, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE day < ?", beforeDay)
+		if err != nil {
+			return 0, err
+		}
+		n, _ := res.RowsAffected()
+		removed += n
+	}
+	return removed, tx.Commit()
+}
+
+// TopTemplates returns the most-fetched templates on or after sinceDay.
+func TopTemplates(sinceDay string, limit int) ([]TemplateUsage, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	ctx, cancel := readCtx()
+	defer cancel()
+
+	rows, err := conn.QueryContext(ctx, `
+SELECT template,
+       SUM(count),
+       SUM(CASE WHEN source = 'native' THEN count ELSE 0 END),
+       SUM(CASE WHEN source = 'compat' THEN count ELSE 0 END)
+FROM server_usage_fetches
+WHERE day >= ?
+GROUP BY template
+ORDER BY SUM(count) DESC, template
+LIMIT ?`, sinceDay, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []TemplateUsage{}
+	for rows.Next() {
+		var u TemplateUsage
+		if err := rows.Scan(&u.Template, &u.Total, &u.Native, &u.Compat); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+// UsageBySource returns total fetches per source on or after sinceDay.
+func UsageBySource(sinceDay string) (map[string]int64, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	ctx, cancel := readCtx()
+	defer cancel()
+
+	rows, err := conn.QueryContext(ctx, `
+SELECT source, SUM(count) FROM server_usage_fetches
+WHERE day >= ? GROUP BY source`, sinceDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string]int64{}
+	for rows.Next() {
+		var (
+			source string
+			n      int64
+		)
+		if err := rows.Scan(&source, &n); err != nil {
+			return nil, err
+		}
+		out[source] = n
+	}
+	return out, rows.Err()
+}
+
+// DailyFetches returns total fetches per day on or after sinceDay, oldest
+// first. Days without traffic are omitted.
+func DailyFetches(sinceDay string) ([]DayCount, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	ctx, cancel := readCtx()
+	defer cancel()
+
+	rows, err := conn.QueryContext(ctx, `
+SELECT day, SUM(count) FROM server_usage_fetches
+WHERE day >= ? GROUP BY day ORDER BY day`, sinceDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []DayCount{}
+	for rows.Next() {
+		var d DayCount
+		if err := rows.Scan(&d.Day, &d.Count); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// TopMisses returns the most-requested unknown template names on or after
+// sinceDay.
+func TopMisses(sinceDay string, limit int) ([]NameCount, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	ctx, cancel := readCtx()
+	defer cancel()
+
+	rows, err := conn.QueryContext(ctx, `
+SELECT name, SUM(count) FROM server_usage_misses
+WHERE day >= ? GROUP BY name
+ORDER BY SUM(count) DESC, name
+LIMIT ?`, sinceDay, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []NameCount{}
+	for rows.Next() {
+		var n NameCount
+		if err := rows.Scan(&n.Name, &n.Count); err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+// TopPairs returns the template pairs most often requested together on or
+// after sinceDay. A non-empty template restricts it to pairs containing that
+// template.
+func TopPairs(sinceDay, template string, limit int) ([]PairUsage, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	ctx, cancel := readCtx()
+	defer cancel()
+
+	rows, err := conn.QueryContext(ctx, `
+SELECT template_a, template_b, SUM(count) FROM server_usage_pairs
+WHERE day >= ? AND (? = '' OR template_a = ? OR template_b = ?)
+GROUP BY template_a, template_b
+ORDER BY SUM(count) DESC, template_a, template_b
+LIMIT ?`, sinceDay, template, template, template, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []PairUsage{}
+	for rows.Next() {
+		var p PairUsage
+		if err := rows.Scan(&p.Templates[0], &p.Templates[1], &p.Count); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
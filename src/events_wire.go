@@ -0,0 +1,68 @@
+package main
+
+import (
+	"time"
+
+	"github.com/apimgr/gitignore/src/server"
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// eventServer is the running server whose /api/v1/events stream the publish
+// helpers feed. It is nil until the server is built and stays nil on
+// CLI-only paths, so the helpers safely no-op there.
+var eventServer *server.Server
+
+// publishEvent publishes kind with data on the event stream.
+func publishEvent(kind string, data interface{}) {
+	if eventServer != nil {
+		eventServer.PublishEvent(kind, data)
+	}
+}
+
+// publishTemplatesUpdated announces a newly installed dataset. The change
+// lists let clients invalidate only the templates that changed; they are
+// omitted when unknown, as after a reload.
+func publishTemplatesUpdated(tm *template.Manager, added, removed, modified []string) {
+	data := map[string]interface{}{
+		"revision": tm.Revision(),
+		"count":    tm.Count(),
+	}
+	if added != nil || removed != nil || modified != nil {
+		data["added"] = nonNil(added)
+		data["removed"] = nonNil(removed)
+		data["modified"] = nonNil(modified)
+	}
+	publishEvent(server.EventTemplatesUpdated, data)
+}
+
+// publishTaskResult is the scheduler's OnResult hook.
+func publishTaskResult(id, name, status string, dur time.Duration, nextRun time.Time) {
+	if eventServer != nil {
+		eventServer.PublishTaskResult(id, name, status, dur, nextRun)
+	}
+}
+
+// publishTorStatus is the Tor manager's OnStatus hook.
+func publishTorStatus(status, address string) {
+	data := map[string]string{"status": status}
+	if address != "" {
+		data["address"] = address
+	}
+	publishEvent(server.EventTorStatus, data)
+}
+
+// publishUpdate announces update.available or update.installed.
+func publishUpdate(kind, currentVersion, newVersion string) {
+	publishEvent(kind, map[string]string{
+		"current_version": currentVersion,
+		"new_version":     newVersion,
+	})
+}
+
+// nonNil returns s, or an empty slice so it encodes as [] rather than null.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -383,11 +383,15 @@ func main() {
 	}
 	log.Printf("Loaded %d templates", templateMgr.Count())
 
+	// Prefer the last dataset installed by the template_sync task over the
+	// embedded snapshot when upstream sync is enabled.
+	loadSyncedTemplates(templateMgr, newTemplateSyncer(cfg, dataDir))
+
 	// ── Signal handling ──────────────────────────────────────────────────────
 	// Platform-dependent subscription (AI.md PART 8): SIGTERM/SIGINT/SIGQUIT and
 	// SIGRTMIN+3 shut down gracefully, SIGUSR1 reopens logs, SIGUSR2 dumps
-	// status, and SIGHUP is explicitly ignored (config auto-reloads via the file
-	// watcher). See signal_unix.go / signal_windows.go.
+	// status, and SIGHUP reloads the configuration. See signal_unix.go /
+	// signal_windows.go.
 	sigChan := make(chan os.Signal, 1)
 	notifyShutdownSignals(sigChan)
 
@@ -420,6 +424,9 @@ func main() {
 		GeoIP:     geoipMgr,
 	})
 
+	eventServer = srv
+	go publishCLIRelease(cfg, srv)
+
 	log.Printf("gitignore %s (commit: %s, built: %s)", Version, CommitID, BuildDate)
 	log.Printf("Listening on %s:%d", serverAddress, portNum)
 	if devMode {
@@ -436,7 +443,7 @@ func main() {
 
 	// ── Start the always-running task scheduler (AI.md PART 18) ───────────────
 	var sched *scheduler.Scheduler
-	if s, err := buildScheduler(cfg, configDir, dataDir, logsDir, geoipMgr); err != nil {
+	if s, err := buildScheduler(cfg, configDir, dataDir, logsDir, geoipMgr, templateMgr); err != nil {
 		log.Printf("Failed to build scheduler: %v", err)
 	} else {
 		sched = s
@@ -468,6 +475,16 @@ func main() {
 			case sigActionStatusDump:
 				log.Println("Received SIGUSR2, dumping status...")
 				dumpStatus()
+			case sigActionReload:
+				log.Println("Received SIGHUP, reloading configuration...")
+				err := reloadConfig(configPath, dataDir, templateMgr)
+				if err != nil {
+					log.Printf("SIGHUP: %v; keeping the running configuration", err)
+				} else {
+					srv.ReloadBranding(config.Get().Server.Branding)
+					publishEvent(server.EventConfigReloaded, struct{}{})
+				}
+				srv.ObserveConfigReload(err)
 			default:
 				log.Printf("Received signal %v, shutting down...", sig)
 				// Stop Tor FIRST (server owns the Tor lifecycle, AI.md PART 31).
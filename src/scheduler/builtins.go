@@ -24,6 +24,8 @@ const (
 	schedBackupHourly    = "@hourly"
 	schedHealthcheckSelf = "@every 5m"
 	schedTorHealth       = "@every 10m"
+	schedTemplateSync    = "30 4 * * *"
+	schedUsageCleanup    = "15 0 * * *"
 )
 
 // Deps carries the runtime handles the built-in tasks need. Handlers whose
@@ -44,6 +46,13 @@ type Deps struct {
 	// UpdateCheck runs the self-update check (AI.md PART 22). When nil the
 	// update_check task skips.
 	UpdateCheck HandlerFunc
+	// TemplateSync pulls the upstream template dataset from the configured git
+	// mirror. When nil — sync disabled or CLI-only paths — the template_sync
+	// task skips.
+	TemplateSync HandlerFunc
+	// UsageRetentionDays is how many days of usage analytics usage_cleanup
+	// keeps; 90 when unset.
+	UsageRetentionDays int
 	// TorInstalled gates the tor_health task.
 	TorInstalled bool
 }
@@ -55,6 +64,9 @@ func RegisterBuiltins(s *Scheduler, d Deps) error {
 	if d.LogRetentionDays <= 0 {
 		d.LogRetentionDays = 7
 	}
+	if d.UsageRetentionDays <= 0 {
+		d.UsageRetentionDays = 90
+	}
 
 	tasks := []struct {
 		id, name, schedule string
@@ -73,6 +85,8 @@ func RegisterBuiltins(s *Scheduler, d Deps) error {
 		{"backup_hourly", "Backup Hourly", schedBackupHourly, true, false, backupHandler(d.BackupEnabled, d.BackupHourly)},
 		{"healthcheck_self", "Health Check", schedHealthcheckSelf, false, false, healthcheckSelfHandler},
 		{"tor_health", "Tor Health", schedTorHealth, false, false, torHealthHandler(d)},
+		{"template_sync", "Template Sync", schedTemplateSync, true, true, templateSyncHandler(d)},
+		{"usage_cleanup", "Usage Cleanup", schedUsageCleanup, false, false, usageCleanupHandler(d)},
 	}
 
 	for _, t := range tasks {
@@ -118,6 +132,22 @@ func tokenCleanupHandler(ctx context.Context) error {
 	return nil
 }
 
+// usageCleanupHandler deletes the usage analytics of days that have left the
+// retention window, so server.db does not grow with every day served.
+func usageCleanupHandler(d Deps) HandlerFunc {
+	return func(ctx context.Context) error {
+		cutoff := time.Now().UTC().AddDate(0, 0, -d.UsageRetentionDays).Format(db.UsageDayLayout)
+		n, err := db.PruneUsage(cutoff)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			log.Printf("scheduler: usage_cleanup removed %d rows from before %s", n, cutoff)
+		}
+		return nil
+	}
+}
+
 // healthcheckSelfHandler verifies core dependencies (currently the database).
 func healthcheckSelfHandler(ctx context.Context) error {
 	return db.Ping()
@@ -158,6 +188,17 @@ func updateCheckHandler(d Deps) HandlerFunc {
 	}
 }
 
+// templateSyncHandler runs the injected upstream dataset sync. When no handler
+// is injected — sync disabled or CLI-only paths — it skips.
+func templateSyncHandler(d Deps) HandlerFunc {
+	return func(ctx context.Context) error {
+		if d.TemplateSync == nil {
+			return ErrSkipped
+		}
+		return d.TemplateSync(ctx)
+	}
+}
+
 // backupHandler runs the injected backup function when backups are enabled.
 func backupHandler(enabled bool, fn HandlerFunc) HandlerFunc {
 	return func(ctx context.Context) error {
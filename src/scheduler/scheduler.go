@@ -104,6 +104,10 @@ type Config struct {
 	// operator email (AI.md PART 17). Never called while holding the scheduler
 	// lock.
 	OnError func(id, name string, err error, nextRun time.Time)
+	// OnResult, when set, is invoked (in a goroutine) after every task run
+	// with its status (success, skipped or failed), duration and next run.
+	// Used by the wire layer to publish scheduler.task events.
+	OnResult func(id, name, status string, dur time.Duration, nextRun time.Time)
 }
 
 // Scheduler owns the registered tasks and the background execution loop.
@@ -117,6 +121,7 @@ type Scheduler struct {
 	now      func() time.Time
 	persist  func(db.SchedulerState) error
 	onError  func(id, name string, err error, nextRun time.Time)
+	onResult func(id, name, status string, dur time.Duration, nextRun time.Time)
 
 	stop    chan struct{}
 	done    chan struct{}
@@ -145,13 +150,14 @@ func New(cfg Config) *Scheduler {
 		tick = defaultTickEvery
 	}
 	return &Scheduler{
-		tasks:   make(map[string]*Task),
-		loc:     loc,
-		catchUp: catchUp,
-		tick:    tick,
-		now:     time.Now,
-		persist: db.UpsertSchedulerState,
-		onError: cfg.OnError,
+		tasks:    make(map[string]*Task),
+		loc:      loc,
+		catchUp:  catchUp,
+		tick:     tick,
+		now:      time.Now,
+		persist:  db.UpsertSchedulerState,
+		onError:  cfg.OnError,
+		onResult: cfg.OnResult,
 	}
 }
 
@@ -384,6 +390,9 @@ func (s *Scheduler) execute(ctx context.Context, t *Task, markRunning bool) {
 			go s.onError(t.ID, t.Name, err, t.nextRun)
 		}
 	}
+	if s.onResult != nil {
+		go s.onResult(t.ID, t.Name, t.lastStatus, dur, t.nextRun)
+	}
 	s.saveTaskLocked(t)
 }
 
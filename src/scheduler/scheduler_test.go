@@ -69,6 +69,36 @@ func TestExecuteSkipped(t *testing.T) {
 	}
 }
 
+// TestExecuteOnResult verifies every run, not just failures, reports its
+// outcome to OnResult.
+func TestExecuteOnResult(t *testing.T) {
+	s, _ := newTestScheduler()
+	results := make(chan string, 2)
+	s.onResult = func(id, name, status string, dur time.Duration, nextRun time.Time) {
+		if nextRun.IsZero() {
+			t.Errorf("%s: next run not set", id)
+		}
+		results <- id + ":" + status
+	}
+	_ = s.RegisterTask("ok", "OK", "@every 1h", false, func(ctx context.Context) error { return nil })
+	_ = s.RegisterTask("bad", "Bad", "@every 1h", false, func(ctx context.Context) error { return errors.New("boom") })
+	s.execute(context.Background(), s.tasks["ok"], true)
+	s.execute(context.Background(), s.tasks["bad"], true)
+
+	got := map[string]bool{}
+	for range 2 {
+		select {
+		case r := <-results:
+			got[r] = true
+		case <-time.After(time.Second):
+			t.Fatal("OnResult was not called")
+		}
+	}
+	if !got["ok:success"] || !got["bad:failed"] {
+		t.Errorf("results = %v", got)
+	}
+}
+
 func TestExecuteFailureAndRetryBackoff(t *testing.T) {
 	s, _ := newTestScheduler()
 	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
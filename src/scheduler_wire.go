@@ -13,12 +13,13 @@ import (
 	"github.com/apimgr/gitignore/src/geoip"
 	apppath "github.com/apimgr/gitignore/src/path"
 	"github.com/apimgr/gitignore/src/scheduler"
+	"github.com/apimgr/gitignore/src/template"
 )
 
 // buildScheduler constructs the scheduler from config and registers every
 // built-in task (AI.md PART 18). Backup handlers are injected here because
 // backup logic lives in package main.
-func buildScheduler(cfg *config.Config, configDir, dataDir, logsDir string, gm *geoip.Manager) (*scheduler.Scheduler, error) {
+func buildScheduler(cfg *config.Config, configDir, dataDir, logsDir string, gm *geoip.Manager, tm *template.Manager) (*scheduler.Scheduler, error) {
 	catchUp := time.Hour
 	if cfg.Server.Schedule.CatchUpWindow != "" {
 		if d, err := time.ParseDuration(cfg.Server.Schedule.CatchUpWindow); err == nil && d > 0 {
@@ -34,6 +35,8 @@ func buildScheduler(cfg *config.Config, configDir, dataDir, logsDir string, gm *
 		OnError: func(id, name string, err error, nextRun time.Time) {
 			emitSchedulerError(id, name, err.Error(), fmtTime(nextRun))
 		},
+		// scheduler.task events on /api/v1/events.
+		OnResult: publishTaskResult,
 	})
 
 	retention := cfg.Server.Maintenance.Cleanup.LogRetentionDays
@@ -42,19 +45,23 @@ func buildScheduler(cfg *config.Config, configDir, dataDir, logsDir string, gm *
 	}
 
 	deps := scheduler.Deps{
-		LogsDir:          logsDir,
-		LogRetentionDays: retention,
-		SSLEnabled:       cfg.Server.SSL.Enabled,
-		BackupEnabled:    true,
-		BackupDaily:      backupTaskHandler(cfg, configDir, dataDir, "daily"),
-		BackupHourly:     backupTaskHandler(cfg, configDir, dataDir, "hourly"),
-		UpdateCheck:      updateCheckHandler(cfg, dataDir),
-		TorInstalled:     torBinaryInstalled(cfg),
+		LogsDir:            logsDir,
+		LogRetentionDays:   retention,
+		UsageRetentionDays: cfg.Server.Maintenance.Cleanup.UsageRetentionDays,
+		SSLEnabled:         cfg.Server.SSL.Enabled,
+		BackupEnabled:      true,
+		BackupDaily:        backupTaskHandler(cfg, configDir, dataDir, "daily"),
+		BackupHourly:       backupTaskHandler(cfg, configDir, dataDir, "hourly"),
+		UpdateCheck:        updateCheckHandler(cfg, dataDir),
+		TorInstalled:       torBinaryInstalled(cfg),
 	}
 
 	if gm != nil {
 		deps.GeoIPUpdate = geoipUpdateHandler(gm)
 	}
+	if syncer := newTemplateSyncer(cfg, dataDir); syncer != nil && tm != nil {
+		deps.TemplateSync = templateSyncHandler(tm, syncer)
+	}
 
 	if err := scheduler.RegisterBuiltins(s, deps); err != nil {
 		return nil, err
@@ -123,7 +130,17 @@ func handleSchedulerCommand(args []string, cfg *config.Config, configDir, dataDi
 	defer db.Close()
 
 	gm := newGeoIP(cfg, dataDir)
-	sched, err := buildScheduler(cfg, configDir, dataDir, logsDir, gm)
+
+	// A manual `scheduler run template_sync` stages the dataset on disk and
+	// records its changelog; a running server loads it on its next restart.
+	tm, err := template.New()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load templates: %v\n", err)
+		os.Exit(exOSFile)
+	}
+	loadSyncedTemplates(tm, newTemplateSyncer(cfg, dataDir))
+
+	sched, err := buildScheduler(cfg, configDir, dataDir, logsDir, gm, tm)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to build scheduler: %v\n", err)
 		os.Exit(exConfig)
@@ -0,0 +1,72 @@
+package sdk
+
+import (
+	"container/list"
+	"sync"
+)
+
+// CacheEntry is a cached response body and the ETag it was served with.
+type CacheEntry struct {
+	ETag string
+	Body []byte
+}
+
+// Cache stores responses by request for revalidation with If-None-Match.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (CacheEntry, bool)
+	Set(key string, e CacheEntry)
+}
+
+// MemoryCache is an in-memory Cache holding the most recently used
+// entries.
+type MemoryCache struct {
+	max     int
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// memoryItem is one MemoryCache entry in its recency list.
+type memoryItem struct {
+	key   string
+	entry CacheEntry
+}
+
+// NewMemoryCache returns a MemoryCache of at most max entries; max <= 0
+// means 256.
+func NewMemoryCache(max int) *MemoryCache {
+	if max <= 0 {
+		max = 256
+	}
+	return &MemoryCache{max: max, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Get returns the entry for key.
+func (m *MemoryCache) Get(key string) (CacheEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[key]
+	if !ok {
+		return CacheEntry{}, false
+	}
+	m.order.MoveToFront(el)
+	return el.Value.(*memoryItem).entry, true
+}
+
+// Set stores e for key, evicting the least recently used entry when full.
+func (m *MemoryCache) Set(key string, e CacheEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.entries[key]; ok {
+		el.Value.(*memoryItem).entry = e
+		m.order.MoveToFront(el)
+		return
+	}
+	m.entries[key] = m.order.PushFront(&memoryItem{key: key, entry: e})
+	if m.order.Len() > m.max {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryItem).key)
+	}
+}
@@ -0,0 +1,235 @@
+// Package sdk is the Go client for the gitignore server's public API. It
+// covers every read endpoint: templates, search, combine and compose,
+// compare, categories, stats and usage analytics, the dataset archive,
+// health, autodiscovery, and the gitignore.io-compatible routes.
+//
+//	c := sdk.New("https://gitignore.example.com")
+//	content, err := c.Combine(ctx, []string{"Go", "Node"}, nil)
+//
+// Every call takes a context. Failed requests return *Error carrying the
+// server's error code, message and, for unknown templates, suggestions.
+// Connection failures and 429/502/503/504 answers are retried with
+// exponential backoff, honoring Retry-After (see RetryPolicy). Responses
+// with an ETag can be cached and revalidated (see Cache). Requests go
+// through HTTPClient, so any http.RoundTripper plugs in as its Transport.
+//
+// The package depends on the standard library only. Version is its own
+// semantic version, raised with every change to its exported API.
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Version is the SDK's semantic version, sent in the default User-Agent.
+const Version = "1.0.0"
+
+// APIVersion is the server API version the SDK speaks.
+const APIVersion = "v1"
+
+// apiBase is the path prefix of the versioned API.
+const apiBase = "/api/" + APIVersion
+
+// maxResponseSize bounds a response body, far above the templates archive,
+// the largest at a few hundred kilobytes.
+const maxResponseSize = 64 << 20
+
+// Client talks to one gitignore server. Set its fields before the first
+// request; it is safe for concurrent use afterwards.
+type Client struct {
+	// BaseURL is the server's root URL, without the /api/v1 prefix.
+	BaseURL string
+	// HTTPClient sends the requests; its Transport is the place to plug in
+	// proxies, tracing or test doubles. New sets one without an overall
+	// timeout: Timeout and the caller's context bound each request.
+	HTTPClient *http.Client
+	// Timeout bounds each attempt of a request; 0 leaves it to the context.
+	Timeout time.Duration
+	// UserAgent is sent with every request.
+	UserAgent string
+	// Lang is sent as Accept-Language so the server localizes messages;
+	// empty leaves the server default.
+	Lang string
+	// Retry decides which failed requests are tried again and when.
+	Retry RetryPolicy
+	// Cache, when set, keeps responses carrying an ETag and revalidates
+	// them with If-None-Match, so unchanged data is not downloaded again.
+	Cache Cache
+}
+
+// New returns a Client for the server at baseURL with the default retry
+// policy, a 30 second timeout per attempt and no cache.
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{},
+		Timeout:    30 * time.Second,
+		UserAgent:  "gitignore-sdk-go/" + Version,
+		Retry:      DefaultRetryPolicy,
+	}
+}
+
+// envelope is the {"ok": true, "data": ...} body of JSON responses.
+type envelope struct {
+	Data json.RawMessage `json:"data"`
+}
+
+// Fetch GETs path (with query, when set) from the server and returns the
+// body of a successful response, applying the client's retries, cache and
+// headers. header adds to or overrides the default ones; JSON is accepted
+// unless it sets Accept. A 304 answer to an If-None-Match set in header
+// returns a nil body. It serves endpoints this package has no method for
+// and, pointed at another service, gitignore.io-compatible or GitHub APIs.
+func (c *Client) Fetch(ctx context.Context, path string, query url.Values, header http.Header) ([]byte, error) {
+	body, _, err := c.fetch(ctx, path, query, header)
+	return body, err
+}
+
+// fetch is Fetch returning the final response's headers as well.
+func (c *Client) fetch(ctx context.Context, path string, query url.Values, header http.Header) ([]byte, http.Header, error) {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, nil, fmt.Errorf("invalid server URL: %s", c.BaseURL)
+	}
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+	req.Header.Set("Accept", "application/json")
+	if c.Lang != "" {
+		req.Header.Set("Accept-Language", c.Lang)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	// Responses differ by language and format, so both are part of the key.
+	key := target + "\x00" + req.Header.Get("Accept") + "\x00" + c.Lang
+	var cached *CacheEntry
+	if c.Cache != nil && req.Header.Get("If-None-Match") == "" {
+		if e, ok := c.Cache.Get(key); ok {
+			cached = &e
+			req.Header.Set("If-None-Match", e.ETag)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		body, resp, err := c.attempt(ctx, req)
+		var wait time.Duration
+		switch {
+		case err != nil:
+			// The connection failed; the caller's context ending is final.
+			if ctx.Err() != nil || attempt >= c.Retry.MaxAttempts {
+				return nil, nil, err
+			}
+			wait = c.Retry.backoff(attempt)
+		case resp.StatusCode == http.StatusNotModified:
+			// Without a cached copy the If-None-Match was the caller's own.
+			if cached == nil {
+				return nil, resp.Header, nil
+			}
+			return cached.Body, resp.Header, nil
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			if etag := resp.Header.Get("ETag"); c.Cache != nil && etag != "" {
+				c.Cache.Set(key, CacheEntry{ETag: etag, Body: body})
+			}
+			return body, resp.Header, nil
+		default:
+			apiErr := newError(resp, body)
+			if !retryable(resp.StatusCode) || attempt >= c.Retry.MaxAttempts {
+				return nil, resp.Header, apiErr
+			}
+			wait = c.Retry.backoff(attempt)
+			if apiErr.RetryAfter > 0 {
+				if apiErr.RetryAfter > c.Retry.MaxRetryAfter {
+					return nil, resp.Header, apiErr
+				}
+				wait = apiErr.RetryAfter
+			}
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends req once and reads the response body.
+func (c *Client) attempt(ctx context.Context, req *http.Request) ([]byte, *http.Response, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req.Clone(ctx))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading response: %w", err)
+	}
+	if len(body) > maxResponseSize {
+		return nil, nil, fmt.Errorf("response exceeds %d bytes", maxResponseSize)
+	}
+	return body, resp, nil
+}
+
+// getJSON fetches path from the versioned API and decodes the envelope's
+// data into out.
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out interface{}) error {
+	body, err := c.Fetch(ctx, apiBase+path, query, nil)
+	if err != nil {
+		return err
+	}
+	var env envelope
+	if err := decode(body, &env); err != nil {
+		return err
+	}
+	return decode(env.Data, out)
+}
+
+// decode unmarshals a JSON response body into out.
+func decode(body []byte, out interface{}) error {
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+// getText fetches path as plain text.
+func (c *Client) getText(ctx context.Context, path string, query url.Values) (string, error) {
+	body, err := c.Fetch(ctx, path, query, http.Header{"Accept": {"text/plain"}})
+	return string(body), err
+}
+
+// jitter spreads d over [d/2, d) so clients retrying together do not stay
+// in step.
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	return d/2 + rand.N(d/2)
+}
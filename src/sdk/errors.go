@@ -0,0 +1,120 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error is a response the server answered with a non-2xx status.
+type Error struct {
+	// Status is the HTTP status code.
+	Status int
+	// Code is the server's machine-readable error code, such as NOT_FOUND
+	// or RATE_LIMITED; empty for services that send none.
+	Code string
+	// Message is the human-readable explanation, localized to Client.Lang.
+	Message string
+	// Suggestions are the known template names closest to an unknown one.
+	Suggestions []string
+	// Details is the error's structured context, as sent.
+	Details map[string]interface{}
+	// RetryAfter is how long the server asked clients to wait, from the
+	// Retry-After header.
+	RetryAfter time.Duration
+
+	// body is the response body, for endpoints whose error statuses still
+	// carry data.
+	body []byte
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("server returned %d: %s", e.Status, e.Message)
+}
+
+// IsNotFound reports whether err is an *Error for a missing template,
+// category or other resource.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound
+}
+
+// ParseError returns the *Error for a failed response whose body was read
+// by the caller, as for streams that Fetch does not serve.
+func ParseError(resp *http.Response, body []byte) *Error {
+	return newError(resp, body)
+}
+
+// newError builds the *Error for a failed response. It reads this server's
+// {"ok": false, "error": CODE, "message": ..., "details": {...}} envelope,
+// GitHub's {"message": ...}, or a plain-text body.
+func newError(resp *http.Response, body []byte) *Error {
+	e := &Error{
+		Status:     resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		body:       body,
+	}
+	var env struct {
+		Error   string                 `json:"error"`
+		Message string                 `json:"message"`
+		Details map[string]interface{} `json:"details"`
+	}
+	if json.Unmarshal(body, &env) == nil {
+		e.Message = env.Message
+		e.Details = env.Details
+		if isCode(env.Error) {
+			e.Code = env.Error
+		} else if e.Message == "" {
+			e.Message = env.Error
+		}
+		if list, ok := env.Details["suggestions"].([]interface{}); ok {
+			for _, s := range list {
+				if name, ok := s.(string); ok {
+					e.Suggestions = append(e.Suggestions, name)
+				}
+			}
+		}
+	} else {
+		e.Message = strings.TrimSpace(string(body))
+	}
+	if e.Message == "" {
+		e.Message = e.Code
+	}
+	if e.Message == "" {
+		e.Message = resp.Status
+	}
+	return e
+}
+
+// isCode reports whether s looks like an error code (UPPER_SNAKE_CASE)
+// rather than a message some services put in the same field.
+func isCode(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseRetryAfter reads a Retry-After header, in seconds or as an HTTP date;
+// 0 when absent or unreadable.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,56 @@
+package sdk
+
+import (
+	"net/http"
+	"time"
+)
+
+// RetryPolicy decides how a request that failed to connect, or that the
+// server answered with 429, 502, 503 or 504, is tried again. Waits double
+// from MinBackoff up to MaxBackoff, with jitter, unless the server sends
+// Retry-After.
+type RetryPolicy struct {
+	// MaxAttempts is the number of tries per request, the first included;
+	// 1 or less disables retries.
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+	// MaxRetryAfter caps how long a Retry-After header may make a request
+	// wait; when the server asks for longer the *Error is returned at once,
+	// its RetryAfter set.
+	MaxRetryAfter time.Duration
+}
+
+// DefaultRetryPolicy is the policy New sets: three tries, waiting about a
+// quarter and then half a second, and up to 30 seconds when asked to.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:   3,
+	MinBackoff:    250 * time.Millisecond,
+	MaxBackoff:    5 * time.Second,
+	MaxRetryAfter: 30 * time.Second,
+}
+
+// NoRetry is a policy that never retries.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// backoff returns the wait after the given failed attempt, counting from 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return jitter(d)
+}
+
+// retryable reports whether a response with status may succeed if sent
+// again.
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
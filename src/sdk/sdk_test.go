@@ -0,0 +1,251 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetry keeps retrying tests quick.
+var fastRetry = RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond, MaxRetryAfter: 2 * time.Second}
+
+// newTestClient returns a Client for a server running h.
+func newTestClient(t *testing.T, h http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	c := New(srv.URL)
+	c.Retry = fastRetry
+	return c
+}
+
+// TestErrors verifies that failed requests return *Error carrying the
+// server's code, message and suggestions, and that other services' error
+// bodies still give a message.
+func TestErrors(t *testing.T) {
+	cases := []struct {
+		name, body, code, message string
+		status                    int
+		suggestions               []string
+	}{
+		{"envelope", `{"ok":false,"error":"NOT_FOUND","message":"template not found","details":{"name":"Pyhton","suggestions":["Python"]}}`, "NOT_FOUND", "template not found", 404, []string{"Python"}},
+		{"github", `{"message":"Not Found"}`, "", "Not Found", 404, nil},
+		{"text", "no such template\n", "", "no such template", 404, nil},
+		{"empty", "", "", "400 Bad Request", 400, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			})
+			_, err := c.Template(context.Background(), "Pyhton")
+			var apiErr *Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want *Error", err)
+			}
+			if apiErr.Status != tc.status || apiErr.Code != tc.code || apiErr.Message != tc.message ||
+				strings.Join(apiErr.Suggestions, ",") != strings.Join(tc.suggestions, ",") {
+				t.Errorf("got %+v", apiErr)
+			}
+			if IsNotFound(err) != (tc.status == http.StatusNotFound) {
+				t.Errorf("IsNotFound = %v", IsNotFound(err))
+			}
+		})
+	}
+}
+
+// TestRetry verifies that 503s are retried after the server's Retry-After,
+// that requests give up after MaxAttempts, and that a Retry-After beyond
+// MaxRetryAfter is returned at once.
+func TestRetry(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ok":true,"data":["Go","Node"]}`))
+	})
+	start := time.Now()
+	names, err := c.List(context.Background())
+	if err != nil || strings.Join(names, ",") != "Go,Node" {
+		t.Fatalf("List = %v, %v", names, err)
+	}
+	if calls.Load() != 2 || time.Since(start) < time.Second {
+		t.Errorf("%d calls in %v, want 2 a second apart", calls.Load(), time.Since(start))
+	}
+
+	calls.Store(0)
+	c = newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	if _, err := c.List(context.Background()); err == nil || calls.Load() != 3 {
+		t.Errorf("502s: %d calls, err %v; want 3 and an error", calls.Load(), err)
+	}
+
+	calls.Store(0)
+	c = newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"ok":false,"error":"RATE_LIMITED","message":"slow down"}`))
+	})
+	_, err = c.List(context.Background())
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != "RATE_LIMITED" || apiErr.RetryAfter != time.Hour || calls.Load() != 1 {
+		t.Errorf("429 with an hour's Retry-After: %d calls, err %+v", calls.Load(), err)
+	}
+
+	calls.Store(0)
+	c = newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	})
+	if _, err := c.List(context.Background()); !IsNotFound(err) || calls.Load() != 1 {
+		t.Errorf("404: %d calls, err %v; want one try", calls.Load(), err)
+	}
+}
+
+// TestConnectionErrors verifies that connection failures are retried and
+// surface as *url.Error, and that a cancelled context stops a request.
+func TestConnectionErrors(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	c := New(srv.URL)
+	c.Retry = fastRetry
+	var urlErr *url.Error
+	if _, err := c.List(context.Background()); !errors.As(err, &urlErr) {
+		t.Errorf("List against a closed server: %v, want *url.Error", err)
+	}
+
+	c = newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.List(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("List past its deadline: %v, want context.DeadlineExceeded", err)
+	}
+}
+
+// TestCache verifies that responses with an ETag are revalidated with
+// If-None-Match and a 304 answers from the cache, separately per language.
+func TestCache(t *testing.T) {
+	var full atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		etag := `"v1-` + r.Header.Get("Accept-Language") + `"`
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Write([]byte(`{"ok":true,"data":{"total_templates":2,"categories":1,"category_breakdown":{"Root":2},"total_size_bytes":10}}`))
+	})
+	c.Cache = NewMemoryCache(0)
+	for range 3 {
+		stats, err := c.Stats(context.Background())
+		if err != nil || stats.TotalTemplates != 2 || stats.CategoryBreakdown["Root"] != 2 {
+			t.Fatalf("Stats = %+v, %v", stats, err)
+		}
+	}
+	c.Lang = "fr"
+	if _, err := c.Stats(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if full.Load() != 2 {
+		t.Errorf("%d full responses, want one per language", full.Load())
+	}
+
+	m := NewMemoryCache(2)
+	m.Set("a", CacheEntry{ETag: "1"})
+	m.Set("b", CacheEntry{ETag: "2"})
+	m.Get("a")
+	m.Set("c", CacheEntry{ETag: "3"})
+	if _, ok := m.Get("b"); ok {
+		t.Error("MemoryCache kept the least recently used entry")
+	}
+	if _, ok := m.Get("a"); !ok {
+		t.Error("MemoryCache evicted a recently used entry")
+	}
+}
+
+// roundTripFunc is an http.RoundTripper backed by a function.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// TestTransport verifies that requests go through a custom Transport with
+// the client's headers, escaped paths and queries.
+func TestTransport(t *testing.T) {
+	var got *http.Request
+	c := New("https://gitignore.example.com/")
+	c.Lang = "de"
+	c.HTTPClient.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		got = r
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       http.NoBody,
+			Request:    r,
+		}, nil
+	})
+	if _, err := c.Combine(context.Background(), []string{"Go", "C++"}, nil); err == nil {
+		t.Error("Combine with an empty body: want a decoding error")
+	}
+	if got == nil || got.URL.String() != "https://gitignore.example.com/api/v1/combine?templates=Go%2CC%2B%2B" {
+		t.Fatalf("request = %v", got)
+	}
+	if got.Header.Get("Accept-Language") != "de" || !strings.HasPrefix(got.Header.Get("User-Agent"), "gitignore-sdk-go/") {
+		t.Errorf("headers = %v", got.Header)
+	}
+	c.Template(context.Background(), "a/b")
+	if got.URL.EscapedPath() != "/api/v1/templates/a%2Fb" {
+		t.Errorf("template path = %s", got.URL.EscapedPath())
+	}
+}
+
+// TestParseRetryAfter verifies both Retry-After forms.
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for v, want := range map[string]time.Duration{
+		"":                              0,
+		"120":                           2 * time.Minute,
+		"-5":                            0,
+		"Fri, 02 Jan 2026 03:05:05 GMT": time.Minute,
+		"Fri, 02 Jan 2026 03:00:00 GMT": 0,
+		"soon":                          0,
+	} {
+		if got := parseRetryAfter(v, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", v, got, want)
+		}
+	}
+}
+
+// TestHealth verifies that an unhealthy server's report is returned along
+// with its 503.
+func TestHealth(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"status":"unhealthy","version":"1.2.0","checks":{"database":"error","disk":"ok"}}`))
+	})
+	c.Retry = NoRetry
+	h, err := c.Health(context.Background())
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusServiceUnavailable {
+		t.Errorf("err = %v, want a 503 *Error", err)
+	}
+	if h == nil || h.Status != "unhealthy" || h.Checks["database"] != "error" {
+		t.Errorf("Health = %+v", h)
+	}
+}
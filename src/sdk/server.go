@@ -0,0 +1,42 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Health returns the server's health report. A server that reports itself
+// unhealthy answers 503; its report is still returned, with the *Error.
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	body, err := c.Fetch(ctx, apiBase+"/server/healthz", nil, nil)
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusServiceUnavailable {
+		body = apiErr.body
+	} else if err != nil {
+		return nil, err
+	}
+	var h Health
+	if jsonErr := decode(body, &h); jsonErr != nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, jsonErr
+	}
+	return &h, err
+}
+
+// Autodiscover returns the server's self-description.
+func (c *Client) Autodiscover(ctx context.Context) (*Autodiscover, error) {
+	body, err := c.Fetch(ctx, "/api/autodiscover", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var env struct {
+		Data Autodiscover `json:"data"`
+	}
+	if err := decode(body, &env); err != nil {
+		return nil, err
+	}
+	return &env.Data, nil
+}
@@ -0,0 +1,55 @@
+package sdk
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// Stats returns the size of the template dataset.
+func (c *Client) Stats(ctx context.Context) (*Stats, error) {
+	var stats Stats
+	if err := c.getJSON(ctx, "/stats", nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// Popular returns per-template fetch counts over the last days days (the
+// server default, 30, when 0), at most limit templates (20 when 0). Servers
+// without usage analytics answer with a NOT_IMPLEMENTED *Error.
+func (c *Client) Popular(ctx context.Context, days, limit int) (*Popular, error) {
+	var p Popular
+	if err := c.getJSON(ctx, "/stats/popular", windowQuery(days, limit), &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Combinations returns the template pairs most often requested together
+// over the last days days, limited to pairs including template when it is
+// set. days and limit default as in Popular.
+func (c *Client) Combinations(ctx context.Context, template string, days, limit int) (*Combinations, error) {
+	query := windowQuery(days, limit)
+	if template != "" {
+		query.Set("template", template)
+	}
+	var combos Combinations
+	if err := c.getJSON(ctx, "/stats/combinations", query, &combos); err != nil {
+		return nil, err
+	}
+	return &combos, nil
+}
+
+// windowQuery is the ?days=&limit= of the usage analytics endpoints, each
+// left out when 0.
+func windowQuery(days, limit int) url.Values {
+	query := url.Values{}
+	if days > 0 {
+		query.Set("days", strconv.Itoa(days))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	return query
+}
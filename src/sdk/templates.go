@@ -0,0 +1,148 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// List returns every template name.
+func (c *Client) List(ctx context.Context) ([]string, error) {
+	var names []string
+	err := c.getJSON(ctx, "/list", nil, &names)
+	return names, err
+}
+
+// Template returns the named template, matched ignoring case. An unknown
+// name is a NOT_FOUND *Error whose Suggestions hold the closest names.
+func (c *Client) Template(ctx context.Context, name string) (*Template, error) {
+	var tmpl Template
+	if err := c.getJSON(ctx, "/templates/"+url.PathEscape(name), nil, &tmpl); err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+// Search returns the templates matching q by name, tag or description,
+// best match first.
+func (c *Client) Search(ctx context.Context, q string) ([]Template, error) {
+	var results []Template
+	err := c.getJSON(ctx, "/search", url.Values{"q": {q}}, &results)
+	return results, err
+}
+
+// Categories returns every category name.
+func (c *Client) Categories(ctx context.Context) ([]string, error) {
+	var cats []string
+	err := c.getJSON(ctx, "/categories", nil, &cats)
+	return cats, err
+}
+
+// Category returns the templates in the named category.
+func (c *Client) Category(ctx context.Context, name string) ([]Template, error) {
+	var templates []Template
+	err := c.getJSON(ctx, "/categories/"+url.PathEscape(name), nil, &templates)
+	return templates, err
+}
+
+// CombineOptions adjusts Combine.
+type CombineOptions struct {
+	// SkipUnknown leaves out names the server does not know instead of
+	// failing; Compose reports which they were. Such requests do not count
+	// toward the server's usage analytics.
+	SkipUnknown bool
+}
+
+// Combine returns the named templates merged into one .gitignore, in order
+// and without repeated lines. Unless opts.SkipUnknown is set, an unknown
+// name is a NOT_FOUND *Error whose Suggestions hold the closest names.
+func (c *Client) Combine(ctx context.Context, names []string, opts *CombineOptions) (string, error) {
+	if opts != nil && opts.SkipUnknown {
+		comp, err := c.Compose(ctx, names)
+		if err != nil {
+			return "", err
+		}
+		return comp.Content, nil
+	}
+	var content string
+	err := c.getJSON(ctx, "/combine", templatesQuery(names), &content)
+	return content, err
+}
+
+// Compose combines the named templates like Combine but returns the result
+// per template, with the lines dropped as duplicates and the rules in
+// conflict annotated. Unknown names are reported, with suggestions, rather
+// than failing the request.
+func (c *Client) Compose(ctx context.Context, names []string) (*Combination, error) {
+	var comp Combination
+	if err := c.getJSON(ctx, "/compose", templatesQuery(names), &comp); err != nil {
+		return nil, err
+	}
+	return &comp, nil
+}
+
+// Compare returns the rule matrix of two or more templates: each
+// normalized pattern with every template's sense of it and whether they
+// agree. Unknown names are reported, with suggestions.
+func (c *Client) Compare(ctx context.Context, names []string) (*Comparison, error) {
+	var cmp Comparison
+	if err := c.getJSON(ctx, "/compare", templatesQuery(names), &cmp); err != nil {
+		return nil, err
+	}
+	return &cmp, nil
+}
+
+// TemplatesArchive downloads every template as a gzip-compressed tar
+// archive. When etag is set it is sent as If-None-Match, and an unchanged
+// dataset returns nil data with the same etag. Otherwise it returns the
+// archive and its ETag, the dataset version.
+func (c *Client) TemplatesArchive(ctx context.Context, etag string) ([]byte, string, error) {
+	header := http.Header{"Accept": {"application/gzip"}}
+	if etag != "" {
+		header.Set("If-None-Match", etag)
+	}
+	data, respHeader, err := c.fetch(ctx, apiBase+"/templates.tar.gz", nil, header)
+	if err != nil {
+		return nil, "", err
+	}
+	if data == nil {
+		return nil, etag, nil
+	}
+	return data, respHeader.Get("ETag"), nil
+}
+
+// CompatList returns the template keys of the gitignore.io-compatible
+// /api/list route: lowercase names, as gitignore.io clients expect them.
+func (c *Client) CompatList(ctx context.Context) ([]string, error) {
+	body, err := c.getText(ctx, "/api/list", nil)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, line := range strings.Split(body, "\n") {
+		for _, key := range strings.Split(line, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// CompatCombine returns the combined file of the gitignore.io-compatible
+// /api/{key,key,...} route, for keys as CompatList returns them.
+func (c *Client) CompatCombine(ctx context.Context, keys []string) (string, error) {
+	// Each key is escaped on its own: the commas between them must stay
+	// literal.
+	escaped := make([]string, len(keys))
+	for i, key := range keys {
+		escaped[i] = url.PathEscape(strings.ToLower(strings.TrimSpace(key)))
+	}
+	return c.getText(ctx, "/api/"+strings.Join(escaped, ","), nil)
+}
+
+// templatesQuery is the ?templates= list of names.
+func templatesQuery(names []string) url.Values {
+	return url.Values{"templates": {strings.Join(names, ",")}}
+}
@@ -0,0 +1,206 @@
+package sdk
+
+import "time"
+
+// Template is one .gitignore template.
+type Template struct {
+	Name     string `json:"name"`
+	FileName string `json:"file_name"`
+	Category string `json:"category"`
+	// Path is the template's file in the dataset, as in github/gitignore:
+	// "community/Java/JBoss6.gitignore".
+	Path string `json:"path"`
+	// Content is empty in listings that carry metadata only.
+	Content     string   `json:"content,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Size        int      `json:"size"`
+}
+
+// Unknown is a requested template name the server does not know, with the
+// closest names it does.
+type Unknown struct {
+	Name        string   `json:"name"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// Line is one line of a template in a Combination.
+type Line struct {
+	Text string `json:"text"`
+	// DuplicateOf names the earlier template that already contributed the
+	// pattern; the combined file omits the line.
+	DuplicateOf string `json:"duplicate_of,omitempty"`
+	// ConflictsWith names an earlier template whose rule for the pattern
+	// has the opposite sense; git applies the last matching rule.
+	ConflictsWith string `json:"conflicts_with,omitempty"`
+}
+
+// Section is one template's contribution to a Combination.
+type Section struct {
+	Template string `json:"template"`
+	Category string `json:"category"`
+	Lines    []Line `json:"lines"`
+}
+
+// Combination is a combined .gitignore broken into per-template sections,
+// as the web composer shows it.
+type Combination struct {
+	// Templates are the known names combined, in order.
+	Templates []string  `json:"templates"`
+	Sections  []Section `json:"sections"`
+	// Duplicates counts the lines dropped as repeats of an earlier
+	// template's, Conflicts the rules contradicting one.
+	Duplicates int `json:"duplicates"`
+	Conflicts  int `json:"conflicts"`
+	// Summary is a one-line description in the client's language.
+	Summary string    `json:"summary"`
+	Unknown []Unknown `json:"unknown"`
+	// Content is the combined file, as Combine returns it.
+	Content string `json:"content"`
+	// Permalink is the path of a link to this combination that follows
+	// dataset updates; PinnedPermalink keeps today's content.
+	Permalink       string `json:"permalink"`
+	PinnedPermalink string `json:"pinned_permalink"`
+}
+
+// Rule statuses in a Comparison.
+const (
+	// StatusShared rules appear in every compared template with the same
+	// sense.
+	StatusShared = "shared"
+	// StatusPartial rules appear in more than one template but not all.
+	StatusPartial = "partial"
+	// StatusUnique rules appear in exactly one template.
+	StatusUnique = "unique"
+	// StatusConflict rules are ignored by some templates and re-included by
+	// others.
+	StatusConflict = "conflict"
+)
+
+// Senses of a rule in a CompareRow.
+const (
+	// SenseNone means the template has no rule for the pattern.
+	SenseNone = ""
+	// SenseIgnore means the template ignores matching paths.
+	SenseIgnore = "ignore"
+	// SenseInclude means the template re-includes matching paths with "!".
+	SenseInclude = "include"
+)
+
+// CompareRow is one normalized rule across the compared templates.
+type CompareRow struct {
+	// Pattern is the normalized pattern, with a trailing "/" for
+	// directory-only rules and without the "!" prefix.
+	Pattern string `json:"pattern"`
+	// Senses holds each template's treatment of the pattern, in
+	// Comparison.Templates order.
+	Senses []string `json:"senses"`
+	Status string   `json:"status"`
+}
+
+// Comparison is a matrix of normalized rules against templates.
+type Comparison struct {
+	Templates []string     `json:"templates"`
+	Rules     []CompareRow `json:"rules"`
+	// Counts maps each status to its number of rules.
+	Counts  map[string]int `json:"counts"`
+	Unknown []Unknown      `json:"unknown"`
+	// UnionURL and IntersectionURL are paths exporting the comparison as a
+	// .gitignore.
+	UnionURL        string `json:"union_url"`
+	IntersectionURL string `json:"intersection_url"`
+}
+
+// Stats is the size of the template dataset.
+type Stats struct {
+	TotalTemplates int `json:"total_templates"`
+	Categories     int `json:"categories"`
+	// CategoryBreakdown maps each category to its number of templates.
+	CategoryBreakdown map[string]int `json:"category_breakdown"`
+	TotalSizeBytes    int            `json:"total_size_bytes"`
+}
+
+// TemplateUsage is how often a template was fetched, by route.
+type TemplateUsage struct {
+	Template string `json:"template"`
+	Total    int64  `json:"total"`
+	Native   int64  `json:"native"`
+	Compat   int64  `json:"compat"`
+}
+
+// NameCount is how often an unknown name was requested.
+type NameCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// DayCount is a total for one day, formatted 2006-01-02.
+type DayCount struct {
+	Day   string `json:"day"`
+	Count int64  `json:"count"`
+}
+
+// Popular is the server's usage analytics over a window of days.
+type Popular struct {
+	Days int `json:"days"`
+	// Since is the first day counted.
+	Since     string          `json:"since"`
+	Templates []TemplateUsage `json:"templates"`
+	// Routes splits fetches between the native and gitignore.io-compatible
+	// routes.
+	Routes  map[string]int64 `json:"routes"`
+	Daily   []DayCount       `json:"daily"`
+	Unknown []NameCount      `json:"unknown"`
+}
+
+// Pair is how often two templates were requested together.
+type Pair struct {
+	Templates [2]string `json:"templates"`
+	Count     int64     `json:"count"`
+}
+
+// Combinations are the template pairs most often requested together.
+type Combinations struct {
+	Days  int    `json:"days"`
+	Since string `json:"since"`
+	// Template is the name pairs were limited to, if any.
+	Template     string `json:"template"`
+	Combinations []Pair `json:"combinations"`
+}
+
+// Health is the server's health report. Status is "healthy" when every
+// check passes.
+type Health struct {
+	Status    string    `json:"status"`
+	Version   string    `json:"version"`
+	GoVersion string    `json:"go_version"`
+	Uptime    string    `json:"uptime"`
+	Mode      string    `json:"mode"`
+	Timestamp time.Time `json:"timestamp"`
+	// Checks maps each component, such as database or disk, to "ok" or
+	// "error".
+	Checks map[string]string `json:"checks"`
+}
+
+// CLIVersion is a gitignore-cli build the server publishes for one
+// platform.
+type CLIVersion struct {
+	Version string `json:"version"`
+	SHA256  string `json:"sha256"`
+	// URL is where the server hands out the binary, usually a path on the
+	// server itself.
+	URL string `json:"url"`
+}
+
+// Autodiscover is the server's self-description for zero-config clients.
+type Autodiscover struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Commit     string `json:"commit"`
+	BuildDate  string `json:"buildDate"`
+	APIVersion string `json:"api_version"`
+	APIBase    string `json:"api_base"`
+	// CLIVersions maps "{os}-{arch}" to the gitignore-cli build of the
+	// server's release; empty for development servers.
+	CLIVersions map[string]CLIVersion `json:"cli_versions,omitempty"`
+}
@@ -8,8 +8,10 @@ import (
 	"io/fs"
 	"net/http"
 	"os"
+	"slices"
 
 	"github.com/apimgr/gitignore/src/common/i18n"
+	"github.com/apimgr/gitignore/src/config"
 )
 
 //go:embed assets/static
@@ -40,8 +42,8 @@ func init() {
 	}
 
 	pages := []string{
-		"home", "search", "template", "combine", "categories", "list", "stats", "docs", "cli",
-		"server", "about", "privacy", "contact", "help", "terms", "error",
+		"home", "search", "template", "combine", "compare", "permalink", "categories", "list", "stats", "docs", "cli",
+		"server", "about", "privacy", "contact", "help", "terms", "markdown", "error",
 	}
 	for _, name := range pages {
 		body, err := htmlFS.ReadFile("assets/html/" + name + ".html")
@@ -54,6 +56,17 @@ func init() {
 		pageTemplates[name] = t
 	}
 
+	// Standalone pages define their own "layout" without the site chrome,
+	// for views other sites frame.
+	for _, name := range []string{"embed"} {
+		body, err := htmlFS.ReadFile("assets/html/" + name + ".html")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "embed: missing %s.html: %v\n", name, err)
+			os.Exit(exOSFile)
+		}
+		pageTemplates[name] = template.Must(template.New(name).Parse(string(body)))
+	}
+
 	sub, err := fs.Sub(staticFS, "assets/static")
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "embed: static sub: %v\n", err)
@@ -71,7 +84,53 @@ type PageData struct {
 	Lang        string
 	Dir         string
 	Theme       string
-	Data        map[string]interface{}
+	// Canonical is the page's absolute canonical URL; it defaults to the
+	// request path on the site URL and is omitted on error pages.
+	Canonical string
+	// Image is the absolute Open Graph preview image URL, defaulting to the
+	// site card.
+	Image    string
+	Keywords []string
+	// StructuredData is rendered as the page's JSON-LD block when set.
+	StructuredData interface{}
+	// OEmbed is the page's oEmbed discovery URL, for pages that can be
+	// embedded by URL.
+	OEmbed string
+	// Branding is the operator's site name, logo, favicon, theme overrides,
+	// footer links and announcement, resolved per request so SIGHUP reloads
+	// and replaced files apply immediately.
+	Branding brandingView
+	// Notify configures the "templates updated" toast: where it appears, how
+	// long it stays, and the event stream it listens on.
+	Notify notifyView
+	Data   map[string]interface{}
+}
+
+// notifyPositions are the toast corners server.notifications.webui.position
+// accepts.
+var notifyPositions = []string{"top-right", "top-left", "bottom-right", "bottom-left"}
+
+// notifyView is PageData.Notify.
+type notifyView struct {
+	Position string
+	// Duration is in seconds; 0 leaves the toast up until dismissed.
+	Duration  int
+	EventsURL string
+}
+
+// notifyView resolves the toast settings from the live configuration (it
+// follows SIGHUP reloads),
+// falling back to the defaults for values it does not accept.
+func (s *Server) notifyView() notifyView {
+	n := notifyView{Position: "top-right", Duration: 5, EventsURL: apiBasePath() + "/events?types=" + EventTemplatesUpdated}
+	webui := config.Get().Server.Notifications.WebUI
+	if slices.Contains(notifyPositions, webui.Position) {
+		n.Position = webui.Position
+	}
+	if webui.Duration >= 0 {
+		n.Duration = webui.Duration
+	}
+	return n
 }
 
 // validThemes is the set of theme values accepted from the theme cookie and
@@ -125,6 +184,17 @@ func (s *Server) renderPageStatus(w http.ResponseWriter, r *http.Request, page s
 	if data.Data == nil {
 		data.Data = map[string]interface{}{}
 	}
+	if data.Canonical == "" && status == http.StatusOK {
+		data.Canonical = s.siteURL(r) + r.URL.Path
+	}
+	if data.Image == "" {
+		data.Image = s.siteURL(r) + "/og/site.png"
+	}
+	if data.Keywords == nil {
+		data.Keywords = s.seoKeywords()
+	}
+	data.Branding = s.brandingView()
+	data.Notify = s.notifyView()
 
 	var buf bytes.Buffer
 	if err := t.ExecuteTemplate(&buf, "layout", data); err != nil {
@@ -1,6 +1,6 @@
 // GitIgnore frontend behaviors. CSS does all theming via custom properties;
-// JS only sets the theme cookie, swaps the <html> class, and registers the
-// service worker. Kept minimal and CSP-safe (no inline handlers).
+// JS sets the theme cookie, swaps the <html> class, registers the
+// service worker and enhances the composer. CSP-safe (no inline handlers).
 
 // ============================================================================
 // Theme toggle
@@ -44,8 +44,546 @@ document.querySelectorAll('[data-action="theme-toggle"]').forEach(function (btn)
 // ============================================================================
 if ('serviceWorker' in navigator) {
   window.addEventListener('load', function () {
-    navigator.serviceWorker.register('/sw.js').catch(function () {
+    navigator.serviceWorker.register('/sw.js').then(function (reg) {
+      // Ask the worker to check for a newer dataset on every visit, and
+      // where supported, in the background as well.
+      if (navigator.serviceWorker.controller) {
+        navigator.serviceWorker.controller.postMessage({ type: 'sync-dataset' });
+      }
+      if (reg.periodicSync) {
+        reg.periodicSync.register('dataset', { minInterval: 24 * 60 * 60 * 1000 }).catch(function () {
+          // Periodic sync needs a permission most browsers only grant to installed apps.
+        });
+      }
+    }).catch(function () {
       // Registration failure is non-fatal: the site works without offline caching.
     });
   });
 }
+
+// ============================================================================
+// Dataset update toast
+// ============================================================================
+// The layout names the event stream and the operator's toast position and
+// duration. When a new template dataset is installed the page offers a
+// refresh instead of silently showing stale templates.
+(function () {
+  var body = document.body;
+  var url = body.getAttribute('data-events');
+  if (!url || !window.EventSource) return;
+  var position = body.getAttribute('data-notify-position') || 'top-right';
+  var duration = parseInt(body.getAttribute('data-notify-duration'), 10);
+  var toast = null;
+  var timer = null;
+
+  function dismiss() {
+    clearTimeout(timer);
+    if (toast) {
+      toast.remove();
+      toast = null;
+    }
+  }
+
+  function show(message) {
+    dismiss();
+    toast = document.createElement('div');
+    toast.className = 'toast toast-' + position;
+    toast.setAttribute('role', 'status');
+    var text = document.createElement('span');
+    text.textContent = message;
+    var refresh = document.createElement('button');
+    refresh.type = 'button';
+    refresh.textContent = 'Refresh';
+    refresh.addEventListener('click', function () { window.location.reload(); });
+    var close = document.createElement('button');
+    close.type = 'button';
+    close.className = 'toast-close';
+    close.setAttribute('aria-label', 'Dismiss');
+    close.textContent = '×';
+    close.addEventListener('click', dismiss);
+    toast.append(text, refresh, close);
+    body.appendChild(toast);
+    if (duration > 0) {
+      timer = setTimeout(dismiss, duration * 1000);
+    }
+  }
+
+  var source = new EventSource(url);
+  source.addEventListener('templates.updated', function (e) {
+    var count = '';
+    try {
+      count = JSON.parse(e.data).data.count;
+    } catch (err) {
+      // The toast does not need the count.
+    }
+    show('Templates updated' + (count ? ' (' + count + ' templates)' : '') + '.');
+    // Bring the offline copy up to date as well.
+    if ('serviceWorker' in navigator && navigator.serviceWorker.controller) {
+      navigator.serviceWorker.controller.postMessage({ type: 'sync-dataset' });
+    }
+  });
+  // Browsers keep a stream open across back/forward navigation; closing it
+  // on unload frees the server's per-client stream slot.
+  window.addEventListener('pagehide', function () { source.close(); });
+})();
+
+// ============================================================================
+// Template composer (/combine)
+// ============================================================================
+// Without JS the composer is plain links and a GET form. With JS the same
+// markup becomes a live editor: the ordered selection lives in `names`, is
+// mirrored into ?templates= so the URL stays shareable, and every change
+// re-renders the preview from the JSON compose endpoint.
+(function () {
+  var form = document.querySelector('[data-composer]');
+  if (!form) {
+    return;
+  }
+  var api = form.getAttribute('data-api') || '/api/v1';
+  var base = form.getAttribute('data-base') || '';
+  var hidden = form.querySelector('input[name="templates"]');
+  var addInput = document.getElementById('composer-add');
+  var filter = form.querySelector('[data-picker-filter]');
+  var chipList = document.querySelector('.chips');
+  var notices = document.querySelector('[data-composer-notices]');
+  var output = document.querySelector('.composer-output');
+  var summary = document.querySelector('[data-composer-summary]');
+  var preview = document.querySelector('[data-composer-preview]');
+  var download = document.querySelector('[data-composer-download]');
+  var compareLink = document.querySelector('[data-composer-compare]');
+  var copyBtn = document.querySelector('[data-action="composer-copy"]');
+  var checkboxes = form.querySelectorAll('input[name="t"]');
+
+  var names = hidden.value ? hidden.value.split(',') : [];
+  var unknown = {};
+  var content = '';
+  var requestSeq = 0;
+  // Canonical names for autocomplete; seeded from the picker, then replaced
+  // by the JSON list endpoint.
+  var allNames = Array.prototype.map.call(checkboxes, function (cb) { return cb.value; });
+
+  function el(tag, className, text) {
+    var node = document.createElement(tag);
+    if (className) {
+      node.className = className;
+    }
+    if (text !== undefined) {
+      node.textContent = text;
+    }
+    return node;
+  }
+
+  function canonical(name) {
+    var lower = name.toLowerCase();
+    for (var i = 0; i < allNames.length; i++) {
+      if (allNames[i].toLowerCase() === lower) {
+        return allNames[i];
+      }
+    }
+    return name;
+  }
+
+  function indexOfName(name) {
+    var lower = name.toLowerCase();
+    for (var i = 0; i < names.length; i++) {
+      if (names[i].toLowerCase() === lower) {
+        return i;
+      }
+    }
+    return -1;
+  }
+
+  function encodeList(list, lower) {
+    return list.map(function (n) {
+      return encodeURIComponent(lower ? n.toLowerCase() : n);
+    }).join(',');
+  }
+
+  function setNames(list) {
+    names = list;
+    hidden.value = names.join(',');
+    checkboxes.forEach(function (cb) {
+      cb.checked = indexOfName(cb.value) !== -1;
+    });
+    var url = window.location.pathname + (names.length ? '?templates=' + encodeList(names) : '');
+    window.history.replaceState(null, '', url);
+    renderChips();
+    refresh();
+  }
+
+  function addNames(list) {
+    var next = names.slice();
+    list.forEach(function (raw) {
+      var name = canonical(raw.trim());
+      if (name && next.map(function (n) { return n.toLowerCase(); }).indexOf(name.toLowerCase()) === -1) {
+        next.push(name);
+      }
+    });
+    setNames(next);
+  }
+
+  function removeAt(i) {
+    var next = names.slice();
+    next.splice(i, 1);
+    setNames(next);
+  }
+
+  function move(from, to) {
+    if (to < 0 || to >= names.length || from === to) {
+      return;
+    }
+    var next = names.slice();
+    var item = next.splice(from, 1)[0];
+    next.splice(to, 0, item);
+    setNames(next);
+  }
+
+  // --------------------------------------------------------------------------
+  // Chips: reorder by drag and drop or the arrow buttons, remove with ×.
+  // --------------------------------------------------------------------------
+  var dragFrom = -1;
+
+  function chipButton(label, text, onClick) {
+    var btn = el('button', 'chip-action', text);
+    btn.type = 'button';
+    btn.setAttribute('aria-label', label);
+    btn.addEventListener('click', onClick);
+    return btn;
+  }
+
+  function renderChips() {
+    chipList.textContent = '';
+    if (!names.length) {
+      chipList.appendChild(el('li', 'chips-empty', 'Nothing selected yet.'));
+      return;
+    }
+    names.forEach(function (name, i) {
+      var li = el('li', 'chip' + (unknown[name] ? ' chip-unknown' : ''));
+      li.draggable = true;
+      li.setAttribute('data-name', name);
+      li.appendChild(el('span', 'chip-name', name));
+      if (i > 0) {
+        li.appendChild(chipButton('Move ' + name + ' up', '↑', function () { move(i, i - 1); }));
+      }
+      if (i < names.length - 1) {
+        li.appendChild(chipButton('Move ' + name + ' down', '↓', function () { move(i, i + 1); }));
+      }
+      li.appendChild(chipButton('Remove ' + name, '×', function () { removeAt(i); }));
+      li.addEventListener('dragstart', function (e) {
+        dragFrom = i;
+        e.dataTransfer.effectAllowed = 'move';
+        e.dataTransfer.setData('text/plain', name);
+      });
+      li.addEventListener('dragover', function (e) {
+        e.preventDefault();
+        li.classList.add('chip-drop');
+      });
+      li.addEventListener('dragleave', function () {
+        li.classList.remove('chip-drop');
+      });
+      li.addEventListener('drop', function (e) {
+        e.preventDefault();
+        move(dragFrom, i);
+      });
+      chipList.appendChild(li);
+    });
+  }
+
+  // --------------------------------------------------------------------------
+  // Preview
+  // --------------------------------------------------------------------------
+  function renderNotices(list) {
+    notices.textContent = '';
+    unknown = {};
+    list.forEach(function (u) {
+      unknown[u.name] = true;
+      var p = el('p', 'notice', 'No template named “' + u.name + '”.');
+      p.setAttribute('role', 'alert');
+      if (u.suggestions && u.suggestions.length) {
+        p.appendChild(document.createTextNode(' Did you mean '));
+        u.suggestions.forEach(function (s, i) {
+          if (i) {
+            p.appendChild(document.createTextNode(', '));
+          }
+          var btn = el('button', 'link-button', s);
+          btn.type = 'button';
+          btn.addEventListener('click', function () {
+            var next = names.slice();
+            var at = next.indexOf(u.name);
+            if (at === -1) {
+              return;
+            }
+            if (indexOfName(s) !== -1) {
+              next.splice(at, 1);
+            } else {
+              next[at] = s;
+            }
+            setNames(next);
+          });
+          p.appendChild(btn);
+        });
+        p.appendChild(document.createTextNode('?'));
+      }
+      notices.appendChild(p);
+    });
+  }
+
+  function renderSections(sections) {
+    preview.textContent = '';
+    sections.forEach(function (section) {
+      var details = el('details', 'composer-section');
+      details.open = true;
+      var sum = el('summary', '', section.template + ' ');
+      sum.appendChild(el('span', 'hint', section.category));
+      details.appendChild(sum);
+      var pre = el('pre');
+      section.lines.forEach(function (line) {
+        if (line.duplicate_of) {
+          var del = el('del', '', line.text);
+          del.title = 'Already added by ' + line.duplicate_of;
+          pre.appendChild(del);
+        } else if (line.conflicts_with) {
+          var mark = el('mark', '', line.text);
+          mark.title = 'Contradicts a rule in ' + line.conflicts_with;
+          pre.appendChild(mark);
+        } else {
+          pre.appendChild(document.createTextNode(line.text));
+        }
+        pre.appendChild(document.createTextNode('\n'));
+      });
+      details.appendChild(pre);
+      preview.appendChild(details);
+    });
+  }
+
+  function render(data) {
+    renderNotices(data.unknown);
+    renderChips();
+    content = data.content;
+    var known = data.templates;
+    output.hidden = known.length === 0;
+    // The server localizes the summary; the offline worker's compose
+    // answer has none, so fall back to English counts.
+    summary.textContent = data.summary || known.length + ' template(s) · ' + data.duplicates +
+      ' duplicate line(s) removed · ' + data.conflicts + ' conflict(s)';
+    download.href = api + '/combine.txt?templates=' + encodeList(known) + '&download=1';
+    compareLink.href = '/compare?templates=' + encodeList(known);
+    compareLink.hidden = known.length < 2;
+    var oneliners = {
+      curl: "curl -sL '" + base + api + '/combine.txt?templates=' + encodeList(known) + "' -o .gitignore",
+      cli: 'gitignore-cli ' + known.join(' ') + ' > .gitignore',
+      compat: base + '/api/' + encodeList(known, true),
+      badge: '[![gitignore](' + base + '/badge/' + encodeList(known) + '.svg)](' +
+        base + '/combine?templates=' + encodeList(known) + ')'
+    };
+    document.querySelectorAll('[data-composer-permalink]').forEach(function (a) {
+      a.href = data[a.getAttribute('data-composer-permalink')];
+    });
+    document.querySelectorAll('[data-oneliner]').forEach(function (code) {
+      code.textContent = oneliners[code.getAttribute('data-oneliner')];
+    });
+    renderSections(data.sections || []);
+  }
+
+  function refresh() {
+    var seq = ++requestSeq;
+    if (!names.length) {
+      renderNotices([]);
+      output.hidden = true;
+      content = '';
+      return;
+    }
+    fetch(api + '/compose?templates=' + encodeList(names), { headers: { Accept: 'application/json' } })
+      .then(function (res) { return res.json(); })
+      .then(function (body) {
+        // Drop responses that an edit made while in flight has superseded.
+        if (seq === requestSeq && body.ok) {
+          render(body.data);
+        }
+      })
+      .catch(function () {
+        // The server-rendered preview stays; the next edit retries.
+      });
+  }
+
+  // --------------------------------------------------------------------------
+  // Picker: checkboxes apply immediately; the filter narrows every category.
+  // --------------------------------------------------------------------------
+  checkboxes.forEach(function (cb) {
+    cb.addEventListener('change', function () {
+      var i = indexOfName(cb.value);
+      if (cb.checked && i === -1) {
+        addNames([cb.value]);
+      } else if (!cb.checked && i !== -1) {
+        removeAt(i);
+      }
+    });
+  });
+  form.querySelectorAll('.picker-submit').forEach(function (btn) {
+    btn.hidden = true;
+  });
+
+  if (filter) {
+    filter.hidden = false;
+    filter.addEventListener('input', function () {
+      var q = filter.value.trim().toLowerCase();
+      form.querySelectorAll('.picker-group').forEach(function (group) {
+        var matches = 0;
+        group.querySelectorAll('label').forEach(function (label) {
+          var hit = !q || label.textContent.toLowerCase().indexOf(q) !== -1;
+          label.hidden = !hit;
+          if (hit) {
+            matches++;
+          }
+        });
+        group.hidden = matches === 0;
+        group.open = q !== '' && matches > 0;
+      });
+    });
+  }
+
+  // --------------------------------------------------------------------------
+  // Autocomplete: a listbox under the add field, ranked prefix-first.
+  // --------------------------------------------------------------------------
+  var listbox = el('ul', 'autocomplete');
+  listbox.id = 'composer-suggestions';
+  listbox.setAttribute('role', 'listbox');
+  listbox.hidden = true;
+  addInput.parentNode.appendChild(listbox);
+  addInput.removeAttribute('list');
+  addInput.setAttribute('role', 'combobox');
+  addInput.setAttribute('aria-autocomplete', 'list');
+  addInput.setAttribute('aria-controls', listbox.id);
+  addInput.setAttribute('aria-expanded', 'false');
+  var active = -1;
+
+  function currentTerm() {
+    var parts = addInput.value.split(',');
+    return parts[parts.length - 1].trim().toLowerCase();
+  }
+
+  function closeList() {
+    listbox.hidden = true;
+    listbox.textContent = '';
+    active = -1;
+    addInput.setAttribute('aria-expanded', 'false');
+    addInput.removeAttribute('aria-activedescendant');
+  }
+
+  function highlight(i) {
+    var items = listbox.children;
+    if (!items.length) {
+      return;
+    }
+    active = (i + items.length) % items.length;
+    for (var j = 0; j < items.length; j++) {
+      items[j].setAttribute('aria-selected', j === active ? 'true' : 'false');
+    }
+    addInput.setAttribute('aria-activedescendant', items[active].id);
+  }
+
+  function pick(name) {
+    var parts = addInput.value.split(',');
+    parts.pop();
+    addNames(parts.concat([name]));
+    addInput.value = '';
+    closeList();
+  }
+
+  function suggest() {
+    var term = currentTerm();
+    closeList();
+    if (!term) {
+      return;
+    }
+    var prefix = [];
+    var contains = [];
+    allNames.forEach(function (name) {
+      var lower = name.toLowerCase();
+      if (indexOfName(name) !== -1) {
+        return;
+      }
+      if (lower.indexOf(term) === 0) {
+        prefix.push(name);
+      } else if (lower.indexOf(term) !== -1) {
+        contains.push(name);
+      }
+    });
+    prefix.concat(contains).slice(0, 8).forEach(function (name, i) {
+      var li = el('li', '', name);
+      li.id = 'composer-suggestion-' + i;
+      li.setAttribute('role', 'option');
+      li.addEventListener('mousedown', function (e) {
+        // mousedown, not click, so the input keeps focus.
+        e.preventDefault();
+        pick(name);
+      });
+      listbox.appendChild(li);
+    });
+    if (listbox.children.length) {
+      listbox.hidden = false;
+      addInput.setAttribute('aria-expanded', 'true');
+    }
+  }
+
+  addInput.addEventListener('input', suggest);
+  addInput.addEventListener('blur', closeList);
+  addInput.addEventListener('keydown', function (e) {
+    if (e.key === 'ArrowDown' || e.key === 'ArrowUp') {
+      if (!listbox.hidden) {
+        e.preventDefault();
+        highlight(active + (e.key === 'ArrowDown' ? 1 : -1));
+      }
+    } else if (e.key === 'Enter' && !listbox.hidden && active !== -1) {
+      e.preventDefault();
+      pick(listbox.children[active].textContent);
+    } else if (e.key === 'Escape') {
+      closeList();
+    }
+  });
+
+  form.addEventListener('submit', function (e) {
+    e.preventDefault();
+    if (addInput.value.trim()) {
+      addNames(addInput.value.split(','));
+      addInput.value = '';
+    }
+    closeList();
+  });
+
+  fetch(api + '/list', { headers: { Accept: 'application/json' } })
+    .then(function (res) { return res.json(); })
+    .then(function (body) {
+      if (Array.isArray(body.data)) {
+        allNames = body.data.slice().sort(function (a, b) {
+          return a.toLowerCase() < b.toLowerCase() ? -1 : 1;
+        });
+      }
+    })
+    .catch(function () {
+      // Keep the names seeded from the picker.
+    });
+
+  // --------------------------------------------------------------------------
+  // Copy
+  // --------------------------------------------------------------------------
+  if (copyBtn && navigator.clipboard) {
+    copyBtn.hidden = false;
+    copyBtn.addEventListener('click', function () {
+      navigator.clipboard.writeText(content).then(function () {
+        copyBtn.textContent = 'Copied';
+        setTimeout(function () { copyBtn.textContent = 'Copy to clipboard'; }, 2000);
+      });
+    });
+  }
+
+  // Offline, the service worker answers /combine?templates=… with the saved
+  // page for bare /combine, so the selection comes from the URL instead.
+  var fromURL = new URLSearchParams(window.location.search).get('templates');
+  if (!names.length && fromURL) {
+    setNames(fromURL.split(',').map(function (n) { return n.trim(); }).filter(Boolean));
+  } else {
+    renderChips();
+    refresh();
+  }
+})();
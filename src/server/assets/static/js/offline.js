@@ -0,0 +1,232 @@
+// Offline renderer for the app shell (/static/offline.html). The service
+// worker answers with the shell when the network is unreachable and it has no
+// saved copy of the page; this script then renders template, search, list
+// and category pages from the dataset bundle the worker keeps. Any other path
+// keeps the shell's static "Offline" message.
+(function () {
+  var main = document.querySelector('[data-offline]');
+  if (!main) {
+    return;
+  }
+  var API = '/api/v1';
+
+  // The shell is a static file, so apply the theme cookie here the way the
+  // server does for rendered pages.
+  var theme = document.cookie.match(/(?:^|; )theme=(dark|light|auto)(?:;|$)/);
+  if (theme) {
+    document.documentElement.className = 'theme-' + theme[1];
+    var label = document.querySelector('.theme-label');
+    if (label) {
+      label.textContent = theme[1];
+    }
+  }
+
+  function el(tag, className, text) {
+    var node = document.createElement(tag);
+    if (className) {
+      node.className = className;
+    }
+    if (text !== undefined) {
+      node.textContent = text;
+    }
+    return node;
+  }
+
+  function link(href, text) {
+    var a = el('a', '', text);
+    a.href = href;
+    return a;
+  }
+
+  function templateList(templates) {
+    var ul = el('ul', 'templates');
+    templates.forEach(function (t) {
+      var li = el('li');
+      li.appendChild(link('/template/' + encodeURIComponent(t.name), t.name));
+      ul.appendChild(li);
+    });
+    return ul;
+  }
+
+  function byName(a, b) {
+    return a.name.toLowerCase() < b.name.toLowerCase() ? -1 : 1;
+  }
+
+  function show(title, nodes, version) {
+    document.title = title + ' — GitIgnore';
+    main.textContent = '';
+    var banner = el('p', 'notice', 'You are offline. This page was built from dataset ' + version + ' saved on this device.');
+    banner.setAttribute('role', 'status');
+    main.appendChild(banner);
+    nodes.forEach(function (node) {
+      main.appendChild(node);
+    });
+  }
+
+  function renderTemplate(ds, name) {
+    var lower = name.toLowerCase();
+    var t = ds.templates.filter(function (x) { return x.name.toLowerCase() === lower; })[0];
+    if (!t) {
+      var p = el('p', '', 'There is no template named “' + name + '”. ');
+      p.appendChild(link('/search?q=' + encodeURIComponent(name), 'Search for it'));
+      show('Template not found', [el('h1', '', 'Template not found'), p], ds.version);
+      return;
+    }
+    var raw = API + '/templates/' + encodeURIComponent(t.name) + '.txt';
+    var article = el('article', 'template-page');
+    var header = el('header', 'template-header');
+    header.appendChild(el('h1', '', t.name));
+    if (t.description) {
+      header.appendChild(el('p', 'hint', t.description));
+    }
+    var actions = el('div', 'template-actions');
+    var add = link('/combine?templates=' + encodeURIComponent(t.name), 'Add to composer');
+    add.className = 'btn';
+    actions.appendChild(add);
+    actions.appendChild(document.createTextNode(' '));
+    actions.appendChild(link(raw, 'Raw'));
+    actions.appendChild(document.createTextNode(' · '));
+    var download = link(raw + '?download=1', 'Download');
+    download.setAttribute('download', '.gitignore');
+    actions.appendChild(download);
+    header.appendChild(actions);
+    article.appendChild(header);
+
+    var lines = t.content.replace(/\n$/, '').split('\n');
+    var meta = el('dl', 'template-meta');
+    meta.appendChild(el('dt', '', 'Category'));
+    var dd = el('dd');
+    dd.appendChild(link('/list?category=' + encodeURIComponent(t.category), t.category));
+    meta.appendChild(dd);
+    meta.appendChild(el('dt', '', 'Tags'));
+    dd = el('dd');
+    (t.tags || []).forEach(function (tag, i) {
+      if (i) {
+        dd.appendChild(document.createTextNode(', '));
+      }
+      dd.appendChild(link('/search?q=' + encodeURIComponent(tag), tag));
+    });
+    meta.appendChild(dd);
+    meta.appendChild(el('dt', '', 'Size'));
+    meta.appendChild(el('dd', '', lines.length + ' lines · ' + t.size + ' bytes'));
+    article.appendChild(meta);
+
+    var pre = el('pre', 'highlight');
+    pre.setAttribute('aria-label', t.name + '.gitignore');
+    lines.forEach(function (text, i) {
+      var n = String(i + 1);
+      var line = el('span', 'line');
+      line.id = 'L' + n;
+      var ln = link('#L' + n, n);
+      ln.className = 'ln';
+      ln.setAttribute('aria-label', 'Line ' + n);
+      line.appendChild(ln);
+      line.appendChild(document.createTextNode(text));
+      pre.appendChild(line);
+    });
+    article.appendChild(pre);
+    show(t.name, [article], ds.version);
+    if (window.location.hash) {
+      var target = document.getElementById(window.location.hash.slice(1));
+      if (target) {
+        target.scrollIntoView();
+      }
+    }
+  }
+
+  // search mirrors Manager.Search: a case-insensitive substring of the name,
+  // category, any tag or the description.
+  function renderSearch(ds, query) {
+    var form = el('form');
+    form.action = '/search';
+    form.method = 'get';
+    var input = el('input');
+    input.type = 'text';
+    input.name = 'q';
+    input.value = query;
+    input.placeholder = 'Search templates…';
+    input.setAttribute('aria-label', 'Search');
+    form.appendChild(input);
+    var btn = el('button', '', 'Search');
+    btn.type = 'submit';
+    form.appendChild(btn);
+    var nodes = [el('h1', '', 'Search Templates'), form];
+    if (query) {
+      var q = query.toLowerCase();
+      var results = ds.templates.filter(function (t) {
+        return t.name.toLowerCase().indexOf(q) !== -1 ||
+          t.category.toLowerCase().indexOf(q) !== -1 ||
+          (t.tags || []).some(function (tag) { return tag.indexOf(q) !== -1; }) ||
+          (t.description || '').toLowerCase().indexOf(q) !== -1;
+      }).sort(byName);
+      nodes.push(el('p', '', results.length + ' result(s) for "' + query + '":'));
+      nodes.push(templateList(results));
+    }
+    show('Search', nodes, ds.version);
+  }
+
+  function renderList(ds, category) {
+    var templates = ds.templates.filter(function (t) {
+      return !category || t.category === category;
+    }).sort(byName);
+    var title = category ? category + ' Templates' : 'All Templates';
+    show(title, [el('h1', '', title), el('p', '', templates.length + ' templates.'), templateList(templates)], ds.version);
+  }
+
+  function renderCategories(ds) {
+    var seen = {};
+    var ul = el('ul', 'templates');
+    ds.templates.map(function (t) { return t.category; }).sort().forEach(function (c) {
+      if (seen[c]) {
+        return;
+      }
+      seen[c] = true;
+      var li = el('li');
+      li.appendChild(link('/list?category=' + encodeURIComponent(c), c));
+      ul.appendChild(li);
+    });
+    show('Categories', [el('h1', '', 'Categories'), ul], ds.version);
+  }
+
+  function render(ds) {
+    var path = window.location.pathname;
+    var params = new URLSearchParams(window.location.search);
+    var m = path.match(/^\/template\/([^/]+)$/);
+    if (m) {
+      renderTemplate(ds, decodeURIComponent(m[1]));
+    } else if (path === '/search') {
+      renderSearch(ds, params.get('q') || '');
+    } else if (path === '/list') {
+      renderList(ds, params.get('category') || '');
+    } else if (path === '/categories') {
+      renderCategories(ds);
+    }
+  }
+
+  function load() {
+    fetch(API + '/dataset', { headers: { Accept: 'application/json' } })
+      .then(function (res) { return res.json(); })
+      .then(function (body) {
+        if (!body.ok) {
+          throw new Error(body.message);
+        }
+        return fetch(body.data.bundle);
+      })
+      .then(function (res) { return res.json(); })
+      .then(function (body) {
+        render(body.data);
+      })
+      .catch(function () {
+        // No dataset saved yet: the static offline message stays.
+      });
+  }
+
+  if ('serviceWorker' in navigator) {
+    navigator.serviceWorker.addEventListener('message', function (event) {
+      if (event.data && event.data.type === 'dataset-updated') {
+        load();
+      }
+    });
+  }
+  load();
+})();
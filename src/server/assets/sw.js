@@ -0,0 +1,724 @@
+// GitIgnore service worker. The server prepends BUILD (the release version)
+// and API (the versioned API base path).
+//
+// Three caches:
+//   shell   - static assets and the offline app shell, one per release
+//   pages   - HTML pages as last fetched, emptied when the dataset changes
+//   dataset - the API + '/dataset' manifest and the bundle it names
+//
+// Pages and API calls go to the network first, so online behavior is
+// unchanged. Offline, the cached dataset answers the composer's API calls with
+// the JSON and text the server would send (compose, combine, list, template
+// text, permalinks), and the app shell renders template, search, list and
+// category pages from it (see /static/js/offline.js).
+
+const SHELL_CACHE = 'gitignore-shell-' + BUILD;
+const PAGES_CACHE = 'gitignore-pages';
+const DATASET_CACHE = 'gitignore-dataset';
+const OFFLINE_URL = '/static/offline.html';
+const MANIFEST_URL = API + '/dataset';
+const SHELL = [
+  '/static/css/main.css',
+  '/static/js/app.js',
+  '/static/js/offline.js',
+  '/static/images/icon.svg',
+  '/manifest.json',
+  OFFLINE_URL
+];
+// Pages whose content depends only on the dataset. They are saved on install
+// and fetched again whenever the dataset changes.
+const PAGES = ['/', '/combine', '/list', '/categories', '/search'];
+// How often a page load may trigger a dataset version check.
+const CHECK_INTERVAL = 10 * 60 * 1000;
+// A network that accepts connections but never answers (common behind lab
+// firewalls) falls back to the offline copy after this long.
+const NAVIGATION_TIMEOUT = 6000;
+
+self.addEventListener('install', function (event) {
+  event.waitUntil(
+    caches.open(SHELL_CACHE).then(function (cache) {
+      return cache.addAll(SHELL);
+    }).then(function () {
+      // Installing a dataset refreshes the pages too; otherwise they are
+      // saved here so a new release's markup replaces the old.
+      return syncDataset(true);
+    }).then(function (updated) {
+      return updated || refreshPages();
+    })
+  );
+  self.skipWaiting();
+});
+
+self.addEventListener('activate', function (event) {
+  var keep = [SHELL_CACHE, PAGES_CACHE, DATASET_CACHE];
+  event.waitUntil(
+    caches.keys().then(function (keys) {
+      return Promise.all(keys.filter(function (k) {
+        return keep.indexOf(k) === -1;
+      }).map(function (k) {
+        return caches.delete(k);
+      }));
+    }).then(function () {
+      return self.clients.claim();
+    })
+  );
+});
+
+self.addEventListener('message', function (event) {
+  if (event.data && event.data.type === 'sync-dataset') {
+    event.waitUntil(syncDataset(false));
+  }
+});
+
+self.addEventListener('periodicsync', function (event) {
+  if (event.tag === 'dataset') {
+    event.waitUntil(syncDataset(true));
+  }
+});
+
+self.addEventListener('fetch', function (event) {
+  var req = event.request;
+  if (req.method !== 'GET') {
+    return;
+  }
+  var url = new URL(req.url);
+  if (url.origin !== self.location.origin) {
+    return;
+  }
+  var path = url.pathname;
+  if (path === API + '/events') {
+    // The event stream is long-lived and has no offline answer.
+    return;
+  }
+
+  if (req.mode === 'navigate') {
+    event.respondWith(navigate(req, url));
+  } else if (path === MANIFEST_URL) {
+    event.respondWith(fetch(req).catch(function () {
+      return cached(DATASET_CACHE, MANIFEST_URL).then(function (res) {
+        return res || apiError(503, 'OFFLINE', 'the dataset has not been saved for offline use yet');
+      });
+    }));
+  } else if (path.indexOf(API + '/dataset/') === 0) {
+    // Bundles are keyed by content hash and never change.
+    event.respondWith(cached(DATASET_CACHE, path).then(function (res) {
+      return res || fetch(req);
+    }));
+  } else if (path.indexOf(API + '/') === 0 || /^\/c\/[^/]+\.txt$/.test(path)) {
+    event.respondWith(fetch(req).catch(function () {
+      return offlineAPI(url);
+    }));
+  } else if (path.indexOf('/static/') === 0 || path === '/manifest.json') {
+    event.respondWith(cached(SHELL_CACHE, req).then(function (res) {
+      return res || fetch(req);
+    }));
+  }
+});
+
+function cached(cacheName, req) {
+  return caches.open(cacheName).then(function (cache) {
+    return cache.match(req);
+  });
+}
+
+function withTimeout(promise, ms) {
+  return new Promise(function (resolve, reject) {
+    var timer = setTimeout(function () {
+      reject(new Error('timeout'));
+    }, ms);
+    promise.then(function (v) {
+      clearTimeout(timer);
+      resolve(v);
+    }, function (err) {
+      clearTimeout(timer);
+      reject(err);
+    });
+  });
+}
+
+// ============================================================================
+// Dataset sync
+// ============================================================================
+var lastCheck = 0;
+var syncing = null;
+// dataset memoizes the parsed bundle (a promise) until the next sync.
+var dataset = null;
+
+function jsonResponse(body, status) {
+  return new Response(JSON.stringify(body), {
+    status: status || 200,
+    headers: { 'Content-Type': 'application/json' }
+  });
+}
+
+function apiOK(data) {
+  return jsonResponse({ ok: true, data: data });
+}
+
+function apiError(status, code, message) {
+  return jsonResponse({ ok: false, error: code, message: message }, status);
+}
+
+function textResponse(text, download) {
+  var headers = { 'Content-Type': 'text/plain; charset=utf-8' };
+  if (download) {
+    headers['Content-Disposition'] = 'attachment; filename=".gitignore"';
+  }
+  return new Response(text, { headers: headers });
+}
+
+function notify(message) {
+  return self.clients.matchAll().then(function (list) {
+    list.forEach(function (client) {
+      client.postMessage(message);
+    });
+  });
+}
+
+function currentManifest() {
+  return cached(DATASET_CACHE, MANIFEST_URL).then(function (res) {
+    return res ? res.json().then(function (body) { return body.data; }) : null;
+  });
+}
+
+// syncDataset downloads the served dataset when its version differs from the
+// saved one. Unless forced it runs at most once per CHECK_INTERVAL. Resolves
+// to true when a new dataset was installed.
+function syncDataset(force) {
+  if (syncing) {
+    return syncing;
+  }
+  if (!force && Date.now() - lastCheck < CHECK_INTERVAL) {
+    return Promise.resolve(false);
+  }
+  lastCheck = Date.now();
+  syncing = fetch(MANIFEST_URL, { cache: 'no-store', headers: { Accept: 'application/json' } })
+    .then(function (res) { return res.json(); })
+    .then(function (body) {
+      if (!body.ok) {
+        return false;
+      }
+      var latest = body.data;
+      return currentManifest().then(function (current) {
+        if (current && current.version === latest.version) {
+          return false;
+        }
+        return installDataset(latest).then(function () {
+          return notify({
+            type: 'dataset-updated',
+            version: latest.version,
+            previous: current ? current.version : ''
+          });
+        }).then(function () {
+          return true;
+        });
+      });
+    })
+    .catch(function () {
+      // Offline or the server is unreachable; keep the saved copy.
+      return false;
+    })
+    .then(function (updated) {
+      syncing = null;
+      return updated;
+    });
+  return syncing;
+}
+
+function installDataset(manifest) {
+  return caches.open(DATASET_CACHE).then(function (cache) {
+    return fetch(manifest.bundle).then(function (res) {
+      if (!res.ok) {
+        throw new Error('bundle download failed: ' + res.status);
+      }
+      return cache.put(manifest.bundle, res);
+    }).then(function () {
+      // The manifest is written last: it is what marks the bundle usable.
+      return cache.put(MANIFEST_URL, apiOK(manifest));
+    }).then(function () {
+      return cache.keys();
+    }).then(function (keys) {
+      return Promise.all(keys.filter(function (req) {
+        var p = new URL(req.url).pathname;
+        return p !== MANIFEST_URL && p !== manifest.bundle;
+      }).map(function (req) {
+        return cache.delete(req);
+      }));
+    });
+  }).then(function () {
+    dataset = null;
+    return caches.delete(PAGES_CACHE);
+  }).then(refreshPages);
+}
+
+function refreshPages() {
+  return caches.open(PAGES_CACHE).then(function (cache) {
+    return Promise.all(PAGES.map(function (page) {
+      return fetch(page).then(function (res) {
+        if (res.ok) {
+          return cache.put(page, res);
+        }
+      }).catch(function () {});
+    }));
+  });
+}
+
+// loadDataset resolves to the saved dataset indexed by lower-cased name, or
+// null when none has been downloaded yet.
+function loadDataset() {
+  if (dataset) {
+    return dataset;
+  }
+  dataset = currentManifest().then(function (manifest) {
+    if (!manifest) {
+      return null;
+    }
+    return cached(DATASET_CACHE, manifest.bundle).then(function (res) {
+      return res ? res.json() : null;
+    });
+  }).then(function (body) {
+    if (!body) {
+      dataset = null;
+      return null;
+    }
+    var byName = new Map();
+    body.data.templates.forEach(function (t) {
+      byName.set(t.name.toLowerCase(), t);
+    });
+    return { version: body.data.version, templates: body.data.templates, byName: byName };
+  });
+  return dataset;
+}
+
+// ============================================================================
+// Pages
+// ============================================================================
+// Paths the app shell can render from the dataset when no saved copy exists.
+var SHELL_ROUTES = /^\/(template\/[^/]+|search|list|categories)$/;
+
+function navigate(req, url) {
+  return withTimeout(fetch(req), NAVIGATION_TIMEOUT).then(function (res) {
+    // Save query-less pages (plus the PAGES set) for offline use; query
+    // strings would let the cache grow without bound.
+    if (res.ok && res.type === 'basic' && !url.search) {
+      var copy = res.clone();
+      caches.open(PAGES_CACHE).then(function (cache) {
+        cache.put(url.pathname, copy);
+      });
+    }
+    syncDataset(false);
+    return res;
+  }).catch(function () {
+    return offlinePage(url);
+  });
+}
+
+function offlinePage(url) {
+  var path = url.pathname;
+  var permalink = path.match(/^\/c\/([^/.]+)$/);
+  if (permalink) {
+    // A permalink opens the composer on its templates.
+    var p = decodePermalink(permalink[1]);
+    if (p) {
+      return Response.redirect('/combine?templates=' + p.templates.map(encodeURIComponent).join(','), 302);
+    }
+  }
+  if (path === '/combine') {
+    // The composer reads its selection from the URL and previews through
+    // the compose API, which is answered from the dataset below.
+    return cached(PAGES_CACHE, '/combine').then(function (res) {
+      return res || cached(SHELL_CACHE, OFFLINE_URL);
+    });
+  }
+  return cached(PAGES_CACHE, path).then(function (res) {
+    // A saved page stands in for the same path with any query, except where
+    // the shell can render the query itself (search results, one category).
+    if (res && (!url.search || !SHELL_ROUTES.test(path))) {
+      return res;
+    }
+    return cached(SHELL_CACHE, OFFLINE_URL);
+  });
+}
+
+// ============================================================================
+// Offline API
+// ============================================================================
+function offlineAPI(url) {
+  return loadDataset().then(function (ds) {
+    if (!ds) {
+      return apiError(503, 'OFFLINE', 'the dataset has not been saved for offline use yet');
+    }
+    var path = url.pathname;
+    var q = url.searchParams;
+    var m;
+    if (path === API + '/list') {
+      return apiOK(ds.templates.map(function (t) { return t.name; }));
+    }
+    if (path === API + '/compose') {
+      return composeResponse(ds, parseTemplateList(q.get('templates') || ''));
+    }
+    if (path === API + '/combine' || path === API + '/combine.txt') {
+      var names = (q.get('templates') || '').split(',').map(function (n) { return n.trim(); });
+      if (!q.get('templates')) {
+        return apiError(400, 'BAD_REQUEST', "query parameter 'templates' is required");
+      }
+      var missing = names.filter(function (n) { return !ds.byName.has(n.toLowerCase()); });
+      if (missing.length) {
+        return apiError(400, 'BAD_REQUEST', 'template not found: ' + missing[0]);
+      }
+      return textResponse(render(compose(ds, names), names), q.has('download'));
+    }
+    if ((m = path.match(new RegExp('^' + API + '/templates/(.+?)(\\.txt|\\.json)?$')))) {
+      var tmpl = ds.byName.get(decodeURIComponent(m[1]).toLowerCase());
+      if (!tmpl) {
+        return apiError(404, 'NOT_FOUND', 'template not found');
+      }
+      return m[2] === '.txt' ? textResponse(tmpl.content, q.has('download')) : apiOK(tmpl);
+    }
+    if ((m = path.match(/^\/c\/([^/]+)\.txt$/))) {
+      var p = decodePermalink(m[1]);
+      if (!p) {
+        return apiError(404, 'NOT_FOUND', 'invalid permalink');
+      }
+      var known = resolveTemplates(ds, p.templates).known;
+      if (!known.length) {
+        return apiError(404, 'NOT_FOUND', "none of the permalink's templates exist");
+      }
+      return textResponse(render(compose(ds, known), known), q.has('download'));
+    }
+    return apiError(503, 'OFFLINE', 'this endpoint is not available offline');
+  });
+}
+
+function composeResponse(ds, names) {
+  if (!names.length) {
+    return apiError(400, 'BAD_REQUEST', "query parameter 'templates' is required");
+  }
+  var resolved = resolveTemplates(ds, names);
+  var known = resolved.known;
+  var comp = known.length ? compose(ds, known) : { templates: [], sections: [], duplicates: 0, conflicts: 0 };
+  return apiOK({
+    templates: comp.templates,
+    sections: comp.sections,
+    duplicates: comp.duplicates,
+    conflicts: comp.conflicts,
+    unknown: resolved.unknown,
+    content: known.length ? render(comp, known) : '',
+    permalink: known.length ? '/c/' + encodePermalink(known, '') : '',
+    pinned_permalink: known.length ? '/c/' + encodePermalink(known, ds.version) : ''
+  });
+}
+
+// ============================================================================
+// Composition: mirrors Manager.Compose and Composition.Render so the offline
+// result is byte-for-byte what the server would produce.
+// ============================================================================
+
+// parseTemplateList mirrors the server's parseTemplateList: blanks and
+// case-insensitive repeats are dropped, first occurrence wins.
+function parseTemplateList(param) {
+  var seen = new Set();
+  var out = [];
+  param.split(',').forEach(function (name) {
+    name = name.trim();
+    var key = name.toLowerCase();
+    if (name && !seen.has(key)) {
+      seen.add(key);
+      out.push(name);
+    }
+  });
+  return out;
+}
+
+function resolveTemplates(ds, names) {
+  var known = [];
+  var unknown = [];
+  var seen = new Set();
+  names.forEach(function (name) {
+    var t = ds.byName.get(name.toLowerCase());
+    if (!t) {
+      var suggestions = suggest(ds, name, 3);
+      unknown.push(suggestions.length ? { name: name, suggestions: suggestions } : { name: name });
+      return;
+    }
+    if (!seen.has(t.name)) {
+      seen.add(t.name);
+      known.push(t.name);
+    }
+  });
+  return { known: known, unknown: unknown };
+}
+
+function compose(ds, names) {
+  var c = { templates: [], sections: [], duplicates: 0, conflicts: 0 };
+  // seen maps a trimmed pattern line to the template that first wrote it;
+  // polarity maps a rule's pattern to the first template that ignored it
+  // (index 0) or re-included it (index 1).
+  var seen = new Map();
+  var polarity = new Map();
+  names.forEach(function (name) {
+    var t = ds.byName.get(name.toLowerCase());
+    c.templates.push(t.name);
+    var section = { template: t.name, category: t.category, lines: [] };
+    t.content.split('\n').forEach(function (text) {
+      var line = { text: text };
+      var trimmed = text.trim();
+      if (trimmed === '' || trimmed[0] === '#') {
+        section.lines.push(line);
+        return;
+      }
+      if (seen.has(trimmed)) {
+        line.duplicate_of = seen.get(trimmed);
+        c.duplicates++;
+        section.lines.push(line);
+        return;
+      }
+      seen.set(trimmed, t.name);
+
+      var rule = parseRule(text);
+      if (rule) {
+        var key = rule.pattern + (rule.dirOnly ? '/' : '');
+        var sense = rule.negate ? 1 : 0;
+        var p = polarity.get(key);
+        if (!p) {
+          p = ['', ''];
+          polarity.set(key, p);
+        }
+        var other = p[1 - sense];
+        if (other !== '' && other !== t.name) {
+          line.conflicts_with = other;
+          c.conflicts++;
+        }
+        if (p[sense] === '') {
+          p[sense] = t.name;
+        }
+      }
+      section.lines.push(line);
+    });
+    c.sections.push(section);
+  });
+  return c;
+}
+
+function render(comp, names) {
+  var joined = names.join(', ');
+  var out = '# Combined .gitignore\n# Generated: ' + goBase(joined) + '\n# Templates: ' + joined + '\n\n';
+  comp.sections.forEach(function (section) {
+    out += '### ' + section.template + ' ###\n';
+    section.lines.forEach(function (line) {
+      if (!line.duplicate_of) {
+        out += line.text + '\n';
+      }
+    });
+    out += '\n';
+  });
+  return out;
+}
+
+// goBase mirrors Go's filepath.Base, which Render applies to the name list.
+function goBase(p) {
+  if (p === '') {
+    return '.';
+  }
+  p = p.replace(/\/+$/, '');
+  if (p === '') {
+    return '/';
+  }
+  return p.slice(p.lastIndexOf('/') + 1);
+}
+
+// parseRule mirrors template.ParseRule, returning null for blank lines,
+// comments and invalid rules.
+function parseRule(line) {
+  var raw = line.replace(/\r+$/, '');
+  var text = trimTrailingSpaces(raw);
+  if (text === '' || text[0] === '#') {
+    return null;
+  }
+  var rule = { negate: false, dirOnly: false, pattern: '' };
+  if (text[0] === '!') {
+    rule.negate = true;
+    text = text.slice(1);
+  } else if (text.indexOf('\\#') === 0 || text.indexOf('\\!') === 0) {
+    text = text.slice(1);
+  }
+  if (text.slice(-1) === '/' && text.slice(-2) !== '\\/') {
+    rule.dirOnly = true;
+    text = text.replace(/\/+$/, '');
+  }
+  if (text === '' || endsWithEscape(text) || !bracketsBalanced(text)) {
+    return null;
+  }
+  rule.pattern = text;
+  return rule;
+}
+
+function trimTrailingSpaces(s) {
+  while (/[ \t]$/.test(s)) {
+    var trimmed = s.slice(0, -1);
+    if (trimmed.slice(-1) === '\\' && !endsWithEscape(trimmed.slice(0, -1))) {
+      return s;
+    }
+    s = trimmed;
+  }
+  return s;
+}
+
+function endsWithEscape(s) {
+  var n = 0;
+  for (var i = s.length - 1; i >= 0 && s[i] === '\\'; i--) {
+    n++;
+  }
+  return n % 2 === 1;
+}
+
+function bracketsBalanced(s) {
+  for (var i = 0; i < s.length; i++) {
+    if (s[i] === '\\') {
+      i++;
+    } else if (s[i] === '[') {
+      var j = i + 1;
+      if (j < s.length && (s[j] === '!' || s[j] === '^')) {
+        j++;
+      }
+      if (j < s.length && s[j] === ']') {
+        j++;
+      }
+      while (j < s.length && s[j] !== ']') {
+        j++;
+      }
+      if (j >= s.length) {
+        return false;
+      }
+      i = j;
+    }
+  }
+  return true;
+}
+
+// suggest mirrors Manager.Suggest: close edit distance first, then prefix
+// matches, ties by name.
+function suggest(ds, name, limit) {
+  var query = name.trim().toLowerCase();
+  if (!query) {
+    return [];
+  }
+  var maxDist = Math.max(1, Math.min(3, Math.floor(query.length / 3)));
+  var found = [];
+  ds.byName.forEach(function (t, key) {
+    if (key === query) {
+      return;
+    }
+    var d = editDistance(query, key);
+    if (d > maxDist) {
+      if (query.length < 3 || !(key.indexOf(query) === 0 || query.indexOf(key) === 0)) {
+        return;
+      }
+      d = maxDist + 1 + Math.abs(key.length - query.length);
+    }
+    found.push({ name: t.name, dist: d });
+  });
+  found.sort(function (a, b) {
+    if (a.dist !== b.dist) {
+      return a.dist - b.dist;
+    }
+    return a.name < b.name ? -1 : a.name > b.name ? 1 : 0;
+  });
+  return found.slice(0, limit).map(function (c) { return c.name; });
+}
+
+function editDistance(a, b) {
+  var prev = [];
+  for (var j = 0; j <= b.length; j++) {
+    prev.push(j);
+  }
+  for (var i = 1; i <= a.length; i++) {
+    var cur = [i];
+    for (j = 1; j <= b.length; j++) {
+      var cost = a[i - 1] === b[j - 1] ? 0 : 1;
+      cur.push(Math.min(prev[j] + 1, cur[j - 1] + 1, prev[j - 1] + cost));
+    }
+    prev = cur;
+  }
+  return prev[b.length];
+}
+
+// ============================================================================
+// Permalinks: mirrors template.Permalink's encoding (version | flags |
+// [6-byte dataset] | comma-joined names | CRC-32, base64url).
+// ============================================================================
+var CRC_TABLE = (function () {
+  var table = [];
+  for (var n = 0; n < 256; n++) {
+    var c = n;
+    for (var k = 0; k < 8; k++) {
+      c = c & 1 ? 0xedb88320 ^ (c >>> 1) : c >>> 1;
+    }
+    table.push(c >>> 0);
+  }
+  return table;
+})();
+
+function crc32(bytes) {
+  var crc = 0xffffffff;
+  for (var i = 0; i < bytes.length; i++) {
+    crc = CRC_TABLE[(crc ^ bytes[i]) & 0xff] ^ (crc >>> 8);
+  }
+  return (crc ^ 0xffffffff) >>> 0;
+}
+
+function encodePermalink(names, datasetVersion) {
+  var bytes = [1, 0];
+  if (datasetVersion) {
+    bytes[1] |= 1;
+    for (var i = 0; i < 12; i += 2) {
+      bytes.push(parseInt(datasetVersion.substr(i, 2), 16));
+    }
+  }
+  new TextEncoder().encode(names.join(',')).forEach(function (b) {
+    bytes.push(b);
+  });
+  var sum = crc32(bytes);
+  bytes.push(sum >>> 24, (sum >>> 16) & 0xff, (sum >>> 8) & 0xff, sum & 0xff);
+  return btoa(String.fromCharCode.apply(null, bytes))
+    .replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '');
+}
+
+// decodePermalink returns {templates, dataset} or null for an invalid ID.
+function decodePermalink(id) {
+  var bin;
+  try {
+    bin = atob(id.replace(/-/g, '+').replace(/_/g, '/'));
+  } catch (e) {
+    return null;
+  }
+  var bytes = [];
+  for (var i = 0; i < bin.length; i++) {
+    bytes.push(bin.charCodeAt(i));
+  }
+  if (bytes.length < 6) {
+    return null;
+  }
+  var body = bytes.slice(0, -4);
+  var tail = bytes.slice(-4);
+  var sum = ((tail[0] << 24) | (tail[1] << 16) | (tail[2] << 8) | tail[3]) >>> 0;
+  if (crc32(body) !== sum || body[0] !== 1) {
+    return null;
+  }
+  var rest = body.slice(2);
+  var p = { templates: [], dataset: '' };
+  if (body[1] & 1) {
+    if (rest.length < 6) {
+      return null;
+    }
+    p.dataset = rest.slice(0, 6).map(function (b) {
+      return (b < 16 ? '0' : '') + b.toString(16);
+    }).join('');
+    rest = rest.slice(6);
+  }
+  if (!rest.length) {
+    return null;
+  }
+  p.templates = new TextDecoder().decode(new Uint8Array(rest)).split(',');
+  return p;
+}
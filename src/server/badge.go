@@ -0,0 +1,196 @@
+package server
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// badgeColors are the named badge colors, matching the shields.io palette so
+// READMEs mixing both look consistent.
+var badgeColors = map[string]string{
+	"brightgreen": "#4c1",
+	"green":       "#97ca00",
+	"yellow":      "#dfb317",
+	"orange":      "#fe7d37",
+	"red":         "#e05d44",
+	"blue":        "#007ec6",
+	"grey":        "#555",
+	"lightgrey":   "#9f9f9f",
+	"accent":      "#f05033",
+}
+
+// badgeHexColor matches a 3- or 6-digit hex color without the "#".
+var badgeHexColor = regexp.MustCompile(`^(?:[0-9a-fA-F]{3}){1,2}$`)
+
+// badgeMaxNames caps how many names the badge spells out; the rest are
+// counted.
+const badgeMaxNames = 5
+
+// verdanaWidths are Verdana's advance widths for ASCII 32–126 in font units
+// (2048 per em). Badge text is 11px Verdana, as on shields.io, and the SVG
+// has no layout engine, so segment widths are computed from these.
+var verdanaWidths = [95]uint16{
+	720, 824, 1048, 1716, 1303, 2222, 1556, 614, 926, 926, 1303, 1716, 745, 882, 745, 1040,
+	1303, 1303, 1303, 1303, 1303, 1303, 1303, 1303, 1303, 1303, 836, 836, 1716, 1716, 1716, 1112,
+	2134, 1401, 1405, 1430, 1577, 1294, 1178, 1587, 1540, 862, 921, 1425, 1145, 1712, 1532, 1648,
+	1255, 1648, 1450, 1405, 1257, 1511, 1401, 2025, 1403, 1256, 1403, 926, 1040, 926, 1716, 1303,
+	1303, 1229, 1276, 1067, 1276, 1220, 720, 1276, 1296, 562, 705, 1198, 562, 1992, 1296, 1243,
+	1276, 1276, 874, 1067, 807, 1296, 1198, 1675, 1198, 1198, 1051, 1306, 926, 1306, 1716,
+}
+
+// badgeTextWidth is the rendered width of s in 11px Verdana, in pixels.
+func badgeTextWidth(s string) float64 {
+	var units int
+	for _, r := range s {
+		switch {
+		case r >= 32 && r <= 126:
+			units += int(verdanaWidths[r-32])
+		case r == '·':
+			units += 745
+		default:
+			units += 1303
+		}
+	}
+	return float64(units) * 11 / 2048
+}
+
+// badge is a two-segment shields-style badge.
+type badge struct {
+	Label   string
+	Message string
+	Color   string
+	Link    string
+	// Square drops the rounded corners and gloss (shields' flat-square).
+	Square bool
+}
+
+// segments returns the label and message segment widths: each is its text
+// width plus 5px padding on either side.
+func (b badge) segments() (label, message int) {
+	return int(badgeTextWidth(b.Label)+0.5) + 10, int(badgeTextWidth(b.Message)+0.5) + 10
+}
+
+// Width is the rendered badge width in pixels.
+func (b badge) Width() int {
+	lw, mw := b.segments()
+	return lw + mw
+}
+
+// SVG renders the badge, with a 1px drop shadow under the text like shields'
+// flat style.
+func (b badge) SVG() string {
+	lw, mw := b.segments()
+	w := lw + mw
+	title := html.EscapeString(b.Label + ": " + b.Message)
+	label := html.EscapeString(b.Label)
+	message := html.EscapeString(b.Message)
+	radius := "3"
+	if b.Square {
+		radius = "0"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="%d" height="20" role="img" aria-label="%s">`, w, title)
+	fmt.Fprintf(&sb, `<title>%s</title>`, title)
+	if b.Link != "" {
+		fmt.Fprintf(&sb, `<a target="_blank" xlink:href="%s">`, html.EscapeString(b.Link))
+	}
+	if !b.Square {
+		sb.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	}
+	fmt.Fprintf(&sb, `<clipPath id="r"><rect width="%d" height="20" rx="%s" fill="#fff"/></clipPath>`, w, radius)
+	fmt.Fprintf(&sb, `<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/>`, lw, lw, mw, b.Color)
+	if !b.Square {
+		fmt.Fprintf(&sb, `<rect width="%d" height="20" fill="url(#s)"/>`, w)
+	}
+	sb.WriteString(`</g><g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	for _, seg := range []struct {
+		x, w int
+		text string
+	}{{lw / 2, lw - 10, label}, {lw + mw/2, mw - 10, message}} {
+		fmt.Fprintf(&sb, `<text x="%d" y="15" fill="#010101" fill-opacity=".3" textLength="%d">%s</text>`, seg.x, seg.w, seg.text)
+		fmt.Fprintf(&sb, `<text x="%d" y="14" textLength="%d">%s</text>`, seg.x, seg.w, seg.text)
+	}
+	sb.WriteString(`</g>`)
+	if b.Link != "" {
+		sb.WriteString(`</a>`)
+	}
+	sb.WriteString(`</svg>`)
+	return sb.String()
+}
+
+// badgeColor resolves ?color= to a fill: a shields color name or a hex code.
+// Anything else falls back to the site accent.
+func badgeColor(v string) string {
+	if c, ok := badgeColors[strings.ToLower(v)]; ok {
+		return c
+	}
+	if badgeHexColor.MatchString(v) {
+		return "#" + v
+	}
+	return badgeColors["accent"]
+}
+
+// badgeMessage joins names for the message segment, counting those past
+// badgeMaxNames.
+func badgeMessage(names []string) string {
+	if len(names) <= badgeMaxNames {
+		return strings.Join(names, " · ")
+	}
+	return strings.Join(names[:badgeMaxNames], " · ") + fmt.Sprintf(" · +%d", len(names)-badgeMaxNames)
+}
+
+// badgePath is the badge URL path for names.
+func badgePath(names []string) string {
+	return "/badge/" + strings.Join(escapeAll(names), ",") + ".svg"
+}
+
+// badgeMarkdown is the README snippet for names: the badge linking to the
+// composer with them selected.
+func badgeMarkdown(base string, names []string) string {
+	return "[![gitignore](" + base + badgePath(names) + ")](" + base + composerURL(names) + ")"
+}
+
+// handleBadge serves /badge/{templates}.svg, a README badge naming the
+// templates a project's .gitignore is built from and linking to the composer
+// with them selected. ?label=, ?color= and ?style=flat-square restyle it.
+// Unknown names still get a badge, in red and naming the first unknown, so a
+// typo shows up in the README rather than as a broken image.
+func (s *Server) handleBadge(w http.ResponseWriter, r *http.Request) {
+	// Matched as {file} and split here: template names may contain dots.
+	file := chi.URLParam(r, "file")
+	if !strings.HasSuffix(file, ".svg") {
+		http.NotFound(w, r)
+		return
+	}
+	names := parseTemplateList(strings.TrimSuffix(file, ".svg"))
+	q := r.URL.Query()
+
+	b := badge{
+		Label:  "gitignore",
+		Color:  badgeColor(q.Get("color")),
+		Square: q.Get("style") == "flat-square",
+	}
+	if label := strings.TrimSpace(q.Get("label")); label != "" {
+		b.Label = label
+	}
+	known, unknown := s.resolveTemplates(names)
+	switch {
+	case len(names) == 0:
+		b.Message, b.Color = "no templates", badgeColors["lightgrey"]
+	case len(unknown) > 0:
+		b.Message, b.Color = "unknown: "+unknown[0].Name, badgeColors["red"]
+	default:
+		b.Message = badgeMessage(known)
+		b.Link = s.siteURL(r) + composerURL(known)
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	setCacheHeaders(w, "image")
+	fmt.Fprint(w, b.SVG())
+}
@@ -0,0 +1,441 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/config"
+)
+
+// Operator branding (IDEA.md "Business logic"): Markdown overrides for the
+// standard pages live in {config_dir}/pages, the logo and favicon in
+// {config_dir}/branding, and theme tokens, footer links and the announcement
+// banner in server.branding. Files are re-read when they change and the
+// settings follow SIGHUP reloads, so none of it needs a restart.
+
+// brandingDefaultName is the site name shown while server.branding.title is
+// left at its default.
+const brandingDefaultName = "GitIgnore"
+
+// maxBrandingFileSize caps page sources and uploaded images.
+const maxBrandingFileSize = 512 << 10
+
+// operatorPages are the standard pages an operator may replace with
+// {config_dir}/pages/{name}.md.
+var operatorPages = map[string]bool{"about": true, "privacy": true, "terms": true, "contact": true}
+
+// brandingAssets lists the accepted file extensions for each uploadable
+// asset, in lookup order.
+var brandingAssets = map[string][]string{
+	"logo":    {".svg", ".png", ".webp", ".jpg"},
+	"favicon": {".ico", ".png", ".svg"},
+}
+
+// brandingTypes maps an asset extension to its Content-Type.
+var brandingTypes = map[string]string{
+	".svg":  "image/svg+xml",
+	".png":  "image/png",
+	".webp": "image/webp",
+	".jpg":  "image/jpeg",
+	".ico":  "image/x-icon",
+}
+
+// brandingAssetCSP is the policy for /branding/*. An uploaded SVG opened
+// directly is a document of its own, so it may not run script or load
+// anything.
+var brandingAssetCSP = map[string]string{
+	"default-src": "'none'",
+	"script-src":  "'none'",
+	"style-src":   "'unsafe-inline'",
+	"img-src":     "data:",
+}
+
+// themeTokenNames are the custom properties main.css defines, the only
+// tokens server.branding.theme may override.
+var themeTokenNames = func() map[string]bool {
+	css, _ := staticFS.ReadFile("assets/static/css/main.css")
+	names := map[string]bool{}
+	for _, m := range regexp.MustCompile(`(?m)^\s*--([a-z0-9-]+)\s*:`).FindAllSubmatch(css, -1) {
+		names[string(m[1])] = true
+	}
+	return names
+}()
+
+// themeTokenValue admits colors, lengths and font stacks while excluding
+// everything that could end the declaration or start a comment (";", "{",
+// quotes, "/*"). Functions are limited to themeTokenFuncs so a value cannot
+// load a resource (url(), image-set()).
+var (
+	themeTokenValue = regexp.MustCompile(`^[A-Za-z0-9#%.,() -]{1,64}$`)
+	themeTokenFunc  = regexp.MustCompile(`([A-Za-z-]*)\(`)
+	themeTokenFuncs = map[string]bool{
+		"rgb": true, "rgba": true, "hsl": true, "hsla": true, "hwb": true,
+		"oklch": true, "oklab": true, "calc": true, "var": true, "min": true, "max": true, "clamp": true,
+	}
+)
+
+// safeThemeValue reports whether v may be emitted as a token value.
+func safeThemeValue(v string) bool {
+	if !themeTokenValue.MatchString(v) {
+		return false
+	}
+	for _, m := range themeTokenFunc.FindAllStringSubmatch(v, -1) {
+		if !themeTokenFuncs[strings.ToLower(m[1])] {
+			return false
+		}
+	}
+	return true
+}
+
+// ReloadBranding publishes the branding settings from the startup or a
+// reloaded configuration, logging those that will be ignored.
+func (s *Server) ReloadBranding(b config.BrandingConfig) {
+	for _, problem := range brandingProblems(b) {
+		log.Printf("branding: %s", problem)
+	}
+	s.branding.Store(&b)
+}
+
+// brandingConfig returns the live branding settings.
+func (s *Server) brandingConfig() config.BrandingConfig {
+	if b := s.branding.Load(); b != nil {
+		return *b
+	}
+	if s.config.Cfg != nil {
+		return s.config.Cfg.Server.Branding
+	}
+	return config.BrandingConfig{}
+}
+
+// brandingProblems lists the settings that will be ignored.
+func brandingProblems(b config.BrandingConfig) []string {
+	var problems []string
+	for scheme, tokens := range map[string]map[string]string{"dark": b.Theme.Dark, "light": b.Theme.Light} {
+		for name, value := range tokens {
+			if !themeTokenNames[name] {
+				problems = append(problems, fmt.Sprintf("theme.%s: unknown token %q", scheme, name))
+			} else if !safeThemeValue(value) {
+				problems = append(problems, fmt.Sprintf("theme.%s.%s: unsafe value %q", scheme, name, value))
+			}
+		}
+	}
+	for _, l := range b.FooterLinks {
+		if _, ok := safeLinkURL(l.URL); !ok || l.Label == "" {
+			problems = append(problems, fmt.Sprintf("footer link %q: needs a label and an http(s), mailto or site-relative url", l.URL))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// brandingView is the layout's view of the branding settings.
+type brandingView struct {
+	Name         string
+	Logo         string
+	Favicon      string
+	ThemeCSS     string
+	FooterLinks  []config.FooterLink
+	Announcement *config.AnnouncementConfig
+}
+
+// brandingView resolves the live settings and files into layout URLs. Asset
+// URLs carry a version so browsers pick up replacements immediately.
+func (s *Server) brandingView() brandingView {
+	b := s.brandingConfig()
+	v := brandingView{Name: b.Title}
+	if v.Name == "" || v.Name == config.DefaultConfig().Server.Branding.Title {
+		v.Name = brandingDefaultName
+	}
+	for asset, dst := range map[string]*string{"logo": &v.Logo, "favicon": &v.Favicon} {
+		if path, _ := s.brandingAsset(asset); path != "" {
+			if fi, err := os.Stat(path); err == nil {
+				*dst = "/branding/" + asset + "?v=" + strconv.FormatInt(fi.ModTime().Unix(), 36)
+			}
+		}
+	}
+	if css := themeCSS(b.Theme); css != "" {
+		sum := sha256.Sum256([]byte(css))
+		v.ThemeCSS = "/branding/theme.css?v=" + hex.EncodeToString(sum[:6])
+	}
+	for _, l := range b.FooterLinks {
+		if href, ok := safeLinkURL(l.URL); ok && l.Label != "" {
+			v.FooterLinks = append(v.FooterLinks, config.FooterLink{Label: l.Label, URL: href})
+		}
+	}
+	if a := b.Announcement; strings.TrimSpace(a.Message) != "" {
+		if a.Level != "warning" {
+			a.Level = "info"
+		}
+		if _, ok := safeLinkURL(a.Link); !ok {
+			a.Link = ""
+		}
+		v.Announcement = &a
+	}
+	return v
+}
+
+// themeCSS renders the valid token overrides as a stylesheet layered over
+// main.css, mirroring its theme-dark/theme-auto/theme-light selectors.
+func themeCSS(t config.ThemeTokens) string {
+	decls := func(tokens map[string]string, indent string) string {
+		names := make([]string, 0, len(tokens))
+		for name, value := range tokens {
+			if themeTokenNames[name] && safeThemeValue(value) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		var b strings.Builder
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s--%s: %s;\n", indent, name, tokens[name])
+		}
+		return b.String()
+	}
+	dark, light := decls(t.Dark, "  "), decls(t.Light, "  ")
+	var b strings.Builder
+	if dark != "" {
+		b.WriteString("html.theme-dark,\nhtml.theme-auto {\n" + dark + "}\n")
+	}
+	if light != "" {
+		b.WriteString("html.theme-light {\n" + light + "}\n")
+		b.WriteString("@media (prefers-color-scheme: light) {\n  html.theme-auto {\n" + decls(t.Light, "    ") + "  }\n}\n")
+	}
+	return b.String()
+}
+
+// brandingAsset returns the path and Content-Type of the operator's logo or
+// favicon, or "" when none has been provided.
+func (s *Server) brandingAsset(asset string) (path, contentType string) {
+	if s.config.Paths == nil {
+		return "", ""
+	}
+	for _, ext := range brandingAssets[asset] {
+		p := s.config.Paths.ConfigPath(filepath.Join("branding", asset+ext))
+		if fi, err := os.Stat(p); err == nil && fi.Mode().IsRegular() {
+			return p, brandingTypes[ext]
+		}
+	}
+	return "", ""
+}
+
+// markdownPage is a rendered operator page, cached until its file changes.
+type markdownPage struct {
+	modTime time.Time
+	size    int64
+	title   string
+	body    template.HTML
+}
+
+var (
+	markdownPagesMu sync.Mutex
+	markdownPages   = map[string]markdownPage{}
+)
+
+// operatorPage returns the operator's Markdown replacement for a standard
+// page, if {config_dir}/pages/{name}.md exists.
+func (s *Server) operatorPage(name string) (markdownPage, bool) {
+	if s.config.Paths == nil || !operatorPages[name] {
+		return markdownPage{}, false
+	}
+	path := s.config.Paths.ConfigPath(filepath.Join("pages", name+".md"))
+	fi, err := os.Stat(path)
+	if err != nil || !fi.Mode().IsRegular() {
+		return markdownPage{}, false
+	}
+
+	markdownPagesMu.Lock()
+	defer markdownPagesMu.Unlock()
+	if p, ok := markdownPages[path]; ok && p.modTime.Equal(fi.ModTime()) && p.size == fi.Size() {
+		return p, true
+	}
+	if fi.Size() > maxBrandingFileSize {
+		log.Printf("branding: %s exceeds %d bytes; serving the built-in page", path, maxBrandingFileSize)
+		return markdownPage{}, false
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("branding: %v; serving the built-in page", err)
+		return markdownPage{}, false
+	}
+	p := markdownPage{modTime: fi.ModTime(), size: fi.Size()}
+	p.title, p.body = renderMarkdown(string(src))
+	markdownPages[path] = p
+	return p, true
+}
+
+// renderStandardPage serves one of the operatorPages, preferring the
+// operator's Markdown over the embedded page.
+func (s *Server) renderStandardPage(w http.ResponseWriter, r *http.Request, name, title string) {
+	if p, ok := s.operatorPage(name); ok {
+		if p.title != "" {
+			title = p.title
+		}
+		s.renderPage(w, r, "markdown", PageData{Title: title, Data: map[string]interface{}{"body": p.body}})
+		return
+	}
+	s.renderPage(w, r, name, PageData{Title: title})
+}
+
+// handleBrandingTheme serves /branding/theme.css, the theme token overrides.
+func (s *Server) handleBrandingTheme(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	setCacheHeaders(w, "api")
+	_, _ = io.WriteString(w, themeCSS(s.brandingConfig().Theme))
+}
+
+// handleBrandingAsset serves /branding/{asset}, the operator's logo or
+// favicon.
+func (s *Server) handleBrandingAsset(w http.ResponseWriter, r *http.Request) {
+	path, contentType := s.brandingAsset(chi.URLParam(r, "asset"))
+	if path == "" {
+		sendAPIResponseError(w, "NOT_FOUND", "no such branding asset")
+		return
+	}
+	s.serveBrandingFile(w, r, path, contentType)
+}
+
+func (s *Server) serveBrandingFile(w http.ResponseWriter, r *http.Request, path, contentType string) {
+	f, err := os.Open(path)
+	if err != nil {
+		sendAPIResponseError(w, "NOT_FOUND", "no such branding asset")
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		sendAPIResponseError(w, "SERVER_ERROR", "branding asset unreadable")
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	setCacheHeaders(w, "api")
+	http.ServeContent(w, r, "", fi.ModTime(), f)
+}
+
+// handleBrandingUpload implements PUT /api/v1/admin/branding/{asset}: the
+// request body is the image, stored as {config_dir}/branding/{asset}.{ext}.
+func (s *Server) handleBrandingUpload(w http.ResponseWriter, r *http.Request) {
+	asset := chi.URLParam(r, "asset")
+	exts, ok := brandingAssets[asset]
+	if !ok {
+		sendAPIResponseError(w, "NOT_FOUND", "asset must be logo or favicon")
+		return
+	}
+	if s.config.Paths == nil {
+		sendAPIResponseError(w, "SERVER_ERROR", "config directory unavailable")
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBrandingFileSize))
+	if err != nil {
+		sendAPIResponseError(w, "VALIDATION_FAILED", fmt.Sprintf("image must be at most %d KiB", maxBrandingFileSize>>10))
+		return
+	}
+	ext := brandingImageExt(body)
+	allowed := false
+	for _, e := range exts {
+		allowed = allowed || e == ext
+	}
+	if !allowed {
+		sendAPIResponseError(w, "VALIDATION_FAILED", fmt.Sprintf("%s must be one of %s", asset, strings.Join(exts, ", ")))
+		return
+	}
+
+	dir := s.config.Paths.ConfigPath("branding")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		sendAPIResponseError(w, "SERVER_ERROR", "cannot create branding directory")
+		return
+	}
+	dst := filepath.Join(dir, asset+ext)
+	tmp := dst + ".tmp"
+	if err := os.WriteFile(tmp, body, 0o644); err != nil {
+		sendAPIResponseError(w, "SERVER_ERROR", "cannot write branding asset")
+		return
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		_ = os.Remove(tmp)
+		sendAPIResponseError(w, "SERVER_ERROR", "cannot write branding asset")
+		return
+	}
+	// The lookup order would otherwise keep serving an older upload in
+	// another format.
+	for _, e := range exts {
+		if e != ext {
+			_ = os.Remove(filepath.Join(dir, asset+e))
+		}
+	}
+	sendAPIResponseOK(w, map[string]interface{}{
+		"asset":        asset,
+		"content_type": brandingTypes[ext],
+		"size":         len(body),
+		"url":          s.brandingView().assetURL(asset),
+	})
+}
+
+// handleBrandingDelete implements DELETE /api/v1/admin/branding/{asset},
+// restoring the built-in image.
+func (s *Server) handleBrandingDelete(w http.ResponseWriter, r *http.Request) {
+	asset := chi.URLParam(r, "asset")
+	if _, ok := brandingAssets[asset]; !ok {
+		sendAPIResponseError(w, "NOT_FOUND", "asset must be logo or favicon")
+		return
+	}
+	for path, _ := s.brandingAsset(asset); path != ""; path, _ = s.brandingAsset(asset) {
+		if err := os.Remove(path); err != nil {
+			sendAPIResponseError(w, "SERVER_ERROR", "cannot remove branding asset")
+			return
+		}
+	}
+	sendAPIResponseOK(w, map[string]interface{}{"asset": asset, "removed": true})
+}
+
+func (v brandingView) assetURL(asset string) string {
+	if asset == "favicon" {
+		return v.Favicon
+	}
+	return v.Logo
+}
+
+// brandingImageExt identifies an uploaded image by content, never by the
+// client's Content-Type. SVG is recognized by its root element.
+func brandingImageExt(data []byte) string {
+	switch http.DetectContentType(data) {
+	case "image/png":
+		return ".png"
+	case "image/webp":
+		return ".webp"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/x-icon", "image/vnd.microsoft.icon":
+		return ".ico"
+	}
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return ""
+		}
+		if el, ok := tok.(xml.StartElement); ok {
+			if el.Name.Local == "svg" {
+				return ".svg"
+			}
+			return ""
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/config"
+	apppath "github.com/apimgr/gitignore/src/path"
+)
+
+func newTestBrandingServer(t *testing.T) (*Server, http.Handler, string) {
+	t.Helper()
+	dir := t.TempDir()
+	pm := apppath.New()
+	pm.SetConfigDir(dir)
+	s := &Server{config: &Config{Version: "test", Paths: pm, Cfg: &config.Config{}}}
+	r := chi.NewRouter()
+	r.Get("/server/privacy", s.handlePrivacyPage)
+	r.Get("/server/help", s.handleHelpPage)
+	r.Get("/favicon.ico", s.handleFavicon)
+	r.Get("/branding/theme.css", s.handleBrandingTheme)
+	r.With(s.cspOverride(brandingAssetCSP)).Get("/branding/{asset}", s.handleBrandingAsset)
+	r.Put("/admin/branding/{asset}", s.handleBrandingUpload)
+	r.Delete("/admin/branding/{asset}", s.handleBrandingDelete)
+	return s, r, dir
+}
+
+func TestOperatorMarkdownPage(t *testing.T) {
+	_, h, dir := newTestBrandingServer(t)
+	if body := doGet(t, h, "/server/privacy").Body.String(); !strings.Contains(body, "collect as little as possible") {
+		t.Fatal("built-in privacy page not served without an override")
+	}
+
+	path := filepath.Join(dir, "pages", "privacy.md")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(src string, mtime time.Time) {
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("# Acme Privacy Policy\n\nContact <script>x</script> [IT](mailto:it@acme.example).\n", time.Now().Add(-time.Hour))
+	body := doGet(t, h, "/server/privacy").Body.String()
+	for _, want := range []string{"<title>Acme Privacy Policy — GitIgnore</title>", `<a href="mailto:it@acme.example">IT</a>`, "&lt;script&gt;"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+	if strings.Contains(body, "collect as little as possible") {
+		t.Error("built-in page served despite the override")
+	}
+
+	// Edits apply without a restart.
+	write("# Revised policy\n", time.Now())
+	if body := doGet(t, h, "/server/privacy").Body.String(); !strings.Contains(body, "Revised policy") {
+		t.Error("edited page not picked up")
+	}
+	// Only the overridable pages are read from disk.
+	if err := os.WriteFile(filepath.Join(dir, "pages", "help.md"), []byte("# Custom help\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(doGet(t, h, "/server/help").Body.String(), "Custom help") {
+		t.Error("help is not an operator page")
+	}
+}
+
+func TestBrandingLayoutAndReload(t *testing.T) {
+	s, h, _ := newTestBrandingServer(t)
+	body := doGet(t, h, "/server/help").Body.String()
+	if strings.Contains(body, "/branding/theme.css") || strings.Contains(body, `class="announcement`) {
+		t.Fatal("branding chrome rendered with no settings")
+	}
+
+	s.ReloadBranding(config.BrandingConfig{
+		Title: "Acme Ignore",
+		Theme: config.ThemeTokens{
+			Dark:  map[string]string{"accent": "#d9480f", "bg": "url(https://evil.example/x)", "bogus": "red"},
+			Light: map[string]string{"accent": "rgb(200, 72, 15)", "fg": "image-set(x.png 1x)"},
+		},
+		FooterLinks: []config.FooterLink{
+			{Label: "Internal support", URL: "https://support.acme.example/"},
+			{Label: "Bad", URL: "javascript:alert(1)"},
+		},
+		Announcement: config.AnnouncementConfig{Message: "Maintenance Saturday", Link: "/server/help", Level: "warning"},
+	})
+	body = doGet(t, h, "/server/help").Body.String()
+	for _, want := range []string{
+		"<title>Help — Acme Ignore</title>",
+		`<link rel="stylesheet" href="/branding/theme.css?v=`,
+		`<a href="https://support.acme.example/">Internal support</a>`,
+		`<div class="announcement announcement-warning" role="alert">`,
+		`<a href="/server/help">Maintenance Saturday</a>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+	if strings.Contains(body, "javascript:") {
+		t.Error("unsafe footer link rendered")
+	}
+
+	css := doGet(t, h, "/branding/theme.css").Body.String()
+	want := "html.theme-dark,\nhtml.theme-auto {\n  --accent: #d9480f;\n}\n" +
+		"html.theme-light {\n  --accent: rgb(200, 72, 15);\n}\n" +
+		"@media (prefers-color-scheme: light) {\n  html.theme-auto {\n    --accent: rgb(200, 72, 15);\n  }\n}\n"
+	if css != want {
+		t.Errorf("theme.css =\n%s\nwant\n%s", css, want)
+	}
+}
+
+func TestBrandingUpload(t *testing.T) {
+	_, h, dir := newTestBrandingServer(t)
+	put := func(asset string, body []byte) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/branding/"+asset, bytes.NewReader(body)))
+		return rec
+	}
+
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...)
+	svg := []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`)
+	for _, tc := range []struct {
+		asset string
+		body  []byte
+		code  int
+	}{
+		{"logo", []byte("<html>not an image</html>"), http.StatusBadRequest},
+		{"favicon", []byte("GIF89a......"), http.StatusBadRequest},
+		{"logo", bytes.Repeat([]byte{0}, maxBrandingFileSize+1), http.StatusBadRequest},
+		{"banner", png, http.StatusNotFound},
+		{"logo", svg, http.StatusOK},
+		{"logo", png, http.StatusOK},
+		{"favicon", png, http.StatusOK},
+	} {
+		if rec := put(tc.asset, tc.body); rec.Code != tc.code {
+			t.Errorf("PUT %s (%d bytes) = %d, want %d: %s", tc.asset, len(tc.body), rec.Code, tc.code, rec.Body)
+		}
+	}
+	// The PNG replaced the SVG rather than being shadowed by it.
+	if _, err := os.Stat(filepath.Join(dir, "branding", "logo.svg")); !os.IsNotExist(err) {
+		t.Error("logo.svg left behind after a PNG upload")
+	}
+
+	rec := doGet(t, h, "/branding/logo")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" || !bytes.Equal(rec.Body.Bytes(), png) {
+		t.Fatalf("logo = %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if csp := rec.Header().Get("Content-Security-Policy") + rec.Header().Get("Content-Security-Policy-Report-Only"); !strings.Contains(csp, "script-src 'none'") {
+		t.Errorf("branding CSP = %q", csp)
+	}
+	if rec := doGet(t, h, "/favicon.ico"); rec.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("favicon.ico served %q, want the uploaded PNG", rec.Header().Get("Content-Type"))
+	}
+	body := doGet(t, h, "/server/help").Body.String()
+	if !strings.Contains(body, `<img class="site-logo" src="/branding/logo?v=`) || !strings.Contains(body, `<link rel="icon" href="/branding/favicon?v=`) {
+		t.Error("layout does not reference the uploaded logo and favicon")
+	}
+
+	del := httptest.NewRecorder()
+	h.ServeHTTP(del, httptest.NewRequest(http.MethodDelete, "/admin/branding/logo", nil))
+	if del.Code != http.StatusOK || doGet(t, h, "/branding/logo").Code != http.StatusNotFound {
+		t.Errorf("DELETE logo = %d; logo still served", del.Code)
+	}
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/apimgr/gitignore/src/config"
+	"github.com/apimgr/gitignore/src/server/metrics"
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// TestBusinessMetricsBoundLabels verifies fetches are labelled only with known
+// template names and misses are bucketed rather than echoed.
+func TestBusinessMetricsBoundLabels(t *testing.T) {
+	tm, err := template.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{
+		config:  &Config{Templates: tm, Cfg: &config.Config{}},
+		metrics: metrics.New(metrics.Options{}),
+	}
+
+	s.recordUsage(sourceNative, []string{"go", "Pyhton", "../../etc/passwd", "zzzzzzzz"})
+	s.recordUsage(sourceCompat, []string{"GO"})
+
+	m := s.metrics
+	if got := testutil.ToFloat64(m.TemplateFetches.WithLabelValues("Go", sourceNative)); got != 1 {
+		t.Errorf("Go/native = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.TemplateFetches.WithLabelValues(metrics.UnknownTemplate, sourceNative)); got != 3 {
+		t.Errorf("_unknown/native = %v, want 3", got)
+	}
+	for bucket, want := range map[string]float64{"near_miss": 1, "invalid": 1, "other": 1} {
+		if got := testutil.ToFloat64(m.TemplateNotFound.WithLabelValues(sourceNative, bucket)); got != want {
+			t.Errorf("not_found %s = %v, want %v", bucket, got, want)
+		}
+	}
+	if n := testutil.CollectAndCount(m.TemplateFetches); n != 3 {
+		t.Errorf("template_fetches series = %d, want 3", n)
+	}
+
+	s.searchTemplates("no-such-template-anywhere")
+	if got := testutil.ToFloat64(m.SearchZeroResults); got != 1 {
+		t.Errorf("zero-result searches = %v, want 1", got)
+	}
+
+	s.ObserveConfigReload(nil)
+	s.ObserveConfigReload(errors.New("bad yaml"))
+	if got := testutil.ToFloat64(m.ConfigReloads.WithLabelValues("failure")); got != 1 {
+		t.Errorf("failed reloads = %v, want 1", got)
+	}
+}
+
+// TestCombineMetrics verifies a successful combine records its template count
+// and output size.
+func TestCombineMetrics(t *testing.T) {
+	tm, err := template.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{
+		config:  &Config{Templates: tm, Cfg: &config.Config{}},
+		metrics: metrics.New(metrics.Options{}),
+	}
+	rec := doGet(t, http.HandlerFunc(s.handleAPICombine), "/api/v1/combine?templates=Go,Node")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if n := testutil.CollectAndCount(s.metrics.CombineOutputSize); n != 1 {
+		t.Errorf("combine_output_bytes series = %d, want 1", n)
+	}
+}
@@ -0,0 +1,263 @@
+package server
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/db"
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// feedMaxEntries caps the Atom feed so it stays small however long the
+// changelog grows; feed readers only need the recent revisions.
+const feedMaxEntries = 50
+
+// ReloadChangelog rebuilds the served changelog from the one embedded at
+// build time and the revisions template_sync recorded in server.db, so
+// datasets synced since the build have their changes, history and feed
+// entries too. It runs at startup and after each sync or reload; a corrupt
+// embedded changelog disables the changes/history endpoints rather than the
+// server, and an unreadable database leaves the embedded revisions alone.
+func (s *Server) ReloadChangelog() {
+	cl, err := template.LoadChangelog()
+	if err != nil {
+		log.Printf("server: %v", err)
+		return
+	}
+	rows, err := db.ListTemplateRevisions()
+	if err != nil {
+		log.Printf("server: synced dataset revisions: %v", err)
+	}
+	var synced []template.Revision
+	for _, row := range rows {
+		var rev template.Revision
+		if err := json.Unmarshal(row.Data, &rev); err != nil {
+			log.Printf("server: synced dataset revision %s: %v", row.Version, err)
+			continue
+		}
+		synced = append(synced, rev)
+	}
+	s.changelog.Store(cl.Merge(synced))
+}
+
+// handleAPIChanges returns the dataset revisions newer than ?since= (a dataset
+// version or a date), each listing the templates it touched with unified diffs
+// for modified ones. ?templates=a,b narrows the changes to those templates.
+func (s *Server) handleAPIChanges(w http.ResponseWriter, r *http.Request) {
+	changelog := s.changelog.Load()
+	if changelog == nil {
+		sendAPIResponseError(w, "NOT_IMPLEMENTED", "dataset changelog unavailable")
+		return
+	}
+	since := r.URL.Query().Get("since")
+	revs, err := changelog.Since(since)
+	if err != nil {
+		sendAPIResponseError(w, "NOT_FOUND", err.Error())
+		return
+	}
+	revs = filterRevisions(revs, templateFilter(r))
+
+	latest := ""
+	if rev := changelog.Latest(); rev != nil {
+		latest = rev.Version
+	}
+	if revs == nil {
+		revs = []template.Revision{}
+	}
+	sendAPIResponseOK(w, map[string]interface{}{
+		"current":   s.config.Templates.Version(),
+		"latest":    latest,
+		"since":     since,
+		"revisions": revs,
+	})
+}
+
+// handleAPITemplateHistory returns every dataset revision that touched a
+// template, newest first.
+func (s *Server) handleAPITemplateHistory(w http.ResponseWriter, r *http.Request) {
+	changelog := s.changelog.Load()
+	if changelog == nil {
+		sendAPIResponseError(w, "NOT_IMPLEMENTED", "dataset changelog unavailable")
+		return
+	}
+	name := chi.URLParam(r, "name")
+	history := changelog.History(name)
+	tmpl, err := s.config.Templates.Get(name)
+	if err != nil && len(history) == 0 {
+		sendAPIResponseErrorLocalized(w, r, "NOT_FOUND", "template not found")
+		return
+	}
+	if tmpl != nil {
+		name = tmpl.Name
+	}
+	if history == nil {
+		history = []template.HistoryEntry{}
+	}
+	sendAPIResponseOK(w, map[string]interface{}{
+		"template": name,
+		"history":  history,
+	})
+}
+
+// atomFeed is the subset of RFC 4287 the dataset feed uses.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// handleTemplatesFeed serves the dataset changelog as an Atom feed, one entry
+// per revision, newest first. ?templates=a,b limits it to revisions touching
+// those templates so users can follow just the stacks they use.
+func (s *Server) handleTemplatesFeed(w http.ResponseWriter, r *http.Request) {
+	changelog := s.changelog.Load()
+	if changelog == nil {
+		http.NotFound(w, r)
+		return
+	}
+	base := s.detectServerURL(r)
+	self := base + r.URL.RequestURI()
+	revs := filterRevisions(changelog.Revisions, templateFilter(r))
+	// Each entry links to the changes since the revision before it, so the
+	// link resolves to exactly that entry's revision.
+	previous := map[string]string{}
+	for i := 1; i < len(changelog.Revisions); i++ {
+		previous[changelog.Revisions[i].Version] = changelog.Revisions[i-1].Version
+	}
+
+	feed := atomFeed{
+		ID:     self,
+		Title:  "GitIgnore template changes",
+		Link:   []atomLink{{Href: self, Rel: "self", Type: "application/atom+xml"}, {Href: base + "/"}},
+		Author: atomAuthor{Name: "GitIgnore"},
+	}
+	for i := len(revs) - 1; i >= 0 && len(feed.Entries) < feedMaxEntries; i-- {
+		rev := revs[i]
+		updated := rev.Date.UTC().Format(time.RFC3339)
+		if feed.Updated == "" {
+			feed.Updated = updated
+		}
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:      "urn:gitignore:dataset:" + rev.Version,
+			Title:   fmt.Sprintf("Dataset %s: %s", rev.Version, summarizeChanges(rev.Changes)),
+			Updated: updated,
+			Link:    atomLink{Href: base + apiBasePath() + "/changes?since=" + previous[rev.Version]},
+			Content: atomContent{Type: "text", Body: describeRevision(rev)},
+		})
+	}
+	if feed.Updated == "" {
+		feed.Updated = s.startTime.UTC().Format(time.RFC3339)
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	setCacheHeaders(w, "api")
+	fmt.Fprint(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	_ = enc.Encode(feed)
+}
+
+// templateFilter parses ?templates=a,b into a lower-cased set; nil means all.
+func templateFilter(r *http.Request) map[string]bool {
+	raw := r.URL.Query().Get("templates")
+	if raw == "" {
+		return nil
+	}
+	set := map[string]bool{}
+	for _, name := range strings.Split(raw, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			set[strings.ToLower(name)] = true
+		}
+	}
+	return set
+}
+
+// filterRevisions keeps only the changes to templates in filter, dropping
+// revisions left empty. A nil filter returns revs unchanged.
+func filterRevisions(revs []template.Revision, filter map[string]bool) []template.Revision {
+	if filter == nil {
+		return revs
+	}
+	var out []template.Revision
+	for _, rev := range revs {
+		var kept []template.TemplateChange
+		for _, ch := range rev.Changes {
+			if filter[strings.ToLower(ch.Template)] {
+				kept = append(kept, ch)
+			}
+		}
+		if len(kept) > 0 {
+			rev.Changes = kept
+			out = append(out, rev)
+		}
+	}
+	return out
+}
+
+// summarizeChanges renders "2 added, 1 modified" style counts.
+func summarizeChanges(changes []template.TemplateChange) string {
+	counts := map[string]int{}
+	for _, ch := range changes {
+		counts[ch.Change]++
+	}
+	var parts []string
+	for _, kind := range []string{template.ChangeAdded, template.ChangeModified, template.ChangeRemoved} {
+		if n := counts[kind]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, kind))
+		}
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeRevision is the plain-text entry body: one line per template, then
+// the diffs of modified templates.
+func describeRevision(rev template.Revision) string {
+	var b strings.Builder
+	for _, ch := range rev.Changes {
+		fmt.Fprintf(&b, "%s %s\n", ch.Change, ch.Template)
+	}
+	for _, ch := range rev.Changes {
+		if ch.Diff != "" {
+			b.WriteString("\n")
+			b.WriteString(ch.Diff)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/config"
+	"github.com/apimgr/gitignore/src/db"
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// newTestChangesServer routes the changelog endpoints over a two-revision
+// changelog: Go added, then Go modified and Zig added.
+func newTestChangesServer(t *testing.T) (*Server, http.Handler) {
+	t.Helper()
+	s := newTestTemplatesServer(t)
+	v1 := []*template.Template{{Name: "Go", Category: "Root", Content: "*.exe\n"}}
+	v2 := []*template.Template{
+		{Name: "Go", Category: "Root", Content: "*.exe\n*.test\n"},
+		{Name: "Zig", Category: "Root", Content: "zig-out/\n"},
+	}
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.changelog.Store(&template.Changelog{Revisions: []template.Revision{
+		template.NewRevision(nil, v1, day, ""),
+		template.NewRevision(v1, v2, day.AddDate(0, 0, 7), ""),
+	}})
+	r := chi.NewRouter()
+	r.Get("/api/v1/changes", s.handleAPIChanges)
+	r.Get("/api/v1/templates/{name}/history", s.handleAPITemplateHistory)
+	r.Get("/feeds/templates.atom", s.handleTemplatesFeed)
+	return s, r
+}
+
+// newTestTemplatesServer returns a Server over the embedded dataset with the
+// default configuration, for tests that route its handlers themselves.
+func newTestTemplatesServer(t *testing.T) *Server {
+	t.Helper()
+	tm, err := template.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Server{config: &Config{Version: "test", Templates: tm, Cfg: &config.Config{}}}
+}
+
+func doGet(t *testing.T, h http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestAPIChangesSince(t *testing.T) {
+	s, h := newTestChangesServer(t)
+	first := s.changelog.Load().Revisions[0].Version
+
+	rec := doGet(t, h, "/api/v1/changes?since="+first)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var resp struct {
+		Data struct {
+			Revisions []template.Revision `json:"revisions"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	revs := resp.Data.Revisions
+	if len(revs) != 1 || len(revs[0].Changes) != 2 || !strings.Contains(revs[0].Changes[0].Diff, "+*.test") {
+		t.Errorf("revisions = %+v", revs)
+	}
+
+	rec = doGet(t, h, "/api/v1/changes?since=2024-01-01&templates=zig")
+	if !strings.Contains(rec.Body.String(), `"Zig"`) || strings.Contains(rec.Body.String(), `"Go"`) {
+		t.Errorf("filtered body = %s", rec.Body.String())
+	}
+
+	if rec := doGet(t, h, "/api/v1/changes?since=nope"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown version status = %d, want 404", rec.Code)
+	}
+}
+
+func TestAPITemplateHistory(t *testing.T) {
+	_, h := newTestChangesServer(t)
+	rec := doGet(t, h, "/api/v1/templates/go/history")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var resp struct {
+		Data struct {
+			Template string                  `json:"template"`
+			History  []template.HistoryEntry `json:"history"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.Template != "Go" || len(resp.Data.History) != 2 || resp.Data.History[0].Change != template.ChangeModified {
+		t.Errorf("history = %+v", resp.Data)
+	}
+	if rec := doGet(t, h, "/api/v1/templates/NoSuchThing/history"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown template status = %d, want 404", rec.Code)
+	}
+}
+
+func TestTemplatesFeed(t *testing.T) {
+	_, h := newTestChangesServer(t)
+	rec := doGet(t, h, "/feeds/templates.atom?templates=Go")
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/atom+xml") {
+		t.Errorf("content type = %q", ct)
+	}
+	var feed atomFeed
+	if err := xml.Unmarshal(rec.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("feed does not parse: %v", err)
+	}
+	if len(feed.Entries) != 2 || !strings.Contains(feed.Entries[0].Title, "1 modified") {
+		t.Errorf("entries = %+v", feed.Entries)
+	}
+	if feed.Updated != "2024-01-08T00:00:00Z" {
+		t.Errorf("updated = %s", feed.Updated)
+	}
+}
+
+// TestReloadChangelogMergesSynced verifies that a revision template_sync
+// recorded is served after the embedded ones: the running dataset version
+// stays a valid ?since= and the synced change reaches history and the feed.
+func TestReloadChangelogMergesSynced(t *testing.T) {
+	if err := db.Init(t.TempDir()); err != nil {
+		t.Fatalf("db init: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	s, h := newTestChangesServer(t)
+	s.ReloadChangelog()
+	embedded := s.changelog.Load().Latest().Version
+
+	prev := s.config.Templates.ListAll()
+	var next []*template.Template
+	for _, tmpl := range prev {
+		if tmpl.Name == "Go" {
+			changed := *tmpl
+			changed.Content += "\n# synced\n"
+			tmpl = &changed
+		}
+		next = append(next, tmpl)
+	}
+	rev := template.NewRevision(prev, next, time.Now().AddDate(1, 0, 0), "abc123")
+	data, err := json.Marshal(rev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.RecordTemplateRevision(db.TemplateRevision{Version: rev.Version, SyncedAt: rev.Date, Data: data}); err != nil {
+		t.Fatal(err)
+	}
+	s.ReloadChangelog()
+
+	rec := doGet(t, h, "/api/v1/changes?since="+embedded)
+	var resp struct {
+		Data struct {
+			Latest    string              `json:"latest"`
+			Revisions []template.Revision `json:"revisions"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("status %d: %v", rec.Code, err)
+	}
+	if resp.Data.Latest != rev.Version || len(resp.Data.Revisions) != 1 || resp.Data.Revisions[0].Upstream != "abc123" {
+		t.Errorf("changes since %s = %+v, want the synced revision %s", embedded, resp.Data, rev.Version)
+	}
+	if body := doGet(t, h, "/api/v1/templates/Go/history").Body.String(); !strings.Contains(body, rev.Version) {
+		t.Errorf("Go history lacks the synced revision: %s", body)
+	}
+	if body := doGet(t, h, "/feeds/templates.atom").Body.String(); !strings.Contains(body, "urn:gitignore:dataset:"+rev.Version) {
+		t.Error("feed lacks the synced revision")
+	}
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/client/api"
+	"github.com/apimgr/gitignore/src/common/i18n"
+)
+
+// TestCLIBackends verifies gitignore-cli's backend detection, the
+// gitignore.io and GitHub backends, and failover between servers.
+func TestCLIBackends(t *testing.T) {
+	s := newTestTemplatesServer(t)
+	tm := s.config.Templates
+
+	native := chi.NewRouter()
+	native.Get("/api/autodiscover", s.handleAPIAutodiscover)
+	native.Get("/api/v1/list", s.handleAPIList)
+	native.Get("/api/v1/categories", s.handleAPICategories)
+	nativeServer := httptest.NewServer(native)
+	defer nativeServer.Close()
+
+	// A gitignore.io clone: only the compatibility routes.
+	compat := chi.NewRouter()
+	compat.Use(i18n.Middleware)
+	compat.Get("/api/list", s.handleCompatList)
+	compat.Get("/api/{list}", s.handleCompatTemplates)
+	compatServer := httptest.NewServer(compat)
+	defer compatServer.Close()
+
+	// GitHub's gitignore API, with its case-sensitive names.
+	github := chi.NewRouter()
+	github.Get("/gitignore/templates", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(tm.List())
+	})
+	github.Get("/gitignore/templates/{name}", func(w http.ResponseWriter, r *http.Request) {
+		name := chi.URLParam(r, "name")
+		for _, tmpl := range tm.ListAll() {
+			if tmpl.Name == name {
+				json.NewEncoder(w).Encode(map[string]string{"name": tmpl.Name, "source": tmpl.Content})
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Not Found"})
+	})
+	githubServer := httptest.NewServer(github)
+	defer githubServer.Close()
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	c := api.New("")
+	for url, want := range map[string]string{
+		nativeServer.URL: api.BackendNative,
+		compatServer.URL: api.BackendGitignoreIO,
+		githubServer.URL: api.BackendGitHub,
+	} {
+		if got, err := c.Detect(url); err != nil || got != want {
+			t.Errorf("detect %s = %q, %v; want %q", url, got, err, want)
+		}
+	}
+	if _, err := c.Detect(down.URL); !api.Unreachable(err) {
+		t.Errorf("detect of a closed server: %v, want a connection error", err)
+	}
+
+	goTemplate, err := tm.Get("Go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kind := range []string{api.BackendGitignoreIO, api.BackendGitHub} {
+		url := compatServer.URL
+		if kind == api.BackendGitHub {
+			url = githubServer.URL
+		}
+		b, err := c.BackendFor(url, kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names, err := b.List()
+		if err != nil || len(names) != tm.Count() {
+			t.Errorf("%s: list returned %d names (%v), want %d", kind, len(names), err, tm.Count())
+		}
+		tmpl, err := b.GetTemplate("go")
+		if err != nil || tmpl.Content != goTemplate.Content {
+			t.Errorf("%s: get go = %v, %v", kind, tmpl, err)
+		}
+		combined, err := b.Combine([]string{"Go", "Node"})
+		if err != nil || !strings.Contains(combined, "### Go ###") || !strings.Contains(combined, "### Node ###") {
+			t.Errorf("%s: combine = %q, %v", kind, combined, err)
+		}
+		for _, names := range [][]string{{"NoSuchTemplate", "Go"}, {"Go", "NoSuchTemplate"}} {
+			var apiErr *api.APIError
+			if _, err := b.Combine(names); !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound {
+				t.Errorf("%s: combine %v = %v, want a 404", kind, names, err)
+			}
+		}
+		var unsupported *api.UnsupportedError
+		if _, err := b.Categories(); !errors.As(err, &unsupported) {
+			t.Errorf("%s: categories = %v, want unsupported", kind, err)
+		}
+	}
+
+	// The primary is down: the client fails over to the detected clone, and
+	// past it to the native server for what the clone cannot do.
+	detected := map[string]string{}
+	fc := api.New(down.URL)
+	fc.Fallbacks = []api.Server{{URL: compatServer.URL, Backend: api.BackendAuto}, {URL: nativeServer.URL}}
+	fc.OnDetect = func(url, kind string) { detected[url] = kind }
+	names, err := fc.Search("python")
+	if err != nil || len(names) == 0 {
+		t.Fatalf("failover search = %v, %v", names, err)
+	}
+	if cats, err := fc.Categories(); err != nil || len(cats) == 0 {
+		t.Errorf("failover categories = %v, %v", cats, err)
+	}
+	if want := map[string]string{compatServer.URL: api.BackendGitignoreIO, nativeServer.URL: api.BackendNative}; !reflect.DeepEqual(detected, want) {
+		t.Errorf("detected = %v, want %v", detected, want)
+	}
+	// With no server offering the command, the connection error wins so the
+	// CLI still falls back to its cache.
+	fc.Fallbacks = fc.Fallbacks[:1]
+	if _, err := fc.Stats(); !api.Unreachable(err) {
+		t.Errorf("stats with no native server = %v, want the primary's connection error", err)
+	}
+	list, err := fc.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := tm.List()
+	for i := range want {
+		want[i] = strings.ToLower(want[i])
+	}
+	sort.Strings(want)
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("failover list differs from the compat keys")
+	}
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/client/api"
+	"github.com/apimgr/gitignore/src/client/cache"
+)
+
+// TestCLIDatasetParity verifies that gitignore-cli answers every command
+// identically from the API, from the embedded dataset and from a cache
+// unpacked from the templates archive.
+func TestCLIDatasetParity(t *testing.T) {
+	s := newTestTemplatesServer(t)
+	tm := s.config.Templates
+	r := chi.NewRouter()
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Get("/list", s.handleAPIList)
+		r.Get("/search", s.handleAPISearch)
+		r.Get("/templates/{name}", s.handleAPITemplate)
+		r.Get("/combine", s.handleAPICombine)
+		r.Get("/categories", s.handleAPICategories)
+		r.Get("/categories/{name}", s.handleAPICategoryTemplates)
+		r.Get("/stats", s.handleAPIStats)
+		r.Get("/templates.tar.gz", s.handleAPITemplatesTarGz)
+	})
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+	c := api.New(ts.URL)
+
+	archive, _, err := c.TemplatesArchive("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cached, err := cache.ParseArchive(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	datasets := map[string]*cache.Dataset{"embedded": cache.NewDataset(tm), "cached": cached}
+
+	sorted := func(names []string, err error) []string {
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(names)
+		return names
+	}
+	for source, d := range datasets {
+		if d.Version() != tm.Version() {
+			t.Errorf("%s: version = %s, want %s", source, d.Version(), tm.Version())
+		}
+		if got, want := sorted(d.List(), nil), sorted(c.List()); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: list differs from the API", source)
+		}
+		for _, q := range []string{"python", "golang", "js", "visual", "global"} {
+			if got, want := d.Search(q), sorted(c.Search(q)); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: search %q = %v, API %v", source, q, got, want)
+			}
+		}
+		if got, want := sorted(d.Categories(), nil), sorted(c.Categories()); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: categories = %v, API %v", source, got, want)
+		}
+		if got, want := sorted(d.CategoryTemplates("Global")), sorted(c.CategoryTemplates("Global")); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: category Global differs from the API", source)
+		}
+		stats, err := d.Stats()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, err := c.Stats(); err != nil || !reflect.DeepEqual(stats, want) {
+			t.Errorf("%s: stats = %v, API %v (%v)", source, stats, want, err)
+		}
+		for _, name := range []string{"Go", "macos", "JupyterNotebooks"} {
+			got, err := d.Get(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, err := c.GetTemplate(name); err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: get %s differs from the API (%v)", source, name, err)
+			}
+		}
+		for _, names := range [][]string{{"Go", "Node", "macOS"}, {" python", "Python ", "VisualStudioCode"}} {
+			got, err := d.Combine(names)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, err := c.Combine(names); err != nil || got != want {
+				t.Errorf("%s: combine %q differs from the API (%v)", source, names, err)
+			}
+		}
+		if _, err := d.Combine([]string{"Go", "NoSuchTemplate"}); err == nil {
+			t.Errorf("%s: combine of an unknown template succeeded", source)
+		}
+	}
+}
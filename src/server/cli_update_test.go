@@ -0,0 +1,210 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/client/api"
+	"github.com/apimgr/gitignore/src/client/cmd"
+	clicfg "github.com/apimgr/gitignore/src/client/config"
+	"github.com/apimgr/gitignore/src/client/output"
+	"github.com/apimgr/gitignore/src/updater"
+)
+
+// fakeReleases serves a GitHub-style release API for gitignore: tag v1.2.0
+// is the server's release and v1.3.0 the latest, each with a gitignore-cli
+// build for the running platform and a checksums.txt.
+func fakeReleases(t *testing.T) (srv *httptest.Server, binaries map[string][]byte) {
+	t.Helper()
+	asset := "gitignore-cli-" + updater.Platform()
+	if runtime.GOOS == "windows" {
+		asset += ".exe"
+	}
+	binaries = map[string][]byte{
+		"v1.2.0": []byte("#!/fake gitignore-cli 1.2.0\n"),
+		"v1.3.0": []byte("#!/fake gitignore-cli 1.3.0\n"),
+	}
+	release := func(tag string) updater.Release {
+		return updater.Release{TagName: tag, Assets: []updater.Asset{
+			{Name: asset, BrowserDownloadURL: srv.URL + "/download/" + tag + "/" + asset},
+			{Name: "gitignore-linux-amd64", BrowserDownloadURL: srv.URL + "/download/" + tag + "/gitignore-linux-amd64"},
+			{Name: "checksums.txt", BrowserDownloadURL: srv.URL + "/download/" + tag + "/checksums.txt"},
+		}}
+	}
+
+	r := chi.NewRouter()
+	r.Get("/repos/apimgr/gitignore/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(release("v1.3.0"))
+	})
+	r.Get("/repos/apimgr/gitignore/releases/tags/{tag}", func(w http.ResponseWriter, r *http.Request) {
+		tag := chi.URLParam(r, "tag")
+		if binaries[tag] == nil {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(release(tag))
+	})
+	r.Get("/download/{tag}/checksums.txt", func(w http.ResponseWriter, r *http.Request) {
+		sum := sha256.Sum256(binaries[chi.URLParam(r, "tag")])
+		fmt.Fprintf(w, "%s  %s\n", hex.EncodeToString(sum[:]), asset)
+	})
+	r.Get("/download/{tag}/"+asset, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(binaries[chi.URLParam(r, "tag")])
+	})
+	srv = httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv, binaries
+}
+
+// TestCLIUpdate verifies gitignore-cli's self-update: the server publishes
+// the gitignore-cli builds of its release in autodiscover's cli_versions,
+// --update installs the server's build once GitHub's release vouches for
+// its checksum, falls back to GitHub when the server publishes none or one
+// GitHub does not list, and the opt-in background check runs at most once a
+// day.
+func TestCLIUpdate(t *testing.T) {
+	releases, binaries := fakeReleases(t)
+
+	u := updater.New(updater.Config{Repo: "apimgr/gitignore", APIBaseURL: releases.URL})
+	rel, err := u.ReleaseByTag(context.Background(), "v1.2.0")
+	if err != nil || rel == nil {
+		t.Fatalf("ReleaseByTag: %v, %v", rel, err)
+	}
+	sums, err := u.Checksums(context.Background(), rel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cliRelease := CLIReleaseFrom(rel, "gitignore-cli", sums)
+	if len(cliRelease.Binaries) != 1 || cliRelease.Version != "1.2.0" {
+		t.Fatalf("CLIReleaseFrom = %+v, want the one gitignore-cli build of 1.2.0", cliRelease)
+	}
+
+	s := newTestTemplatesServer(t)
+	s.config.Version = "1.2.0"
+	r := chi.NewRouter()
+	r.Get("/api/autodiscover", s.handleAPIAutodiscover)
+	r.Get("/api/v1/cli/binaries/{name}", s.handleCLIBinary)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	c := api.New(srv.URL)
+	if versions, err := c.CLIVersions(context.Background()); err != nil || len(versions) != 0 {
+		t.Fatalf("CLIVersions before publishing = %v, %v; want none", versions, err)
+	}
+	s.SetCLIRelease(cliRelease)
+	versions, err := c.CLIVersions(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, ok := versions[updater.Platform()]
+	if !ok || v.Version != "1.2.0" || v.SHA256 != sums[cliRelease.Binaries[updater.Platform()].Asset] {
+		t.Fatalf("CLIVersions = %+v, want 1.2.0 with its checksum", versions)
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	defer func(v string) { api.Version = v }(api.Version)
+	api.Version = "1.0.0"
+	cmd.UpdateAPIBase = releases.URL
+	cmd.UpdateExecutable = filepath.Join(t.TempDir(), "gitignore-cli")
+	defer func() { cmd.UpdateAPIBase, cmd.UpdateExecutable = "", "" }()
+	old := []byte("#!/fake gitignore-cli 1.0.0\n")
+	if err := os.WriteFile(cmd.UpdateExecutable, old, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	installed := func() string {
+		data, _ := os.ReadFile(cmd.UpdateExecutable)
+		return string(data)
+	}
+
+	cfgPath := filepath.Join(t.TempDir(), "cli.yml")
+	cfg := clicfg.Default()
+	p := output.New(false)
+
+	if code := cmd.CmdUpdate(c, p, cfg, cfgPath, []string{"check"}); code != output.ExitSuccess || installed() != string(old) {
+		t.Fatalf("--update check: exit %d, binary %q; want it untouched", code, installed())
+	}
+
+	// A build whose checksum GitHub's release does not list is ignored, even
+	// when the server serves a binary matching it.
+	forged := *cliRelease
+	forged.Binaries = map[string]CLIBinary{}
+	for platform, b := range cliRelease.Binaries {
+		sum := sha256.Sum256(old)
+		b.SHA256 = hex.EncodeToString(sum[:])
+		forged.Binaries[platform] = b
+	}
+	s.SetCLIRelease(&forged)
+	if code := cmd.CmdUpdate(c, p, cfg, cfgPath, []string{"yes"}); code != output.ExitSuccess || installed() != string(binaries["v1.3.0"]) {
+		t.Fatalf("--update yes with a forged checksum: exit %d, binary %q; want GitHub's 1.3.0", code, installed())
+	}
+	if err := os.WriteFile(cmd.UpdateExecutable, old, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// A binary that does not match GitHub's checksum is refused.
+	swapped := *cliRelease
+	swapped.Binaries = map[string]CLIBinary{}
+	for platform, b := range cliRelease.Binaries {
+		b.URL = releases.URL + "/download/v1.3.0/" + b.Asset
+		swapped.Binaries[platform] = b
+	}
+	s.SetCLIRelease(&swapped)
+	if code := cmd.CmdUpdate(c, p, cfg, cfgPath, []string{"yes"}); code == output.ExitSuccess || installed() != string(old) {
+		t.Fatalf("--update yes with a swapped binary: exit %d, binary %q; want a refusal", code, installed())
+	}
+
+	s.SetCLIRelease(cliRelease)
+	if code := cmd.CmdUpdate(c, p, cfg, cfgPath, nil); code != output.ExitSuccess || installed() != string(binaries["v1.2.0"]) {
+		t.Fatalf("--update: exit %d, binary %q; want the server's 1.2.0 build", code, installed())
+	}
+
+	// A server that publishes nothing leaves the update to GitHub.
+	s.SetCLIRelease(nil)
+	if code := cmd.CmdUpdate(c, p, cfg, cfgPath, []string{"yes"}); code != output.ExitSuccess || installed() != string(binaries["v1.3.0"]) {
+		t.Fatalf("--update yes without cli_versions: exit %d, binary %q; want GitHub's 1.3.0", code, installed())
+	}
+
+	if code := cmd.CmdUpdate(c, p, cfg, cfgPath, []string{"branch", "nightly"}); code != output.ExitUsage {
+		t.Errorf("--update branch nightly: exit %d, want %d", code, output.ExitUsage)
+	}
+	if code := cmd.CmdUpdate(c, p, cfg, cfgPath, []string{"branch", "beta"}); code != output.ExitSuccess {
+		t.Fatalf("--update branch beta: exit %d", code)
+	}
+	if saved, _, err := clicfg.Load(cfgPath); err != nil || saved.Update.Channel != "beta" {
+		t.Fatalf("saved channel: %+v, %v; want beta", saved, err)
+	}
+
+	// The background check installs when update.auto is yes, then waits a
+	// day before checking again.
+	if err := os.WriteFile(cmd.UpdateExecutable, old, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg = clicfg.Default()
+	cmd.StartUpdateCheck(c, p, cfg)()
+	if installed() != string(old) {
+		t.Fatal("background check ran with update.auto off")
+	}
+	cfg.Update.Auto = "yes"
+	cmd.StartUpdateCheck(c, p, cfg)()
+	if installed() != string(binaries["v1.3.0"]) {
+		t.Fatalf("background install: binary %q, want GitHub's 1.3.0", installed())
+	}
+	if err := os.WriteFile(cmd.UpdateExecutable, old, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cmd.StartUpdateCheck(c, p, cfg)()
+	if installed() != string(old) {
+		t.Error("background check ran twice in a day")
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/updater"
+)
+
+// CLI self-update (AI.md PART 32 "CLI Auto-Update"): the server publishes the
+// gitignore-cli builds of its own release in /api/autodiscover as
+// cli_versions, and hands out each binary at /api/v1/cli/binaries/{asset}, so
+// clients update to the version matching the server they talk to.
+
+// CLIBinary is one platform's gitignore-cli build.
+type CLIBinary struct {
+	// Asset is the release asset name, gitignore-cli-{os}-{arch}[.exe].
+	Asset  string
+	SHA256 string
+	// URL is where the release hosts the asset.
+	URL string
+}
+
+// CLIRelease is the gitignore-cli release published alongside this server.
+type CLIRelease struct {
+	Version string
+	// Binaries maps "{os}-{arch}" to that platform's build.
+	Binaries map[string]CLIBinary
+}
+
+// CLIReleaseFrom picks the assets named {cliName}-{os}-{arch}[.exe] out of
+// rel. sums maps asset names to their SHA256; assets without one are left
+// out, since clients refuse to install what they cannot verify.
+func CLIReleaseFrom(rel *updater.Release, cliName string, sums map[string]string) *CLIRelease {
+	out := &CLIRelease{
+		Version:  strings.TrimPrefix(rel.TagName, "v"),
+		Binaries: make(map[string]CLIBinary),
+	}
+	for _, asset := range rel.Assets {
+		platform, ok := strings.CutPrefix(strings.TrimSuffix(asset.Name, ".exe"), cliName+"-")
+		if !ok || strings.Count(platform, "-") != 1 || sums[asset.Name] == "" {
+			continue
+		}
+		out.Binaries[platform] = CLIBinary{Asset: asset.Name, SHA256: sums[asset.Name], URL: asset.BrowserDownloadURL}
+	}
+	return out
+}
+
+// SetCLIRelease publishes rel in cli_versions; nil withdraws it.
+func (s *Server) SetCLIRelease(rel *CLIRelease) {
+	s.cliRelease.Store(rel)
+}
+
+// cliVersions returns the autodiscover cli_versions entries, or nil when no
+// release is published.
+func (s *Server) cliVersions() map[string]interface{} {
+	rel := s.cliRelease.Load()
+	if rel == nil || len(rel.Binaries) == 0 {
+		return nil
+	}
+	versions := make(map[string]interface{}, len(rel.Binaries))
+	for platform, b := range rel.Binaries {
+		versions[platform] = map[string]string{
+			"version": rel.Version,
+			"sha256":  b.SHA256,
+			"url":     apiBasePath() + "/cli/binaries/" + b.Asset,
+		}
+	}
+	return versions
+}
+
+// handleCLIBinary redirects to the release asset of a published
+// gitignore-cli build. Downloads stay unauthenticated: the CLI is how new
+// users get started.
+func (s *Server) handleCLIBinary(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if rel := s.cliRelease.Load(); rel != nil {
+		for _, b := range rel.Binaries {
+			if b.Asset == name {
+				http.Redirect(w, r, b.URL, http.StatusFound)
+				return
+			}
+		}
+	}
+	sendAPIResponseErrorLocalized(w, r, "NOT_FOUND", "CLI binary not found")
+}
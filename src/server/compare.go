@@ -0,0 +1,139 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// compareStatuses are the row statuses in display order, used for the
+// comparison page's filter links.
+var compareStatuses = []string{template.StatusShared, template.StatusPartial, template.StatusUnique, template.StatusConflict}
+
+// compareFilter is one of the comparison page's status filter links.
+type compareFilter struct {
+	Status string
+	Count  int
+	URL    string
+	Active bool
+}
+
+// compareExportURLs returns the union and intersection download URLs.
+func compareExportURLs(names []string) (union, intersection string) {
+	base := apiBasePath() + "/compare.txt?templates=" + strings.Join(escapeAll(names), ",")
+	return base + "&set=union", base + "&set=intersection"
+}
+
+// handleComparePage serves /compare: a matrix of the normalized rules of
+// ?templates= against those templates, filterable by ?show=status.
+func (s *Server) handleComparePage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	names := parseTemplateList(q.Get("templates"))
+	known, unknown := s.resolveTemplates(names)
+	show := q.Get("show")
+	if !slices.Contains(compareStatuses, show) {
+		show = ""
+	}
+
+	data := map[string]interface{}{
+		"templates": strings.Join(names, ","),
+		"notices":   templateNotices("/compare", names, unknown),
+		"names":     sortedNames(s.config.Templates.List()),
+		"show":      show,
+	}
+	if len(known) >= 2 {
+		c, err := s.config.Templates.Compare(known)
+		if err != nil {
+			s.renderErrorPage(w, r, http.StatusInternalServerError, "The templates could not be compared.")
+			return
+		}
+		rows := c.Rules
+		if show != "" {
+			rows = make([]template.CompareRow, 0, c.Counts[show])
+			for _, row := range c.Rules {
+				if row.Status == show {
+					rows = append(rows, row)
+				}
+			}
+		}
+		page := templatesPageURL("/compare", known)
+		filters := []compareFilter{{Status: "all", Count: len(c.Rules), URL: page, Active: show == ""}}
+		for _, status := range compareStatuses {
+			filters = append(filters, compareFilter{Status: status, Count: c.Counts[status], URL: page + "&show=" + status, Active: show == status})
+		}
+		union, intersection := compareExportURLs(known)
+		data["comparison"] = c
+		data["rows"] = rows
+		data["filters"] = filters
+		data["union_url"] = union
+		data["intersection_url"] = intersection
+		data["composer_url"] = composerURL(known)
+	}
+	s.renderPage(w, r, "compare", PageData{Title: "Compare", Data: data})
+}
+
+// handleAPICompare returns the rule matrix for ?templates= with each row's
+// per-template sense and status. Like /compose it reports unknown names with
+// suggestions instead of failing.
+func (s *Server) handleAPICompare(w http.ResponseWriter, r *http.Request) {
+	names := parseTemplateList(r.URL.Query().Get("templates"))
+	if len(names) < 2 {
+		sendAPIResponseError(w, "BAD_REQUEST", "query parameter 'templates' needs at least two names")
+		return
+	}
+	known, unknown := s.resolveTemplates(names)
+	c := &template.Comparison{Templates: []string{}, Rules: []template.CompareRow{}, Counts: map[string]int{}}
+	if len(known) > 0 {
+		var err error
+		if c, err = s.config.Templates.Compare(known); err != nil {
+			sendAPIResponseError(w, "SERVER_ERROR", "failed to compare templates")
+			return
+		}
+	}
+	if unknown == nil {
+		unknown = []unknownTemplate{}
+	}
+	union, intersection := compareExportURLs(known)
+	sendAPIResponseOK(w, map[string]interface{}{
+		"templates":        c.Templates,
+		"rules":            c.Rules,
+		"counts":           c.Counts,
+		"unknown":          unknown,
+		"union_url":        union,
+		"intersection_url": intersection,
+	})
+}
+
+// handleAPICompareText exports a comparison as a .gitignore: ?set=union
+// (the default) holds every rule, ?set=intersection only the shared ones.
+func (s *Server) handleAPICompareText(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	names := parseTemplateList(q.Get("templates"))
+	if len(names) == 0 {
+		sendAPIResponseError(w, "BAD_REQUEST", "query parameter 'templates' is required")
+		return
+	}
+	set := q.Get("set")
+	if set != "" && set != "union" && set != "intersection" {
+		sendAPIResponseError(w, "BAD_REQUEST", "set must be union or intersection")
+		return
+	}
+	c, err := s.config.Templates.Compare(names)
+	if err != nil {
+		sendAPIResponseError(w, "NOT_FOUND", err.Error())
+		return
+	}
+	out := c.Union()
+	if set == "intersection" {
+		out = c.Intersection()
+	}
+	if q.Has("download") {
+		w.Header().Set("Content-Disposition", `attachment; filename=".gitignore"`)
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	setCacheHeaders(w, "api")
+	_, _ = io.WriteString(w, out)
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/template"
+)
+
+func TestCompare(t *testing.T) {
+	s := newTestTemplatesServer(t)
+	r := chi.NewRouter()
+	r.Get("/compare", s.handleComparePage)
+	r.Get("/api/v1/compare", s.handleAPICompare)
+	r.Get("/api/v1/compare.txt", s.handleAPICompareText)
+
+	rec := doGet(t, r, "/api/v1/compare?templates=Python,community/Python/JupyterNotebooks,Jupyter")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("compare = %d: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Data struct {
+			Templates []string              `json:"templates"`
+			Rules     []template.CompareRow `json:"rules"`
+			Counts    map[string]int        `json:"counts"`
+			Unknown   []unknownTemplate     `json:"unknown"`
+			UnionURL  string                `json:"union_url"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	d := resp.Data
+	if strings.Join(d.Templates, ",") != "Python,JupyterNotebooks" {
+		t.Errorf("templates = %v", d.Templates)
+	}
+	if len(d.Unknown) != 1 || d.Unknown[0].Name != "Jupyter" {
+		t.Errorf("unknown = %+v", d.Unknown)
+	}
+	sharedCheckpoints := false
+	for _, row := range d.Rules {
+		if row.Pattern == ".ipynb_checkpoints" && row.Status == template.StatusShared {
+			sharedCheckpoints = true
+		}
+	}
+	if !sharedCheckpoints || d.Counts[template.StatusUnique] == 0 || len(d.Rules) != d.Counts["shared"]+d.Counts["partial"]+d.Counts["unique"]+d.Counts["conflict"] {
+		t.Errorf("counts = %v (shared .ipynb_checkpoints: %v)", d.Counts, sharedCheckpoints)
+	}
+	if d.UnionURL != "/api/v1/compare.txt?templates=Python,JupyterNotebooks&set=union" {
+		t.Errorf("union_url = %q", d.UnionURL)
+	}
+	if doGet(t, r, "/api/v1/compare?templates=Go").Code != http.StatusBadRequest {
+		t.Error("single-template compare accepted")
+	}
+
+	union := doGet(t, r, "/api/v1/compare.txt?templates=Python,JupyterNotebooks").Body.String()
+	inter := doGet(t, r, "/api/v1/compare.txt?templates=Python,JupyterNotebooks&set=intersection").Body.String()
+	if !strings.HasPrefix(union, "# Union of: Python, JupyterNotebooks\n") || !strings.Contains(inter, "\n.ipynb_checkpoints\n") || len(inter) >= len(union) {
+		t.Errorf("union/intersection exports:\n%s\n---\n%s", union, inter)
+	}
+	if doGet(t, r, "/api/v1/compare.txt?templates=Python,Nope").Code != http.StatusNotFound {
+		t.Error("export with an unknown template did not 404")
+	}
+
+	body := doGet(t, r, "/compare?templates=Python,JupyterNotebooks,pyhton&show=shared").Body.String()
+	for _, want := range []string{
+		`<th scope="col"><a href="/template/JupyterNotebooks">JupyterNotebooks</a></th>`,
+		`<tr class="compare-shared">`,
+		`href="/compare?templates=Python,JupyterNotebooks&amp;show=shared" class="compare-filter compare-shared" aria-current="true"`,
+		"No template named “pyhton”.",
+		`/api/v1/compare.txt?templates=Python,JupyterNotebooks&amp;set=intersection&amp;download=1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("compare page missing %q", want)
+		}
+	}
+	if strings.Contains(body, `<tr class="compare-unique">`) {
+		t.Error("show=shared still lists unique rules")
+	}
+}
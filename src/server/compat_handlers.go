@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/common/i18n"
 )
 
 // gitignoreIOEntry matches gitignore.io's raw JSON list entry shape exactly:
@@ -23,6 +25,7 @@ type gitignoreIOEntry struct {
 // format=lines (default): text/plain, comma-separated sorted keys.
 // format=json: application/json, flat object keyed by lowercase template key.
 func (s *Server) handleCompatList(w http.ResponseWriter, r *http.Request) {
+	s.observeCompat("list")
 	all := s.config.Templates.ListAll()
 
 	keys := make([]string, 0, len(all))
@@ -56,8 +59,9 @@ func (s *Server) handleCompatList(w http.ResponseWriter, r *http.Request) {
 // handleCompatTemplates implements gitignore.io's GET /api/{name1,name2,...} route.
 // Resolved names render as "### {Name} ###\n{contents}" blocks; unresolved names
 // render as "#!! ERROR: {name} is undefined. Use list command to see defined
-// gitignore types !!#" blocks. Status is 404 if the first requested name fails
-// to resolve, 200 otherwise, matching the live gitignore.io service.
+// gitignore types !!#" blocks, with the message between the markers in the
+// request language. Status is 404 if the first requested name fails to
+// resolve, 200 otherwise, matching the live gitignore.io service.
 func (s *Server) handleCompatTemplates(w http.ResponseWriter, r *http.Request) {
 	list := chi.URLParam(r, "list")
 
@@ -65,6 +69,8 @@ func (s *Server) handleCompatTemplates(w http.ResponseWriter, r *http.Request) {
 	for i, name := range names {
 		names[i] = strings.TrimSpace(name)
 	}
+	s.observeCompat("templates")
+	s.recordUsage(sourceCompat, names)
 
 	serverURL := s.detectServerURL(r)
 	header := fmt.Sprintf("# Created by %s/api/%s", serverURL, list)
@@ -86,7 +92,7 @@ func (s *Server) handleCompatTemplates(w http.ResponseWriter, r *http.Request) {
 				firstOK = false
 				firstResolved = false
 			}
-			fmt.Fprintf(&body, "#!! ERROR: %s is undefined. Use list command to see defined gitignore types !!#\n\n", name)
+			fmt.Fprintf(&body, "#!! ERROR: %s !!#\n\n", i18n.TF(r, "compat.undefined", "name", name))
 			continue
 		}
 		if firstResolved {
@@ -104,6 +110,8 @@ func (s *Server) handleCompatTemplates(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	// Error lines are localized.
+	w.Header().Add("Vary", "Accept-Language")
 	w.WriteHeader(status)
 	fmt.Fprint(w, body.String())
 }
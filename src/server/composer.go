@@ -0,0 +1,359 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/apimgr/gitignore/src/common/i18n"
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// unknownTemplate is a requested name that did not resolve, with the closest
+// real template names as "did you mean" hints.
+type unknownTemplate struct {
+	Name        string   `json:"name"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// composerChip is one selected template on the /combine page. The move and
+// remove links rewrite ?templates= so ordering works without JavaScript.
+type composerChip struct {
+	Name        string
+	Known       bool
+	Suggestions []string
+	UpURL       string
+	DownURL     string
+	RemoveURL   string
+}
+
+// composerNotice reports an unknown name on the /combine page. Each suggestion
+// links to the same selection with the unknown name replaced by it.
+type composerNotice struct {
+	Name        string
+	Suggestions []composerLink
+}
+
+// composerLink is a named link.
+type composerLink struct {
+	Name string
+	URL  string
+}
+
+// composerCategory is one <details> group of the template picker.
+type composerCategory struct {
+	Name string
+	// Label is the category name in the request language.
+	Label     string
+	Templates []composerOption
+}
+
+// composerOption is one picker checkbox.
+type composerOption struct {
+	Name     string
+	Selected bool
+}
+
+// parseTemplateList splits a comma-separated template list, dropping blanks and
+// repeats (case-insensitively) while keeping the first occurrence's order.
+func parseTemplateList(param string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// composerSelection reads the page's selection from the query. ?templates=
+// carries the ordered list; a picker submission (?picker=1) keeps the listed
+// names still checked (plus unknown names, which have no checkbox) and appends
+// newly checked ones; ?add= appends typed names. Known names come back in
+// their canonical case.
+func (s *Server) composerSelection(q url.Values) []string {
+	names := parseTemplateList(q.Get("templates"))
+	if q.Get("picker") == "1" {
+		checked := make(map[string]bool)
+		for _, name := range q["t"] {
+			checked[strings.ToLower(name)] = true
+		}
+		var kept []string
+		for _, name := range names {
+			key := strings.ToLower(name)
+			if _, err := s.config.Templates.Get(name); err != nil || checked[key] {
+				kept = append(kept, name)
+				delete(checked, key)
+			}
+		}
+		for _, name := range q["t"] {
+			if checked[strings.ToLower(name)] {
+				kept = append(kept, name)
+			}
+		}
+		names = kept
+	}
+	if add := q.Get("add"); add != "" {
+		names = append(names, strings.Split(add, ",")...)
+	}
+	names = parseTemplateList(strings.Join(names, ","))
+	for i, name := range names {
+		if tmpl, err := s.config.Templates.Get(name); err == nil {
+			names[i] = tmpl.Name
+		}
+	}
+	return names
+}
+
+// resolveTemplates maps names to canonical template names, collecting the
+// names that do not resolve along with suggestions for each.
+func (s *Server) resolveTemplates(names []string) (known []string, unknown []unknownTemplate) {
+	seen := make(map[string]bool)
+	for _, name := range names {
+		tmpl, err := s.config.Templates.Get(name)
+		if err != nil {
+			unknown = append(unknown, unknownTemplate{Name: name, Suggestions: s.config.Templates.Suggest(name, 3)})
+			continue
+		}
+		if !seen[tmpl.Name] {
+			seen[tmpl.Name] = true
+			known = append(known, tmpl.Name)
+		}
+	}
+	return known, unknown
+}
+
+// composerURL is the /combine page URL for names, with commas left readable.
+func composerURL(names []string) string {
+	return templatesPageURL("/combine", names)
+}
+
+// templatesPageURL is page with ?templates= set to names.
+func templatesPageURL(page string, names []string) string {
+	if len(names) == 0 {
+		return page
+	}
+	return page + "?templates=" + strings.Join(escapeAll(names), ",")
+}
+
+// composerChips builds the chip list for names in order, with the links that
+// move each one up, down or out of the list.
+func composerChips(names []string, unknown []unknownTemplate) []composerChip {
+	missing := make(map[string][]string, len(unknown))
+	for _, u := range unknown {
+		missing[u.Name] = u.Suggestions
+	}
+	moved := func(i, j int) string {
+		out := append([]string(nil), names...)
+		out[i], out[j] = out[j], out[i]
+		return composerURL(out)
+	}
+	chips := make([]composerChip, len(names))
+	for i, name := range names {
+		suggestions, isMissing := missing[name]
+		chip := composerChip{Name: name, Known: !isMissing, Suggestions: suggestions}
+		if i > 0 {
+			chip.UpURL = moved(i, i-1)
+		}
+		if i < len(names)-1 {
+			chip.DownURL = moved(i, i+1)
+		}
+		rest := append(append([]string(nil), names[:i]...), names[i+1:]...)
+		chip.RemoveURL = composerURL(rest)
+		chips[i] = chip
+	}
+	return chips
+}
+
+// composerNotices explains each unknown name in names.
+func composerNotices(names []string, unknown []unknownTemplate) []composerNotice {
+	return templateNotices("/combine", names, unknown)
+}
+
+// templateNotices builds the "no template named" notices for a page taking
+// ?templates=, each suggestion linking to page with the name replaced.
+func templateNotices(page string, names []string, unknown []unknownTemplate) []composerNotice {
+	notices := make([]composerNotice, 0, len(unknown))
+	for _, u := range unknown {
+		notice := composerNotice{Name: u.Name}
+		for _, suggestion := range u.Suggestions {
+			replaced := make([]string, 0, len(names))
+			for _, name := range names {
+				if name == u.Name {
+					name = suggestion
+				}
+				replaced = append(replaced, name)
+			}
+			notice.Suggestions = append(notice.Suggestions, composerLink{
+				Name: suggestion,
+				URL:  templatesPageURL(page, parseTemplateList(strings.Join(replaced, ","))),
+			})
+		}
+		notices = append(notices, notice)
+	}
+	return notices
+}
+
+// composerCategories groups every template for the picker, labeled in lang
+// and marking selected
+// ones. Root comes first, the rest alphabetically.
+func (s *Server) composerCategories(lang string, selected []string) []composerCategory {
+	chosen := make(map[string]bool, len(selected))
+	for _, name := range selected {
+		chosen[name] = true
+	}
+	cats := s.config.Templates.GetCategories()
+	sort.Slice(cats, func(i, j int) bool {
+		if (cats[i] == "Root") != (cats[j] == "Root") {
+			return cats[i] == "Root"
+		}
+		return cats[i] < cats[j]
+	})
+	out := make([]composerCategory, 0, len(cats))
+	for _, cat := range cats {
+		templates := s.config.Templates.GetByCategory(cat)
+		opts := make([]composerOption, len(templates))
+		for i, t := range templates {
+			opts[i] = composerOption{Name: t.Name, Selected: chosen[t.Name]}
+		}
+		sort.Slice(opts, func(i, j int) bool {
+			return strings.ToLower(opts[i].Name) < strings.ToLower(opts[j].Name)
+		})
+		out = append(out, composerCategory{Name: cat, Label: i18n.CategoryName(lang, cat), Templates: opts})
+	}
+	return out
+}
+
+// composerOneLiners returns ready-to-paste commands that fetch the same
+// combined file: curl against the native API, the CLI, and the
+// gitignore.io-compatible URL; plus the README badge for the selection.
+func composerOneLiners(base string, names []string) map[string]string {
+	lower := make([]string, len(names))
+	for i, name := range names {
+		lower[i] = url.PathEscape(strings.ToLower(name))
+	}
+	return map[string]string{
+		"curl":   "curl -sL '" + base + apiBasePath() + "/combine.txt?templates=" + strings.Join(escapeAll(names), ",") + "' -o .gitignore",
+		"cli":    "gitignore-cli " + strings.Join(names, " ") + " > .gitignore",
+		"compat": base + "/api/" + strings.Join(lower, ","),
+		"badge":  badgeMarkdown(base, names),
+	}
+}
+
+// handleCombinePage serves the template composer. Everything works as plain
+// links and GET forms; app.js enhances the same markup with live preview,
+// drag-to-reorder and autocomplete.
+func (s *Server) handleCombinePage(w http.ResponseWriter, r *http.Request) {
+	names := s.composerSelection(r.URL.Query())
+	known, unknown := s.resolveTemplates(names)
+	base := s.detectServerURL(r)
+
+	data := map[string]interface{}{
+		"templates":  strings.Join(names, ","),
+		"chips":      composerChips(names, unknown),
+		"notices":    composerNotices(names, unknown),
+		"api":        apiBasePath(),
+		"categories": s.composerCategories(i18n.LangFromContext(r.Context()), known),
+		"names":      sortedNames(s.config.Templates.List()),
+		// The result section is always rendered (hidden when empty) so
+		// app.js can fill it in without a reload.
+		"oneliners":    composerOneLiners(base, known),
+		"download_url": apiBasePath() + "/combine.txt?templates=" + strings.Join(escapeAll(known), ",") + "&download=1",
+		"summary":      composerSummary(r, 0, 0, 0),
+	}
+	if len(known) > 0 {
+		comp, err := s.config.Templates.Compose(known)
+		if err != nil {
+			s.renderErrorPage(w, r, http.StatusInternalServerError, "The templates could not be combined.")
+			return
+		}
+		data["sections"] = comp.Sections
+		if len(known) >= 2 {
+			data["compare_url"] = templatesPageURL("/compare", known)
+		}
+		data["summary"] = composerSummary(r, len(known), comp.Duplicates, comp.Conflicts)
+	}
+	plain, pinned, err := s.newPermalinkIDs(known)
+	if err != nil {
+		s.renderErrorPage(w, r, http.StatusInternalServerError, "The templates could not be combined.")
+		return
+	}
+	data["permalink"] = permalinkPath(plain)
+	data["pinned_permalink"] = permalinkPath(pinned)
+	data["dataset"] = s.config.Templates.Version()
+	s.renderPage(w, r, "combine", PageData{Title: "Combine", BaseURL: base, Data: data})
+}
+
+// handleAPICompose returns the structured combine of ?templates= for the
+// composer's live preview: per-template sections with duplicate and conflict
+// annotations, the rendered file, permalinks, and suggestions for unknown
+// names. Unlike /combine it tolerates unknown names and does not count toward
+// usage analytics, since the preview refreshes on every edit.
+func (s *Server) handleAPICompose(w http.ResponseWriter, r *http.Request) {
+	names := parseTemplateList(r.URL.Query().Get("templates"))
+	if len(names) == 0 {
+		sendAPIResponseError(w, "BAD_REQUEST", "query parameter 'templates' is required")
+		return
+	}
+	known, unknown := s.resolveTemplates(names)
+	comp := &template.Composition{Templates: []string{}}
+	content := ""
+	if len(known) > 0 {
+		var err error
+		if comp, err = s.config.Templates.Compose(known); err != nil {
+			sendAPIResponseError(w, "SERVER_ERROR", "failed to combine templates")
+			return
+		}
+		content = comp.Render(known)
+	}
+	if unknown == nil {
+		unknown = []unknownTemplate{}
+	}
+	plain, pinned, err := s.newPermalinkIDs(known)
+	if err != nil {
+		sendAPIResponseError(w, "SERVER_ERROR", "failed to encode permalink")
+		return
+	}
+	sendAPIResponseOK(w, map[string]interface{}{
+		"templates":        comp.Templates,
+		"sections":         comp.Sections,
+		"duplicates":       comp.Duplicates,
+		"conflicts":        comp.Conflicts,
+		"summary":          composerSummary(r, len(comp.Templates), comp.Duplicates, comp.Conflicts),
+		"unknown":          unknown,
+		"content":          content,
+		"permalink":        permalinkPath(plain),
+		"pinned_permalink": permalinkPath(pinned),
+	})
+}
+
+// composerSummary is the line under the composer result, in the request
+// language: "2 templates · 3 duplicate lines removed · 0 conflicts".
+func composerSummary(r *http.Request, templates, duplicates, conflicts int) string {
+	return i18n.TP(r, "plurals.templates", templates) + " · " +
+		i18n.TP(r, "plurals.duplicate_lines", duplicates) + " · " +
+		i18n.TP(r, "plurals.conflicts", conflicts)
+}
+
+// escapeAll query-escapes each name.
+func escapeAll(names []string) []string {
+	out := make([]string, len(names))
+	for i, name := range names {
+		out[i] = url.QueryEscape(name)
+	}
+	return out
+}
+
+// sortedNames returns names sorted case-insensitively.
+func sortedNames(names []string) []string {
+	sort.Slice(names, func(i, j int) bool {
+		return strings.ToLower(names[i]) < strings.ToLower(names[j])
+	})
+	return names
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/template"
+)
+
+func newTestComposerServer(t *testing.T) (*Server, http.Handler) {
+	t.Helper()
+	s := newTestTemplatesServer(t)
+	r := chi.NewRouter()
+	r.Get("/combine", s.handleCombinePage)
+	r.Get("/api/v1/compose", s.handleAPICompose)
+	r.Get("/api/v1/combine.txt", s.handleAPICombineText)
+	return s, r
+}
+
+func TestComposerSelection(t *testing.T) {
+	s, _ := newTestComposerServer(t)
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"templates=go,,node,GO", []string{"Go", "Node"}},
+		{"templates=Go,Node&add=python,%20rust", []string{"Go", "Node", "Python", "Rust"}},
+		// A picker submission drops unchecked names, keeps unknown ones and
+		// appends new picks after the existing order.
+		{"templates=Node,Go,pyhton&picker=1&t=Go&t=Node&t=macOS", []string{"Node", "Go", "pyhton", "macOS"}},
+	}
+	for _, c := range cases {
+		q, _ := url.ParseQuery(c.query)
+		if got := s.composerSelection(q); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %v, want %v", c.query, got, c.want)
+		}
+	}
+}
+
+func TestComposerPage(t *testing.T) {
+	_, h := newTestComposerServer(t)
+	rec := doGet(t, h, "/combine?templates=Go,macOS,pyhton")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`No template named “pyhton”`,
+		`href="/combine?templates=Go,macOS,Python"`,
+		`<a href="/combine?templates=macOS,Go,pyhton" class="chip-action" aria-label="Move macOS up">`,
+		`<summary>Go <span class="hint">Root</span></summary>`,
+		`value="Go" checked`,
+		`/api/v1/combine.txt?templates=Go,macOS&amp;download=1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+	if strings.Contains(body, "template not found") {
+		t.Error("page shows a raw error")
+	}
+
+	empty := doGet(t, h, "/combine").Body.String()
+	if !strings.Contains(empty, "Nothing selected yet.") || strings.Contains(empty, "no value") {
+		t.Error("empty composer rendered incorrectly")
+	}
+}
+
+func TestAPICompose(t *testing.T) {
+	_, h := newTestComposerServer(t)
+	rec := doGet(t, h, "/api/v1/compose?templates=macos,Global/nope,Node")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var resp struct {
+		Data struct {
+			Templates []string                   `json:"templates"`
+			Sections  []template.ComposedSection `json:"sections"`
+			Unknown   []unknownTemplate          `json:"unknown"`
+			Content   string                     `json:"content"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	d := resp.Data
+	if !reflect.DeepEqual(d.Templates, []string{"macOS", "Node"}) || len(d.Sections) != 2 {
+		t.Errorf("templates = %v, sections = %d", d.Templates, len(d.Sections))
+	}
+	if len(d.Unknown) != 1 || d.Unknown[0].Name != "Global/nope" {
+		t.Errorf("unknown = %+v", d.Unknown)
+	}
+	if !strings.HasPrefix(d.Content, "# Combined .gitignore") {
+		t.Errorf("content = %.40q", d.Content)
+	}
+
+	if rec := doGet(t, h, "/api/v1/compose"); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing templates: status = %d", rec.Code)
+	}
+}
+
+func TestCombineDownload(t *testing.T) {
+	_, h := newTestComposerServer(t)
+	rec := doGet(t, h, "/api/v1/combine.txt?templates=Go&download=1")
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename=".gitignore"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+}
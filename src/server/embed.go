@@ -0,0 +1,200 @@
+package server
+
+import (
+	"encoding/json"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/common/i18n"
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// embedCSP is the policy for /embed/*: any page may frame it, and since the
+// view is static markup it runs no scripts, makes no requests and submits no
+// forms.
+var embedCSP = map[string]string{
+	"default-src":     "'none'",
+	"script-src":      "'none'",
+	"connect-src":     "'none'",
+	"worker-src":      "'none'",
+	"manifest-src":    "'none'",
+	"frame-src":       "'none'",
+	"frame-ancestors": "*",
+	"form-action":     "'none'",
+}
+
+// Embed frame sizing for oEmbed responses: a header plus one row per line,
+// clamped to the consumer's maxwidth/maxheight.
+const (
+	embedWidth      = 640
+	embedMaxHeight  = 480
+	embedHeaderPx   = 48
+	embedLinePx     = 20
+	embedCacheAge   = 86400
+	oembedThumbSize = 1200
+)
+
+// embedLineRange parses ?lines= ("12" or "12-20") into a 1-based inclusive
+// range. ok is false when v is empty or malformed.
+func embedLineRange(v string) (from, to int, ok bool) {
+	if v == "" {
+		return 0, 0, false
+	}
+	a, b, found := strings.Cut(v, "-")
+	from, err := strconv.Atoi(a)
+	if err != nil || from < 1 {
+		return 0, 0, false
+	}
+	to = from
+	if found {
+		if to, err = strconv.Atoi(b); err != nil || to < from {
+			return 0, 0, false
+		}
+	}
+	return from, to, true
+}
+
+// handleEmbed serves /embed/{name}: the highlighted template without site
+// chrome, for other sites to frame. The theme comes from ?theme= since
+// third-party frames rarely get the theme cookie, and ?lines=12-20 shows a
+// slice of the file. Links open in a new tab rather than inside the frame.
+func (s *Server) handleEmbed(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	q := r.URL.Query()
+	theme := q.Get("theme")
+	if !validThemes[theme] {
+		theme = themeFromRequest(r)
+	}
+	tmpl, err := s.config.Templates.Get(name)
+	if err != nil {
+		s.renderPageStatus(w, r, "embed", http.StatusNotFound, PageData{
+			Title: "Template not found",
+			Theme: theme,
+			Data:  map[string]interface{}{"missing": name, "site_url": s.siteURL(r)},
+		})
+		return
+	}
+
+	lang := i18n.LangFromContext(r.Context())
+	lines := template.Highlight(tmpl.Content)
+	base := s.siteURL(r)
+	page := base + "/template/" + url.PathEscape(tmpl.Name)
+	data := map[string]interface{}{
+		"name":     tmpl.Name,
+		"category": i18n.CategoryName(lang, tmpl.Category),
+		"lines":    lines,
+		"page_url": page,
+		"raw_url":  base + apiBasePath() + "/templates/" + url.PathEscape(tmpl.Name) + ".txt",
+		"site_url": base,
+	}
+	if from, to, ok := embedLineRange(q.Get("lines")); ok && from <= len(lines) {
+		shown := lines[from-1 : min(to, len(lines))]
+		data["lines"] = shown
+		if len(shown) < len(lines) {
+			data["shown"] = i18n.TF(r, "pages.lines_shown", "shown", i18n.FormatNumber(lang, len(shown)), "lines", i18n.TP(r, "plurals.lines", len(lines)))
+		}
+	}
+	s.renderPage(w, r, "embed", PageData{
+		Title:       tmpl.Name,
+		Description: templateDescription(lang, tmpl),
+		Canonical:   page,
+		Theme:       theme,
+		Data:        data,
+	})
+}
+
+// oembedTarget resolves a site URL to the templates it shows: one template
+// for /template/{name}, the selection for /combine?templates= and /c/{id}.
+// ok is false for URLs on other hosts or pages that have nothing to embed.
+func (s *Server) oembedTarget(r *http.Request, raw string) (names []string, single bool, ok bool) {
+	u, err := url.Parse(raw)
+	site, _ := url.Parse(s.siteURL(r))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !strings.EqualFold(u.Host, site.Host) {
+		return nil, false, false
+	}
+	path := strings.TrimPrefix(u.Path, strings.TrimSuffix(site.Path, "/"))
+	switch {
+	case strings.HasPrefix(path, "/template/"):
+		tmpl, err := s.config.Templates.Get(strings.TrimPrefix(path, "/template/"))
+		if err != nil {
+			return nil, false, false
+		}
+		return []string{tmpl.Name}, true, true
+	case path == "/combine":
+		names, _ = s.resolveTemplates(parseTemplateList(u.Query().Get("templates")))
+	case strings.HasPrefix(path, "/c/"):
+		v, err := s.resolvePermalink(strings.TrimPrefix(path, "/c/"))
+		if err != nil {
+			return nil, false, false
+		}
+		names = v.Templates
+	}
+	return names, false, len(names) > 0
+}
+
+// handleOEmbed implements the oEmbed provider endpoint (/oembed?url=) so
+// wikis and docs sites can embed pages by pasting their URL. Template pages
+// embed as a framed /embed view ("rich"); compositions embed as their badge
+// linking to the composer. Only JSON is offered: format=xml is 501 as the
+// spec requires.
+func (s *Server) handleOEmbed(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if f := q.Get("format"); f != "" && f != "json" {
+		http.Error(w, "only format=json is supported", http.StatusNotImplemented)
+		return
+	}
+	names, single, ok := s.oembedTarget(r, q.Get("url"))
+	if !ok {
+		sendAPIResponseError(w, "NOT_FOUND", "url is not an embeddable page on this site")
+		return
+	}
+	base := s.siteURL(r)
+	width, height := embedWidth, embedMaxHeight
+	resp := map[string]interface{}{
+		"version":       "1.0",
+		"type":          "rich",
+		"provider_name": "GitIgnore",
+		"provider_url":  base + "/",
+		"cache_age":     embedCacheAge,
+	}
+	if single {
+		tmpl, _ := s.config.Templates.Get(names[0])
+		lines := strings.Count(strings.TrimRight(tmpl.Content, "\n"), "\n") + 1
+		height = min(embedHeaderPx+lines*embedLinePx, embedMaxHeight)
+		width, height = oembedClamp(q, width, height)
+		src := base + "/embed/" + url.PathEscape(tmpl.Name)
+		resp["title"] = tmpl.Name + " .gitignore"
+		resp["thumbnail_url"] = ogImageURL(base, tmpl.Name)
+		resp["thumbnail_width"] = oembedThumbSize
+		resp["thumbnail_height"] = oembedThumbSize * ogHeight / ogWidth
+		resp["html"] = `<iframe src="` + html.EscapeString(src) + `" width="` + strconv.Itoa(width) + `" height="` + strconv.Itoa(height) +
+			`" title="` + html.EscapeString(tmpl.Name) + ` .gitignore" style="border:0" loading="lazy"></iframe>`
+	} else {
+		width, height = badge{Label: "gitignore", Message: badgeMessage(names)}.Width(), 20
+		resp["title"] = ".gitignore for " + strings.Join(names, ", ")
+		resp["html"] = `<a href="` + html.EscapeString(base+composerURL(names)) + `"><img src="` + html.EscapeString(base+badgePath(names)) +
+			`" alt="gitignore: ` + html.EscapeString(strings.Join(names, ", ")) + `"></a>`
+	}
+	resp["width"] = width
+	resp["height"] = height
+
+	w.Header().Set("Content-Type", "application/json")
+	setCacheHeaders(w, "api")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// oembedClamp applies the consumer's maxwidth/maxheight to a frame size.
+func oembedClamp(q url.Values, width, height int) (int, int) {
+	if mw, err := strconv.Atoi(q.Get("maxwidth")); err == nil && mw > 0 && mw < width {
+		width = mw
+	}
+	if mh, err := strconv.Atoi(q.Get("maxheight")); err == nil && mh > 0 && mh < height {
+		height = mh
+	}
+	return width, height
+}
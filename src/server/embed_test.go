@@ -0,0 +1,145 @@
+package server
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/config"
+	"github.com/apimgr/gitignore/src/template"
+)
+
+func newEmbedTestServer(t *testing.T) http.Handler {
+	t.Helper()
+	tm, err := template.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{}
+	cfg.Server.FQDN = "example.com"
+	s := &Server{config: &Config{Version: "test", Templates: tm, Cfg: cfg}}
+	r := chi.NewRouter()
+	r.Use(s.securityHeaders)
+	r.Get("/badge/{file}", s.handleBadge)
+	r.With(s.cspOverride(embedCSP)).Get("/embed/{name}", s.handleEmbed)
+	r.Get("/oembed", s.handleOEmbed)
+	r.Get("/template/{name}", s.handleTemplatePage)
+	return r
+}
+
+func TestBadge(t *testing.T) {
+	h := newEmbedTestServer(t)
+
+	rec := doGet(t, h, "/badge/go,Node,Go.AllowList.svg")
+	body := rec.Body.String()
+	if rec.Header().Get("Content-Type") != "image/svg+xml" {
+		t.Fatalf("content type = %q", rec.Header().Get("Content-Type"))
+	}
+	if err := xml.Unmarshal(rec.Body.Bytes(), new(struct{})); err != nil {
+		t.Fatalf("badge is not well-formed: %v", err)
+	}
+	for _, want := range []string{
+		`aria-label="gitignore: Go · Node · Go.AllowList"`,
+		`xlink:href="https://example.com/combine?templates=Go,Node,Go.AllowList"`,
+		`fill="#f05033"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("badge missing %q", want)
+		}
+	}
+
+	body = doGet(t, h, "/badge/Go,Nope.svg?style=flat-square&color=blue").Body.String()
+	if !strings.Contains(body, "unknown: Nope") || !strings.Contains(body, badgeColors["red"]) || strings.Contains(body, "xlink:href") {
+		t.Errorf("unknown-name badge:\n%s", body)
+	}
+	if !strings.Contains(doGet(t, h, "/badge/Go.svg?color=007ec6&label=ignores").Body.String(), `aria-label="ignores: Go"`) {
+		t.Error("?label= not applied")
+	}
+	if rec := doGet(t, h, "/badge/Go.png"); rec.Code != http.StatusNotFound {
+		t.Errorf("non-svg badge: status = %d", rec.Code)
+	}
+}
+
+func TestBadgeMessage(t *testing.T) {
+	names := []string{"A", "B", "C", "D", "E", "F", "G"}
+	if got := badgeMessage(names); got != "A · B · C · D · E · +2" {
+		t.Errorf("badgeMessage = %q", got)
+	}
+	short := badge{Label: "gitignore", Message: "Go"}
+	long := badge{Label: "gitignore", Message: "Go · Node"}
+	if short.Width() >= long.Width() {
+		t.Errorf("widths: %d >= %d", short.Width(), long.Width())
+	}
+}
+
+func TestEmbed(t *testing.T) {
+	h := newEmbedTestServer(t)
+
+	rec := doGet(t, h, "/embed/Go?theme=light&lines=2-3")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	csp := rec.Header().Get("Content-Security-Policy")
+	if !strings.Contains(csp, "frame-ancestors *") || !strings.Contains(csp, "script-src 'none'") {
+		t.Errorf("embed CSP = %q", csp)
+	}
+	if rec.Header().Get("X-Frame-Options") != "" {
+		t.Error("embed should not send X-Frame-Options")
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `class="theme-light"`) || !strings.Contains(body, `id="L2"`) || strings.Contains(body, `id="L1"`) {
+		t.Error("embed should render lines 2-3 in the light theme")
+	}
+	if strings.Contains(body, `class="nav"`) || strings.Contains(body, "<script") {
+		t.Error("embed should have no site chrome or scripts")
+	}
+
+	rec = doGet(t, h, "/template/Go")
+	if rec.Header().Get("X-Frame-Options") != "SAMEORIGIN" || !strings.Contains(rec.Header().Get("Content-Security-Policy"), "frame-ancestors 'self'") {
+		t.Error("other pages must keep the default framing policy")
+	}
+	if !strings.Contains(rec.Body.String(), `<link rel="alternate" type="application/json+oembed" href="https://example.com/oembed?url=https%3A%2F%2Fexample.com%2Ftemplate%2FGo"`) {
+		t.Error("template page is missing oEmbed discovery")
+	}
+
+	if rec := doGet(t, h, "/embed/Nope"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown embed: status = %d", rec.Code)
+	}
+}
+
+func TestOEmbed(t *testing.T) {
+	h := newEmbedTestServer(t)
+	get := func(page string, extra string) (int, map[string]interface{}) {
+		rec := doGet(t, h, "/oembed?url="+url.QueryEscape(page)+extra)
+		var out map[string]interface{}
+		_ = json.Unmarshal(rec.Body.Bytes(), &out)
+		return rec.Code, out
+	}
+
+	code, out := get("https://example.com/template/Go", "&maxwidth=400")
+	if code != http.StatusOK || out["type"] != "rich" || out["width"] != float64(400) {
+		t.Fatalf("template oEmbed: %d %v", code, out)
+	}
+	if html, _ := out["html"].(string); !strings.Contains(html, `src="https://example.com/embed/Go"`) {
+		t.Errorf("html = %q", html)
+	}
+
+	code, out = get("https://example.com/combine?templates=Go,Node", "")
+	if html, _ := out["html"].(string); code != http.StatusOK || !strings.Contains(html, "/badge/Go,Node.svg") {
+		t.Errorf("composition oEmbed: %d %v", code, out)
+	}
+
+	for _, page := range []string{"https://other.example/template/Go", "https://example.com/template/Nope", "https://example.com/stats"} {
+		if code, _ := get(page, ""); code != http.StatusNotFound {
+			t.Errorf("%s: status = %d", page, code)
+		}
+	}
+	if code, _ := get("https://example.com/template/Go", "&format=xml"); code != http.StatusNotImplemented {
+		t.Errorf("format=xml: status = %d", code)
+	}
+}
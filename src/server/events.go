@@ -0,0 +1,352 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apimgr/gitignore/src/config"
+)
+
+// Event types published on /api/v1/events.
+const (
+	// EventTemplatesUpdated fires when a different template dataset is
+	// installed, by the template_sync task or a reload.
+	EventTemplatesUpdated = "templates.updated"
+	// EventConfigReloaded fires after a successful SIGHUP reload.
+	EventConfigReloaded = "config.reloaded"
+	// EventSchedulerTask carries the outcome of a scheduler task run.
+	EventSchedulerTask = "scheduler.task"
+	// EventUpdateAvailable fires when update_check first sees a release.
+	EventUpdateAvailable = "update.available"
+	// EventUpdateInstalled fires after a self-update replaced the binary.
+	EventUpdateInstalled = "update.installed"
+	// EventTorStatus fires when the hidden service connects or drops.
+	EventTorStatus = "tor.status"
+
+	// eventResync tells a resuming client that events were missed (the
+	// server restarted or the ID fell out of the buffer), so it should
+	// refetch whatever it caches instead of relying on the replay.
+	eventResync = "resync"
+)
+
+// eventTypes lists the types a ?types= filter may name.
+var eventTypes = []string{
+	EventTemplatesUpdated, EventConfigReloaded, EventSchedulerTask,
+	EventUpdateAvailable, EventUpdateInstalled, EventTorStatus,
+}
+
+// operatorEvents are only sent to streams opened with the admin API token.
+// Like the update_available email they are operator concerns: the public
+// stream never advertises the running version or Tor health.
+var operatorEvents = map[string]bool{
+	EventUpdateAvailable: true,
+	EventUpdateInstalled: true,
+	EventTorStatus:       true,
+}
+
+const (
+	defaultEventBuffer    = 256
+	defaultEventHeartbeat = 25 * time.Second
+	// eventRetry is the reconnect delay sent to EventSource clients.
+	eventRetry = 5 * time.Second
+	// eventQueue is how many undelivered events a subscriber may lag by
+	// before it is dropped; it reconnects and resumes from the buffer.
+	eventQueue = 32
+)
+
+// event is one published event. payload is the complete JSON data line.
+type event struct {
+	seq     uint64
+	id      string
+	kind    string
+	public  bool
+	payload []byte
+}
+
+// eventSubscriber is one open stream.
+type eventSubscriber struct {
+	ch       chan *event
+	operator bool
+	types    []string // empty means all
+}
+
+// wants reports whether e should be delivered to sub.
+func (sub *eventSubscriber) wants(e *event) bool {
+	if e.kind == eventResync {
+		return true
+	}
+	if !e.public && !sub.operator {
+		return false
+	}
+	return len(sub.types) == 0 || slices.Contains(sub.types, e.kind)
+}
+
+// eventHub fans published events out to subscribers and keeps the last
+// buffer of them so a reconnecting client can resume with Last-Event-ID.
+// Event IDs are "<boot>-<seq>": boot changes with every process start, so an
+// ID from before a restart is recognised as unresumable.
+type eventHub struct {
+	mu        sync.Mutex
+	boot      string
+	seq       uint64
+	ring      []*event
+	next      int // ring index of the next write
+	size      int
+	heartbeat time.Duration
+	subs      map[*eventSubscriber]struct{}
+	closed    bool
+}
+
+// newEventHub builds a hub keeping buffer events and pinging every heartbeat,
+// defaulting either when non-positive.
+func newEventHub(buffer int, heartbeat time.Duration) *eventHub {
+	if buffer <= 0 {
+		buffer = defaultEventBuffer
+	}
+	if heartbeat <= 0 {
+		heartbeat = defaultEventHeartbeat
+	}
+	return &eventHub{
+		boot:      strconv.FormatInt(time.Now().UnixNano(), 36),
+		ring:      make([]*event, buffer),
+		heartbeat: heartbeat,
+		subs:      make(map[*eventSubscriber]struct{}),
+	}
+}
+
+// publish records an event and delivers it to every interested subscriber.
+// A subscriber whose queue is full is disconnected rather than blocking the
+// publisher.
+func (h *eventHub) publish(kind string, public bool, data interface{}) {
+	body, err := json.Marshal(map[string]interface{}{
+		"type": kind,
+		"time": time.Now().UTC().Format(time.RFC3339),
+		"data": data,
+	})
+	if err != nil {
+		log.Printf("events: encode %s: %v", kind, err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.seq++
+	e := &event{seq: h.seq, id: h.boot + "-" + strconv.FormatUint(h.seq, 10), kind: kind, public: public, payload: body}
+	h.ring[h.next] = e
+	h.next = (h.next + 1) % len(h.ring)
+	h.size = min(h.size+1, len(h.ring))
+
+	for sub := range h.subs {
+		if !sub.wants(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			delete(h.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// subscribe registers sub and returns the events it should be sent first:
+// those buffered after lastID, or a lone resync event when lastID cannot be
+// resumed. Replay and registration happen under one lock so no event falls
+// between them.
+func (h *eventHub) subscribe(sub *eventSubscriber, lastID string) (replay []*event, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, false
+	}
+	if lastID != "" {
+		var resumable bool
+		if replay, resumable = h.since(lastID); !resumable {
+			replay = []*event{{
+				seq:     h.seq,
+				id:      h.boot + "-" + strconv.FormatUint(h.seq, 10),
+				kind:    eventResync,
+				payload: []byte(`{"type":"` + eventResync + `"}`),
+			}}
+		}
+	}
+	h.subs[sub] = struct{}{}
+	return replay, true
+}
+
+// since returns the buffered events after lastID, reporting false when
+// lastID is from another boot or older than the buffer. Caller holds h.mu.
+func (h *eventHub) since(lastID string) ([]*event, bool) {
+	boot, seqStr, found := strings.Cut(lastID, "-")
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	if !found || err != nil || boot != h.boot || seq > h.seq {
+		return nil, false
+	}
+	if oldest := h.seq - uint64(h.size) + 1; seq+1 < oldest {
+		// Events between lastID and the oldest buffered one are gone.
+		return nil, false
+	}
+	var out []*event
+	for i := 0; i < h.size; i++ {
+		e := h.ring[(h.next-h.size+i+len(h.ring))%len(h.ring)]
+		if e.seq > seq {
+			out = append(out, e)
+		}
+	}
+	return out, true
+}
+
+// unsubscribe removes sub unless publish already dropped it.
+func (h *eventHub) unsubscribe(sub *eventSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// close ends every stream so graceful shutdown is not held open by them.
+func (h *eventHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.subs {
+		delete(h.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// PublishEvent publishes an event of kind with data as its payload. Update
+// and Tor events reach only operator streams; everything else is public.
+func (s *Server) PublishEvent(kind string, data interface{}) {
+	if s.events == nil {
+		return
+	}
+	s.events.publish(kind, !operatorEvents[kind], data)
+}
+
+// PublishTaskResult publishes a scheduler task outcome. It is public only
+// when the task is listed in server.events.public_tasks, read from the live
+// configuration so a SIGHUP can change the list.
+func (s *Server) PublishTaskResult(id, name, status string, dur time.Duration, nextRun time.Time) {
+	if s.events == nil {
+		return
+	}
+	public := config.Get().Server.Events.PublicTasks
+	data := map[string]interface{}{
+		"task":        id,
+		"name":        name,
+		"status":      status,
+		"duration_ms": dur.Milliseconds(),
+	}
+	if !nextRun.IsZero() {
+		data["next_run"] = nextRun.UTC().Format(time.RFC3339)
+	}
+	s.events.publish(EventSchedulerTask, slices.Contains(public, id), data)
+}
+
+// handleEvents serves /api/v1/events as a Server-Sent Events stream.
+// ?types= narrows it to a comma-separated list of event types. A client
+// resumes with the Last-Event-ID header (EventSource sends it on reconnect)
+// or ?last_event_id=; if the ID can no longer be resumed it gets a resync
+// event first. Streams opened with the admin API token also receive the
+// operator-only events.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if s.events == nil {
+		sendAPIResponseError(w, "SERVER_ERROR", "event stream is not available")
+		return
+	}
+	var types []string
+	if t := r.URL.Query().Get("types"); t != "" {
+		for _, kind := range strings.Split(t, ",") {
+			kind = strings.TrimSpace(kind)
+			if !slices.Contains(eventTypes, kind) {
+				sendAPIResponseError(w, "BAD_REQUEST", fmt.Sprintf("unknown event type %q", kind))
+				return
+			}
+			types = append(types, kind)
+		}
+	}
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+
+	if s.limiter != nil {
+		ip := s.clientIP(r)
+		if !s.limiter.acquireStream(ip) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(eventRetry.Seconds())))
+			sendAPIResponseError(w, "RATE_LIMITED", "too many open event streams")
+			return
+		}
+		defer s.limiter.releaseStream(ip)
+	}
+
+	sub := &eventSubscriber{
+		ch:       make(chan *event, eventQueue),
+		operator: s.adminHandler != nil && s.adminHandler.Authorized(r),
+		types:    types,
+	}
+	replay, ok := s.events.subscribe(sub, lastID)
+	if !ok {
+		sendAPIResponseError(w, "SERVER_ERROR", "server is shutting down")
+		return
+	}
+	defer s.events.unsubscribe(sub)
+
+	// The stream outlives the server's write timeout.
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-store")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", eventRetry.Milliseconds())
+	for _, e := range replay {
+		if sub.wants(e) {
+			writeEvent(w, e)
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(s.events.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, open := <-sub.ch:
+			if !open {
+				return
+			}
+			writeEvent(w, e)
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent writes e in the text/event-stream format.
+func writeEvent(w http.ResponseWriter, e *event) {
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.id, e.kind, e.payload)
+}
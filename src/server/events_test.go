@@ -0,0 +1,268 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/apimgr/gitignore/src/config"
+	"github.com/apimgr/gitignore/src/server/metrics"
+)
+
+// newTestEventsServer serves /api/v1/events from a real listener, since the
+// stream only works over a connection that can be flushed.
+func newTestEventsServer(t *testing.T, buffer int, rl *rateLimiter) (*Server, *httptest.Server) {
+	t.Helper()
+	s := &Server{
+		config:  &Config{Version: "test", Cfg: &config.Config{}},
+		events:  newEventHub(buffer, time.Hour),
+		limiter: rl,
+	}
+	r := chi.NewRouter()
+	r.Get("/api/v1/events", s.handleEvents)
+	ts := httptest.NewServer(r)
+	t.Cleanup(func() {
+		s.events.close()
+		ts.Close()
+	})
+	return s, ts
+}
+
+// sseEvent is one parsed text/event-stream message.
+type sseEvent struct {
+	id, kind, data string
+}
+
+// openStream connects to the stream and returns a channel of its events.
+func openStream(t *testing.T, url string, header http.Header) (*http.Response, <-chan sseEvent) {
+	t.Helper()
+	// A stream whose headers are never flushed would otherwise block Do.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	ch := make(chan sseEvent, 16)
+	go func() {
+		defer close(ch)
+		var e sseEvent
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			line := sc.Text()
+			field, value, _ := strings.Cut(line, ": ")
+			switch {
+			case line == "":
+				if e.kind != "" {
+					ch <- e
+				}
+				e = sseEvent{}
+			case field == "id":
+				e.id = value
+			case field == "event":
+				e.kind = value
+			case field == "data":
+				e.data = value
+			}
+		}
+	}()
+	return resp, ch
+}
+
+func nextEvent(t *testing.T, ch <-chan sseEvent) sseEvent {
+	t.Helper()
+	select {
+	case e, ok := <-ch:
+		if !ok {
+			t.Fatal("stream closed")
+		}
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event received")
+	}
+	return sseEvent{}
+}
+
+// waitSubscribers waits until n streams are registered with the hub, so an
+// event published afterwards is delivered live rather than lost.
+func waitSubscribers(t *testing.T, h *eventHub, n int) {
+	t.Helper()
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		h.mu.Lock()
+		got := len(h.subs)
+		h.mu.Unlock()
+		if got == n {
+			return
+		}
+	}
+	t.Fatalf("subscribers never reached %d", n)
+}
+
+// TestEventsStream verifies live delivery, the JSON envelope and that
+// operator-only events are withheld from anonymous streams.
+func TestEventsStream(t *testing.T) {
+	s, ts := newTestEventsServer(t, 8, nil)
+	resp, ch := openStream(t, ts.URL+"/api/v1/events", nil)
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	waitSubscribers(t, s.events, 1)
+
+	s.PublishEvent(EventUpdateAvailable, map[string]string{"new_version": "9.9.9"})
+	s.PublishEvent(EventTemplatesUpdated, map[string]interface{}{"revision": "abc", "count": 1})
+
+	e := nextEvent(t, ch)
+	if e.kind != EventTemplatesUpdated {
+		t.Fatalf("event = %q, want %s (operator event leaked?)", e.kind, EventTemplatesUpdated)
+	}
+	if !strings.HasPrefix(e.data, `{"data":{"count":1,"revision":"abc"},"time":`) || !strings.Contains(e.data, `"type":"templates.updated"`) {
+		t.Errorf("data = %s", e.data)
+	}
+	if !strings.HasSuffix(e.id, "-2") {
+		t.Errorf("id = %q, want sequence 2", e.id)
+	}
+}
+
+// TestEventsStreamThroughMiddleware verifies events are flushed through the
+// response writers the real middleware chain wraps around the handler.
+func TestEventsStreamThroughMiddleware(t *testing.T) {
+	s := &Server{
+		config:  &Config{Version: "test", Cfg: &config.Config{}},
+		events:  newEventHub(8, time.Hour),
+		metrics: metrics.New(metrics.Options{}),
+	}
+	r := chi.NewRouter()
+	r.Use(middleware.Logger, s.metricsMiddleware, middleware.Compress(5))
+	r.Get("/api/v1/events", s.handleEvents)
+	ts := httptest.NewServer(r)
+	t.Cleanup(func() {
+		s.events.close()
+		ts.Close()
+	})
+
+	_, ch := openStream(t, ts.URL+"/api/v1/events", http.Header{"Accept-Encoding": {"gzip"}})
+	waitSubscribers(t, s.events, 1)
+	s.PublishEvent(EventConfigReloaded, struct{}{})
+	if e := nextEvent(t, ch); e.kind != EventConfigReloaded {
+		t.Errorf("event = %q", e.kind)
+	}
+}
+
+// TestEventsResume verifies Last-Event-ID replays the buffered events after
+// it, and that an ID the buffer no longer covers, or one from an earlier
+// process, gets a resync instead.
+func TestEventsResume(t *testing.T) {
+	s, ts := newTestEventsServer(t, 3, nil)
+	for i := 0; i < 4; i++ {
+		s.PublishEvent(EventConfigReloaded, struct{}{})
+	}
+	boot := s.events.boot
+
+	_, ch := openStream(t, ts.URL+"/api/v1/events", http.Header{"Last-Event-ID": {boot + "-2"}})
+	for _, want := range []string{"-3", "-4"} {
+		if e := nextEvent(t, ch); e.id != boot+want {
+			t.Errorf("replayed %q, want %s", e.id, boot+want)
+		}
+	}
+
+	for _, stale := range []string{boot + "-0", "olderboot-3", "garbage"} {
+		_, ch := openStream(t, ts.URL+"/api/v1/events?last_event_id="+stale, nil)
+		if e := nextEvent(t, ch); e.kind != eventResync || e.id != boot+"-4" {
+			t.Errorf("%s: got %+v, want resync at -4", stale, e)
+		}
+	}
+}
+
+// TestEventsTypesFilter verifies ?types= narrows the stream and rejects
+// unknown types.
+func TestEventsTypesFilter(t *testing.T) {
+	s, ts := newTestEventsServer(t, 8, nil)
+	resp, err := http.Get(ts.URL + "/api/v1/events?types=nope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unknown type status = %d, want 400", resp.StatusCode)
+	}
+
+	_, ch := openStream(t, ts.URL+"/api/v1/events?types="+EventTemplatesUpdated, nil)
+	waitSubscribers(t, s.events, 1)
+	s.PublishEvent(EventConfigReloaded, struct{}{})
+	s.PublishEvent(EventTemplatesUpdated, struct{}{})
+	if e := nextEvent(t, ch); e.kind != EventTemplatesUpdated {
+		t.Errorf("event = %q, want only %s", e.kind, EventTemplatesUpdated)
+	}
+}
+
+// TestEventsTaskVisibility verifies only tasks listed in public_tasks are
+// published to anonymous streams.
+func TestEventsTaskVisibility(t *testing.T) {
+	h := newEventHub(8, time.Hour)
+	s := &Server{config: &Config{Cfg: &config.Config{}}, events: h}
+	sub := &eventSubscriber{ch: make(chan *event, 4)}
+	h.subscribe(sub, "")
+
+	// The default configuration lists template_sync only.
+	s.PublishTaskResult("backup_daily", "Daily backup", "success", time.Second, time.Now())
+	s.PublishTaskResult("template_sync", "Template sync", "success", time.Second, time.Now())
+	select {
+	case e := <-sub.ch:
+		if !strings.Contains(string(e.payload), `"task":"template_sync"`) {
+			t.Errorf("got %s, want only the template_sync outcome", e.payload)
+		}
+	default:
+		t.Fatal("public task outcome not delivered")
+	}
+	if len(sub.ch) != 0 {
+		t.Error("private task outcome delivered to an anonymous stream")
+	}
+}
+
+// TestEventsStreamCap verifies the per-client stream limit and that closing
+// a stream frees its slot.
+func TestEventsStreamCap(t *testing.T) {
+	cfg := testRateLimitConfig()
+	cfg.MaxStreams = 1
+	s, ts := newTestEventsServer(t, 8, newRateLimiter(cfg))
+
+	first, _ := openStream(t, ts.URL+"/api/v1/events", nil)
+	waitSubscribers(t, s.events, 1)
+	resp, err := http.Get(ts.URL + "/api/v1/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("second stream status = %d, want 429", resp.StatusCode)
+	}
+
+	first.Body.Close()
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(5 * time.Millisecond) {
+		s.limiter.mu.Lock()
+		open := s.limiter.streams["127.0.0.1"]
+		s.limiter.mu.Unlock()
+		if open == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stream slot never released")
+		}
+	}
+	second, _ := openStream(t, ts.URL+"/api/v1/events", nil)
+	if second.StatusCode != http.StatusOK {
+		t.Errorf("stream after close status = %d, want 200", second.StatusCode)
+	}
+}
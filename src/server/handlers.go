@@ -7,15 +7,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/template"
 )
 
 // handleHome serves the home page
 func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
 	s.renderPage(w, r, "home", PageData{
-		Title: "Home",
+		Title:          "Home",
+		StructuredData: websiteLD(s.siteURL(r)),
 		Data: map[string]interface{}{
 			"total":      s.config.Templates.Count(),
 			"categories": len(s.config.Templates.GetCategories()),
@@ -37,8 +41,19 @@ func (s *Server) handleAPIInfo(w http.ResponseWriter, r *http.Request) {
 			"search":       base + "/search?q={query}",
 			"template":     base + "/templates/{name}",
 			"combine":      base + "/combine?templates={name1,name2}",
+			"compose":      base + "/compose?templates={name1,name2}",
+			"permalink":    base + "/permalinks?templates={name1,name2}&pin={0|1}",
+			"changes":      base + "/changes?since={version|date}",
+			"history":      base + "/templates/{name}/history",
+			"feed":         "/feeds/templates.atom",
+			"dataset":      base + "/dataset",
+			"sitemap":      "/sitemap.xml",
+			"badge":        "/badge/{name1,name2}.svg",
+			"oembed":       "/oembed?url={page_url}",
 			"categories":   base + "/categories",
 			"stats":        base + "/stats",
+			"popular":      base + "/stats/popular?days={days}",
+			"combinations": base + "/stats/combinations?template={name}",
 			"swagger":      base + "/server/swagger",
 			"graphql":      base + "/server/graphql",
 			"autodiscover": "/api/autodiscover",
@@ -47,11 +62,12 @@ func (s *Server) handleAPIInfo(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleAPIAutodiscover returns machine-readable server metadata for
-// zero-config client discovery (AI.md PART 14 "/api/autodiscover"). CLI
-// self-update version feeds are not implemented (see TODO.AI.md), so
-// "cli_versions" is intentionally omitted rather than faked.
+// zero-config client discovery (AI.md PART 14 "/api/autodiscover").
+// "cli_versions" lists the gitignore-cli builds of this server's release once
+// it has been looked up (see SetCLIRelease); it is omitted before then and
+// for development builds, which have no release.
 func (s *Server) handleAPIAutodiscover(w http.ResponseWriter, r *http.Request) {
-	sendAPIResponseOK(w, map[string]interface{}{
+	data := map[string]interface{}{
 		"name":        "GitIgnore API",
 		"version":     s.config.Version,
 		"commit":      s.config.Commit,
@@ -61,18 +77,24 @@ func (s *Server) handleAPIAutodiscover(w http.ResponseWriter, r *http.Request) {
 		"swagger":     "/api/swagger",
 		"graphql":     "/api/graphql",
 		"healthz":     "/api/healthz",
-	})
+	}
+	if versions := s.cliVersions(); versions != nil {
+		data["cli_versions"] = versions
+	}
+	sendAPIResponseOK(w, data)
 }
 
 // handleAPITemplate returns a template's content
 func (s *Server) handleAPITemplate(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
+	s.recordUsage(sourceNative, []string{name})
 	s.config.Templates.HandleGetTemplate(w, r, name)
 }
 
 // handleAPITemplateJSON returns a template's metadata as JSON
 func (s *Server) handleAPITemplateJSON(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
+	s.recordUsage(sourceNative, []string{name})
 	r.Header.Set("Accept", "application/json")
 	s.config.Templates.HandleGetTemplate(w, r, name)
 }
@@ -84,12 +106,34 @@ func (s *Server) handleAPIList(w http.ResponseWriter, r *http.Request) {
 
 // handleAPISearch searches templates
 func (s *Server) handleAPISearch(w http.ResponseWriter, r *http.Request) {
-	s.config.Templates.HandleSearch(w, r)
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		s.config.Templates.HandleSearch(w, r)
+		return
+	}
+	s.config.Templates.WriteSearchResults(w, r, query, s.searchTemplates(query))
 }
 
 // handleAPICombine combines multiple templates
 func (s *Server) handleAPICombine(w http.ResponseWriter, r *http.Request) {
-	s.config.Templates.HandleCombine(w, r)
+	param := r.URL.Query().Get("templates")
+	if param == "" {
+		s.config.Templates.HandleCombine(w, r)
+		return
+	}
+	names := strings.Split(param, ",")
+	s.recordUsage(sourceNative, names)
+	// ?download asks browsers to save the result as .gitignore (the web
+	// composer's download link).
+	if r.URL.Query().Has("download") {
+		w.Header().Set("Content-Disposition", `attachment; filename=".gitignore"`)
+	}
+
+	rw := &metricsResponseWriter{ResponseWriter: w}
+	s.config.Templates.HandleCombine(rw, r)
+	if rw.status == http.StatusOK {
+		s.observeCombine(len(names), rw.size)
+	}
 }
 
 // handleAPICategories returns all categories
@@ -120,25 +164,46 @@ func (s *Server) handleAPITemplatesJSON(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// archiveCategoryRecord is the PAX record naming each archive entry's
+// category, so offline copies can rebuild the category and tag indexes.
+const archiveCategoryRecord = "GITIGNORE.category"
+
+// archivePathRecord is the PAX record giving each archive entry's path in
+// the dataset, which the flat entry names leave out for nested templates.
+const archivePathRecord = "GITIGNORE.path"
+
 // handleAPITemplatesTarGz streams every template as a gzip-compressed tar
-// archive (AI.md PART 14).
+// archive (AI.md PART 14). The ETag is the dataset version, so clients
+// keeping a copy revalidate it with If-None-Match and get 304 until the
+// dataset changes.
 func (s *Server) handleAPITemplatesTarGz(w http.ResponseWriter, r *http.Request) {
+	templates := s.config.Templates.ListAll()
+	etag := `"` + template.DatasetVersion(templates) + `"`
+	w.Header().Set("ETag", etag)
+	setCacheHeaders(w, "api")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
 	w.Header().Set("Content-Type", "application/gzip")
 	w.Header().Set("Content-Disposition", `attachment; filename="gitignore-templates.tar.gz"`)
-	setCacheHeaders(w, "api")
 
 	gz := gzip.NewWriter(w)
 	defer gz.Close()
 	tw := tar.NewWriter(gz)
 	defer tw.Close()
 
-	for _, tmpl := range s.config.Templates.ListAll() {
+	for _, tmpl := range templates {
 		content := []byte(tmpl.Content)
 		hdr := &tar.Header{
 			Name:    tmpl.Name + ".gitignore",
 			Mode:    0o644,
 			Size:    int64(len(content)),
 			ModTime: time.Now(),
+			PAXRecords: map[string]string{
+				archiveCategoryRecord: tmpl.Category,
+				archivePathRecord:     tmpl.UpstreamPath(),
+			},
 		}
 		if err := tw.WriteHeader(hdr); err != nil {
 			return
@@ -149,6 +214,18 @@ func (s *Server) handleAPITemplatesTarGz(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// etagMatches reports whether an If-None-Match header lists etag, comparing
+// weakly as RFC 9110 requires for that header.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
 // handleSwaggerUI serves a Swagger UI page bound to the OpenAPI JSON endpoint.
 func (s *Server) handleSwaggerUI(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -183,8 +260,14 @@ func (s *Server) handleStatic(w http.ResponseWriter, r *http.Request) {
 	http.StripPrefix("/static/", http.FileServer(staticHTTPFS)).ServeHTTP(w, r)
 }
 
-// handleFavicon serves the embedded favicon.
+// handleFavicon serves the operator's favicon when one was provided, else the
+// embedded one.
 func (s *Server) handleFavicon(w http.ResponseWriter, r *http.Request) {
+	if path, contentType := s.brandingAsset("favicon"); path != "" {
+		s.setCSP(w, r, brandingAssetCSP)
+		s.serveBrandingFile(w, r, path, contentType)
+		return
+	}
 	setCacheHeaders(w, "static")
 	f, err := staticHTTPFS.Open("favicon.ico")
 	if err != nil {
@@ -212,6 +295,7 @@ func (s *Server) handleRobotsTxt(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "Allow: /api")
 		fmt.Fprintln(w, "Disallow: /debug")
 	}
+	fmt.Fprintf(w, "\nSitemap: %s/sitemap.xml\n", s.siteURL(r))
 }
 
 // handleSecurityTxt serves security.txt
@@ -251,59 +335,6 @@ func (s *Server) handleManifest(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprint(w, manifest)
 }
 
-// handleServiceWorker serves the service worker
-func (s *Server) handleServiceWorker(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/javascript")
-	sw := `// GitIgnore Service Worker
-const CACHE_NAME = 'gitignore-v2';
-const OFFLINE_URL = '/static/offline.html';
-const urlsToCache = [
-  '/',
-  '/static/css/main.css',
-  '/static/js/app.js',
-  '/static/images/icon.svg',
-  '/manifest.json',
-  OFFLINE_URL
-];
-
-self.addEventListener('install', function(event) {
-  event.waitUntil(
-    caches.open(CACHE_NAME).then(function(cache) {
-      return cache.addAll(urlsToCache);
-    })
-  );
-  self.skipWaiting();
-});
-
-self.addEventListener('activate', function(event) {
-  event.waitUntil(
-    caches.keys().then(function(keys) {
-      return Promise.all(keys.filter(function(k) {
-        return k !== CACHE_NAME;
-      }).map(function(k) {
-        return caches.delete(k);
-      }));
-    })
-  );
-  self.clients.claim();
-});
-
-self.addEventListener('fetch', function(event) {
-  if (event.request.method !== 'GET') {
-    return;
-  }
-  event.respondWith(
-    caches.match(event.request).then(function(response) {
-      return response || fetch(event.request).catch(function() {
-        return caches.match(OFFLINE_URL);
-      });
-    })
-  );
-});
-`
-	fmt.Fprint(w, sw)
-}
-
 // handleAPITemplateText returns a template's content as plain text
 func (s *Server) handleAPITemplateText(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
@@ -311,12 +342,18 @@ func (s *Server) handleAPITemplateText(w http.ResponseWriter, r *http.Request) {
 	if len(name) > 4 && name[len(name)-4:] == ".txt" {
 		name = name[:len(name)-4]
 	}
+	s.recordUsage(sourceNative, []string{name})
 	tmpl, err := s.config.Templates.Get(name)
 	if err != nil {
 		sendAPIResponseErrorLocalized(w, r, "NOT_FOUND", "template not found")
 		return
 	}
 	w.Header().Set("Content-Type", "text/plain")
+	// ?download saves the template as .gitignore (the template page's
+	// download link).
+	if r.URL.Query().Has("download") {
+		w.Header().Set("Content-Disposition", `attachment; filename=".gitignore"`)
+	}
 	fmt.Fprint(w, tmpl.Content)
 }
 
@@ -336,7 +373,7 @@ func (s *Server) handleAPISearchText(w http.ResponseWriter, r *http.Request) {
 		sendAPIResponseError(w, "BAD_REQUEST", "query parameter 'q' is required")
 		return
 	}
-	results := s.config.Templates.Search(query)
+	results := s.searchTemplates(query)
 	w.Header().Set("Content-Type", "text/plain")
 	for _, tmpl := range results {
 		fmt.Fprintln(w, tmpl.Name)
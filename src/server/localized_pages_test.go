@@ -0,0 +1,60 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/common/i18n"
+)
+
+func TestLocalizedPages(t *testing.T) {
+	s := newTestTemplatesServer(t)
+	r := chi.NewRouter()
+	r.Use(i18n.Middleware)
+	r.Get("/categories", s.handleCategoriesPage)
+	r.Get("/list", s.handleListPage)
+	r.Get("/search", s.handleSearchPage)
+	r.Get("/template/{name}", s.handleTemplatePage)
+	r.Get("/api/v1/compose", s.handleAPICompose)
+	r.Get("/api/{list}", s.handleCompatTemplates)
+
+	goDE := i18n.TemplateDescription("de", "Go", "")
+	for path, wants := range map[string][]string{
+		"/categories?lang=de":         {"Sprachen &amp; Frameworks", `href="/list?category=Root"`},
+		"/list?category=Root&lang=ja": {"言語とフレームワーク", "件のテンプレート"},
+		"/search?q=zzzzqqq&lang=en":   {"No results for &#34;zzzzqqq&#34;:"},
+		"/template/Go?lang=de":        {goDE, " Zeilen · ", " Byte<", ">Sprachen &amp; Frameworks</a>"},
+	} {
+		body := doGet(t, r, path).Body.String()
+		for _, want := range wants {
+			if !strings.Contains(body, want) {
+				t.Errorf("%s: missing %q", path, want)
+			}
+		}
+	}
+
+	var compose struct {
+		Data struct {
+			Summary string `json:"summary"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(doGet(t, r, "/api/v1/compose?templates=Go&lang=de").Body.Bytes(), &compose); err != nil {
+		t.Fatal(err)
+	}
+	if compose.Data.Summary != "1 Vorlage · 0 doppelte Zeilen entfernt · 0 Konflikte" {
+		t.Errorf("compose summary = %q", compose.Data.Summary)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/go,nope", nil)
+	req.Header.Set("Accept-Language", "ja")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if !strings.Contains(rec.Body.String(), "#!! ERROR: nope は定義されていません。") || rec.Header().Get("Vary") != "Accept-Language" {
+		t.Errorf("compat error line not localized (Vary %q):\n%s", rec.Header().Get("Vary"), rec.Body.String())
+	}
+}
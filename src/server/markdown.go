@@ -0,0 +1,269 @@
+package server
+
+import (
+	"html/template"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Operator pages are written in the small Markdown subset below. Nothing in
+// the source is ever passed through as HTML: every character of text is
+// escaped and only the tags this renderer emits reach the page, so a page
+// file cannot inject script or markup the CSP would have to catch.
+//
+// Blocks: ATX headings, paragraphs, "-"/"*"/"+" and "1." lists, ">" quotes,
+// ``` fenced code and --- rules. Inline: `code`, **strong**, *em*/_em_ and
+// [text](url) links restricted to safeLinkURL.
+
+var (
+	mdHeading = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdRule    = regexp.MustCompile(`^(?:-\s*){3,}$|^(?:\*\s*){3,}$|^(?:_\s*){3,}$`)
+	mdBullet  = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	mdOrdered = regexp.MustCompile(`^\d{1,9}[.)]\s+(.*)$`)
+	mdSlugSep = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+)
+
+// renderMarkdown renders src and returns the text of its first level-one
+// heading, which operator pages use as their title.
+func renderMarkdown(src string) (title string, out template.HTML) {
+	var b strings.Builder
+	renderMarkdownBlocks(&b, strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n"), &title)
+	return title, template.HTML(b.String())
+}
+
+func renderMarkdownBlocks(b *strings.Builder, lines []string, title *string) {
+	var para []string
+	flush := func() {
+		if len(para) == 0 {
+			return
+		}
+		b.WriteString("<p>")
+		for i, l := range para {
+			if i > 0 {
+				if strings.HasSuffix(para[i-1], "  ") {
+					b.WriteString("<br>\n")
+				} else {
+					b.WriteString("\n")
+				}
+			}
+			b.WriteString(renderInline(strings.TrimSpace(l)))
+		}
+		b.WriteString("</p>\n")
+		para = nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flush()
+
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			b.WriteString("<pre><code>")
+			b.WriteString(template.HTMLEscapeString(strings.Join(code, "\n")))
+			b.WriteString("</code></pre>\n")
+
+		case mdHeading.MatchString(trimmed):
+			flush()
+			m := mdHeading.FindStringSubmatch(trimmed)
+			level := strconv.Itoa(len(m[1]))
+			if len(m[1]) == 1 && *title == "" {
+				*title = plainInline(m[2])
+			}
+			b.WriteString("<h" + level + ` id="` + template.HTMLEscapeString(headingSlug(m[2])) + `">`)
+			b.WriteString(renderInline(m[2]))
+			b.WriteString("</h" + level + ">\n")
+
+		case mdRule.MatchString(trimmed):
+			flush()
+			b.WriteString("<hr>\n")
+
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				q := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quote = append(quote, strings.TrimPrefix(q, " "))
+			}
+			i--
+			b.WriteString("<blockquote>\n")
+			renderMarkdownBlocks(b, quote, title)
+			b.WriteString("</blockquote>\n")
+
+		case mdBullet.MatchString(trimmed), mdOrdered.MatchString(trimmed):
+			flush()
+			marker, tag := mdBullet, "ul"
+			if !mdBullet.MatchString(trimmed) {
+				marker, tag = mdOrdered, "ol"
+			}
+			b.WriteString("<" + tag + ">\n")
+			var item []string
+			writeItem := func() {
+				if item != nil {
+					b.WriteString("<li>" + renderInline(strings.Join(item, " ")) + "</li>\n")
+				}
+			}
+			for ; i < len(lines); i++ {
+				t := strings.TrimSpace(lines[i])
+				if m := marker.FindStringSubmatch(t); m != nil {
+					writeItem()
+					item = []string{m[1]}
+					continue
+				}
+				// Indented lines continue the current item; anything else
+				// ends the list.
+				if t == "" || !strings.HasPrefix(lines[i], " ") && !strings.HasPrefix(lines[i], "\t") {
+					break
+				}
+				item = append(item, t)
+			}
+			writeItem()
+			i--
+			b.WriteString("</" + tag + ">\n")
+
+		default:
+			para = append(para, line)
+		}
+	}
+	flush()
+}
+
+// renderInline renders the inline subset of s, escaping all other text.
+func renderInline(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_[]()#+-.!>", s[i+1]) >= 0:
+			b.WriteString(template.HTMLEscapeString(s[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '`':
+			if end := strings.IndexByte(s[i+1:], '`'); end >= 0 {
+				b.WriteString("<code>" + template.HTMLEscapeString(s[i+1:i+1+end]) + "</code>")
+				i += end + 2
+				continue
+			}
+
+		case c == '[':
+			if text, href, n, ok := parseLink(s[i:]); ok {
+				if safe, ok := safeLinkURL(href); ok {
+					b.WriteString(`<a href="` + template.HTMLEscapeString(safe) + `"`)
+					if u, _ := url.Parse(safe); u != nil && u.Scheme != "" && u.Scheme != "mailto" {
+						b.WriteString(` rel="noopener noreferrer"`)
+					}
+					b.WriteString(">" + renderInline(text) + "</a>")
+				} else {
+					b.WriteString(renderInline(text))
+				}
+				i += n
+				continue
+			}
+
+		case c == '*' && strings.HasPrefix(s[i:], "**"):
+			if end := strings.Index(s[i+2:], "**"); end > 0 {
+				b.WriteString("<strong>" + renderInline(s[i+2:i+2+end]) + "</strong>")
+				i += end + 4
+				continue
+			}
+
+		case c == '*' || c == '_':
+			// Intraword underscores (snake_case) are literal.
+			if c == '_' && i > 0 && isWordByte(s[i-1]) {
+				break
+			}
+			if end := strings.IndexByte(s[i+1:], c); end > 0 && s[i+1] != ' ' {
+				b.WriteString("<em>" + renderInline(s[i+1:i+1+end]) + "</em>")
+				i += end + 2
+				continue
+			}
+		}
+		b.WriteString(template.HTMLEscapeString(s[i : i+1]))
+		i++
+	}
+	return b.String()
+}
+
+// parseLink parses "[text](href)" at the start of s, returning the number of
+// bytes consumed.
+func parseLink(s string) (text, href string, n int, ok bool) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				if i+1 >= len(s) || s[i+1] != '(' {
+					return "", "", 0, false
+				}
+				end := strings.IndexByte(s[i+2:], ')')
+				if end < 0 {
+					return "", "", 0, false
+				}
+				return s[1:i], strings.TrimSpace(s[i+2 : i+2+end]), i + 3 + end, true
+			}
+		}
+	}
+	return "", "", 0, false
+}
+
+// safeLinkURL accepts http, https and mailto links plus same-site paths and
+// fragments; anything else (javascript:, data:, protocol-relative) is
+// dropped and only the link text is rendered.
+func safeLinkURL(href string) (string, bool) {
+	if href == "" || strings.HasPrefix(href, "//") || strings.ContainsAny(href, " \t\n\\") {
+		return "", false
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "mailto":
+		return href, true
+	case "":
+		if u.Host == "" {
+			return href, true
+		}
+	}
+	return "", false
+}
+
+// plainInline returns s with the inline markup removed, for titles.
+func plainInline(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '[' {
+			if t, _, n, ok := parseLink(s[i:]); ok {
+				b.WriteString(plainInline(t))
+				i += n - 1
+				continue
+			}
+		}
+		if strings.IndexByte("`*_\\", s[i]) >= 0 {
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// headingSlug builds a heading anchor id such as "what-we-store".
+func headingSlug(s string) string {
+	return strings.Trim(mdSlugSep.ReplaceAllString(strings.ToLower(plainInline(s)), "-"), "-")
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
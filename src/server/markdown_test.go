@@ -0,0 +1,52 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	src := "# Privacy at *Acme*\n\nWe keep **nothing** about `you`.\nSee [support](https://support.example.com/) or [mail](mailto:it@example.com).\n\n" +
+		"## What we store\n\n- theme cookie\n- lang cookie\n  (optional)\n\n1. first\n2. second\n\n> quoted\n> text\n\n```\n<b>raw</b>\n```\n\n---\n"
+	title, out := renderMarkdown(src)
+	if title != "Privacy at Acme" {
+		t.Errorf("title = %q", title)
+	}
+	for _, want := range []string{
+		`<h1 id="privacy-at-acme">Privacy at <em>Acme</em></h1>`,
+		"<p>We keep <strong>nothing</strong> about <code>you</code>.\n",
+		`<a href="https://support.example.com/" rel="noopener noreferrer">support</a>`,
+		`<a href="mailto:it@example.com">mail</a>`,
+		`<h2 id="what-we-store">What we store</h2>`,
+		"<ul>\n<li>theme cookie</li>\n<li>lang cookie (optional)</li>\n</ul>",
+		"<ol>\n<li>first</li>\n<li>second</li>\n</ol>",
+		"<blockquote>\n<p>quoted\ntext</p>\n</blockquote>",
+		"<pre><code>&lt;b&gt;raw&lt;/b&gt;</code></pre>",
+		"<hr>",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestRenderMarkdownSanitizes(t *testing.T) {
+	for src, bad := range map[string]string{
+		`<script>alert(1)</script>`:               "<script",
+		`<img src=x onerror=alert(1)>`:            "<img",
+		`[click](javascript:alert(1))`:            "javascript:",
+		`[click](JaVaScRiPt:alert(1))`:            "alert",
+		`[x](data:text/html;base64,PHNjcmlwdD4=)`: "data:",
+		`[x](//evil.example.com)`:                 "evil.example.com",
+		"[a\"b](/ok\" onclick=\"x)":               `onclick="`,
+		"# <i>title</i>":                          "<i>",
+	} {
+		_, out := renderMarkdown(src)
+		if strings.Contains(string(out), bad) {
+			t.Errorf("%q rendered %q", src, out)
+		}
+	}
+	if _, out := renderMarkdown("snake_case_name and [rel](/server/help#top)"); !strings.Contains(string(out), `snake_case_name and <a href="/server/help#top">rel</a>`) {
+		t.Errorf("got %q", out)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/apimgr/gitignore/src/common/i18n"
+	"github.com/apimgr/gitignore/src/server/metrics"
+	"github.com/apimgr/gitignore/src/template"
 	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -18,6 +20,10 @@ var (
 	numericIDRegex = regexp.MustCompile(`/\d+(?:/|$)`)
 )
 
+// validNameRegex matches names that could plausibly be a template; anything
+// else requested is counted as an invalid miss rather than a typo.
+var validNameRegex = regexp.MustCompile(`^[A-Za-z0-9._+-]+$`)
+
 // normalizePath collapses UUIDs and numeric IDs to ":id" for label cardinality.
 func normalizePath(path string) string {
 	path = uuidRegex.ReplaceAllString(path, ":id")
@@ -50,6 +56,18 @@ func (rw *metricsResponseWriter) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Flush passes flushes through, for the event stream. Middleware above this
+// one (compression) flushes through http.Flusher rather than unwrapping.
+func (rw *metricsResponseWriter) Flush() {
+	_ = http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController, which the
+// event stream uses to lift the write deadline.
+func (rw *metricsResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // metricsMiddleware records request count, duration, size, and in-flight gauge
 // for every HTTP request (AI.md PART 20). It uses the chi route pattern as the
 // path label to keep cardinality bounded.
@@ -120,3 +138,75 @@ func (s *Server) metricsHandler() http.Handler {
 		handler.ServeHTTP(w, r)
 	})
 }
+
+// observeFetches counts resolved template fetches by name and buckets unknown
+// names as near_miss (a typo of a real template), invalid (not name-shaped)
+// or other, so a client looping on a typo stands out from real traffic.
+func (s *Server) observeFetches(route string, resolved, unknown []string) {
+	if s.metrics == nil {
+		return
+	}
+	for _, name := range resolved {
+		s.metrics.TemplateFetches.WithLabelValues(name, route).Inc()
+	}
+	for _, name := range unknown {
+		s.metrics.TemplateFetches.WithLabelValues(metrics.UnknownTemplate, route).Inc()
+		s.metrics.TemplateNotFound.WithLabelValues(route, s.notFoundBucket(name)).Inc()
+	}
+}
+
+// notFoundBucket classifies an unknown template name for the not-found metric.
+func (s *Server) notFoundBucket(name string) string {
+	switch {
+	case !validNameRegex.MatchString(name):
+		return "invalid"
+	case s.config.Templates.IsNearMiss(name):
+		return "near_miss"
+	default:
+		return "other"
+	}
+}
+
+// searchTemplates runs a template search, recording its latency and whether
+// it found nothing.
+func (s *Server) searchTemplates(query string) []*template.Template {
+	start := time.Now()
+	results := s.config.Templates.Search(query)
+	if s.metrics != nil {
+		s.metrics.SearchDuration.Observe(time.Since(start).Seconds())
+		if len(results) == 0 {
+			s.metrics.SearchZeroResults.Inc()
+		}
+	}
+	return results
+}
+
+// observeCombine records one successful combine of n templates producing
+// size bytes.
+func (s *Server) observeCombine(n, size int) {
+	if s.metrics == nil {
+		return
+	}
+	s.metrics.CombineTemplates.Observe(float64(n))
+	s.metrics.CombineOutputSize.Observe(float64(size))
+}
+
+// observeCompat counts a hit on a gitignore.io-compatible route; kind is
+// "list" or "templates".
+func (s *Server) observeCompat(kind string) {
+	if s.metrics != nil {
+		s.metrics.CompatRequests.WithLabelValues(kind).Inc()
+	}
+}
+
+// ObserveConfigReload records the outcome of a SIGHUP configuration reload.
+func (s *Server) ObserveConfigReload(err error) {
+	if s.metrics == nil {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	s.metrics.ConfigReloads.WithLabelValues(result).Inc()
+}
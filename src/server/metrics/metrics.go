@@ -19,6 +19,9 @@ const namespace = "gitignore"
 // defaultDurationBuckets mirrors the AI.md PART 20 duration histogram buckets.
 var defaultDurationBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
 
+// combineCountBuckets bucket how many templates one combine request merges.
+var combineCountBuckets = []float64{1, 2, 3, 4, 5, 8, 12, 20, 50}
+
 // defaultSizeBuckets mirrors the AI.md PART 20 request/response size buckets.
 var defaultSizeBuckets = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
 
@@ -33,6 +36,9 @@ type Options struct {
 	// TemplatesFn reports the current loaded-template count for the
 	// gitignore_templates_total business gauge. Optional; nil omits the gauge.
 	TemplatesFn func() int
+	// RateLimitClientsFn reports how many clients the rate limiter currently
+	// tracks for gitignore_ratelimit_clients. Optional; nil omits the gauge.
+	RateLimitClientsFn func() int
 }
 
 // Metrics holds the registry and the HTTP metric vectors. Application, runtime,
@@ -46,8 +52,29 @@ type Metrics struct {
 	HTTPRequestSize     *prometheus.HistogramVec
 	HTTPResponseSize    *prometheus.HistogramVec
 	HTTPActiveRequests  prometheus.Gauge
+
+	// Business metrics. Every label is bounded by the caller: template names
+	// are the loaded dataset's names or UnknownTemplate, and the remaining
+	// labels take a fixed set of values.
+	TemplateFetches   *prometheus.CounterVec
+	CombineTemplates  prometheus.Histogram
+	CombineOutputSize prometheus.Histogram
+	CompatRequests    *prometheus.CounterVec
+	TemplateNotFound  *prometheus.CounterVec
+	SearchDuration    prometheus.Histogram
+	SearchZeroResults prometheus.Counter
+	ConfigReloads     *prometheus.CounterVec
+
+	// Rate limiter. Policy labels are operator-configured policy names and
+	// class labels the fixed route cost classes.
+	RateLimitRejected  *prometheus.CounterVec
+	RateLimitEvictions prometheus.Counter
 }
 
+// UnknownTemplate is the template label value for names that are not in the
+// loaded dataset, so typos and garbage cannot create new series.
+const UnknownTemplate = "_unknown"
+
 // New builds a Metrics instance with its own registry and registers every
 // mandated collector. It never panics on duplicate registration because the
 // registry is private to this instance.
@@ -105,6 +132,84 @@ func New(opts Options) *Metrics {
 				Help:      "Number of HTTP requests currently being processed.",
 			},
 		),
+		TemplateFetches: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "template_fetches_total",
+				Help:      "Template fetches by template name; unknown names share the _unknown label.",
+			},
+			[]string{"template", "route"},
+		),
+		CombineTemplates: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "combine_templates",
+				Help:      "Number of templates requested per combine request.",
+				Buckets:   combineCountBuckets,
+			},
+		),
+		CombineOutputSize: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "combine_output_bytes",
+				Help:      "Size of combined .gitignore output in bytes.",
+				Buckets:   sizeBuckets,
+			},
+		),
+		CompatRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "compat_requests_total",
+				Help:      "Requests to the gitignore.io-compatible routes by kind.",
+			},
+			[]string{"kind"},
+		),
+		TemplateNotFound: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "template_not_found_total",
+				Help:      "Requested template names that did not resolve, by route and kind of miss.",
+			},
+			[]string{"route", "bucket"},
+		),
+		SearchDuration: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "search_duration_seconds",
+				Help:      "Template search latency in seconds.",
+				Buckets:   durationBuckets,
+			},
+		),
+		SearchZeroResults: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "search_zero_results_total",
+				Help:      "Searches that returned no templates.",
+			},
+		),
+		ConfigReloads: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "config_reloads_total",
+				Help:      "SIGHUP configuration reloads by result.",
+			},
+			[]string{"result"},
+		),
+		RateLimitRejected: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "ratelimit_rejected_total",
+				Help:      "Requests rejected with 429 by the rate limiter, by policy and route cost class.",
+			},
+			[]string{"policy", "class"},
+		),
+		RateLimitEvictions: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "ratelimit_evictions_total",
+				Help:      "Clients evicted from the rate limiter's bounded client table.",
+			},
+		),
 	}
 
 	m.reg.MustRegister(
@@ -113,6 +218,16 @@ func New(opts Options) *Metrics {
 		m.HTTPRequestSize,
 		m.HTTPResponseSize,
 		m.HTTPActiveRequests,
+		m.TemplateFetches,
+		m.CombineTemplates,
+		m.CombineOutputSize,
+		m.CompatRequests,
+		m.TemplateNotFound,
+		m.SearchDuration,
+		m.SearchZeroResults,
+		m.ConfigReloads,
+		m.RateLimitRejected,
+		m.RateLimitEvictions,
 		newInfoCollector(opts),
 	)
 
@@ -135,11 +250,13 @@ type infoCollector struct {
 	buildDate      string
 	includeRuntime bool
 	templatesFn    func() int
+	clientsFn      func() int
 
 	appInfo      *prometheus.Desc
 	appUptime    *prometheus.Desc
 	appStart     *prometheus.Desc
 	templates    *prometheus.Desc
+	rlClients    *prometheus.Desc
 	goGoroutines *prometheus.Desc
 	goMemAlloc   *prometheus.Desc
 	goMemSys     *prometheus.Desc
@@ -156,6 +273,7 @@ func newInfoCollector(opts Options) *infoCollector {
 		buildDate:      opts.BuildDate,
 		includeRuntime: opts.IncludeRuntime,
 		templatesFn:    opts.TemplatesFn,
+		clientsFn:      opts.RateLimitClientsFn,
 		appInfo: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "app_info"),
 			"Application build information; always 1, labels carry the values.",
@@ -173,6 +291,10 @@ func newInfoCollector(opts Options) *infoCollector {
 			prometheus.BuildFQName(namespace, "", "templates_total"),
 			"Number of loaded gitignore templates.", nil, nil,
 		),
+		rlClients: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "ratelimit_clients"),
+			"Number of clients currently tracked by the rate limiter.", nil, nil,
+		),
 		goGoroutines: prometheus.NewDesc(
 			prometheus.BuildFQName(namespace, "", "go_goroutines"),
 			"Current number of goroutines.", nil, nil,
@@ -204,6 +326,9 @@ func (c *infoCollector) Describe(ch chan<- *prometheus.Desc) {
 	if c.templatesFn != nil {
 		ch <- c.templates
 	}
+	if c.clientsFn != nil {
+		ch <- c.rlClients
+	}
 	if c.includeRuntime {
 		ch <- c.goGoroutines
 		ch <- c.goMemAlloc
@@ -226,6 +351,10 @@ func (c *infoCollector) Collect(ch chan<- prometheus.Metric) {
 		ch <- prometheus.MustNewConstMetric(c.templates, prometheus.GaugeValue,
 			float64(c.templatesFn()))
 	}
+	if c.clientsFn != nil {
+		ch <- prometheus.MustNewConstMetric(c.rlClients, prometheus.GaugeValue,
+			float64(c.clientsFn()))
+	}
 
 	if c.includeRuntime {
 		var ms runtime.MemStats
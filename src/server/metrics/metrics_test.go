@@ -93,3 +93,33 @@ func TestTemplatesOmittedWhenNil(t *testing.T) {
 		t.Errorf("expected no templates_total series, got %d", c)
 	}
 }
+
+// TestBusinessMetricsRegistered verifies the template-usage families are in
+// the instance registry once observed.
+func TestBusinessMetricsRegistered(t *testing.T) {
+	m := New(Options{})
+	m.TemplateFetches.WithLabelValues("Go", "native").Inc()
+	m.TemplateFetches.WithLabelValues(UnknownTemplate, "compat").Inc()
+	m.CombineTemplates.Observe(3)
+	m.CombineOutputSize.Observe(4096)
+	m.CompatRequests.WithLabelValues("list").Inc()
+	m.TemplateNotFound.WithLabelValues("native", "near_miss").Inc()
+	m.SearchDuration.Observe(0.001)
+	m.SearchZeroResults.Inc()
+	m.ConfigReloads.WithLabelValues("success").Inc()
+
+	for name, want := range map[string]int{
+		"gitignore_template_fetches_total":    2,
+		"gitignore_combine_templates":         1,
+		"gitignore_combine_output_bytes":      1,
+		"gitignore_compat_requests_total":     1,
+		"gitignore_template_not_found_total":  1,
+		"gitignore_search_duration_seconds":   1,
+		"gitignore_search_zero_results_total": 1,
+		"gitignore_config_reloads_total":      1,
+	} {
+		if got := testutil.CollectAndCount(m.Registry(), name); got != want {
+			t.Errorf("%s: %d series, want %d", name, got, want)
+		}
+	}
+}
@@ -5,8 +5,6 @@ import (
 	"net"
 	"net/http"
 	"strings"
-	"sync"
-	"time"
 
 	"github.com/apimgr/gitignore/src/mode"
 )
@@ -86,27 +84,39 @@ const permissionsPolicyDefault = "accelerometer=(), ambient-light-sensor=(), bat
 	"payment=(self), picture-in-picture=(self), " +
 	"publickey-credentials-get=(self), storage-access=(self), web-share=(self)"
 
-// cspDirectives builds the Content-Security-Policy value from the PART 11
-// per-directive defaults. upgrade-insecure-requests is emitted only when TLS
-// is active: over plaintext it would upgrade same-origin subresource fetches
-// to https and break the page (documented deviation, matches HSTS gating).
+// cspDefaults are the PART 11 per-directive Content-Security-Policy defaults,
+// in emission order.
+var cspDefaults = [][2]string{
+	{"default-src", "'self'"},
+	{"script-src", "'self'"},
+	{"style-src", "'self' 'unsafe-inline'"},
+	{"img-src", "'self' data: blob: https:"},
+	{"font-src", "'self' https:"},
+	{"connect-src", "'self'"},
+	{"media-src", "'self' blob:"},
+	{"worker-src", "'self' blob:"},
+	{"manifest-src", "'self'"},
+	{"frame-src", "'self'"},
+	{"frame-ancestors", "'self'"},
+	{"base-uri", "'self'"},
+	{"form-action", "'self'"},
+	{"object-src", "'none'"},
+}
+
+// cspDirectives builds the Content-Security-Policy value from cspDefaults,
+// with overrides replacing the value of the directives they name.
+// upgrade-insecure-requests is emitted only when TLS is active: over
+// plaintext it would upgrade same-origin subresource fetches to https and
+// break the page (documented deviation, matches HSTS gating).
 // {learned_origins} is omitted — the domain-learning subsystem is absent.
-func cspDirectives(tlsActive bool, api string) string {
-	directives := []string{
-		"default-src 'self'",
-		"script-src 'self'",
-		"style-src 'self' 'unsafe-inline'",
-		"img-src 'self' data: blob: https:",
-		"font-src 'self' https:",
-		"connect-src 'self'",
-		"media-src 'self' blob:",
-		"worker-src 'self' blob:",
-		"manifest-src 'self'",
-		"frame-src 'self'",
-		"frame-ancestors 'self'",
-		"base-uri 'self'",
-		"form-action 'self'",
-		"object-src 'none'",
+func cspDirectives(tlsActive bool, api string, overrides map[string]string) string {
+	directives := make([]string, 0, len(cspDefaults)+3)
+	for _, d := range cspDefaults {
+		value := d[1]
+		if v, ok := overrides[d[0]]; ok {
+			value = v
+		}
+		directives = append(directives, d[0]+" "+value)
 	}
 	if tlsActive {
 		directives = append(directives, "upgrade-insecure-requests")
@@ -118,10 +128,27 @@ func cspDirectives(tlsActive bool, api string) string {
 	return strings.Join(directives, "; ")
 }
 
+// setCSP writes the Content-Security-Policy for r. In development mode it is
+// emitted in Report-Only form so violations are logged, not blocked.
+func (s *Server) setCSP(w http.ResponseWriter, r *http.Request, overrides map[string]string) {
+	h := w.Header()
+	csp := cspDirectives(s.tlsActive(r), apiBasePath(), overrides)
+	if mode.IsAppModeDev() {
+		h.Set("Content-Security-Policy-Report-Only", csp)
+	} else {
+		h.Set("Content-Security-Policy", csp)
+	}
+}
+
+// tlsActive reports whether r arrived over TLS or TLS is configured.
+func (s *Server) tlsActive(r *http.Request) bool {
+	return r.TLS != nil || (s.config.Cfg != nil && s.config.Cfg.Server.SSL.Enabled)
+}
+
 // securityHeaders sets the always-on security response headers mandated by
 // AI.md PART 11. HSTS and upgrade-insecure-requests are gated on TLS being
-// active, per RFC 6797 (never send HSTS over plaintext). In development mode
-// CSP is emitted in Report-Only form so violations are logged, not blocked.
+// active, per RFC 6797 (never send HSTS over plaintext). Routes that need a
+// different policy layer cspOverride on top.
 func (s *Server) securityHeaders(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		h := w.Header()
@@ -136,15 +163,7 @@ func (s *Server) securityHeaders(next http.Handler) http.Handler {
 		h.Set("Cross-Origin-Resource-Policy", "cross-origin")
 		h.Set("Permissions-Policy", permissionsPolicyDefault)
 
-		sslEnabled := s.config.Cfg != nil && s.config.Cfg.Server.SSL.Enabled
-		tlsActive := r.TLS != nil || sslEnabled
-
-		csp := cspDirectives(tlsActive, apiBasePath())
-		if mode.IsAppModeDev() {
-			h.Set("Content-Security-Policy-Report-Only", csp)
-		} else {
-			h.Set("Content-Security-Policy", csp)
-		}
+		s.setCSP(w, r, nil)
 
 		base := s.detectServerURL(r)
 		reportURL := base + apiBasePath() + "/server/reports/default"
@@ -152,7 +171,7 @@ func (s *Server) securityHeaders(next http.Handler) http.Handler {
 		h.Set("Report-To", `{"group":"default","max_age":10886400,"endpoints":[{"url":"`+reportURL+`"}]}`)
 		h.Set("NEL", `{"report_to":"default","max_age":2592000,"include_subdomains":true}`)
 
-		if tlsActive {
+		if s.tlsActive(r) {
 			h.Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains; preload")
 		}
 
@@ -160,72 +179,21 @@ func (s *Server) securityHeaders(next http.Handler) http.Handler {
 	})
 }
 
-// rateLimiter is a fixed-window per-client-IP limiter. It is enabled only when
-// the operator turns it on in config (AI.md PART 11).
-type rateLimiter struct {
-	mu     sync.Mutex
-	hits   map[string]*rateBucket
-	limit  int
-	window time.Duration
-	lastGC time.Time
-}
-
-// rateBucket tracks a single client's request count within the current window.
-type rateBucket struct {
-	count int
-	reset time.Time
-}
-
-// newRateLimiter builds a limiter allowing limit requests per window seconds.
-func newRateLimiter(limit, windowSeconds int) *rateLimiter {
-	if windowSeconds <= 0 {
-		windowSeconds = 60
-	}
-	return &rateLimiter{
-		hits:   make(map[string]*rateBucket),
-		limit:  limit,
-		window: time.Duration(windowSeconds) * time.Second,
-		lastGC: time.Now(),
-	}
-}
-
-// allow reports whether the request from ip is within the limit and records it.
-func (rl *rateLimiter) allow(ip string) bool {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-
-	now := time.Now()
-	if now.Sub(rl.lastGC) > rl.window {
-		for k, b := range rl.hits {
-			if now.After(b.reset) {
-				delete(rl.hits, k)
+// cspOverride returns middleware that reissues the Content-Security-Policy
+// with overrides for routes whose needs differ from the site default. An
+// overridden frame-ancestors also drops X-Frame-Options, which cannot express
+// anything but SAMEORIGIN or DENY and would otherwise block the framing the
+// override allows.
+func (s *Server) cspOverride(overrides map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s.setCSP(w, r, overrides)
+			if _, ok := overrides["frame-ancestors"]; ok {
+				w.Header().Del("X-Frame-Options")
 			}
-		}
-		rl.lastGC = now
-	}
-
-	b, ok := rl.hits[ip]
-	if !ok || now.After(b.reset) {
-		rl.hits[ip] = &rateBucket{count: 1, reset: now.Add(rl.window)}
-		return true
-	}
-	if b.count >= rl.limit {
-		return false
+			next.ServeHTTP(w, r)
+		})
 	}
-	b.count++
-	return true
-}
-
-// rateLimitMiddleware rejects requests exceeding the configured per-IP rate
-// with the spec's RATE_LIMITED envelope.
-func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if s.limiter != nil && !s.limiter.allow(s.clientIP(r)) {
-			sendAPIResponseError(w, "RATE_LIMITED", "too many requests")
-			return
-		}
-		next.ServeHTTP(w, r)
-	})
 }
 
 // geoipMiddleware enforces the country-based access policy as a risk signal
@@ -248,3 +216,18 @@ func (s *Server) geoipMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// skipTimeout applies timeout to every request except those for path, whose
+// handler streams for as long as the client stays connected.
+func skipTimeout(timeout func(http.Handler) http.Handler, path string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		limited := timeout(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == path {
+				next.ServeHTTP(w, r)
+				return
+			}
+			limited.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// serviceWorkerJS is the PWA service worker. It is served from /sw.js rather
+// than /static so its scope covers the whole site and browsers revalidate it.
+//
+//go:embed assets/sw.js
+var serviceWorkerJS string
+
+// datasetBundle is the offline copy of the dataset the service worker
+// downloads: every template with its content, keyed by the dataset version.
+type datasetBundle struct {
+	Version   string               `json:"version"`
+	Templates []*template.Template `json:"templates"`
+}
+
+// datasetBundlePath is the bundle URL for a dataset version. The version is
+// a content hash, so a bundle URL never changes meaning and can be cached
+// forever.
+func datasetBundlePath(version string) string {
+	return apiBasePath() + "/dataset/" + version + ".json"
+}
+
+// handleAPIDataset returns the loaded dataset version and where to download
+// it. The service worker polls this to decide whether its offline copy is
+// stale.
+func (s *Server) handleAPIDataset(w http.ResponseWriter, r *http.Request) {
+	version := s.config.Templates.Version()
+	sendAPIResponseOK(w, map[string]interface{}{
+		"version": version,
+		"count":   s.config.Templates.Count(),
+		"bundle":  datasetBundlePath(version),
+	})
+}
+
+// handleAPIDatasetBundle serves /api/v1/dataset/{version}.json. Only the
+// loaded version is available; an older one is gone for good, so clients go
+// back to /api/v1/dataset for the current URL.
+func (s *Server) handleAPIDatasetBundle(w http.ResponseWriter, r *http.Request) {
+	version := s.config.Templates.Version()
+	if chi.URLParam(r, "version") != version {
+		sendAPIResponseError(w, "NOT_FOUND", "dataset version is not the one being served")
+		return
+	}
+	templates := s.config.Templates.ListAll()
+	sort.Slice(templates, func(i, j int) bool {
+		return strings.ToLower(templates[i].Name) < strings.ToLower(templates[j].Name)
+	})
+	w.Header().Set("Content-Type", "application/json")
+	setCacheHeaders(w, "static")
+	_ = json.NewEncoder(w).Encode(APIResponse{
+		OK:   true,
+		Data: datasetBundle{Version: version, Templates: templates},
+		Meta: map[string]interface{}{"count": len(templates)},
+	})
+}
+
+// handleServiceWorker serves the service worker, prefixed with the build
+// version so each release installs a fresh copy of the app shell.
+func (s *Server) handleServiceWorker(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/javascript")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprintf(w, "const BUILD = %q;\nconst API = %q;\n", s.config.Version, apiBasePath())
+	fmt.Fprint(w, serviceWorkerJS)
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestDatasetBundle(t *testing.T) {
+	s := newTestTemplatesServer(t)
+	tm := s.config.Templates
+	r := chi.NewRouter()
+	r.Get("/api/v1/dataset", s.handleAPIDataset)
+	r.Get("/api/v1/dataset/{version}.json", s.handleAPIDatasetBundle)
+	r.Get("/sw.js", s.handleServiceWorker)
+
+	var manifest struct {
+		Data struct {
+			Version string `json:"version"`
+			Count   int    `json:"count"`
+			Bundle  string `json:"bundle"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(doGet(t, r, "/api/v1/dataset").Body.Bytes(), &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Data.Version != tm.Version() || manifest.Data.Count != tm.Count() {
+		t.Fatalf("manifest = %+v", manifest.Data)
+	}
+
+	rec := doGet(t, r, manifest.Data.Bundle)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Header().Get("Cache-Control"), "immutable") {
+		t.Fatalf("bundle: status = %d, cache = %q", rec.Code, rec.Header().Get("Cache-Control"))
+	}
+	var bundle struct {
+		Data datasetBundle `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &bundle); err != nil {
+		t.Fatal(err)
+	}
+	if bundle.Data.Version != tm.Version() || len(bundle.Data.Templates) != tm.Count() {
+		t.Fatalf("bundle has version %q and %d templates", bundle.Data.Version, len(bundle.Data.Templates))
+	}
+	for i := 1; i < len(bundle.Data.Templates); i++ {
+		if strings.ToLower(bundle.Data.Templates[i-1].Name) > strings.ToLower(bundle.Data.Templates[i].Name) {
+			t.Fatalf("bundle is not sorted at %q", bundle.Data.Templates[i].Name)
+		}
+	}
+	if bundle.Data.Templates[0].Content == "" {
+		t.Error("bundle templates should carry their content")
+	}
+
+	if rec := doGet(t, r, "/api/v1/dataset/000000000000.json"); rec.Code != http.StatusNotFound {
+		t.Errorf("stale bundle: status = %d", rec.Code)
+	}
+
+	rec = doGet(t, r, "/sw.js")
+	if !strings.HasPrefix(rec.Body.String(), `const BUILD = "test";`) || rec.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("service worker: cache = %q, body starts %q", rec.Header().Get("Cache-Control"), rec.Body.String()[:40])
+	}
+}
+
+// TestTemplatesArchive verifies the archive's ETag revalidation and that each
+// entry carries its category.
+func TestTemplatesArchive(t *testing.T) {
+	s := newTestTemplatesServer(t)
+	tm := s.config.Templates
+	r := chi.NewRouter()
+	r.Get("/api/v1/templates.tar.gz", s.handleAPITemplatesTarGz)
+
+	rec := doGet(t, r, "/api/v1/templates.tar.gz")
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag != `"`+tm.Version()+`"` {
+		t.Fatalf("status = %d, ETag = %q", rec.Code, etag)
+	}
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	entries := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries++
+		name := strings.TrimSuffix(hdr.Name, ".gitignore")
+		tmpl, err := tm.Get(name)
+		if err != nil {
+			t.Fatalf("unexpected entry %q", hdr.Name)
+		}
+		if got := hdr.PAXRecords[archiveCategoryRecord]; got != tmpl.Category {
+			t.Errorf("%s: category = %q, want %q", name, got, tmpl.Category)
+		}
+		if got := hdr.PAXRecords[archivePathRecord]; got != tmpl.UpstreamPath() {
+			t.Errorf("%s: path = %q, want %q", name, got, tmpl.UpstreamPath())
+		}
+	}
+	if entries != tm.Count() {
+		t.Errorf("archive has %d entries, want %d", entries, tm.Count())
+	}
+
+	for _, header := range []string{etag, `W/` + etag, `"other", ` + etag, "*"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/templates.tar.gz", nil)
+		req.Header.Set("If-None-Match", header)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: status = %d, %d bytes", header, rec.Code, rec.Body.Len())
+		}
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/templates.tar.gz", nil)
+	req.Header.Set("If-None-Match", `"000000000000"`)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("stale ETag: status = %d, want 200", rec.Code)
+	}
+}
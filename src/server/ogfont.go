@@ -0,0 +1,111 @@
+package server
+
+// ogFont is a 5×7 bitmap font for printable ASCII (0x20–0x7E), used to draw
+// text on Open Graph cards without shipping a font file. Each glyph is seven
+// rows, top to bottom; bit 4 of a row is its leftmost pixel.
+var ogFont = [95][7]uint8{
+	{0, 0, 0, 0, 0, 0, 0}, // ' '
+	{0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0, 0b00100},       // !
+	{0b01010, 0b01010, 0b01010, 0, 0, 0, 0},                         // "
+	{0b01010, 0b01010, 0b11111, 0b01010, 0b11111, 0b01010, 0b01010}, // #
+	{0b00100, 0b01111, 0b10100, 0b01110, 0b00101, 0b11110, 0b00100}, // $
+	{0b11000, 0b11001, 0b00010, 0b00100, 0b01000, 0b10011, 0b00011}, // %
+	{0b01100, 0b10010, 0b10100, 0b01000, 0b10101, 0b10010, 0b01101}, // &
+	{0b01100, 0b00100, 0b01000, 0, 0, 0, 0},                         // '
+	{0b00010, 0b00100, 0b01000, 0b01000, 0b01000, 0b00100, 0b00010}, // (
+	{0b01000, 0b00100, 0b00010, 0b00010, 0b00010, 0b00100, 0b01000}, // )
+	{0, 0b00100, 0b10101, 0b01110, 0b10101, 0b00100, 0},             // *
+	{0, 0b00100, 0b00100, 0b11111, 0b00100, 0b00100, 0},             // +
+	{0, 0, 0, 0, 0b01100, 0b00100, 0b01000},                         // ,
+	{0, 0, 0, 0b11111, 0, 0, 0},                                     // -
+	{0, 0, 0, 0, 0, 0b01100, 0b01100},                               // .
+	{0, 0b00001, 0b00010, 0b00100, 0b01000, 0b10000, 0},             // /
+	{0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110}, // 0
+	{0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110}, // 1
+	{0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111}, // 2
+	{0b11111, 0b00010, 0b00100, 0b00010, 0b00001, 0b10001, 0b01110}, // 3
+	{0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010}, // 4
+	{0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110}, // 5
+	{0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110}, // 6
+	{0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000}, // 7
+	{0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110}, // 8
+	{0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100}, // 9
+	{0, 0b01100, 0b01100, 0, 0b01100, 0b01100, 0},                   // :
+	{0, 0b01100, 0b01100, 0, 0b01100, 0b00100, 0b01000},             // ;
+	{0b00010, 0b00100, 0b01000, 0b10000, 0b01000, 0b00100, 0b00010}, // <
+	{0, 0, 0b11111, 0, 0b11111, 0, 0},                               // =
+	{0b01000, 0b00100, 0b00010, 0b00001, 0b00010, 0b00100, 0b01000}, // >
+	{0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0, 0b00100},       // ?
+	{0b01110, 0b10001, 0b00001, 0b01101, 0b10101, 0b10101, 0b01110}, // @
+	{0b01110, 0b10001, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001}, // A
+	{0b11110, 0b10001, 0b10001, 0b11110, 0b10001, 0b10001, 0b11110}, // B
+	{0b01110, 0b10001, 0b10000, 0b10000, 0b10000, 0b10001, 0b01110}, // C
+	{0b11100, 0b10010, 0b10001, 0b10001, 0b10001, 0b10010, 0b11100}, // D
+	{0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b11111}, // E
+	{0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b10000}, // F
+	{0b01110, 0b10001, 0b10000, 0b10111, 0b10001, 0b10001, 0b01111}, // G
+	{0b10001, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001}, // H
+	{0b01110, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110}, // I
+	{0b00111, 0b00010, 0b00010, 0b00010, 0b00010, 0b10010, 0b01100}, // J
+	{0b10001, 0b10010, 0b10100, 0b11000, 0b10100, 0b10010, 0b10001}, // K
+	{0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b11111}, // L
+	{0b10001, 0b11011, 0b10101, 0b10101, 0b10001, 0b10001, 0b10001}, // M
+	{0b10001, 0b10001, 0b11001, 0b10101, 0b10011, 0b10001, 0b10001}, // N
+	{0b01110, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110}, // O
+	{0b11110, 0b10001, 0b10001, 0b11110, 0b10000, 0b10000, 0b10000}, // P
+	{0b01110, 0b10001, 0b10001, 0b10001, 0b10101, 0b10010, 0b01101}, // Q
+	{0b11110, 0b10001, 0b10001, 0b11110, 0b10100, 0b10010, 0b10001}, // R
+	{0b01111, 0b10000, 0b10000, 0b01110, 0b00001, 0b00001, 0b11110}, // S
+	{0b11111, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100}, // T
+	{0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110}, // U
+	{0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01010, 0b00100}, // V
+	{0b10001, 0b10001, 0b10001, 0b10101, 0b10101, 0b10101, 0b01010}, // W
+	{0b10001, 0b10001, 0b01010, 0b00100, 0b01010, 0b10001, 0b10001}, // X
+	{0b10001, 0b10001, 0b10001, 0b01010, 0b00100, 0b00100, 0b00100}, // Y
+	{0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b10000, 0b11111}, // Z
+	{0b01110, 0b01000, 0b01000, 0b01000, 0b01000, 0b01000, 0b01110}, // [
+	{0, 0b10000, 0b01000, 0b00100, 0b00010, 0b00001, 0},             // \
+	{0b01110, 0b00010, 0b00010, 0b00010, 0b00010, 0b00010, 0b01110}, // ]
+	{0b00100, 0b01010, 0b10001, 0, 0, 0, 0},                         // ^
+	{0, 0, 0, 0, 0, 0, 0b11111},                                     // _
+	{0b01000, 0b00100, 0b00010, 0, 0, 0, 0},                         // `
+	{0, 0, 0b01110, 0b00001, 0b01111, 0b10001, 0b01111},             // a
+	{0b10000, 0b10000, 0b10110, 0b11001, 0b10001, 0b10001, 0b11110}, // b
+	{0, 0, 0b01110, 0b10000, 0b10000, 0b10001, 0b01110},             // c
+	{0b00001, 0b00001, 0b01101, 0b10011, 0b10001, 0b10001, 0b01111}, // d
+	{0, 0, 0b01110, 0b10001, 0b11111, 0b10000, 0b01110},             // e
+	{0b00110, 0b01001, 0b01000, 0b11100, 0b01000, 0b01000, 0b01000}, // f
+	{0, 0b01111, 0b10001, 0b10001, 0b01111, 0b00001, 0b01110},       // g
+	{0b10000, 0b10000, 0b10110, 0b11001, 0b10001, 0b10001, 0b10001}, // h
+	{0b00100, 0, 0b01100, 0b00100, 0b00100, 0b00100, 0b01110},       // i
+	{0b00010, 0, 0b00110, 0b00010, 0b00010, 0b10010, 0b01100},       // j
+	{0b10000, 0b10000, 0b10010, 0b10100, 0b11000, 0b10100, 0b10010}, // k
+	{0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110}, // l
+	{0, 0, 0b11010, 0b10101, 0b10101, 0b10001, 0b10001},             // m
+	{0, 0, 0b10110, 0b11001, 0b10001, 0b10001, 0b10001},             // n
+	{0, 0, 0b01110, 0b10001, 0b10001, 0b10001, 0b01110},             // o
+	{0, 0, 0b11110, 0b10001, 0b11110, 0b10000, 0b10000},             // p
+	{0, 0, 0b01101, 0b10011, 0b01111, 0b00001, 0b00001},             // q
+	{0, 0, 0b10110, 0b11001, 0b10000, 0b10000, 0b10000},             // r
+	{0, 0, 0b01110, 0b10000, 0b01110, 0b00001, 0b11110},             // s
+	{0b01000, 0b01000, 0b11100, 0b01000, 0b01000, 0b01001, 0b00110}, // t
+	{0, 0, 0b10001, 0b10001, 0b10001, 0b10011, 0b01101},             // u
+	{0, 0, 0b10001, 0b10001, 0b10001, 0b01010, 0b00100},             // v
+	{0, 0, 0b10001, 0b10001, 0b10101, 0b10101, 0b01010},             // w
+	{0, 0, 0b10001, 0b01010, 0b00100, 0b01010, 0b10001},             // x
+	{0, 0, 0b10001, 0b10001, 0b01111, 0b00001, 0b01110},             // y
+	{0, 0, 0b11111, 0b00010, 0b00100, 0b01000, 0b11111},             // z
+	{0b00010, 0b00100, 0b00100, 0b01000, 0b00100, 0b00100, 0b00010}, // {
+	{0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100}, // |
+	{0b01000, 0b00100, 0b00100, 0b00010, 0b00100, 0b00100, 0b01000}, // }
+	{0, 0, 0b01000, 0b10101, 0b00010, 0, 0},                         // ~
+}
+
+// ogGlyph returns the glyph for c, or "?" for anything outside printable
+// ASCII.
+func ogGlyph(c rune) [7]uint8 {
+	if c < 0x20 || c > 0x7e {
+		c = '?'
+	}
+	return ogFont[c-0x20]
+}
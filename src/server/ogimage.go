@@ -0,0 +1,218 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Open Graph card geometry: the 1.91:1 size every major unfurler crops to.
+const (
+	ogWidth  = 1200
+	ogHeight = 630
+	ogMargin = 80
+)
+
+var (
+	ogBackground = color.RGBA{0x1a, 0x1a, 0x1a, 0xff}
+	ogAccent     = color.RGBA{0xf0, 0x50, 0x33, 0xff}
+	ogTitle      = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	ogSubtitle   = color.RGBA{0xb0, 0xb0, 0xb0, 0xff}
+	ogMuted      = color.RGBA{0x80, 0x80, 0x80, 0xff}
+)
+
+// ogRect is a filled rectangle, the only primitive an ogCard is made of.
+type ogRect struct {
+	x, y, w, h int
+	fill       color.RGBA
+}
+
+// ogCard is an Open Graph preview image described as vector rectangles, with
+// text drawn from the ogFont bitmap glyphs. The same description serializes
+// to SVG and rasterizes to PNG, so both formats are pixel-identical and
+// rendering needs nothing beyond the standard library.
+type ogCard struct {
+	rects []ogRect
+}
+
+// newOGCard lays out a card: a brand line, the title scaled to fit the width,
+// a subtitle and a footer (usually the site host).
+func newOGCard(title, subtitle, footer string) *ogCard {
+	c := &ogCard{}
+	c.rect(0, 0, ogWidth, ogHeight, ogBackground)
+	c.rect(0, 0, 16, ogHeight, ogAccent)
+	c.text("GitIgnore", ogMargin, ogMargin, 6, ogAccent)
+
+	// Six columns per character (five plus spacing); the title gets the
+	// largest scale up to 16 that fits, and is cut short below scale 5.
+	avail := ogWidth - 2*ogMargin
+	scale := min(16, avail/(6*max(1, len(title))))
+	if scale < 5 {
+		scale = 5
+		title = truncateASCII(title, avail/(6*scale))
+	}
+	y := 250
+	c.text(title, ogMargin, y, scale, ogTitle)
+	y += 7*scale + 40
+	c.text(truncateASCII(subtitle, avail/(6*4)), ogMargin, y, 4, ogSubtitle)
+	c.text(truncateASCII(footer, avail/(6*4)), ogMargin, ogHeight-ogMargin-7*4, 4, ogMuted)
+	return c
+}
+
+// truncateASCII shortens s to at most n characters, ending in "..." when cut.
+func truncateASCII(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	if n <= 3 {
+		return s[:max(n, 0)]
+	}
+	return s[:n-3] + "..."
+}
+
+func (c *ogCard) rect(x, y, w, h int, fill color.RGBA) {
+	c.rects = append(c.rects, ogRect{x, y, w, h, fill})
+}
+
+// text draws s with its top-left corner at (x, y), each font pixel scale
+// units square. Horizontal runs of lit pixels become one rectangle.
+func (c *ogCard) text(s string, x, y, scale int, fill color.RGBA) {
+	for _, ch := range s {
+		glyph := ogGlyph(ch)
+		for row, bits := range glyph {
+			for col := 0; col < 5; {
+				if bits&(1<<(4-col)) == 0 {
+					col++
+					continue
+				}
+				start := col
+				for col < 5 && bits&(1<<(4-col)) != 0 {
+					col++
+				}
+				c.rect(x+start*scale, y+row*scale, (col-start)*scale, scale, fill)
+			}
+		}
+		x += 6 * scale
+	}
+}
+
+// SVG serializes the card, one path per fill color.
+func (c *ogCard) SVG() []byte {
+	var order []color.RGBA
+	paths := map[color.RGBA]*strings.Builder{}
+	for _, r := range c.rects {
+		b, ok := paths[r.fill]
+		if !ok {
+			b = &strings.Builder{}
+			paths[r.fill] = b
+			order = append(order, r.fill)
+		}
+		fmt.Fprintf(b, "M%d %dh%dv%dh-%dz", r.x, r.y, r.w, r.h, r.w)
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, ogWidth, ogHeight, ogWidth, ogHeight)
+	for _, fill := range order {
+		fmt.Fprintf(&buf, `<path fill="#%02x%02x%02x" d="%s"/>`, fill.R, fill.G, fill.B, paths[fill].String())
+	}
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
+
+// PNG rasterizes the card. The rectangles are pixel-aligned, so painting
+// them in order onto a paletted image is an exact rendering of the SVG.
+func (c *ogCard) PNG() ([]byte, error) {
+	var palette color.Palette
+	index := map[color.RGBA]uint8{}
+	for _, r := range c.rects {
+		if _, ok := index[r.fill]; !ok {
+			index[r.fill] = uint8(len(palette))
+			palette = append(palette, r.fill)
+		}
+	}
+	img := image.NewPaletted(image.Rect(0, 0, ogWidth, ogHeight), palette)
+	for _, r := range c.rects {
+		i := index[r.fill]
+		bounds := image.Rect(r.x, r.y, r.x+r.w, r.y+r.h).Intersect(img.Rect)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			row := img.Pix[img.PixOffset(bounds.Min.X, y):img.PixOffset(bounds.Max.X, y)]
+			for x := range row {
+				row[x] = i
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// siteHost is the host shown in a card's footer.
+func (s *Server) siteHost(r *http.Request) string {
+	base := s.siteURL(r)
+	if i := strings.Index(base, "://"); i >= 0 {
+		base = base[i+3:]
+	}
+	return base
+}
+
+// handleSiteImage serves /og/site.png, the card for pages without their own.
+func (s *Server) handleSiteImage(w http.ResponseWriter, r *http.Request) {
+	card := newOGCard(".gitignore templates",
+		strconv.Itoa(s.config.Templates.Count())+" templates, one API", s.siteHost(r))
+	writeOGCard(w, card, "png")
+}
+
+// handleTemplateImage serves /og/template/{file}, where file is a template
+// name plus ".png" or ".svg". The name is cut from the end by hand because
+// template names may themselves contain dots.
+func (s *Server) handleTemplateImage(w http.ResponseWriter, r *http.Request) {
+	file := chi.URLParam(r, "file")
+	i := strings.LastIndexByte(file, '.')
+	if i < 0 || (file[i+1:] != "png" && file[i+1:] != "svg") {
+		http.NotFound(w, r)
+		return
+	}
+	tmpl, err := s.config.Templates.Get(file[:i])
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	subtitle := ".gitignore template"
+	if tmpl.Category != "Root" {
+		subtitle += " - " + tmpl.Category
+	}
+	if content := strings.TrimRight(tmpl.Content, "\n"); content != "" {
+		subtitle += " - " + strconv.Itoa(strings.Count(content, "\n")+1) + " lines"
+	}
+	writeOGCard(w, newOGCard(tmpl.Name, subtitle, s.siteHost(r)), file[i+1:])
+}
+
+// writeOGCard writes card in format ("png" or "svg").
+func writeOGCard(w http.ResponseWriter, card *ogCard, format string) {
+	body, contentType := card.SVG(), "image/svg+xml"
+	if format == "png" {
+		var err error
+		if body, err = card.PNG(); err != nil {
+			http.Error(w, "image render failed", http.StatusInternalServerError)
+			return
+		}
+		contentType = "image/png"
+	}
+	w.Header().Set("Content-Type", contentType)
+	setCacheHeaders(w, "image")
+	_, _ = w.Write(body)
+}
+
+// ogImageURL is the absolute preview image URL for a template page.
+func ogImageURL(base, name string) string {
+	return base + "/og/template/" + url.PathEscape(name) + ".png"
+}
@@ -51,6 +51,17 @@ func (s *Server) openAPISpec(r *http.Request) map[string]interface{} {
 		},
 	}
 
+	days := map[string]interface{}{
+		"name": "days", "in": "query", "required": false,
+		"description": "Window in days (1-365, default 30)",
+		"schema":      map[string]interface{}{"type": "integer"},
+	}
+	limit := map[string]interface{}{
+		"name": "limit", "in": "query", "required": false,
+		"description": "Maximum rows per list (1-100, default 20)",
+		"schema":      map[string]interface{}{"type": "integer"},
+	}
+
 	get := func(summary string, params []interface{}) map[string]interface{} {
 		op := map[string]interface{}{
 			"summary": summary,
@@ -95,6 +106,93 @@ func (s *Server) openAPISpec(r *http.Request) map[string]interface{} {
 					"schema":      map[string]interface{}{"type": "string"},
 				},
 			}),
+			api + "/compose": get("Combine templates with per-template sections, duplicate and conflict annotations", []interface{}{
+				map[string]interface{}{
+					"name": "templates", "in": "query", "required": true,
+					"description": "Comma-separated template names; unknown names are reported with suggestions",
+					"schema":      map[string]interface{}{"type": "string"},
+				},
+			}),
+			api + "/compare": get("Matrix of normalized rules against templates, marking shared, partial, unique and conflicting rules", []interface{}{
+				map[string]interface{}{
+					"name": "templates", "in": "query", "required": true,
+					"description": "Comma-separated template names or dataset paths (at least two); unknown names are reported with suggestions",
+					"schema":      map[string]interface{}{"type": "string"},
+				},
+			}),
+			api + "/compare.txt": get("Export a comparison's union or intersection as a .gitignore", []interface{}{
+				map[string]interface{}{
+					"name": "templates", "in": "query", "required": true,
+					"description": "Comma-separated template names or dataset paths",
+					"schema":      map[string]interface{}{"type": "string"},
+				},
+				map[string]interface{}{
+					"name": "set", "in": "query", "required": false,
+					"description": "union (every rule, the default) or intersection (rules all templates share)",
+					"schema":      map[string]interface{}{"type": "string", "enum": []string{"union", "intersection"}},
+				},
+			}),
+			api + "/events": get("Server-Sent Events stream of dataset, configuration, scheduler and (with the admin token) update and Tor events", []interface{}{
+				map[string]interface{}{
+					"name": "types", "in": "query", "required": false,
+					"description": "Comma-separated event types to receive",
+					"schema":      map[string]interface{}{"type": "string"},
+				},
+				map[string]interface{}{
+					"name": "last_event_id", "in": "query", "required": false,
+					"description": "Resume after this event ID (alternative to the Last-Event-ID header)",
+					"schema":      map[string]interface{}{"type": "string"},
+				},
+			}),
+			api + "/permalinks": get("Encode templates into a permalink ID", []interface{}{
+				map[string]interface{}{
+					"name": "templates", "in": "query", "required": true,
+					"description": "Comma-separated template names",
+					"schema":      map[string]interface{}{"type": "string"},
+				},
+				map[string]interface{}{
+					"name": "pin", "in": "query", "required": false,
+					"description": "1 to pin the current dataset version",
+					"schema":      map[string]interface{}{"type": "integer", "enum": []int{0, 1}},
+				},
+			}),
+			api + "/permalinks/{id}": get("Resolve a permalink ID", []interface{}{
+				map[string]interface{}{
+					"name": "id", "in": "path", "required": true,
+					"description": "Permalink ID",
+					"schema":      map[string]interface{}{"type": "string"},
+				},
+			}),
+			api + "/stats/popular": get("Template fetch counts, route split and unknown names", []interface{}{days, limit}),
+			api + "/stats/combinations": get("Templates most often requested together", []interface{}{
+				days, limit,
+				map[string]interface{}{
+					"name": "template", "in": "query", "required": false,
+					"description": "Only pairs including this template",
+					"schema":      map[string]interface{}{"type": "string"},
+				},
+			}),
+			api + "/templates/{name}/history": get("Dataset revisions that touched a template", []interface{}{templateName}),
+			api + "/changes": get("Dataset revisions since a version or date", []interface{}{
+				map[string]interface{}{
+					"name": "since", "in": "query", "required": false,
+					"description": "Dataset version (exclusive) or date (YYYY-MM-DD or RFC 3339); omit for all",
+					"schema":      map[string]interface{}{"type": "string"},
+				},
+				map[string]interface{}{
+					"name": "templates", "in": "query", "required": false,
+					"description": "Comma-separated template names to limit the changes to",
+					"schema":      map[string]interface{}{"type": "string"},
+				},
+			}),
+			api + "/dataset": get("Loaded dataset version and the URL of its offline bundle", nil),
+			api + "/dataset/{version}.json": get("Every template with its content, for the given dataset version", []interface{}{
+				map[string]interface{}{
+					"name": "version", "in": "path", "required": true,
+					"description": "Dataset version (only the loaded one is served)",
+					"schema":      map[string]interface{}{"type": "string"},
+				},
+			}),
 		},
 		"components": map[string]interface{}{
 			"schemas": map[string]interface{}{
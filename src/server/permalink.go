@@ -0,0 +1,195 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// permalinkView is a permalink resolved against the loaded dataset.
+type permalinkView struct {
+	ID string `json:"id"`
+	// Templates are the permalink's names that still exist, canonicalized;
+	// Missing are those the current dataset no longer has.
+	Templates []string `json:"templates"`
+	Missing   []string `json:"missing"`
+	// Dataset is the pinned dataset version ("" when unpinned) and Current
+	// the loaded one. Stale is set when they differ; Changes then lists the
+	// revisions since Dataset that touched these templates, and ChangesKnown
+	// is false when the changelog has no record of Dataset.
+	Dataset      string              `json:"dataset,omitempty"`
+	Current      string              `json:"current"`
+	Stale        bool                `json:"stale"`
+	ChangesKnown bool                `json:"changes_known"`
+	Changes      []template.Revision `json:"changes"`
+	Content      string              `json:"content"`
+
+	composition *template.Composition
+}
+
+// newPermalinkIDs returns the follow-the-dataset and pinned permalink IDs for
+// known (canonical) template names.
+func (s *Server) newPermalinkIDs(known []string) (plain, pinned string, err error) {
+	if len(known) == 0 {
+		return "", "", nil
+	}
+	if plain, err = (template.Permalink{Templates: known}).Encode(); err != nil {
+		return "", "", err
+	}
+	pinned, err = (template.Permalink{Templates: known, Dataset: s.config.Templates.Version()}).Encode()
+	return plain, pinned, err
+}
+
+// permalinkPath is the page path for a permalink ID, or "" for no ID.
+func permalinkPath(id string) string {
+	if id == "" {
+		return ""
+	}
+	return "/c/" + id
+}
+
+// resolvePermalink decodes id and combines its templates from the loaded
+// dataset. Only a malformed or corrupted id is an error; templates that have
+// since been removed are reported in Missing.
+func (s *Server) resolvePermalink(id string) (*permalinkView, error) {
+	p, err := template.DecodePermalink(id)
+	if err != nil {
+		return nil, err
+	}
+	v := &permalinkView{
+		ID:      id,
+		Missing: []string{},
+		Dataset: p.Dataset,
+		Current: s.config.Templates.Version(),
+		Changes: []template.Revision{},
+	}
+	known, unknown := s.resolveTemplates(p.Templates)
+	v.Templates = append([]string{}, known...)
+	for _, u := range unknown {
+		v.Missing = append(v.Missing, u.Name)
+	}
+
+	if v.Dataset != "" && v.Dataset != v.Current {
+		v.Stale = true
+		if changelog := s.changelog.Load(); changelog != nil {
+			if revs, err := changelog.Since(v.Dataset); err == nil {
+				v.ChangesKnown = true
+				filter := make(map[string]bool, len(p.Templates))
+				for _, name := range p.Templates {
+					filter[strings.ToLower(name)] = true
+				}
+				if revs = filterRevisions(revs, filter); revs != nil {
+					v.Changes = revs
+				}
+			}
+		}
+	}
+
+	if len(known) > 0 {
+		comp, err := s.config.Templates.Compose(known)
+		if err != nil {
+			return nil, err
+		}
+		v.composition = comp
+		v.Content = comp.Render(known)
+	}
+	return v, nil
+}
+
+// handlePermalinkPage renders /c/{id}: the shared composition, with a warning
+// and the intervening template diffs when the link pins an older dataset.
+func (s *Server) handlePermalinkPage(w http.ResponseWriter, r *http.Request) {
+	v, err := s.resolvePermalink(chi.URLParam(r, "id"))
+	if err != nil {
+		s.renderErrorPage(w, r, http.StatusNotFound, "This permalink is invalid or was cut short when it was copied.")
+		return
+	}
+	data := map[string]interface{}{
+		"view":         v,
+		"composer_url": composerURL(v.Templates),
+		"raw_url":      permalinkPath(v.ID) + ".txt",
+	}
+	if v.composition != nil {
+		data["sections"] = v.composition.Sections
+	}
+	s.renderPage(w, r, "permalink", PageData{
+		Title:       "Shared .gitignore",
+		Description: "Combined .gitignore for " + strings.Join(v.Templates, ", ") + ".",
+		Data:        data,
+	})
+}
+
+// handlePermalinkText serves /c/{id}.txt, the combined file as plain text.
+func (s *Server) handlePermalinkText(w http.ResponseWriter, r *http.Request) {
+	v, err := s.resolvePermalink(chi.URLParam(r, "id"))
+	if err != nil {
+		sendAPIResponseError(w, "NOT_FOUND", "invalid permalink")
+		return
+	}
+	if len(v.Templates) == 0 {
+		sendAPIResponseError(w, "NOT_FOUND", "none of the permalink's templates exist")
+		return
+	}
+	s.recordUsage(sourceNative, v.Templates)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if r.URL.Query().Has("download") {
+		w.Header().Set("Content-Disposition", `attachment; filename=".gitignore"`)
+	}
+	fmt.Fprint(w, v.Content)
+}
+
+// handleAPIPermalinkCreate encodes ?templates= into permalink IDs. The result
+// is a pure function of its input, so nothing is stored and GET is safe.
+// ?pin=1 selects the ID pinned to the loaded dataset version.
+func (s *Server) handleAPIPermalinkCreate(w http.ResponseWriter, r *http.Request) {
+	names := parseTemplateList(r.URL.Query().Get("templates"))
+	if len(names) == 0 {
+		sendAPIResponseError(w, "BAD_REQUEST", "query parameter 'templates' is required")
+		return
+	}
+	known, unknown := s.resolveTemplates(names)
+	if len(unknown) > 0 {
+		missing := make([]string, len(unknown))
+		for i, u := range unknown {
+			missing[i] = u.Name
+		}
+		sendAPIResponseError(w, "BAD_REQUEST", "unknown templates: "+strings.Join(missing, ", "))
+		return
+	}
+	plain, pinned, err := s.newPermalinkIDs(known)
+	if err != nil {
+		sendAPIResponseError(w, "SERVER_ERROR", "failed to encode permalink")
+		return
+	}
+	id, dataset := plain, ""
+	if r.URL.Query().Get("pin") == "1" {
+		id, dataset = pinned, s.config.Templates.Version()
+	}
+	base := s.detectServerURL(r)
+	sendAPIResponseOK(w, map[string]interface{}{
+		"id":        id,
+		"url":       base + "/c/" + id,
+		"raw_url":   base + "/c/" + id + ".txt",
+		"templates": known,
+		"dataset":   dataset,
+	})
+}
+
+// handleAPIPermalink resolves a permalink ID.
+func (s *Server) handleAPIPermalink(w http.ResponseWriter, r *http.Request) {
+	v, err := s.resolvePermalink(chi.URLParam(r, "id"))
+	if errors.Is(err, template.ErrInvalidPermalink) {
+		sendAPIResponseError(w, "NOT_FOUND", err.Error())
+		return
+	}
+	if err != nil {
+		sendAPIResponseError(w, "SERVER_ERROR", "failed to resolve permalink")
+		return
+	}
+	sendAPIResponseOK(w, v)
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/template"
+)
+
+func newTestPermalinkServer(t *testing.T) (*Server, http.Handler) {
+	t.Helper()
+	s := newTestTemplatesServer(t)
+	r := chi.NewRouter()
+	r.Get("/c/{id}", s.handlePermalinkPage)
+	r.Get("/c/{id}.txt", s.handlePermalinkText)
+	r.Get("/api/v1/permalinks", s.handleAPIPermalinkCreate)
+	r.Get("/api/v1/permalinks/{id}", s.handleAPIPermalink)
+	return s, r
+}
+
+func TestPermalinkCreateAndResolve(t *testing.T) {
+	s, h := newTestPermalinkServer(t)
+	rec := doGet(t, h, "/api/v1/permalinks?templates=node,macos")
+	var created struct {
+		Data struct {
+			ID  string `json:"id"`
+			URL string `json:"url"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil || created.Data.ID == "" {
+		t.Fatalf("create: %d %s", rec.Code, rec.Body)
+	}
+	id := created.Data.ID
+	if !strings.HasSuffix(created.Data.URL, "/c/"+id) {
+		t.Errorf("url = %s", created.Data.URL)
+	}
+
+	want, _ := s.config.Templates.Combine([]string{"Node", "macOS"})
+	if rec := doGet(t, h, "/c/"+id+".txt"); rec.Code != http.StatusOK || rec.Body.String() != want {
+		t.Errorf("raw: status %d, matches combine = %v", rec.Code, rec.Body.String() == want)
+	}
+	if rec := doGet(t, h, "/c/"+id); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `href="/combine?templates=Node,macOS"`) {
+		t.Errorf("page: status %d", rec.Code)
+	}
+
+	if rec := doGet(t, h, "/api/v1/permalinks?templates=Go,pyhton"); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown template: status = %d", rec.Code)
+	}
+	if rec := doGet(t, h, "/c/"+id[:len(id)-3]); rec.Code != http.StatusNotFound {
+		t.Errorf("truncated id: status = %d", rec.Code)
+	}
+}
+
+// TestPermalinkStale verifies a link pinned to an older dataset reports the
+// changes to its templates since then, and names removed templates.
+func TestPermalinkStale(t *testing.T) {
+	s, h := newTestPermalinkServer(t)
+	oldGo := []*template.Template{{Name: "Go", Category: "Root", Content: "*.exe\n"}}
+	goTmpl, _ := s.config.Templates.Get("Go")
+	old := template.NewRevision(nil, oldGo, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "")
+	s.changelog.Store(&template.Changelog{Revisions: []template.Revision{
+		old,
+		template.NewRevision(oldGo, []*template.Template{goTmpl}, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), ""),
+	}})
+
+	id, err := template.Permalink{Templates: []string{"Go", "Gone"}, Dataset: old.Version}.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := doGet(t, h, "/api/v1/permalinks/"+id)
+	var resp struct {
+		Data permalinkView `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	v := resp.Data
+	if !v.Stale || !v.ChangesKnown || len(v.Changes) != 1 || v.Changes[0].Changes[0].Change != template.ChangeModified {
+		t.Errorf("stale view = %+v", v)
+	}
+	if len(v.Missing) != 1 || v.Missing[0] != "Gone" || len(v.Templates) != 1 {
+		t.Errorf("templates = %v, missing = %v", v.Templates, v.Missing)
+	}
+
+	body := doGet(t, h, "/c/"+id).Body.String()
+	if !strings.Contains(body, "These templates have changed since") || !strings.Contains(body, "&#43;*.test") {
+		t.Error("page missing the stale warning and diff")
+	}
+}
@@ -0,0 +1,302 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apimgr/gitignore/src/db"
+)
+
+// Route families a template can be fetched through.
+const (
+	sourceNative = "native"
+	sourceCompat = "compat"
+)
+
+const (
+	// popularityFlushInterval is how often in-memory counts are written to
+	// server.db. A crash loses at most this much analytics, never requests.
+	popularityFlushInterval = time.Minute
+	// maxComboSize bounds the pairs recorded per request; a 40-template
+	// combine would otherwise record 780 pairs.
+	maxComboSize = 12
+	// maxMissNamesPerDay caps the distinct unknown names (per route family)
+	// a running server records each day, and maxMissLen their length, so
+	// garbage requests add at most that many rows a day; the usage_cleanup
+	// task deletes days past server.maintenance.cleanup.usage_retention_days.
+	maxMissNamesPerDay = 2000
+	maxMissLen         = 64
+)
+
+type usageKey struct{ day, name, source string }
+
+type pairKey struct{ day, a, b string }
+
+// popularityCollector aggregates template usage in memory and periodically
+// flushes per-day totals to server.db. Like statsCollector it only ever holds
+// counters: no IPs, user agents or per-request rows.
+type popularityCollector struct {
+	mu      sync.Mutex
+	fetches map[usageKey]int64
+	pairs   map[pairKey]int64
+	misses  map[usageKey]int64
+	// missDay and missSeen are the distinct unknown names recorded today,
+	// counted against maxMissNamesPerDay across flushes.
+	missDay  string
+	missSeen map[usageKey]bool
+}
+
+// newPopularityCollector returns an empty collector.
+func newPopularityCollector() *popularityCollector {
+	return &popularityCollector{
+		fetches:  map[usageKey]int64{},
+		pairs:    map[pairKey]int64{},
+		misses:   map[usageKey]int64{},
+		missSeen: map[usageKey]bool{},
+	}
+}
+
+// record counts one request for resolved (canonical template names) and
+// unknown (names that did not resolve) through source. Templates requested
+// together also count as co-occurring pairs.
+func (p *popularityCollector) record(now time.Time, source string, resolved, unknown []string) {
+	day := now.UTC().Format(db.UsageDayLayout)
+	names := dedupeSorted(resolved)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, name := range names {
+		p.fetches[usageKey{day, name, source}]++
+	}
+	if len(names) >= 2 && len(names) <= maxComboSize {
+		for i := range names {
+			for j := i + 1; j < len(names); j++ {
+				p.pairs[pairKey{day, names[i], names[j]}]++
+			}
+		}
+	}
+	for _, name := range unknown {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || len(name) > maxMissLen {
+			continue
+		}
+		if day != p.missDay {
+			p.missDay = day
+			clear(p.missSeen)
+		}
+		key := usageKey{day, name, source}
+		if !p.missSeen[key] {
+			if len(p.missSeen) >= maxMissNamesPerDay {
+				continue
+			}
+			p.missSeen[key] = true
+		}
+		p.misses[key]++
+	}
+}
+
+// drain returns the accumulated counts and resets the collector.
+func (p *popularityCollector) drain() db.UsageBatch {
+	p.mu.Lock()
+	fetches, pairs, misses := p.fetches, p.pairs, p.misses
+	p.fetches, p.pairs, p.misses = map[usageKey]int64{}, map[pairKey]int64{}, map[usageKey]int64{}
+	p.mu.Unlock()
+
+	var b db.UsageBatch
+	for k, n := range fetches {
+		b.Fetches = append(b.Fetches, db.UsageCount{Day: k.day, Name: k.name, Source: k.source, Count: n})
+	}
+	for k, n := range pairs {
+		b.Pairs = append(b.Pairs, db.PairCount{Day: k.day, A: k.a, B: k.b, Count: n})
+	}
+	for k, n := range misses {
+		b.Misses = append(b.Misses, db.UsageCount{Day: k.day, Name: k.name, Source: k.source, Count: n})
+	}
+	return b
+}
+
+// flush writes the accumulated counts to server.db. Analytics are best-effort:
+// a failed write is logged and that interval's counts are dropped.
+func (p *popularityCollector) flush() {
+	if err := db.RecordUsage(p.drain()); err != nil {
+		log.Printf("server: flush usage analytics: %v", err)
+	}
+}
+
+// run flushes every interval until stop is closed, then flushes once more so
+// a graceful shutdown keeps the final partial interval.
+func (p *popularityCollector) run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.flush()
+		case <-stop:
+			p.flush()
+			return
+		}
+	}
+}
+
+// recordUsage resolves requested names against the loaded templates and
+// records them in the usage analytics and the business metrics. Either may be
+// disabled; with both off it does nothing.
+func (s *Server) recordUsage(source string, names []string) {
+	if s.popularity == nil && s.metrics == nil {
+		return
+	}
+	var resolved, unknown []string
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if tmpl, err := s.config.Templates.Get(name); err == nil {
+			resolved = append(resolved, tmpl.Name)
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	if s.popularity != nil {
+		s.popularity.record(time.Now(), source, resolved, unknown)
+	}
+	s.observeFetches(source, resolved, unknown)
+}
+
+// dedupeSorted returns the distinct names in sorted order.
+func dedupeSorted(names []string) []string {
+	out := append([]string(nil), names...)
+	sort.Strings(out)
+	n := 0
+	for i, name := range out {
+		if i == 0 || name != out[n-1] {
+			out[n] = name
+			n++
+		}
+	}
+	return out[:n]
+}
+
+// usageWindow parses ?days= (1–365, default 30) and ?limit= (1–100, default
+// 20) and returns the first day of the window in db.UsageDayLayout.
+func usageWindow(r *http.Request, now time.Time) (sinceDay string, days, limit int) {
+	days = clampQueryInt(r, "days", 30, 1, 365)
+	limit = clampQueryInt(r, "limit", 20, 1, 100)
+	sinceDay = now.UTC().AddDate(0, 0, -(days - 1)).Format(db.UsageDayLayout)
+	return sinceDay, days, limit
+}
+
+// clampQueryInt reads an integer query parameter, falling back to def when it
+// is missing or malformed and clamping it to [lo, hi].
+func clampQueryInt(r *http.Request, key string, def, lo, hi int) int {
+	n, err := strconv.Atoi(r.URL.Query().Get(key))
+	if err != nil {
+		return def
+	}
+	return min(max(n, lo), hi)
+}
+
+// popularStats gathers the /stats/popular payload for a window.
+func popularStats(sinceDay string, days, limit int) (map[string]interface{}, error) {
+	top, err := db.TopTemplates(sinceDay, limit)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := db.UsageBySource(sinceDay)
+	if err != nil {
+		return nil, err
+	}
+	daily, err := db.DailyFetches(sinceDay)
+	if err != nil {
+		return nil, err
+	}
+	unknown, err := db.TopMisses(sinceDay, limit)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"days":      days,
+		"since":     sinceDay,
+		"templates": top,
+		"routes": map[string]int64{
+			sourceNative: sources[sourceNative],
+			sourceCompat: sources[sourceCompat],
+		},
+		"daily":   daily,
+		"unknown": unknown,
+	}, nil
+}
+
+// handleAPIStatsPopular returns per-template fetch counts over ?days=, the
+// native vs gitignore.io-compat route split, daily totals, and the unknown
+// names most often requested.
+func (s *Server) handleAPIStatsPopular(w http.ResponseWriter, r *http.Request) {
+	if s.popularity == nil {
+		sendAPIResponseError(w, "NOT_IMPLEMENTED", "usage analytics unavailable")
+		return
+	}
+	data, err := popularStats(usageWindow(r, time.Now()))
+	if err != nil {
+		sendAPIResponseError(w, "SERVER_ERROR", "failed to load usage analytics")
+		return
+	}
+	sendAPIResponseOK(w, data)
+}
+
+// handleAPIStatsCombinations returns the template pairs most often requested
+// together over ?days=, optionally only those including ?template=.
+func (s *Server) handleAPIStatsCombinations(w http.ResponseWriter, r *http.Request) {
+	if s.popularity == nil {
+		sendAPIResponseError(w, "NOT_IMPLEMENTED", "usage analytics unavailable")
+		return
+	}
+	sinceDay, days, limit := usageWindow(r, time.Now())
+	name := r.URL.Query().Get("template")
+	if name != "" {
+		tmpl, err := s.config.Templates.Get(name)
+		if err != nil {
+			sendAPIResponseErrorLocalized(w, r, "NOT_FOUND", "template not found")
+			return
+		}
+		name = tmpl.Name
+	}
+	pairs, err := db.TopPairs(sinceDay, name, limit)
+	if err != nil {
+		sendAPIResponseError(w, "SERVER_ERROR", "failed to load usage analytics")
+		return
+	}
+	sendAPIResponseOK(w, map[string]interface{}{
+		"days":         days,
+		"since":        sinceDay,
+		"template":     name,
+		"combinations": pairs,
+	})
+}
+
+// combinedWith returns up to limit templates most often requested together
+// with name over the last 30 days, for the "frequently combined with" hint.
+// Errors and disabled analytics yield nil: the hint is optional.
+func (s *Server) combinedWith(name string, limit int) []string {
+	if s.popularity == nil {
+		return nil
+	}
+	since := time.Now().UTC().AddDate(0, 0, -29).Format(db.UsageDayLayout)
+	pairs, err := db.TopPairs(since, name, limit)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, p := range pairs {
+		if p.Templates[0] == name {
+			out = append(out, p.Templates[1])
+		} else {
+			out = append(out, p.Templates[0])
+		}
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/config"
+	"github.com/apimgr/gitignore/src/db"
+	"github.com/apimgr/gitignore/src/template"
+)
+
+func TestPopularityCollectorAggregates(t *testing.T) {
+	p := newPopularityCollector()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	p.record(now, sourceNative, []string{"Node", "Go", "Go"}, nil)
+	p.record(now, sourceCompat, []string{"Go"}, []string{"NoSuch", strings.Repeat("x", maxMissLen+1)})
+
+	b := p.drain()
+	fetches := map[string]int64{}
+	for _, c := range b.Fetches {
+		fetches[c.Name+"/"+c.Source] = c.Count
+	}
+	if fetches["Go/native"] != 1 || fetches["Node/native"] != 1 || fetches["Go/compat"] != 1 {
+		t.Errorf("fetches = %v", fetches)
+	}
+	if len(b.Pairs) != 1 || b.Pairs[0].A != "Go" || b.Pairs[0].B != "Node" || b.Pairs[0].Day != "2024-03-01" {
+		t.Errorf("pairs = %+v", b.Pairs)
+	}
+	if len(b.Misses) != 1 || b.Misses[0].Name != "nosuch" {
+		t.Errorf("misses = %+v", b.Misses)
+	}
+	if !p.drain().Empty() {
+		t.Error("drain did not reset the collector")
+	}
+}
+
+// TestPopularityMissesCappedPerDay verifies the cap on unknown names holds
+// across flushes and resets the next day.
+func TestPopularityMissesCappedPerDay(t *testing.T) {
+	p := newPopularityCollector()
+	day := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	misses := map[string]int64{}
+	collect := func() {
+		for _, c := range p.drain().Misses {
+			misses[c.Name] += c.Count
+		}
+	}
+	for i := range maxMissNamesPerDay + 10 {
+		p.record(day, sourceNative, nil, []string{fmt.Sprintf("garbage-%d", i)})
+		if i%100 == 0 {
+			collect()
+		}
+	}
+	// A name already recorded today still counts.
+	p.record(day, sourceNative, nil, []string{"garbage-0"})
+	collect()
+	if len(misses) != maxMissNamesPerDay || misses["garbage-0"] != 2 {
+		t.Errorf("recorded %d distinct misses (garbage-0 %d times), want the cap %d", len(misses), misses["garbage-0"], maxMissNamesPerDay)
+	}
+	p.record(day.AddDate(0, 0, 1), sourceNative, nil, []string{"garbage-new"})
+	if b := p.drain(); len(b.Misses) != 1 {
+		t.Errorf("next day misses = %+v, want the new name", b.Misses)
+	}
+}
+
+func TestStatsPopularEndpoints(t *testing.T) {
+	if err := db.Init(t.TempDir()); err != nil {
+		t.Fatalf("db init: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	tm, err := template.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{
+		config:     &Config{Version: "test", Templates: tm, Cfg: &config.Config{}},
+		popularity: newPopularityCollector(),
+	}
+	s.recordUsage(sourceNative, []string{"go", "node"})
+	s.recordUsage(sourceNative, []string{"Go", "Python"})
+	s.recordUsage(sourceCompat, []string{"go", "definitely-not-a-template"})
+	s.popularity.flush()
+
+	r := chi.NewRouter()
+	r.Get("/api/v1/stats/popular", s.handleAPIStatsPopular)
+	r.Get("/api/v1/stats/combinations", s.handleAPIStatsCombinations)
+
+	var popular struct {
+		Data struct {
+			Templates []db.TemplateUsage `json:"templates"`
+			Routes    map[string]int64   `json:"routes"`
+			Unknown   []db.NameCount     `json:"unknown"`
+		} `json:"data"`
+	}
+	rec := doGet(t, r, "/api/v1/stats/popular")
+	if err := json.Unmarshal(rec.Body.Bytes(), &popular); err != nil {
+		t.Fatal(err)
+	}
+	if top := popular.Data.Templates; len(top) != 3 || top[0].Template != "Go" || top[0].Total != 3 || top[0].Compat != 1 {
+		t.Errorf("templates = %+v", top)
+	}
+	if popular.Data.Routes[sourceNative] != 4 || popular.Data.Routes[sourceCompat] != 1 {
+		t.Errorf("routes = %v", popular.Data.Routes)
+	}
+	if len(popular.Data.Unknown) != 1 || popular.Data.Unknown[0].Name != "definitely-not-a-template" {
+		t.Errorf("unknown = %+v", popular.Data.Unknown)
+	}
+
+	rec = doGet(t, r, "/api/v1/stats/combinations?template=python")
+	if !strings.Contains(rec.Body.String(), `["Go","Python"]`) || strings.Contains(rec.Body.String(), "Node") {
+		t.Errorf("combinations = %s", rec.Body.String())
+	}
+	if got := s.combinedWith("Go", 5); len(got) != 2 {
+		t.Errorf("combinedWith(Go) = %v", got)
+	}
+	if rec := doGet(t, r, "/api/v1/stats/combinations?template=nope"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown template status = %d", rec.Code)
+	}
+
+	// Pruning drops only the days before the cutoff.
+	old := db.UsageBatch{Misses: []db.UsageCount{{Day: "2000-01-01", Name: "ancient", Source: sourceNative, Count: 1}}}
+	if err := db.RecordUsage(old); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := db.PruneUsage("2001-01-01"); err != nil || n != 1 {
+		t.Errorf("PruneUsage = %d, %v; want the one old row", n, err)
+	}
+	if top, err := db.TopTemplates("2001-01-01", 10); err != nil || len(top) != 3 {
+		t.Errorf("after pruning, templates = %+v, %v", top, err)
+	}
+}
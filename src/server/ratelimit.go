@@ -0,0 +1,323 @@
+package server
+
+import (
+	"container/list"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apimgr/gitignore/src/config"
+)
+
+// Route cost classes. Each request spends its class's token cost, so a bulk
+// archive download drains a bucket far faster than a single template fetch.
+const (
+	costClassDefault = "default"
+	costClassSearch  = "search"
+	costClassCombine = "combine"
+	costClassArchive = "archive"
+)
+
+// defaultMaxClients bounds the tracked-client LRU when config leaves it unset.
+const defaultMaxClients = 10000
+
+// defaultMaxStreams caps concurrent event streams per client when config
+// leaves it unset.
+const defaultMaxStreams = 4
+
+// ratePolicy is one bucket shape: capacity tokens refilled evenly over window.
+type ratePolicy struct {
+	name     string
+	nets     []*net.IPNet
+	capacity float64
+	window   time.Duration
+	rate     float64 // tokens per second
+}
+
+// rateClient is one client's bucket.
+type rateClient struct {
+	key    string
+	policy *ratePolicy
+	tokens float64
+	last   time.Time
+}
+
+// rateDecision is the outcome of one take, with what the headers report.
+type rateDecision struct {
+	allowed    bool
+	policy     *ratePolicy
+	remaining  int
+	reset      time.Duration // until the bucket is full again
+	retryAfter time.Duration // until this request's cost is available
+}
+
+// rateLimiter is a per-client-IP token-bucket limiter (AI.md PART 11). Clients
+// live in a fixed-size LRU so memory stays bounded however many addresses
+// hit the server; an evicted client simply starts again with a full bucket.
+// CIDR policies give matching clients (a CI NAT, a partner) their own limits
+// and exempt CIDRs bypass limiting entirely.
+type rateLimiter struct {
+	mu       sync.Mutex
+	clients  map[string]*list.Element
+	lru      *list.List // front is most recently seen
+	max      int
+	def      *ratePolicy
+	policies []*ratePolicy
+	exempt   []*net.IPNet
+	costs    map[string]int
+	// streams counts each client's open event streams; unlike buckets they
+	// are never evicted, since every entry is released when its stream ends.
+	streams    map[string]int
+	maxStreams int
+	// onEvict is called with the lock held whenever a client is evicted.
+	onEvict func()
+}
+
+// newRateLimiter builds a limiter from config. Unparseable CIDRs are skipped,
+// as for trusted proxies, rather than aborting startup.
+func newRateLimiter(cfg config.RateLimitConfig) *rateLimiter {
+	rl := &rateLimiter{
+		clients:    make(map[string]*list.Element),
+		lru:        list.New(),
+		max:        cfg.MaxClients,
+		def:        newRatePolicy("default", cfg.Requests, cfg.Window, nil),
+		exempt:     parseCIDRs(cfg.Exempt),
+		streams:    make(map[string]int),
+		maxStreams: cfg.MaxStreams,
+		costs: map[string]int{
+			costClassDefault: max(cfg.Costs.Default, 1),
+			costClassSearch:  max(cfg.Costs.Search, 1),
+			costClassCombine: max(cfg.Costs.Combine, 1),
+			costClassArchive: max(cfg.Costs.Archive, 1),
+		},
+	}
+	if rl.max <= 0 {
+		rl.max = defaultMaxClients
+	}
+	if rl.maxStreams <= 0 {
+		rl.maxStreams = defaultMaxStreams
+	}
+	for _, p := range cfg.Policies {
+		if nets := parseCIDRs(p.CIDRs); len(nets) > 0 {
+			rl.policies = append(rl.policies, newRatePolicy(p.Name, p.Requests, p.Window, nets))
+		}
+	}
+	return rl
+}
+
+// newRatePolicy builds a policy, defaulting a non-positive window to 60s and
+// size to one request.
+func newRatePolicy(name string, requests, windowSeconds int, nets []*net.IPNet) *ratePolicy {
+	if windowSeconds <= 0 {
+		windowSeconds = 60
+	}
+	requests = max(requests, 1)
+	return &ratePolicy{
+		name:     name,
+		nets:     nets,
+		capacity: float64(requests),
+		window:   time.Duration(windowSeconds) * time.Second,
+		rate:     float64(requests) / float64(windowSeconds),
+	}
+}
+
+// parseCIDRs parses IPs and CIDRs, widening bare IPs to host routes.
+func parseCIDRs(entries []string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil {
+				bits := 128
+				if ip.To4() != nil {
+					bits = 32
+				}
+				entry = fmt.Sprintf("%s/%d", entry, bits)
+			}
+		}
+		if _, n, err := net.ParseCIDR(entry); err == nil {
+			nets = append(nets, n)
+		}
+	}
+	return nets
+}
+
+// containsIP reports whether any of nets contains ip.
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// exempted reports whether ip bypasses rate limiting.
+func (rl *rateLimiter) exempted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && containsIP(rl.exempt, parsed)
+}
+
+// policyFor returns the first policy whose CIDRs contain ip, else the default.
+func (rl *rateLimiter) policyFor(ip string) *ratePolicy {
+	if parsed := net.ParseIP(ip); parsed != nil {
+		for _, p := range rl.policies {
+			if containsIP(p.nets, parsed) {
+				return p
+			}
+		}
+	}
+	return rl.def
+}
+
+// take spends cost tokens from ip's bucket if it holds enough. A cost larger
+// than the bucket is clamped to the bucket size so it stays satisfiable.
+func (rl *rateLimiter) take(ip string, cost int, now time.Time) rateDecision {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	var c *rateClient
+	if el, ok := rl.clients[ip]; ok {
+		rl.lru.MoveToFront(el)
+		c = el.Value.(*rateClient)
+		elapsed := now.Sub(c.last).Seconds()
+		c.tokens = math.Min(c.policy.capacity, c.tokens+elapsed*c.policy.rate)
+		c.last = now
+	} else {
+		p := rl.policyFor(ip)
+		c = &rateClient{key: ip, policy: p, tokens: p.capacity, last: now}
+		rl.clients[ip] = rl.lru.PushFront(c)
+		for rl.lru.Len() > rl.max {
+			oldest := rl.lru.Back()
+			rl.lru.Remove(oldest)
+			delete(rl.clients, oldest.Value.(*rateClient).key)
+			if rl.onEvict != nil {
+				rl.onEvict()
+			}
+		}
+	}
+
+	p := c.policy
+	need := math.Min(float64(cost), p.capacity)
+	d := rateDecision{policy: p}
+	if c.tokens >= need {
+		c.tokens -= need
+		d.allowed = true
+	} else {
+		d.retryAfter = secondsDuration((need - c.tokens) / p.rate)
+	}
+	d.remaining = int(c.tokens)
+	d.reset = secondsDuration((p.capacity - c.tokens) / p.rate)
+	return d
+}
+
+// acquireStream reserves one of ip's concurrent stream slots, reporting false
+// when the client already holds maxStreams. Exempt clients are not capped.
+// Every successful acquire must be paired with releaseStream.
+func (rl *rateLimiter) acquireStream(ip string) bool {
+	if rl.exempted(ip) {
+		return true
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.streams[ip] >= rl.maxStreams {
+		return false
+	}
+	rl.streams[ip]++
+	return true
+}
+
+// releaseStream frees a slot taken by acquireStream.
+func (rl *rateLimiter) releaseStream(ip string) {
+	if rl.exempted(ip) {
+		return
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if rl.streams[ip] <= 1 {
+		delete(rl.streams, ip)
+		return
+	}
+	rl.streams[ip]--
+}
+
+// size returns the number of clients currently tracked.
+func (rl *rateLimiter) size() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.lru.Len()
+}
+
+// secondsDuration converts fractional seconds to a Duration.
+func secondsDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// ceilSeconds rounds d up to whole seconds for header values.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// costClass maps a request path to its cost class.
+func costClass(path string) string {
+	api := apiBasePath()
+	switch {
+	case path == api+"/templates.tar.gz" || path == api+"/templates.json" || strings.HasPrefix(path, api+"/dataset/"):
+		return costClassArchive
+	case path == api+"/combine" || path == api+"/combine.txt" || path == api+"/compose" || path == "/combine":
+		return costClassCombine
+	case path == api+"/compare" || path == api+"/compare.txt" || path == "/compare":
+		// Comparing parses every rule of each template, as combining does.
+		return costClassCombine
+	case path == api+"/search" || path == api+"/search.txt" || path == "/search":
+		return costClassSearch
+	case strings.HasPrefix(path, "/c/") || strings.HasPrefix(path, api+"/permalinks/"):
+		// Resolving a permalink combines its templates.
+		return costClassCombine
+	case strings.HasPrefix(path, "/api/") && strings.Contains(path[len("/api/"):], ","):
+		// gitignore.io-compatible /api/{a,b,c} combines like /combine.
+		return costClassCombine
+	default:
+		return costClassDefault
+	}
+}
+
+// rateLimitMiddleware charges each request its route's cost against the
+// client's bucket. Every limited response carries the IETF RateLimit-Policy
+// and RateLimit headers; rejections add Retry-After and the spec's
+// RATE_LIMITED envelope.
+func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.limiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ip := s.clientIP(r)
+		if s.limiter.exempted(ip) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		class := costClass(r.URL.Path)
+		d := s.limiter.take(ip, s.limiter.costs[class], time.Now())
+		p := d.policy
+		h := w.Header()
+		h.Set("RateLimit-Policy", fmt.Sprintf("%q;q=%d;w=%d", p.name, int(p.capacity), int(p.window.Seconds())))
+		h.Set("RateLimit", fmt.Sprintf("%q;r=%d;t=%d", p.name, d.remaining, ceilSeconds(d.reset)))
+
+		if !d.allowed {
+			h.Set("Retry-After", strconv.Itoa(max(ceilSeconds(d.retryAfter), 1)))
+			if s.metrics != nil {
+				s.metrics.RateLimitRejected.WithLabelValues(p.name, class).Inc()
+			}
+			sendAPIResponseError(w, "RATE_LIMITED", "too many requests")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/apimgr/gitignore/src/config"
+	"github.com/apimgr/gitignore/src/server/metrics"
+)
+
+func testRateLimitConfig() config.RateLimitConfig {
+	return config.RateLimitConfig{
+		Enabled:    true,
+		Requests:   10,
+		Window:     10,
+		MaxClients: 100,
+		Costs:      config.RateLimitCosts{Default: 1, Search: 2, Combine: 3, Archive: 20},
+	}
+}
+
+// TestRateLimiterTokenBucket verifies costs drain the bucket, it refills at
+// requests/window, and an oversized cost is clamped to the bucket size.
+func TestRateLimiterTokenBucket(t *testing.T) {
+	rl := newRateLimiter(testRateLimitConfig())
+	now := time.Unix(1700000000, 0)
+
+	for i := 0; i < 3; i++ {
+		if d := rl.take("192.0.2.1", 3, now); !d.allowed {
+			t.Fatalf("take %d rejected", i)
+		}
+	}
+	d := rl.take("192.0.2.1", 3, now)
+	if d.allowed {
+		t.Fatal("fourth take of 3 allowed with 1 token left")
+	}
+	if d.remaining != 1 || d.retryAfter != 2*time.Second {
+		t.Errorf("remaining=%d retryAfter=%v, want 1 and 2s", d.remaining, d.retryAfter)
+	}
+
+	// One token per second refills.
+	if d := rl.take("192.0.2.1", 3, now.Add(2*time.Second)); !d.allowed {
+		t.Error("take after refill rejected")
+	}
+
+	// Archive cost exceeds the bucket; a full bucket still admits it.
+	if d := rl.take("192.0.2.2", 20, now); !d.allowed || d.remaining != 0 {
+		t.Errorf("oversized cost: allowed=%v remaining=%d", d.allowed, d.remaining)
+	}
+}
+
+// TestRateLimiterLRU verifies the client table stays bounded and evicts the
+// least recently seen client.
+func TestRateLimiterLRU(t *testing.T) {
+	cfg := testRateLimitConfig()
+	cfg.MaxClients = 2
+	rl := newRateLimiter(cfg)
+	evictions := 0
+	rl.onEvict = func() { evictions++ }
+	now := time.Now()
+
+	rl.take("192.0.2.1", 10, now)
+	rl.take("192.0.2.2", 1, now)
+	rl.take("192.0.2.1", 0, now) // touch .1 so .2 is oldest
+	rl.take("192.0.2.3", 1, now)
+
+	if rl.size() != 2 || evictions != 1 {
+		t.Fatalf("size=%d evictions=%d, want 2 and 1", rl.size(), evictions)
+	}
+	if d := rl.take("192.0.2.1", 1, now); d.allowed {
+		t.Error(".1 was evicted instead of .2")
+	}
+}
+
+// TestRateLimiterPolicies verifies exempt CIDRs and per-CIDR policies.
+func TestRateLimiterPolicies(t *testing.T) {
+	cfg := testRateLimitConfig()
+	cfg.Exempt = []string{"10.0.0.0/8", "2001:db8::1"}
+	cfg.Policies = []config.RateLimitPolicy{
+		{Name: "ci", CIDRs: []string{"203.0.113.0/28", "not-a-cidr"}, Requests: 1000, Window: 60},
+	}
+	rl := newRateLimiter(cfg)
+
+	for ip, want := range map[string]bool{"10.1.2.3": true, "2001:db8::1": true, "2001:db8::2": false, "192.0.2.1": false} {
+		if got := rl.exempted(ip); got != want {
+			t.Errorf("exempted(%s) = %v, want %v", ip, got, want)
+		}
+	}
+	if p := rl.policyFor("203.0.113.5"); p.name != "ci" || p.capacity != 1000 {
+		t.Errorf("policy for CI runner = %s/%v", p.name, p.capacity)
+	}
+	if p := rl.policyFor("203.0.113.17"); p.name != "default" {
+		t.Errorf("policy outside CIDR = %s, want default", p.name)
+	}
+}
+
+// TestCostClass verifies route paths map to their cost classes.
+func TestCostClass(t *testing.T) {
+	for path, want := range map[string]string{
+		"/api/v1/templates.tar.gz": costClassArchive,
+		"/api/v1/templates.json":   costClassArchive,
+		"/api/v1/combine.txt":      costClassCombine,
+		"/api/v1/compare":          costClassCombine,
+		"/api/v1/compare.txt":      costClassCombine,
+		"/compare":                 costClassCombine,
+		"/api/go,node,python":      costClassCombine,
+		"/api/v1/search":           costClassSearch,
+		"/search":                  costClassSearch,
+		"/api/v1/templates/Go":     costClassDefault,
+		"/api/list":                costClassDefault,
+	} {
+		if got := costClass(path); got != want {
+			t.Errorf("costClass(%s) = %s, want %s", path, got, want)
+		}
+	}
+}
+
+// TestRateLimitMiddlewareHeaders verifies the RateLimit headers on allowed
+// requests and Retry-After plus the RATE_LIMITED envelope on rejections.
+func TestRateLimitMiddlewareHeaders(t *testing.T) {
+	cfg := testRateLimitConfig()
+	cfg.Requests = 2
+	s := &Server{limiter: newRateLimiter(cfg), metrics: metrics.New(metrics.Options{})}
+	h := s.rateLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/templates/Go", nil)
+		req.RemoteAddr = "192.0.2.9:4321"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("first request = %d", rec.Code)
+	}
+	if got := rec.Header().Get("RateLimit-Policy"); got != `"default";q=2;w=10` {
+		t.Errorf("RateLimit-Policy = %q", got)
+	}
+	if got := rec.Header().Get("RateLimit"); got != `"default";r=1;t=5` {
+		t.Errorf("RateLimit = %q", got)
+	}
+
+	get()
+	rec = get()
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("third request = %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "5" {
+		t.Errorf("Retry-After = %q, want 5", got)
+	}
+	if got := testutil.ToFloat64(s.metrics.RateLimitRejected.WithLabelValues("default", costClassDefault)); got != 1 {
+		t.Errorf("rejections = %v, want 1", got)
+	}
+}
@@ -91,7 +91,8 @@ func sendAPIResponseErrorLocalized(w http.ResponseWriter, r *http.Request, code,
 
 // setCacheHeaders applies the spec's Cache-Control policy per response class
 // (AI.md PART 9 HTTP Cache Headers): static assets are immutable and long-lived,
-// API responses are briefly cacheable, HTML and error pages are never stored,
+// API responses are briefly cacheable, generated preview images are cacheable
+// for a day, HTML and error pages are never stored,
 // and authenticated responses are private and never stored.
 func setCacheHeaders(w http.ResponseWriter, kind string) {
 	switch kind {
@@ -99,6 +100,9 @@ func setCacheHeaders(w http.ResponseWriter, kind string) {
 		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
 	case "api":
 		w.Header().Set("Cache-Control", "public, max-age=60")
+	case "image":
+		// Generated preview images only change with the dataset.
+		w.Header().Set("Cache-Control", "public, max-age=86400")
 	case "authenticated":
 		w.Header().Set("Cache-Control", "private, no-store")
 	case "html", "error":
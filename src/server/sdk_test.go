@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/common/i18n"
+	"github.com/apimgr/gitignore/src/sdk"
+)
+
+// TestSDK verifies the public Go SDK against the server's own handlers:
+// every endpoint decodes, unknown templates come back as NOT_FOUND errors
+// with suggestions, and the templates archive revalidates by ETag.
+func TestSDK(t *testing.T) {
+	s := newTestTemplatesServer(t)
+	tm := s.config.Templates
+	r := chi.NewRouter()
+	r.Use(i18n.Middleware)
+	r.Get("/api/autodiscover", s.handleAPIAutodiscover)
+	r.Get("/api/list", s.handleCompatList)
+	r.Get("/api/{list}", s.handleCompatTemplates)
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Get("/list", s.handleAPIList)
+		r.Get("/search", s.handleAPISearch)
+		r.Get("/templates/{name}", s.handleAPITemplate)
+		r.Get("/combine", s.handleAPICombine)
+		r.Get("/compose", s.handleAPICompose)
+		r.Get("/compare", s.handleAPICompare)
+		r.Get("/categories", s.handleAPICategories)
+		r.Get("/categories/{name}", s.handleAPICategoryTemplates)
+		r.Get("/stats", s.handleAPIStats)
+		r.Get("/templates.tar.gz", s.handleAPITemplatesTarGz)
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	c := sdk.New(srv.URL)
+	ctx := context.Background()
+
+	names, err := c.List(ctx)
+	if err != nil || len(names) != tm.Count() {
+		t.Fatalf("List: %d names, %v; want %d", len(names), err, tm.Count())
+	}
+	if keys, err := c.CompatList(ctx); err != nil || !slices.Contains(keys, "go") {
+		t.Errorf("CompatList = %d keys, %v; want go among them", len(keys), err)
+	}
+	if results, err := c.Search(ctx, "python"); err != nil || len(results) == 0 || results[0].Name != "Python" {
+		t.Errorf("Search python = %v, %v", results, err)
+	}
+	if cats, err := c.Categories(ctx); err != nil || !slices.Contains(cats, "Global") {
+		t.Errorf("Categories = %v, %v", cats, err)
+	}
+	if templates, err := c.Category(ctx, "Global"); err != nil || len(templates) == 0 {
+		t.Errorf("Category Global = %d templates, %v", len(templates), err)
+	}
+	if stats, err := c.Stats(ctx); err != nil || stats.TotalTemplates != tm.Count() {
+		t.Errorf("Stats = %+v, %v", stats, err)
+	}
+	if info, err := c.Autodiscover(ctx); err != nil || info.APIBase != "/api/"+sdk.APIVersion {
+		t.Errorf("Autodiscover = %+v, %v", info, err)
+	}
+
+	tmpl, err := c.Template(ctx, "go")
+	if err != nil || tmpl.Name != "Go" || tmpl.Content == "" {
+		t.Fatalf("Template go = %+v, %v", tmpl, err)
+	}
+	_, err = c.Template(ctx, "Pyhton")
+	var apiErr *sdk.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != "NOT_FOUND" || !slices.Contains(apiErr.Suggestions, "Python") {
+		t.Errorf("Template Pyhton = %+v, want NOT_FOUND suggesting Python", err)
+	}
+
+	content, err := c.Combine(ctx, []string{"Go", "Node"}, nil)
+	if err != nil || !strings.Contains(content, "### Go ###") || !strings.Contains(content, "### Node ###") {
+		t.Errorf("Combine = %q, %v", content, err)
+	}
+	if compat, err := c.CompatCombine(ctx, []string{"Go", "Node"}); err != nil || !strings.Contains(compat, "### Node ###") {
+		t.Errorf("CompatCombine = %q, %v", compat, err)
+	}
+	_, err = c.Combine(ctx, []string{"Go", "Nodee"}, nil)
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound || !slices.Contains(apiErr.Suggestions, "Node") {
+		t.Errorf("Combine with Nodee = %+v, want a 404 suggesting Node", err)
+	}
+	if skipped, err := c.Combine(ctx, []string{"Go", "Nodee"}, &sdk.CombineOptions{SkipUnknown: true}); err != nil || !strings.Contains(skipped, "### Go ###") {
+		t.Errorf("Combine skipping unknown = %q, %v", skipped, err)
+	}
+
+	comp, err := c.Compose(ctx, []string{"Go", "Nodee"})
+	if err != nil || len(comp.Sections) != 1 || len(comp.Unknown) != 1 || comp.Unknown[0].Name != "Nodee" || comp.Permalink == "" {
+		t.Errorf("Compose = %+v, %v", comp, err)
+	}
+	cmp, err := c.Compare(ctx, []string{"Go", "Rust"})
+	if err != nil || len(cmp.Templates) != 2 || len(cmp.Rules) == 0 || len(cmp.Rules[0].Senses) != 2 {
+		t.Errorf("Compare = %+v, %v", cmp, err)
+	}
+
+	archive, etag, err := c.TemplatesArchive(ctx, "")
+	if err != nil || len(archive) == 0 || etag == "" {
+		t.Fatalf("TemplatesArchive: %d bytes, etag %q, %v", len(archive), etag, err)
+	}
+	if again, same, err := c.TemplatesArchive(ctx, etag); err != nil || again != nil || same != etag {
+		t.Errorf("TemplatesArchive revalidated: %d bytes, etag %q, %v; want 304", len(again), same, err)
+	}
+}
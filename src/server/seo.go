@@ -0,0 +1,238 @@
+package server
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// sitemapNS is the sitemaps.org protocol namespace shared by the index and
+// its child sitemaps.
+const sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// sitemapPages are the static HTML pages listed in the pages sitemap.
+var sitemapPages = []string{
+	"/", "/search", "/list", "/categories", "/combine", "/compare", "/stats", "/docs", "/cli",
+	"/server/about", "/server/help", "/server/privacy", "/server/contact", "/server/terms",
+	"/server/docs/swagger", "/server/docs/graphql",
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name       `xml:"sitemapindex"`
+	NS       string         `xml:"xmlns,attr"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlSet struct {
+	XMLName xml.Name       `xml:"urlset"`
+	NS      string         `xml:"xmlns,attr"`
+	URLs    []sitemapEntry `xml:"url"`
+}
+
+// siteURL is the absolute URL of the site root without a trailing slash: the
+// detected scheme and host plus the configured base URL prefix. Handlers see
+// paths with the prefix already stripped, so links meant for crawlers and
+// link unfurlers have to put it back.
+func (s *Server) siteURL(r *http.Request) string {
+	base := s.detectServerURL(r)
+	if s.config.Cfg != nil {
+		if prefix := s.config.Cfg.Server.BaseURL; prefix != "" && prefix != "/" {
+			base += prefix
+		}
+	}
+	return base
+}
+
+// robotsDeny returns a matcher for the web_robots.deny rules using the
+// robots.txt matching rules (RFC 9309): prefix match, "*" matches any run of
+// characters and a trailing "$" anchors the end. The sitemap leaves matching
+// paths out so it never advertises what robots.txt forbids.
+func (s *Server) robotsDeny() func(path string) bool {
+	rules := []string{"/debug"}
+	if s.config.Cfg != nil {
+		rules = s.config.Cfg.WebRobots.Deny
+	}
+	var patterns []*regexp.Regexp
+	for _, rule := range rules {
+		if rule == "" {
+			continue
+		}
+		anchored := strings.HasSuffix(rule, "$")
+		parts := strings.Split(strings.TrimSuffix(rule, "$"), "*")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		expr := "^" + strings.Join(parts, ".*")
+		if anchored {
+			expr += "$"
+		}
+		patterns = append(patterns, regexp.MustCompile(expr))
+	}
+	return func(path string) bool {
+		for _, p := range patterns {
+			if p.MatchString(path) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// handleSitemapIndex serves /sitemap.xml, an index of the per-kind sitemaps.
+func (s *Server) handleSitemapIndex(w http.ResponseWriter, r *http.Request) {
+	base := s.siteURL(r)
+	index := sitemapIndex{NS: sitemapNS}
+	for _, kind := range []string{"pages", "categories", "templates"} {
+		entry := sitemapEntry{Loc: base + "/sitemaps/" + kind + ".xml"}
+		if kind == "templates" {
+			entry.LastMod = s.datasetLastMod()
+		}
+		index.Sitemaps = append(index.Sitemaps, entry)
+	}
+	writeXML(w, index)
+}
+
+// handleSitemap serves /sitemaps/{kind}.xml, where kind is pages, categories
+// or templates.
+func (s *Server) handleSitemap(w http.ResponseWriter, r *http.Request) {
+	base := s.siteURL(r)
+	denied := s.robotsDeny()
+	set := urlSet{NS: sitemapNS, URLs: []sitemapEntry{}}
+	add := func(path, lastMod string) {
+		if denied(path) {
+			return
+		}
+		set.URLs = append(set.URLs, sitemapEntry{Loc: base + path, LastMod: lastMod})
+	}
+
+	switch chi.URLParam(r, "kind") {
+	case "pages":
+		for _, path := range sitemapPages {
+			add(path, "")
+		}
+	case "categories":
+		for _, category := range s.config.Templates.GetCategories() {
+			add("/list?category="+url.QueryEscape(category), "")
+		}
+	case "templates":
+		for _, name := range s.config.Templates.List() {
+			add("/template/"+url.PathEscape(name), s.templateLastMod(name))
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	writeXML(w, set)
+}
+
+// writeXML writes v as an XML document with the sitemap content type.
+func writeXML(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	setCacheHeaders(w, "api")
+	fmt.Fprint(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// templateLastMod is the date name last changed in the dataset, or "" when
+// the changelog has no record of it.
+func (s *Server) templateLastMod(name string) string {
+	changelog := s.changelog.Load()
+	if changelog == nil {
+		return ""
+	}
+	if history := changelog.History(name); len(history) > 0 {
+		return history[0].Date.UTC().Format("2006-01-02")
+	}
+	return ""
+}
+
+// datasetLastMod is the date of the newest dataset revision, or "".
+func (s *Server) datasetLastMod() string {
+	changelog := s.changelog.Load()
+	if changelog == nil || changelog.Latest() == nil {
+		return ""
+	}
+	return changelog.Latest().Date.UTC().Format("2006-01-02")
+}
+
+// seoKeywords returns the configured site keywords followed by extra,
+// without duplicates.
+func (s *Server) seoKeywords(extra ...string) []string {
+	var keywords []string
+	if s.config.Cfg != nil {
+		keywords = append(keywords, s.config.Cfg.Server.SEO.Keywords...)
+	}
+	keywords = append(keywords, extra...)
+	seen := make(map[string]bool, len(keywords))
+	out := keywords[:0]
+	for _, k := range keywords {
+		if k == "" || seen[strings.ToLower(k)] {
+			continue
+		}
+		seen[strings.ToLower(k)] = true
+		out = append(out, k)
+	}
+	return out
+}
+
+// websiteLD is the home page's JSON-LD: the site with its search action, so
+// search engines can offer a search box for it.
+func websiteLD(base string) map[string]interface{} {
+	return map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "WebSite",
+		"name":     "GitIgnore",
+		"url":      base + "/",
+		"potentialAction": map[string]interface{}{
+			"@type":       "SearchAction",
+			"target":      base + "/search?q={search_term_string}",
+			"query-input": "required name=search_term_string",
+		},
+	}
+}
+
+// templateLD is a template page's JSON-LD: the template as source code plus
+// its breadcrumb trail through the category listing.
+func templateLD(base string, tmpl *template.Template, description string, modified time.Time) []interface{} {
+	page := base + "/template/" + url.PathEscape(tmpl.Name)
+	code := map[string]interface{}{
+		"@context":            "https://schema.org",
+		"@type":               "SoftwareSourceCode",
+		"name":                tmpl.Name + " .gitignore",
+		"description":         description,
+		"url":                 page,
+		"codeRepository":      upstreamBlobURL + tmpl.UpstreamPath(),
+		"programmingLanguage": "gitignore",
+		"keywords":            strings.Join(tmpl.Tags, ", "),
+		// github/gitignore is published under CC0.
+		"license": "https://creativecommons.org/publicdomain/zero/1.0/",
+	}
+	if !modified.IsZero() {
+		code["dateModified"] = modified.UTC().Format(time.RFC3339)
+	}
+	crumbs := map[string]interface{}{
+		"@context": "https://schema.org",
+		"@type":    "BreadcrumbList",
+		"itemListElement": []map[string]interface{}{
+			{"@type": "ListItem", "position": 1, "name": "Templates", "item": base + "/list"},
+			{"@type": "ListItem", "position": 2, "name": tmpl.Category, "item": base + "/list?category=" + url.QueryEscape(tmpl.Category)},
+			{"@type": "ListItem", "position": 3, "name": tmpl.Name, "item": page},
+		},
+	}
+	return []interface{}{code, crumbs}
+}
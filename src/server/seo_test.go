@@ -0,0 +1,196 @@
+package server
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/config"
+	"github.com/apimgr/gitignore/src/template"
+)
+
+func newSEOTestServer(t *testing.T, cfg *config.Config) (*Server, http.Handler) {
+	t.Helper()
+	tm, err := template.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{config: &Config{Version: "test", Templates: tm, Cfg: cfg}}
+	r := chi.NewRouter()
+	r.Get("/robots.txt", s.handleRobotsTxt)
+	r.Get("/sitemap.xml", s.handleSitemapIndex)
+	r.Get("/sitemaps/{kind}.xml", s.handleSitemap)
+	r.Get("/og/site.png", s.handleSiteImage)
+	r.Get("/og/template/{file}", s.handleTemplateImage)
+	r.Get("/template/{name}", s.handleTemplatePage)
+	r.Get("/list", s.handleListPage)
+	return s, r
+}
+
+func TestSitemap(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.BaseURL = "/gi"
+	cfg.Server.FQDN = "example.com"
+	cfg.WebRobots.Deny = []string{"/debug", "/server/docs/", "/template/Visual*io$"}
+	s, h := newSEOTestServer(t, cfg)
+
+	rec := doGet(t, h, "/sitemap.xml")
+	var index sitemapIndex
+	if err := xml.Unmarshal(rec.Body.Bytes(), &index); err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Sitemaps) != 3 || index.Sitemaps[2].Loc != "https://example.com/gi/sitemaps/templates.xml" {
+		t.Fatalf("index = %+v", index.Sitemaps)
+	}
+
+	locs := func(kind string) map[string]bool {
+		rec := doGet(t, h, "/sitemaps/"+kind+".xml")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d", kind, rec.Code)
+		}
+		var set urlSet
+		if err := xml.Unmarshal(rec.Body.Bytes(), &set); err != nil {
+			t.Fatal(err)
+		}
+		out := map[string]bool{}
+		for _, u := range set.URLs {
+			out[strings.TrimPrefix(u.Loc, "https://example.com/gi")] = true
+		}
+		return out
+	}
+
+	templates := locs("templates")
+	if !templates["/template/Go"] || !templates["/template/VisualStudioCode"] {
+		t.Error("templates sitemap is missing Go or VisualStudioCode")
+	}
+	if templates["/template/VisualStudio"] {
+		t.Error("anchored deny rule should drop /template/VisualStudio")
+	}
+	if len(templates) != s.config.Templates.Count()-1 {
+		t.Errorf("templates sitemap has %d URLs, want %d", len(templates), s.config.Templates.Count()-1)
+	}
+
+	pages := locs("pages")
+	if !pages["/docs"] || pages["/server/docs/swagger"] {
+		t.Errorf("pages sitemap = %v", pages)
+	}
+	if !locs("categories")["/list?category=Global"] {
+		t.Error("categories sitemap is missing Global")
+	}
+	if rec := doGet(t, h, "/sitemaps/other.xml"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown sitemap: status = %d", rec.Code)
+	}
+
+	if body := doGet(t, h, "/robots.txt").Body.String(); !strings.Contains(body, "Sitemap: https://example.com/gi/sitemap.xml") {
+		t.Errorf("robots.txt does not point at the sitemap:\n%s", body)
+	}
+}
+
+func TestRobotsDeny(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.WebRobots.Deny = []string{"/private", "/*.txt$", ""}
+	s := &Server{config: &Config{Cfg: cfg}}
+	denied := s.robotsDeny()
+	for path, want := range map[string]bool{
+		"/private":        true,
+		"/private/x":      true,
+		"/template/x.txt": true,
+		"/x.txt?y=1":      false,
+		"/public":         false,
+	} {
+		if got := denied(path); got != want {
+			t.Errorf("denied(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestTemplatePageMeta(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.FQDN = "example.com"
+	cfg.Server.SEO.Keywords = []string{"gitignore", "templates"}
+	_, h := newSEOTestServer(t, cfg)
+
+	body := doGet(t, h, "/template/Go").Body.String()
+	for _, want := range []string{
+		`<link rel="canonical" href="https://example.com/template/Go">`,
+		`<meta property="og:image" content="https://example.com/og/template/Go.png">`,
+		`<meta name="twitter:card" content="summary_large_image">`,
+		`<meta name="keywords" content="gitignore, templates, Go, root, golang">`,
+		`<script type="application/ld+json">`,
+		`"@type":"SoftwareSourceCode"`,
+		`"@type":"BreadcrumbList"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("template page missing %q", want)
+		}
+	}
+
+	body = doGet(t, h, "/template/JBoss6").Body.String()
+	if want := `"codeRepository":"https://github.com/github/gitignore/blob/main/community/Java/JBoss6.gitignore"`; !strings.Contains(body, want) {
+		t.Errorf("nested template page missing %q", want)
+	}
+
+	body = doGet(t, h, "/list?category=Global").Body.String()
+	if !strings.Contains(body, `<link rel="canonical" href="https://example.com/list?category=Global">`) ||
+		!strings.Contains(body, "<title>Global Templates") {
+		t.Error("category listing should have its own title and canonical URL")
+	}
+
+	if body := doGet(t, h, "/template/Nope").Body.String(); strings.Contains(body, `rel="canonical"`) {
+		t.Error("404 page should have no canonical URL")
+	}
+}
+
+func TestOGImage(t *testing.T) {
+	_, h := newSEOTestServer(t, &config.Config{})
+
+	rec := doGet(t, h, "/og/template/Go.AllowList.png")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("status = %d, type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	img, err := png.Decode(bytes.NewReader(rec.Body.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != ogWidth || b.Dy() != ogHeight {
+		t.Errorf("size = %v", b)
+	}
+	// The accent bar on the left edge and the background beside it.
+	if r, g, b, _ := img.At(4, 300).RGBA(); r>>8 != 0xf0 || g>>8 != 0x50 || b>>8 != 0x33 {
+		t.Errorf("accent pixel = %x %x %x", r>>8, g>>8, b>>8)
+	}
+	if r, _, _, _ := img.At(40, 300).RGBA(); r>>8 != 0x1a {
+		t.Errorf("background pixel red = %x", r>>8)
+	}
+
+	rec = doGet(t, h, "/og/template/Go.svg")
+	if rec.Header().Get("Content-Type") != "image/svg+xml" || !strings.HasPrefix(rec.Body.String(), "<svg ") {
+		t.Error("svg variant not served")
+	}
+	if err := xml.Unmarshal(rec.Body.Bytes(), new(struct{})); err != nil {
+		t.Errorf("svg is not well-formed: %v", err)
+	}
+
+	for _, path := range []string{"/og/template/Nope.png", "/og/template/Go.gif", "/og/template/Go"} {
+		if rec := doGet(t, h, path); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %d", path, rec.Code)
+		}
+	}
+	if rec := doGet(t, h, "/og/site.png"); rec.Code != http.StatusOK {
+		t.Errorf("site card: status = %d", rec.Code)
+	}
+}
+
+func TestOGCardTitleFits(t *testing.T) {
+	card := newOGCard(strings.Repeat("W", 80), "", "")
+	for _, r := range card.rects {
+		if r.x+r.w > ogWidth-ogMargin && r.fill == ogTitle {
+			t.Fatalf("title runs past the margin: %+v", r)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"os/signal"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -63,6 +64,16 @@ type Server struct {
 	geoip         *geoip.Manager
 	startTime     time.Time
 	stats         *statsCollector
+	// changelog is the embedded changelog merged with synced revisions
+	// (see ReloadChangelog).
+	changelog     atomic.Pointer[template.Changelog]
+	popularity    *popularityCollector
+	// branding holds the live server.branding settings (see ReloadBranding).
+	branding      atomic.Pointer[config.BrandingConfig]
+	// events backs the /api/v1/events stream (see PublishEvent).
+	events        *eventHub
+	// cliRelease backs autodiscover's cli_versions (see SetCLIRelease).
+	cliRelease    atomic.Pointer[CLIRelease]
 }
 
 // New creates a new server instance
@@ -73,6 +84,28 @@ func New(config *Config) *Server {
 		geoip:     config.GeoIP,
 		startTime: time.Now(),
 		stats:     newStatsCollector(),
+		// New runs after db.Init, so usage analytics can flush to server.db.
+		popularity: newPopularityCollector(),
+	}
+
+	// Branding follows SIGHUP reloads; publishing it here also logs any
+	// settings that will be ignored.
+	if config.Cfg != nil {
+		s.ReloadBranding(config.Cfg.Server.Branding)
+	}
+
+	// The event hub falls back to its own defaults for unset values.
+	var eventBuffer, eventHeartbeat int
+	if config.Cfg != nil {
+		eventBuffer = config.Cfg.Server.Events.Buffer
+		eventHeartbeat = config.Cfg.Server.Events.Heartbeat
+	}
+	s.events = newEventHub(eventBuffer, time.Duration(eventHeartbeat)*time.Second)
+
+	// Enable per-IP rate limiting only when the operator turns it on. Built
+	// before metrics so the tracked-client gauge can read it.
+	if config.Cfg != nil && config.Cfg.Server.RateLimit.Enabled {
+		s.limiter = newRateLimiter(config.Cfg.Server.RateLimit)
 	}
 
 	// Prometheus metrics subsystem (AI.md PART 20). Built on
@@ -88,18 +121,24 @@ func New(config *Config) *Server {
 			templates := config.Templates
 			mOpts.TemplatesFn = func() int { return templates.Count() }
 		}
+		if s.limiter != nil {
+			mOpts.RateLimitClientsFn = s.limiter.size
+		}
 		if config.Cfg != nil {
 			mOpts.IncludeRuntime = config.Cfg.Server.Metrics.IncludeRuntime
 			mOpts.DurationBuckets = config.Cfg.Server.Metrics.DurationBuckets
 			mOpts.SizeBuckets = config.Cfg.Server.Metrics.SizeBuckets
 		}
 		s.metrics = metrics.New(mOpts)
+		if s.limiter != nil {
+			evictions := s.metrics.RateLimitEvictions
+			s.limiter.onEvict = evictions.Inc
+		}
 	}
 
-	// Enable per-IP rate limiting only when the operator turns it on.
-	if config.Cfg != nil && config.Cfg.Server.RateLimit.Enabled {
-		s.limiter = newRateLimiter(config.Cfg.Server.RateLimit.Requests, config.Cfg.Server.RateLimit.Window)
-	}
+	// The dataset changelog: the one embedded at build time plus the
+	// revisions synced since.
+	s.ReloadChangelog()
 
 	// Parse the trusted-proxy allowlist once at startup (AI.md PART 12).
 	var additional []string
@@ -156,6 +195,9 @@ func New(config *Config) *Server {
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
+	// Shutdown waits for active requests; end the event streams so it need
+	// not wait out their clients.
+	s.server.RegisterOnShutdown(s.events.close)
 
 	return s
 }
@@ -204,8 +246,8 @@ func (s *Server) setupMiddleware() {
 		s.router.Use(i18n.Middleware)
 	}
 
-	// Timeout
-	s.router.Use(middleware.Timeout(30 * time.Second))
+	// Timeout, except for the long-lived event stream
+	s.router.Use(skipTimeout(middleware.Timeout(30*time.Second), apiBasePath()+"/events"))
 
 	// Compression
 	s.router.Use(middleware.Compress(5))
@@ -228,6 +270,8 @@ func (s *Server) setupMiddleware() {
 // setupRoutes configures all routes
 func (s *Server) setupRoutes() {
 	// Admin routes (session auth for web, bearer token for API)
+	s.adminHandler.HandleAPI(http.MethodPut, "/branding/{asset}", s.handleBrandingUpload)
+	s.adminHandler.HandleAPI(http.MethodDelete, "/branding/{asset}", s.handleBrandingDelete)
 	s.adminHandler.RegisterRoutes(s.router)
 
 	// Themed error handlers for unmatched routes and methods (AI.md PART 16)
@@ -262,6 +306,21 @@ func (s *Server) setupRoutes() {
 	s.router.Get("/manifest.json", s.handleManifest)
 	s.router.Get("/sw.js", s.handleServiceWorker)
 
+	// Sitemaps and Open Graph preview images for crawlers and link unfurlers
+	s.router.Get("/sitemap.xml", s.handleSitemapIndex)
+	s.router.Get("/sitemaps/{kind}.xml", s.handleSitemap)
+	s.router.Get("/og/site.png", s.handleSiteImage)
+	s.router.Get("/og/template/{file}", s.handleTemplateImage)
+
+	// README badges, the framable template view and oEmbed discovery. Only
+	// /embed may be framed by other sites, so only it gets the relaxed policy.
+	s.router.Get("/badge/{file}", s.handleBadge)
+	s.router.With(s.cspOverride(embedCSP)).Get("/embed/{name}", s.handleEmbed)
+	s.router.Get("/oembed", s.handleOEmbed)
+
+	// Dataset changelog feed (subscribable in any feed reader)
+	s.router.Get("/feeds/templates.atom", s.handleTemplatesFeed)
+
 	// Frontend locale catalogs (AI.md PART 30 "/locales/{lang}.json")
 	s.router.Get("/locales/{lang}.json", s.handleLocaleJSON)
 
@@ -273,6 +332,11 @@ func (s *Server) setupRoutes() {
 
 	// Combine
 	s.router.Get("/combine", s.handleCombinePage)
+	s.router.Get("/compare", s.handleComparePage)
+
+	// Composition permalinks (stateless: the ID encodes the template list)
+	s.router.Get("/c/{id}", s.handlePermalinkPage)
+	s.router.Get("/c/{id}.txt", s.handlePermalinkText)
 
 	// Categories
 	s.router.Get("/categories", s.handleCategoriesPage)
@@ -313,6 +377,11 @@ func (s *Server) setupRoutes() {
 	s.router.Get("/static/*", s.handleStatic)
 	s.router.Get("/favicon.ico", s.handleFavicon)
 
+	// Operator branding: theme token overrides and the uploaded logo and
+	// favicon, which may be SVG and so get a script-free policy.
+	s.router.Get("/branding/theme.css", s.handleBrandingTheme)
+	s.router.With(s.cspOverride(brandingAssetCSP)).Get("/branding/{asset}", s.handleBrandingAsset)
+
 	// Root-level API aliases (AI.md PART 14 "Root-Level Endpoints") — thin
 	// wrappers over the canonical versioned handlers, no logic duplication.
 	s.router.Get("/api/swagger", s.handleOpenAPIJSON)
@@ -344,29 +413,44 @@ func (s *Server) setupRoutes() {
 		r.Get("/templates/{name}", s.handleAPITemplate)
 		r.Get("/templates/{name}.txt", s.handleAPITemplateText)
 		r.Get("/templates/{name}.json", s.handleAPITemplateJSON)
+		r.Get("/templates/{name}/history", s.handleAPITemplateHistory)
+		r.Get("/changes", s.handleAPIChanges)
 		r.Get("/list", s.handleAPIList)
 		r.Get("/list.txt", s.handleAPIListText)
 		r.Get("/search", s.handleAPISearch)
 		r.Get("/search.txt", s.handleAPISearchText)
 		r.Get("/combine", s.handleAPICombine)
 		r.Get("/combine.txt", s.handleAPICombineText)
+		r.Get("/compose", s.handleAPICompose)
+		r.Get("/compare", s.handleAPICompare)
+		r.Get("/compare.txt", s.handleAPICompareText)
+		r.Get("/events", s.handleEvents)
+		r.Get("/permalinks", s.handleAPIPermalinkCreate)
+		r.Get("/permalinks/{id}", s.handleAPIPermalink)
 		r.Get("/categories", s.handleAPICategories)
 		r.Get("/categories.txt", s.handleAPICategoriesText)
 		r.Get("/categories/{name}", s.handleAPICategoryTemplates)
 		r.Get("/categories/{name}.txt", s.handleAPICategoryTemplatesText)
 		r.Get("/stats", s.handleAPIStats)
 		r.Get("/stats.txt", s.handleAPIStatsText)
+		r.Get("/stats/popular", s.handleAPIStatsPopular)
+		r.Get("/stats/combinations", s.handleAPIStatsCombinations)
 
 		// Export
 		r.Get("/templates.json", s.handleAPITemplatesJSON)
 		r.Get("/templates.tar.gz", s.handleAPITemplatesTarGz)
 
+		// Versioned dataset bundle for the PWA's offline copy
+		r.Get("/dataset", s.handleAPIDataset)
+		r.Get("/dataset/{version}.json", s.handleAPIDatasetBundle)
+
 		// CLI scripts
 		r.Get("/cli/sh", s.handleCLIScriptSh)
 		r.Get("/cli/ps", s.handleCLIScriptPs)
 		r.Get("/cli/completion/bash", s.handleCLICompletionBash)
 		r.Get("/cli/completion/zsh", s.handleCLICompletionZsh)
 		r.Get("/cli/completion/fish", s.handleCLICompletionFish)
+		r.Get("/cli/binaries/{name}", s.handleCLIBinary)
 	})
 
 	// gitignore.io route/API compatibility layer (unversioned, mounted
@@ -433,6 +517,19 @@ func (s *Server) Start() error {
 		}
 	}()
 
+	// Flush usage analytics in the background; the final flush runs once
+	// serving stops.
+	stopFlush := make(chan struct{})
+	flushDone := make(chan struct{})
+	go func() {
+		s.popularity.run(popularityFlushInterval, stopFlush)
+		close(flushDone)
+	}()
+	defer func() {
+		close(stopFlush)
+		<-flushDone
+	}()
+
 	// Start server
 	log.Printf("Server starting on %s", s.server.Addr)
 	if s.server.TLSConfig != nil {
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/template"
+)
+
+func TestTemplatePage(t *testing.T) {
+	s := newTestTemplatesServer(t)
+	goTmpl, _ := s.config.Templates.Get("Go")
+	s.changelog.Store(&template.Changelog{Revisions: []template.Revision{
+		template.NewRevision(nil, []*template.Template{goTmpl}, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), ""),
+	}})
+	r := chi.NewRouter()
+	r.Get("/template/{name}", s.handleTemplatePage)
+
+	rec := doGet(t, r, "/template/go")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		`<span class="line" id="L1"><a class="ln" href="#L1" aria-label="Line 1">1</a>`,
+		`<span class="tok-comment">`,
+		`href="/combine?templates=Go"`,
+		`href="/api/v1/templates/Go.txt?download=1"`,
+		`href="https://github.com/github/gitignore/blob/main/Go.gitignore"`,
+		`<time datetime="2024-03-01T00:00:00Z">2024-03-01</time>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+
+	// Templates nested below their category link to their real upstream
+	// file.
+	rec = doGet(t, r, "/template/JBoss6")
+	if want := `href="https://github.com/github/gitignore/blob/main/community/Java/JBoss6.gitignore"`; rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("JBoss6 page: status %d, missing %q", rec.Code, want)
+	}
+
+	rec = doGet(t, r, "/template/Pyhton")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown template: status = %d", rec.Code)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `<a href="/template/Python">Python</a>`) || strings.Contains(body, "<pre") {
+		t.Error("404 page should suggest Python and render no template body")
+	}
+}
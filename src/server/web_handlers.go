@@ -1,10 +1,17 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/apimgr/gitignore/src/common/i18n"
+	"github.com/apimgr/gitignore/src/db"
+	"github.com/apimgr/gitignore/src/template"
 )
 
 // Web page handlers render server-side HTML templates (AI.md PART 16).
@@ -14,75 +21,177 @@ func (s *Server) handleSearchPage(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	data := map[string]interface{}{"query": query}
 	if query != "" {
-		data["results"] = s.config.Templates.Search(query)
+		results := s.searchTemplates(query)
+		data["results"] = results
+		data["summary"] = i18n.TF(r, "pages.results_for", "results", i18n.TP(r, "plurals.results", len(results)), "query", query)
 	}
 	s.renderPage(w, r, "search", PageData{Title: "Search", Data: data})
 }
 
-// handleTemplatePage serves the template detail page.
+// upstreamBlobURL is where the dataset's templates live upstream; a template's
+// source link is this plus its UpstreamPath.
+const upstreamBlobURL = "https://github.com/github/gitignore/blob/main/"
+
+// handleTemplatePage serves the template detail page: highlighted content with
+// #L line anchors, metadata, and related templates. An unknown name gets a
+// 404 page suggesting close matches.
 func (s *Server) handleTemplatePage(w http.ResponseWriter, r *http.Request) {
 	name := chi.URLParam(r, "name")
 	tmpl, err := s.config.Templates.Get(name)
 	if err != nil {
-		s.renderPageStatus(w, r, "template", http.StatusNotFound, PageData{
-			Title: "Not found",
-			Data:  map[string]interface{}{"name": name, "content": "template not found"},
+		s.renderPageStatus(w, r, "error", http.StatusNotFound, PageData{
+			Title: "Template not found",
+			Data: map[string]interface{}{
+				"code":        http.StatusNotFound,
+				"status":      http.StatusText(http.StatusNotFound),
+				"message":     "There is no template named “" + name + "”.",
+				"suggestions": s.config.Templates.Suggest(name, 5),
+				"search_url":  "/search?q=" + url.QueryEscape(name),
+				"search_term": name,
+			},
 		})
 		return
 	}
+
+	lang := i18n.LangFromContext(r.Context())
+	api := apiBasePath() + "/templates/" + url.PathEscape(tmpl.Name)
+	lines := template.Highlight(tmpl.Content)
+	data := map[string]interface{}{
+		"name":           tmpl.Name,
+		"description":    i18n.TemplateDescription(lang, tmpl.Name, tmpl.Description),
+		"category":       tmpl.Category,
+		"category_label": i18n.CategoryName(lang, tmpl.Category),
+		"tags":           tmpl.Tags,
+		"size":           i18n.TP(r, "plurals.lines", len(lines)) + " · " + i18n.TP(r, "plurals.bytes", tmpl.Size),
+		"lines":          lines,
+		"upstream_path":  tmpl.UpstreamPath(),
+		"upstream_url":   upstreamBlobURL + tmpl.UpstreamPath(),
+		"raw_url":        api + ".txt",
+		"json_url":       api + ".json",
+		"download_url":   api + ".txt?download=1",
+		"history_url":    api + "/history",
+		"composer_url":   composerURL([]string{tmpl.Name}),
+		"related":        s.config.Templates.Related(tmpl.Name, 8),
+		"combined_with":  s.combinedWith(tmpl.Name, 5),
+	}
+	var modified time.Time
+	if changelog := s.changelog.Load(); changelog != nil {
+		if history := changelog.History(tmpl.Name); len(history) > 0 {
+			last := history[0]
+			modified = last.Date
+			data["last_changed"] = map[string]string{
+				"date":     last.Date.Format("2006-01-02"),
+				"datetime": last.Date.Format(time.RFC3339),
+				"change":   last.Change,
+				"version":  last.Version,
+			}
+		}
+	}
+	base := s.siteURL(r)
+	page := base + "/template/" + url.PathEscape(tmpl.Name)
+	data["badge_markdown"] = badgeMarkdown(base, []string{tmpl.Name})
+	data["embed_html"] = `<iframe src="` + base + "/embed/" + url.PathEscape(tmpl.Name) +
+		`" width="640" height="400" title="` + tmpl.Name + ` .gitignore" style="border:0" loading="lazy"></iframe>`
+	description := templateDescription(lang, tmpl)
 	s.renderPage(w, r, "template", PageData{
-		Title: tmpl.Name,
-		Data:  map[string]interface{}{"name": tmpl.Name, "content": tmpl.Content},
+		Title:          tmpl.Name,
+		Description:    description,
+		Canonical:      page,
+		Image:          ogImageURL(base, tmpl.Name),
+		Keywords:       s.seoKeywords(append([]string{tmpl.Name, "gitignore"}, tmpl.Tags...)...),
+		StructuredData: templateLD(base, tmpl, description, modified),
+		OEmbed:         base + "/oembed?url=" + url.QueryEscape(page),
+		Data:           data,
 	})
 }
 
-// handleCombinePage serves the combine templates page.
-func (s *Server) handleCombinePage(w http.ResponseWriter, r *http.Request) {
-	param := r.URL.Query().Get("templates")
-	data := map[string]interface{}{"templates": param}
-	if param != "" {
-		names := strings.Split(param, ",")
-		for i := range names {
-			names[i] = strings.TrimSpace(names[i])
-		}
-		combined, err := s.config.Templates.Combine(names)
-		if err != nil {
-			data["error"] = err.Error()
-		} else {
-			data["content"] = combined
-		}
+// templateDescription is the meta description for a template page, using
+// the catalog description for lang when there is one.
+func templateDescription(lang string, tmpl *template.Template) string {
+	if d := i18n.TemplateDescription(lang, tmpl.Name, tmpl.Description); d != "" {
+		return tmpl.Name + " .gitignore template: " + d
 	}
-	s.renderPage(w, r, "combine", PageData{Title: "Combine", Data: data})
+	return tmpl.Name + " .gitignore template (" + i18n.CategoryName(lang, tmpl.Category) + ")."
+}
+
+// categoryLink is a category as the pages show it: linked by its dataset
+// name, labeled and described in the request language.
+type categoryLink struct {
+	Name        string
+	Label       string
+	Description string
+	Count       string
 }
 
 // handleCategoriesPage serves the categories page.
 func (s *Server) handleCategoriesPage(w http.ResponseWriter, r *http.Request) {
+	lang := i18n.LangFromContext(r.Context())
+	var cats []categoryLink
+	for _, c := range s.config.Templates.GetCategories() {
+		cats = append(cats, categoryLink{
+			Name:        c,
+			Label:       i18n.CategoryName(lang, c),
+			Description: i18n.CategoryDescription(lang, c),
+			Count:       i18n.TP(r, "plurals.templates", len(s.config.Templates.GetByCategory(c))),
+		})
+	}
 	s.renderPage(w, r, "categories", PageData{
 		Title: "Categories",
-		Data:  map[string]interface{}{"categories": s.config.Templates.GetCategories()},
+		Data:  map[string]interface{}{"categories": cats},
 	})
 }
 
 // handleListPage serves the list-all-templates page.
 func (s *Server) handleListPage(w http.ResponseWriter, r *http.Request) {
 	category := r.URL.Query().Get("category")
+	lang := i18n.LangFromContext(r.Context())
+	page := PageData{Title: "All Templates"}
+	data := map[string]interface{}{}
 	var names []string
 	if category != "" {
 		for _, t := range s.config.Templates.GetByCategory(category) {
 			names = append(names, t.Name)
 		}
+		// Each category listing is its own page in the sitemap, so it gets
+		// its own title and canonical URL rather than those of /list.
+		label := i18n.CategoryName(lang, category)
+		page.Title = label + " Templates"
+		page.Description = fmt.Sprintf("%d .gitignore templates in the %s category.", len(names), label)
+		page.Canonical = s.siteURL(r) + "/list?category=" + url.QueryEscape(category)
+		data["category_description"] = i18n.CategoryDescription(lang, category)
 	} else {
 		names = s.config.Templates.List()
 	}
-	s.renderPage(w, r, "list", PageData{
-		Title: "All Templates",
-		Data:  map[string]interface{}{"templates": names},
-	})
+	data["templates"] = names
+	data["count"] = i18n.TP(r, "plurals.templates", len(names))
+	page.Data = data
+	s.renderPage(w, r, "list", page)
 }
 
 // handleStatsPage serves the statistics page.
 func (s *Server) handleStatsPage(w http.ResponseWriter, r *http.Request) {
-	s.renderPage(w, r, "stats", PageData{Title: "Statistics", Data: s.config.Templates.Stats()})
+	data := s.config.Templates.Stats()
+	// Usage analytics are optional on this page; without them it shows only
+	// the dataset totals.
+	if s.popularity != nil {
+		sinceDay, days, limit := usageWindow(r, time.Now())
+		if usage, err := popularStats(sinceDay, days, limit); err == nil {
+			data["usage"] = usage
+			// <meter> needs an explicit max to scale the bars.
+			var topMax, dailyMax int64
+			if top := usage["templates"].([]db.TemplateUsage); len(top) > 0 {
+				topMax = top[0].Total
+			}
+			for _, d := range usage["daily"].([]db.DayCount) {
+				dailyMax = max(dailyMax, d.Count)
+			}
+			data["top_max"], data["daily_max"] = topMax, dailyMax
+		}
+		if pairs, err := db.TopPairs(sinceDay, "", 10); err == nil {
+			data["combinations"] = pairs
+		}
+	}
+	s.renderPage(w, r, "stats", PageData{Title: "Statistics", Data: data})
 }
 
 // handleDocsPage serves the API documentation page.
@@ -100,19 +209,20 @@ func (s *Server) handleServerPage(w http.ResponseWriter, r *http.Request) {
 	s.renderPage(w, r, "server", PageData{Title: "Server"})
 }
 
-// handleAboutPage serves the About standard page (AI.md PART 16).
+// handleAboutPage serves the About standard page (AI.md PART 16). About,
+// Privacy, Contact and Terms may be replaced by operator Markdown.
 func (s *Server) handleAboutPage(w http.ResponseWriter, r *http.Request) {
-	s.renderPage(w, r, "about", PageData{Title: "About"})
+	s.renderStandardPage(w, r, "about", "About")
 }
 
 // handlePrivacyPage serves the Privacy standard page.
 func (s *Server) handlePrivacyPage(w http.ResponseWriter, r *http.Request) {
-	s.renderPage(w, r, "privacy", PageData{Title: "Privacy"})
+	s.renderStandardPage(w, r, "privacy", "Privacy")
 }
 
 // handleContactPage serves the Contact standard page.
 func (s *Server) handleContactPage(w http.ResponseWriter, r *http.Request) {
-	s.renderPage(w, r, "contact", PageData{Title: "Contact"})
+	s.renderStandardPage(w, r, "contact", "Contact")
 }
 
 // handleHelpPage serves the Help standard page.
@@ -122,7 +232,7 @@ func (s *Server) handleHelpPage(w http.ResponseWriter, r *http.Request) {
 
 // handleTermsPage serves the Terms standard page.
 func (s *Server) handleTermsPage(w http.ResponseWriter, r *http.Request) {
-	s.renderPage(w, r, "terms", PageData{Title: "Terms"})
+	s.renderStandardPage(w, r, "terms", "Terms")
 }
 
 // handleThemeSet is the no-JS theme switch: it persists the chosen theme in the
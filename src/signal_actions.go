@@ -1,8 +1,12 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"runtime"
+
+	"github.com/apimgr/gitignore/src/config"
+	"github.com/apimgr/gitignore/src/template"
 )
 
 // sigAction is the action the main loop performs in response to a received OS
@@ -22,6 +26,9 @@ const (
 	// sigActionStatusDump writes a runtime status snapshot to the log.
 	// Mapped from SIGUSR2 (Unix only).
 	sigActionStatusDump
+	// sigActionReload re-reads the configuration file. Mapped from SIGHUP
+	// (Unix only), which is what `--service reload` sends.
+	sigActionReload
 )
 
 // reopenLogs handles the SIGUSR1 "reopen logs" request (AI.md PART 8). The
@@ -41,3 +48,30 @@ func dumpStatus() {
 	log.Printf("SIGUSR2 status dump: goroutines=%d alloc=%dKiB sys=%dKiB numgc=%d",
 		runtime.NumGoroutine(), m.Alloc/1024, m.Sys/1024, m.NumGC)
 }
+
+// reloadConfig handles the SIGHUP "reload" request. It re-parses server.yml —
+// a file that fails to parse leaves the running configuration untouched —
+// and reinstalls the synced template dataset it points at, announcing it on
+// the event stream if it differs. Parsing publishes the file to config.Get(),
+// which the web UI notification settings and the event stream's public
+// tasks read per request; the main loop then applies branding. The server
+// keeps the rest of the configuration it started with, so every other
+// setting takes a restart; the log line says so.
+func reloadConfig(configPath, dataDir string, tm *template.Manager) error {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("reload %s: %w", configPath, err)
+	}
+	revision := tm.Revision()
+	loadSyncedTemplates(tm, newTemplateSyncer(cfg, dataDir))
+	if tm.Revision() != revision {
+		// A `scheduler run template_sync` in another process recorded
+		// the dataset's revision; serve it in the changelog too.
+		if eventServer != nil {
+			eventServer.ReloadChangelog()
+		}
+		publishTemplatesUpdated(tm, nil, nil, nil)
+	}
+	log.Printf("SIGHUP: configuration reloaded from %s (other settings than branding, notifications and public event tasks apply on restart)", configPath)
+	return nil
+}
@@ -9,9 +9,8 @@ import (
 )
 
 // notifyShutdownSignals subscribes ch to the Unix signals the server acts on
-// (AI.md PART 8 signal table). SIGHUP is explicitly ignored — configuration
-// reloads automatically via the file watcher, so a hangup must neither reload
-// nor terminate the process. SIGRTMIN+3 is added per-platform (Linux only)
+// (AI.md PART 8 signal table). SIGHUP re-reads the configuration rather than
+// terminating the process. SIGRTMIN+3 is added per-platform (Linux only)
 // through notifyPlatformSignals.
 func notifyShutdownSignals(ch chan<- os.Signal) {
 	signal.Notify(ch,
@@ -20,21 +19,23 @@ func notifyShutdownSignals(ch chan<- os.Signal) {
 		syscall.SIGQUIT,
 		syscall.SIGUSR1,
 		syscall.SIGUSR2,
+		syscall.SIGHUP,
 	)
 	notifyPlatformSignals(ch)
-	signal.Ignore(syscall.SIGHUP)
 }
 
 // classifySignal maps a received Unix signal to the main-loop action per the
-// AI.md PART 8 signal table: SIGUSR1 reopens logs, SIGUSR2 dumps status, and
-// every other subscribed signal (SIGTERM, SIGINT, SIGQUIT, SIGRTMIN+3)
-// triggers graceful shutdown.
+// AI.md PART 8 signal table: SIGUSR1 reopens logs, SIGUSR2 dumps status,
+// SIGHUP reloads the configuration, and every other subscribed signal
+// (SIGTERM, SIGINT, SIGQUIT, SIGRTMIN+3) triggers graceful shutdown.
 func classifySignal(sig os.Signal) sigAction {
 	switch sig {
 	case syscall.SIGUSR1:
 		return sigActionReopenLogs
 	case syscall.SIGUSR2:
 		return sigActionStatusDump
+	case syscall.SIGHUP:
+		return sigActionReload
 	default:
 		return sigActionShutdown
 	}
@@ -8,8 +8,8 @@ import (
 )
 
 // TestClassifySignal verifies the Unix signal-to-action mapping (AI.md PART 8
-// signal table). SIGUSR1 reopens logs, SIGUSR2 dumps status, every other
-// subscribed signal triggers graceful shutdown.
+// signal table). SIGUSR1 reopens logs, SIGUSR2 dumps status, SIGHUP reloads
+// the configuration, every other subscribed signal triggers graceful shutdown.
 func TestClassifySignal(t *testing.T) {
 	cases := []struct {
 		name string
@@ -21,6 +21,7 @@ func TestClassifySignal(t *testing.T) {
 		{"SIGQUIT", syscall.SIGQUIT, sigActionShutdown},
 		{"SIGUSR1", syscall.SIGUSR1, sigActionReopenLogs},
 		{"SIGUSR2", syscall.SIGUSR2, sigActionStatusDump},
+		{"SIGHUP", syscall.SIGHUP, sigActionReload},
 	}
 	for _, c := range cases {
 		if got := classifySignal(c.sig); got != c.want {
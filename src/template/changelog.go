@@ -0,0 +1,242 @@
+package template
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// changelogJSON is the dataset changelog generated at build time by
+// cmd/changelog-gen whenever the embedded snapshot is refreshed.
+//
+//go:embed data/changelog.json
+var changelogJSON []byte
+
+// Change kinds recorded for a template in a Revision.
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// TemplateChange is one template touched by a dataset revision. Diff is a
+// unified diff for modified templates and empty otherwise.
+type TemplateChange struct {
+	Template string `json:"template"`
+	Category string `json:"category"`
+	Change   string `json:"change"`
+	Diff     string `json:"diff,omitempty"`
+}
+
+// Revision is one version of the template dataset, identified by the content
+// hash of every template it contains.
+type Revision struct {
+	Version  string           `json:"version"`
+	Date     time.Time        `json:"date"`
+	Upstream string           `json:"upstream,omitempty"`
+	Count    int              `json:"count"`
+	Changes  []TemplateChange `json:"changes"`
+}
+
+// Changelog is the ordered (oldest first) list of dataset revisions.
+type Changelog struct {
+	Revisions []Revision `json:"revisions"`
+}
+
+// HistoryEntry is one revision that touched a particular template.
+type HistoryEntry struct {
+	Version string    `json:"version"`
+	Date    time.Time `json:"date"`
+	Change  string    `json:"change"`
+	Diff    string    `json:"diff,omitempty"`
+}
+
+// LoadChangelog parses the changelog embedded in the binary.
+func LoadChangelog() (*Changelog, error) {
+	return ParseChangelog(changelogJSON)
+}
+
+// ParseChangelog parses a changelog document and orders it oldest first.
+func ParseChangelog(data []byte) (*Changelog, error) {
+	var c Changelog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parse changelog: %w", err)
+	}
+	sort.SliceStable(c.Revisions, func(i, j int) bool {
+		return c.Revisions[i].Date.Before(c.Revisions[j].Date)
+	})
+	return &c, nil
+}
+
+// Merge returns a changelog with c's revisions and those of revs it does not
+// already have, oldest first. c itself is left unchanged, so a changelog can be
+// swapped for its merge while readers still hold it.
+func (c *Changelog) Merge(revs []Revision) *Changelog {
+	merged := &Changelog{Revisions: append([]Revision(nil), c.Revisions...)}
+	seen := make(map[string]bool, len(c.Revisions))
+	for _, rev := range c.Revisions {
+		seen[rev.Version] = true
+	}
+	for _, rev := range revs {
+		if !seen[rev.Version] {
+			seen[rev.Version] = true
+			merged.Revisions = append(merged.Revisions, rev)
+		}
+	}
+	sort.SliceStable(merged.Revisions, func(i, j int) bool {
+		return merged.Revisions[i].Date.Before(merged.Revisions[j].Date)
+	})
+	return merged
+}
+
+// Names returns every template name c records, in the order it first records
+// them: the templates of the first revision, then those each later revision
+// adds. Appending revisions only ever appends names.
+func (c *Changelog) Names() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, rev := range c.Revisions {
+		for _, ch := range rev.Changes {
+			if !seen[ch.Template] {
+				seen[ch.Template] = true
+				names = append(names, ch.Template)
+			}
+		}
+	}
+	return names
+}
+
+// Latest returns the newest revision, or nil for an empty changelog.
+func (c *Changelog) Latest() *Revision {
+	if len(c.Revisions) == 0 {
+		return nil
+	}
+	return &c.Revisions[len(c.Revisions)-1]
+}
+
+// Since returns the revisions newer than since, which is either a dataset
+// version (exclusive) or a date in YYYY-MM-DD or RFC 3339 form. An empty since
+// returns every revision. An unrecognized version is an error.
+func (c *Changelog) Since(since string) ([]Revision, error) {
+	since = strings.TrimSpace(since)
+	if since == "" {
+		return c.Revisions, nil
+	}
+	for i, rev := range c.Revisions {
+		if rev.Version == since {
+			return c.Revisions[i+1:], nil
+		}
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, since); err == nil {
+			var out []Revision
+			for _, rev := range c.Revisions {
+				if rev.Date.After(t) {
+					out = append(out, rev)
+				}
+			}
+			return out, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown version or date: %s", since)
+}
+
+// History returns the revisions that touched the named template, newest first.
+func (c *Changelog) History(name string) []HistoryEntry {
+	var out []HistoryEntry
+	for i := len(c.Revisions) - 1; i >= 0; i-- {
+		rev := c.Revisions[i]
+		for _, ch := range rev.Changes {
+			if strings.EqualFold(ch.Template, name) {
+				out = append(out, HistoryEntry{Version: rev.Version, Date: rev.Date, Change: ch.Change, Diff: ch.Diff})
+			}
+		}
+	}
+	return out
+}
+
+// DatasetVersion returns the content hash identifying a set of templates: the
+// first 12 hex digits of a SHA-256 over every category, name and content in
+// sorted order. Identical datasets always hash identically, wherever loaded.
+func DatasetVersion(templates []*Template) string {
+	sorted := append([]*Template(nil), templates...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return datasetKey(sorted[i]) < datasetKey(sorted[j])
+	})
+	h := sha256.New()
+	for _, t := range sorted {
+		fmt.Fprintf(h, "%s\x00%d\x00%s", datasetKey(t), len(t.Content), t.Content)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// datasetKey is a template's position in the dataset, e.g. "Global/macOS".
+func datasetKey(t *Template) string {
+	return t.Category + "/" + t.Name
+}
+
+// UpstreamPath is the template's file path in github/gitignore, used in diff
+// headers so they apply cleanly to an upstream checkout. Templates built
+// without a recorded Path fall back to one derived from the category.
+func (t *Template) UpstreamPath() string {
+	if t.Path != "" {
+		return t.Path
+	}
+	if t.Category == "Root" {
+		return t.Name + ".gitignore"
+	}
+	return t.Category + "/" + t.Name + ".gitignore"
+}
+
+// NewRevision builds the revision turning prev into next, dated at. Modified
+// templates carry a unified diff; added and removed ones do not, since the
+// full content is available from the templates API.
+func NewRevision(prev, next []*Template, at time.Time, upstream string) Revision {
+	before := make(map[string]*Template, len(prev))
+	for _, t := range prev {
+		before[datasetKey(t)] = t
+	}
+	var changes []TemplateChange
+	for _, t := range next {
+		key := datasetKey(t)
+		old, ok := before[key]
+		delete(before, key)
+		switch {
+		case !ok:
+			changes = append(changes, TemplateChange{Template: t.Name, Category: t.Category, Change: ChangeAdded})
+		case old.Content != t.Content:
+			changes = append(changes, TemplateChange{
+				Template: t.Name,
+				Category: t.Category,
+				Change:   ChangeModified,
+				Diff:     UnifiedDiff("a/"+t.UpstreamPath(), "b/"+t.UpstreamPath(), old.Content, t.Content),
+			})
+		}
+	}
+	for _, t := range before {
+		changes = append(changes, TemplateChange{Template: t.Name, Category: t.Category, Change: ChangeRemoved})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Category != changes[j].Category {
+			return changes[i].Category < changes[j].Category
+		}
+		return changes[i].Template < changes[j].Template
+	})
+	return Revision{
+		Version:  DatasetVersion(next),
+		Date:     at.UTC().Truncate(time.Second),
+		Upstream: upstream,
+		Count:    len(next),
+		Changes:  changes,
+	}
+}
+
+// Version returns the content hash of the loaded dataset (see DatasetVersion).
+func (m *Manager) Version() string {
+	return DatasetVersion(m.ListAll())
+}
@@ -0,0 +1,104 @@
+package template
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmbeddedChangelogMatchesDataset(t *testing.T) {
+	m, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl, err := LoadChangelog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := cl.Latest()
+	if latest == nil || latest.Version != m.Version() {
+		t.Fatalf("changelog is stale: dataset %s not recorded (run `make changelog`)", m.Version())
+	}
+}
+
+func TestNewRevisionAndQueries(t *testing.T) {
+	v1 := []*Template{
+		{Name: "Go", Category: "Root", Content: "*.exe\n*.test\n"},
+		{Name: "macOS", Category: "Global", Content: ".DS_Store\n"},
+	}
+	v2 := []*Template{
+		{Name: "Go", Category: "Root", Content: "*.exe\n*.out\n"},
+		{Name: "Zig", Category: "Root", Content: "zig-out/\n"},
+	}
+	day1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cl := &Changelog{Revisions: []Revision{
+		NewRevision(nil, v1, day1, ""),
+		NewRevision(v1, v2, day1.AddDate(0, 1, 0), "abc123"),
+	}}
+
+	second := cl.Revisions[1]
+	var kinds []string
+	for _, ch := range second.Changes {
+		kinds = append(kinds, ch.Template+":"+ch.Change)
+	}
+	if got := strings.Join(kinds, ","); got != "macOS:removed,Go:modified,Zig:added" {
+		t.Errorf("changes = %s", got)
+	}
+	wantDiff := "--- a/Go.gitignore\n+++ b/Go.gitignore\n@@ -1,2 +1,2 @@\n *.exe\n-*.test\n+*.out\n"
+	if second.Changes[1].Diff != wantDiff {
+		t.Errorf("diff = %q", second.Changes[1].Diff)
+	}
+
+	if revs, err := cl.Since(cl.Revisions[0].Version); err != nil || len(revs) != 1 {
+		t.Errorf("Since(version) = %d, %v", len(revs), err)
+	}
+	if revs, err := cl.Since("2024-01-15"); err != nil || len(revs) != 1 {
+		t.Errorf("Since(date) = %d, %v", len(revs), err)
+	}
+	if _, err := cl.Since("deadbeef"); err == nil {
+		t.Error("Since(unknown) succeeded")
+	}
+	if h := cl.History("go"); len(h) != 2 || h[0].Change != ChangeModified {
+		t.Errorf("History(go) = %+v", h)
+	}
+	if DatasetVersion(v1) == DatasetVersion(v2) {
+		t.Error("different datasets hashed identically")
+	}
+
+	// Merging a synced revision appends it once and leaves cl alone.
+	v3 := []*Template{{Name: "Zig", Category: "Root", Content: "zig-out/\n"}}
+	synced := NewRevision(v2, v3, day1.AddDate(0, 2, 0), "def456")
+	merged := cl.Merge([]Revision{cl.Revisions[1], synced})
+	if len(cl.Revisions) != 2 || len(merged.Revisions) != 3 || merged.Latest().Version != DatasetVersion(v3) {
+		t.Errorf("Merge = %d revisions ending %s, want 3 ending %s", len(merged.Revisions), merged.Latest().Version, DatasetVersion(v3))
+	}
+	if revs, err := merged.Since(second.Version); err != nil || len(revs) != 1 || revs[0].Upstream != "def456" {
+		t.Errorf("Since(synced predecessor) = %+v, %v", revs, err)
+	}
+}
+
+// TestUpstreamPathNested verifies that templates nested below their category
+// keep their full path, in UpstreamPath and in the diff headers built from
+// it.
+func TestUpstreamPathNested(t *testing.T) {
+	m, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := m.Get("JBoss6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Category != "community" || tmpl.UpstreamPath() != "community/Java/JBoss6.gitignore" {
+		t.Errorf("JBoss6: category %q, upstream path %q", tmpl.Category, tmpl.UpstreamPath())
+	}
+	changed := *tmpl
+	changed.Content += "*.bak\n"
+	rev := NewRevision([]*Template{tmpl}, []*Template{&changed}, time.Now(), "")
+	if len(rev.Changes) != 1 || !strings.HasPrefix(rev.Changes[0].Diff, "--- a/community/Java/JBoss6.gitignore\n") {
+		t.Errorf("diff = %+v", rev.Changes)
+	}
+	if root := NewTemplate("Go.gitignore", ""); root.Category != "Root" || root.UpstreamPath() != "Go.gitignore" {
+		t.Errorf("Go: category %q, upstream path %q", root.Category, root.UpstreamPath())
+	}
+}
@@ -0,0 +1,194 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sense is how a template treats a compared rule.
+type Sense string
+
+const (
+	// SenseNone means the template has no rule for the pattern.
+	SenseNone Sense = ""
+	// SenseIgnore means the template ignores matching paths.
+	SenseIgnore Sense = "ignore"
+	// SenseInclude means the template re-includes matching paths with "!".
+	SenseInclude Sense = "include"
+)
+
+// Rule statuses in a Comparison.
+const (
+	// StatusShared rules appear in every compared template with the same
+	// sense.
+	StatusShared = "shared"
+	// StatusPartial rules appear in more than one template but not all, with
+	// the same sense.
+	StatusPartial = "partial"
+	// StatusUnique rules appear in exactly one template.
+	StatusUnique = "unique"
+	// StatusConflict rules are ignored by some templates and re-included by
+	// others.
+	StatusConflict = "conflict"
+)
+
+// CompareRow is one normalized rule across the compared templates.
+type CompareRow struct {
+	// Pattern is the normalized pattern, with a trailing "/" for
+	// directory-only rules and without the "!" prefix.
+	Pattern string `json:"pattern"`
+	// Senses holds each template's treatment of the pattern, in
+	// Comparison.Templates order.
+	Senses []Sense `json:"senses"`
+	Status string  `json:"status"`
+}
+
+// Comparison is a matrix of normalized rules against templates.
+type Comparison struct {
+	Templates []string       `json:"templates"`
+	Rules     []CompareRow   `json:"rules"`
+	Counts    map[string]int `json:"counts"`
+}
+
+// Compare builds the rule matrix for the named templates. Rows are in the
+// order patterns first appear, reading the templates in order. Patterns are
+// normalized so spellings git treats alike share a row ("**/build" and
+// "build"); within one template the last rule for a pattern wins, as in git.
+func (m *Manager) Compare(names []string) (*Comparison, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c := &Comparison{
+		Templates: make([]string, 0, len(names)),
+		Counts:    map[string]int{StatusShared: 0, StatusPartial: 0, StatusUnique: 0, StatusConflict: 0},
+	}
+	row := make(map[string]int)
+	for col, name := range names {
+		tmpl, ok := m.lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("template not found: %s", name)
+		}
+		c.Templates = append(c.Templates, tmpl.Name)
+		for i, line := range strings.Split(tmpl.Content, "\n") {
+			rule, ok, err := ParseRule(line, i+1)
+			if !ok || err != nil {
+				continue
+			}
+			key := normalizePattern(rule)
+			idx, seen := row[key]
+			if !seen {
+				idx = len(c.Rules)
+				row[key] = idx
+				c.Rules = append(c.Rules, CompareRow{Pattern: key, Senses: make([]Sense, len(names))})
+			}
+			sense := SenseIgnore
+			if rule.Negate {
+				sense = SenseInclude
+			}
+			c.Rules[idx].Senses[col] = sense
+		}
+	}
+	for i := range c.Rules {
+		c.Rules[i].Status = rowStatus(c.Rules[i].Senses)
+		c.Counts[c.Rules[i].Status]++
+	}
+	return c, nil
+}
+
+// normalizePattern returns the row key for rule: its pattern with a leading
+// "**/" dropped when what follows has no other slash (git matches both at
+// any depth) and a trailing "/" for directory-only rules.
+func normalizePattern(rule Rule) string {
+	p := rule.Pattern
+	if rest, ok := strings.CutPrefix(p, "**/"); ok && rest != "" && !strings.Contains(rest, "/") {
+		p = rest
+	}
+	if rule.DirOnly {
+		p += "/"
+	}
+	return p
+}
+
+func rowStatus(senses []Sense) string {
+	var present, ignore, include int
+	for _, s := range senses {
+		switch s {
+		case SenseIgnore:
+			present++
+			ignore++
+		case SenseInclude:
+			present++
+			include++
+		}
+	}
+	switch {
+	case ignore > 0 && include > 0:
+		return StatusConflict
+	case present == 1:
+		return StatusUnique
+	case present == len(senses):
+		return StatusShared
+	default:
+		return StatusPartial
+	}
+}
+
+// Union renders every compared rule as one .gitignore. A conflicting rule
+// takes the sense of the last template that has it, matching what Combine
+// produces for the same order, and is preceded by a comment saying so.
+func (c *Comparison) Union() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Union of: %s\n\n", strings.Join(c.Templates, ", "))
+	for _, r := range c.Rules {
+		last := -1
+		for i, s := range r.Senses {
+			if s != SenseNone {
+				last = i
+			}
+		}
+		if r.Status == StatusConflict {
+			fmt.Fprintf(&b, "# conflict: %s\n", r.describe(c.Templates))
+		}
+		b.WriteString(r.line(r.Senses[last]) + "\n")
+	}
+	return b.String()
+}
+
+// Intersection renders the rules every compared template shares.
+func (c *Comparison) Intersection() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Intersection of: %s\n\n", strings.Join(c.Templates, ", "))
+	for _, r := range c.Rules {
+		if r.Status == StatusShared {
+			b.WriteString(r.line(r.Senses[0]) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// line writes the row back as a .gitignore rule, re-escaping a leading "#"
+// or "!" that the parser unescaped.
+func (r CompareRow) line(sense Sense) string {
+	p := r.Pattern
+	if strings.HasPrefix(p, "#") || strings.HasPrefix(p, "!") {
+		p = `\` + p
+	}
+	if sense == SenseInclude {
+		p = "!" + p
+	}
+	return p
+}
+
+// describe lists which templates ignore and which re-include the row.
+func (r CompareRow) describe(templates []string) string {
+	var ignore, include []string
+	for i, s := range r.Senses {
+		switch s {
+		case SenseIgnore:
+			ignore = append(ignore, templates[i])
+		case SenseInclude:
+			include = append(include, templates[i])
+		}
+	}
+	return "ignored by " + strings.Join(ignore, ", ") + "; re-included by " + strings.Join(include, ", ")
+}
@@ -0,0 +1,50 @@
+package template
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareMatrix(t *testing.T) {
+	m := newTestManager(
+		&Template{Name: "A", Category: "Root", Content: "# A\n*.log\n**/build/\n.env\n\\#notes\n"},
+		&Template{Name: "B", Category: "Global", Content: "*.log\n!build/\ndist\n"},
+		&Template{Name: "C", Category: "community", Path: "community/Lang/C.gitignore", Content: "*.log\n.env\n.env\n"},
+	)
+	if _, err := m.Compare([]string{"a", "community/C"}); err == nil {
+		t.Error("Compare found C by its category alone, want its whole path")
+	}
+	c, err := m.Compare([]string{"a", "Global/B", "community/Lang/C"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.Templates, []string{"A", "B", "C"}) {
+		t.Errorf("templates = %v", c.Templates)
+	}
+	want := []CompareRow{
+		{Pattern: "*.log", Senses: []Sense{SenseIgnore, SenseIgnore, SenseIgnore}, Status: StatusShared},
+		{Pattern: "build/", Senses: []Sense{SenseIgnore, SenseInclude, SenseNone}, Status: StatusConflict},
+		{Pattern: ".env", Senses: []Sense{SenseIgnore, SenseNone, SenseIgnore}, Status: StatusPartial},
+		{Pattern: "#notes", Senses: []Sense{SenseIgnore, SenseNone, SenseNone}, Status: StatusUnique},
+		{Pattern: "dist", Senses: []Sense{SenseNone, SenseIgnore, SenseNone}, Status: StatusUnique},
+	}
+	if !reflect.DeepEqual(c.Rules, want) {
+		t.Errorf("rules =\n%+v\nwant\n%+v", c.Rules, want)
+	}
+	if c.Counts[StatusShared] != 1 || c.Counts[StatusUnique] != 2 || c.Counts[StatusConflict] != 1 || c.Counts[StatusPartial] != 1 {
+		t.Errorf("counts = %v", c.Counts)
+	}
+
+	if got, want := c.Union(), "# Union of: A, B, C\n\n*.log\n# conflict: ignored by A; re-included by B\n!build/\n.env\n\\#notes\ndist\n"; got != want {
+		t.Errorf("union =\n%s\nwant\n%s", got, want)
+	}
+	if got, want := c.Intersection(), "# Intersection of: A, B, C\n\n*.log\n"; got != want {
+		t.Errorf("intersection =\n%s\nwant\n%s", got, want)
+	}
+
+	for _, names := range [][]string{{"A", "Nope"}, {"A", "Root/B"}} {
+		if _, err := m.Compare(names); err == nil {
+			t.Errorf("Compare(%v) succeeded", names)
+		}
+	}
+}
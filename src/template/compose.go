@@ -0,0 +1,120 @@
+package template
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ComposedLine is one line of a template within a Composition.
+type ComposedLine struct {
+	Text string `json:"text"`
+	// DuplicateOf names the template that already contributed this pattern;
+	// the combined output omits the line.
+	DuplicateOf string `json:"duplicate_of,omitempty"`
+	// ConflictsWith names an earlier template whose rule for the same pattern
+	// has the opposite sense (one ignores it, the other re-includes it with
+	// "!"). Git applies the last matching rule, so order decides the result.
+	ConflictsWith string `json:"conflicts_with,omitempty"`
+}
+
+// ComposedSection is one template's contribution to a Composition.
+type ComposedSection struct {
+	Template string         `json:"template"`
+	Category string         `json:"category"`
+	Lines    []ComposedLine `json:"lines"`
+}
+
+// Composition is a combined .gitignore broken into per-template sections, with
+// the lines Combine drops as duplicates and the rules that contradict another
+// template annotated.
+type Composition struct {
+	Templates  []string          `json:"templates"`
+	Sections   []ComposedSection `json:"sections"`
+	Duplicates int               `json:"duplicates"`
+	Conflicts  int               `json:"conflicts"`
+}
+
+// Compose combines the named templates in order like Combine, but keeps the
+// result structured so callers can show what each template contributes.
+func (m *Manager) Compose(names []string) (*Composition, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c := &Composition{Templates: make([]string, 0, len(names))}
+	// seen maps a trimmed pattern line to the template that first wrote it.
+	seen := make(map[string]string)
+	// polarity maps a rule's pattern to the first template that ignored it
+	// (index 0) or re-included it (index 1).
+	polarity := make(map[string]*[2]string)
+
+	for _, name := range names {
+		tmpl, exists := m.templates[strings.ToLower(name)]
+		if !exists {
+			return nil, fmt.Errorf("template not found: %s", name)
+		}
+		c.Templates = append(c.Templates, tmpl.Name)
+		section := ComposedSection{Template: tmpl.Name, Category: tmpl.Category}
+
+		for i, text := range strings.Split(tmpl.Content, "\n") {
+			line := ComposedLine{Text: text}
+			trimmed := strings.TrimSpace(text)
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				section.Lines = append(section.Lines, line)
+				continue
+			}
+			if first, dup := seen[trimmed]; dup {
+				line.DuplicateOf = first
+				c.Duplicates++
+				section.Lines = append(section.Lines, line)
+				continue
+			}
+			seen[trimmed] = tmpl.Name
+
+			if rule, ok, err := ParseRule(text, i+1); ok && err == nil {
+				key := rule.Pattern
+				if rule.DirOnly {
+					key += "/"
+				}
+				sense, opposite := 0, 1
+				if rule.Negate {
+					sense, opposite = 1, 0
+				}
+				p := polarity[key]
+				if p == nil {
+					p = &[2]string{}
+					polarity[key] = p
+				}
+				if other := p[opposite]; other != "" && other != tmpl.Name {
+					line.ConflictsWith = other
+					c.Conflicts++
+				}
+				if p[sense] == "" {
+					p[sense] = tmpl.Name
+				}
+			}
+			section.Lines = append(section.Lines, line)
+		}
+		c.Sections = append(c.Sections, section)
+	}
+	return c, nil
+}
+
+// Render returns the combined .gitignore text, identical to Combine's output
+// for the same names.
+func (c *Composition) Render(names []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Combined .gitignore\n# Generated: %s\n# Templates: %s\n\n",
+		filepath.Base(strings.Join(names, ", ")),
+		strings.Join(names, ", "))
+	for _, section := range c.Sections {
+		fmt.Fprintf(&b, "### %s ###\n", section.Template)
+		for _, line := range section.Lines {
+			if line.DuplicateOf == "" {
+				b.WriteString(line.Text + "\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
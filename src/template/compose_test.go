@@ -0,0 +1,52 @@
+package template
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestManager(templates ...*Template) *Manager {
+	m := &Manager{templates: map[string]*Template{}, categories: map[string][]*Template{}}
+	for _, t := range templates {
+		m.templates[strings.ToLower(t.Name)] = t
+		m.categories[t.Category] = append(m.categories[t.Category], t)
+	}
+	return m
+}
+
+func TestComposeAnnotations(t *testing.T) {
+	m := newTestManager(
+		&Template{Name: "A", Category: "Root", Content: "# A\n*.log\nbuild/\n"},
+		&Template{Name: "B", Category: "Global", Content: "*.log\n!build/\ndist\n"},
+	)
+	c, err := m.Compose([]string{"a", "B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Duplicates != 1 || c.Conflicts != 1 {
+		t.Fatalf("duplicates=%d conflicts=%d, want 1 and 1", c.Duplicates, c.Conflicts)
+	}
+	b := c.Sections[1].Lines
+	if b[0].DuplicateOf != "A" || b[1].ConflictsWith != "A" || b[2].DuplicateOf != "" || b[2].ConflictsWith != "" {
+		t.Errorf("section B annotations = %+v", b)
+	}
+	if _, err := m.Compose([]string{"A", "Nope"}); err == nil {
+		t.Error("Compose with unknown template succeeded")
+	}
+}
+
+// TestCombineDeduplicates verifies the combined file keeps one copy of each
+// pattern and every template header.
+func TestCombineDeduplicates(t *testing.T) {
+	m, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	combined, err := m.Combine([]string{"Go", "macOS", "Node", "macOS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(combined, "### macOS ###\n") != 2 || strings.Count(combined, "\n.DS_Store\n") != 1 {
+		t.Errorf("unexpected combined output:\n%s", combined)
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
-	"path/filepath"
+	"os"
 	"strings"
 	"sync"
 )
@@ -17,6 +17,9 @@ type Template struct {
 	Name        string   `json:"name"`
 	FileName    string   `json:"file_name"`
 	Category    string   `json:"category"`
+	// Path is the template's file relative to the dataset root, as in
+	// github/gitignore: "community/Java/JBoss6.gitignore".
+	Path        string   `json:"path"`
 	Content     string   `json:"content,omitempty"`
 	Description string   `json:"description,omitempty"`
 	Tags        []string `json:"tags,omitempty"`
@@ -27,32 +30,75 @@ type Template struct {
 type Manager struct {
 	templates map[string]*Template // key: lowercase name
 	categories map[string][]*Template
+	revision  string
 	mu        sync.RWMutex
 }
 
+// EmbeddedRevision is the revision reported for the dataset compiled into the
+// binary, as opposed to a commit hash from a synced upstream mirror.
+const EmbeddedRevision = "embedded"
+
 // New creates a new template manager and loads all templates
 func New() (*Manager, error) {
 	m := &Manager{
 		templates:  make(map[string]*Template),
 		categories: make(map[string][]*Template),
+		revision:   EmbeddedRevision,
 	}
 
-	if err := m.loadTemplates(); err != nil {
+	templates, categories, err := loadTemplates(templatesFS, "data/gitignore")
+	if err != nil {
 		return nil, err
 	}
+	m.templates = templates
+	m.categories = categories
 
 	return m, nil
 }
 
-// loadTemplates loads all templates from embedded filesystem
-func (m *Manager) loadTemplates() error {
-	return fs.WalkDir(templatesFS, "data/gitignore", func(path string, d fs.DirEntry, err error) error {
+// LoadDir replaces the loaded dataset with the templates under dir (a checkout
+// laid out like github/gitignore). The swap is atomic: readers see either the
+// old or the new dataset, never a mix. revision identifies the new dataset.
+func (m *Manager) LoadDir(dir, revision string) error {
+	templates, categories, err := loadTemplates(os.DirFS(dir), ".")
+	if err != nil {
+		return err
+	}
+	if len(templates) == 0 {
+		return fmt.Errorf("no templates found in %s", dir)
+	}
+
+	m.mu.Lock()
+	m.templates = templates
+	m.categories = categories
+	m.revision = revision
+	m.mu.Unlock()
+	return nil
+}
+
+// Revision returns the revision of the loaded dataset: EmbeddedRevision for the
+// compiled-in snapshot, or the upstream commit of a synced dataset.
+func (m *Manager) Revision() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.revision
+}
+
+// loadTemplates loads all templates under root in fsys
+func loadTemplates(fsys fs.FS, root string) (map[string]*Template, map[string][]*Template, error) {
+	templates := make(map[string]*Template)
+	categories := make(map[string][]*Template)
+
+	err := fs.WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// Skip directories and non-.gitignore files
+		// Skip directories (and VCS metadata) and non-.gitignore files
 		if d.IsDir() {
+			if d.Name() == ".git" {
+				return fs.SkipDir
+			}
 			return nil
 		}
 
@@ -60,43 +106,76 @@ func (m *Manager) loadTemplates() error {
 			return nil
 		}
 
+		// Upstream aliases some templates with symlinks (Octave -> MATLAB).
+		// go:embed cannot include them, so skip them on disk too and keep a
+		// synced checkout hashing the same as the embedded snapshot.
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+
 		// Read file content
-		content, err := fs.ReadFile(templatesFS, path)
+		content, err := fs.ReadFile(fsys, path)
 		if err != nil {
 			return fmt.Errorf("failed to read %s: %w", path, err)
 		}
 
-		// Determine category from path
-		category := "Root"
-		relPath := strings.TrimPrefix(path, "data/gitignore/")
-		if strings.Contains(relPath, "/") {
-			parts := strings.Split(relPath, "/")
-			category = parts[0]
-		}
-
-		// Template name (without .gitignore extension)
-		name := strings.TrimSuffix(d.Name(), ".gitignore")
-
-		// Create template
-		tmpl := &Template{
-			Name:        name,
-			FileName:    d.Name(),
-			Category:    category,
-			Content:     string(content),
-			Description: extractDescription(string(content)),
-			Tags:        extractTags(name, category),
-			Size:        len(content),
+		relPath := path
+		if root != "." {
+			relPath = strings.TrimPrefix(path, root+"/")
 		}
+		tmpl := NewTemplate(relPath, string(content))
 
 		// Store template (case-insensitive key)
-		key := strings.ToLower(name)
-		m.templates[key] = tmpl
+		templates[strings.ToLower(tmpl.Name)] = tmpl
 
 		// Add to category index
-		m.categories[category] = append(m.categories[category], tmpl)
+		categories[tmpl.Category] = append(categories[tmpl.Category], tmpl)
 
 		return nil
 	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return templates, categories, nil
+}
+
+// NewTemplate builds the template at path, a slash-separated .gitignore file
+// relative to the dataset root. Its name is the file name without the
+// extension and its category the first directory, "Root" at the top level;
+// the description and search tags are derived from the content as the
+// dataset loader does.
+func NewTemplate(path, content string) *Template {
+	name := strings.TrimSuffix(path[strings.LastIndex(path, "/")+1:], ".gitignore")
+	category := "Root"
+	if first, _, nested := strings.Cut(path, "/"); nested {
+		category = first
+	}
+	return &Template{
+		Name:        name,
+		FileName:    name + ".gitignore",
+		Category:    category,
+		Path:        path,
+		Content:     content,
+		Description: extractDescription(content),
+		Tags:        extractTags(name, category),
+		Size:        len(content),
+	}
+}
+
+// FromTemplates builds a manager over templates, such as a dataset unpacked
+// from the /api/v1/templates.tar.gz archive, identified by revision. Like a
+// loaded dataset, a later template replaces an earlier one of the same name.
+func FromTemplates(templates []*Template, revision string) *Manager {
+	m := &Manager{
+		templates:  make(map[string]*Template, len(templates)),
+		categories: make(map[string][]*Template),
+		revision:   revision,
+	}
+	for _, tmpl := range templates {
+		m.templates[strings.ToLower(tmpl.Name)] = tmpl
+		m.categories[tmpl.Category] = append(m.categories[tmpl.Category], tmpl)
+	}
+	return m
 }
 
 // extractDescription extracts description from template content
@@ -139,8 +218,7 @@ func (m *Manager) Get(name string) (*Template, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	key := strings.ToLower(name)
-	tmpl, exists := m.templates[key]
+	tmpl, exists := m.lookup(name)
 	if !exists {
 		return nil, fmt.Errorf("template not found: %s", name)
 	}
@@ -148,6 +226,23 @@ func (m *Manager) Get(name string) (*Template, error) {
 	return tmpl, nil
 }
 
+// lookup finds a template by name or by its dataset path with or without the
+// .gitignore suffix ("community/Python/JupyterNotebooks"), case-insensitively.
+// A path must be the template's whole path. Callers hold m.mu.
+func (m *Manager) lookup(name string) (*Template, bool) {
+	key := strings.TrimSuffix(strings.ToLower(name), ".gitignore")
+	i := strings.LastIndex(key, "/")
+	if i < 0 {
+		tmpl, ok := m.templates[key]
+		return tmpl, ok
+	}
+	tmpl, ok := m.templates[key[i+1:]]
+	if !ok {
+		return nil, false
+	}
+	return tmpl, key == strings.TrimSuffix(strings.ToLower(tmpl.UpstreamPath()), ".gitignore")
+}
+
 // List returns all template names
 func (m *Manager) List() []string {
 	m.mu.RLock()
@@ -231,51 +326,14 @@ func (m *Manager) Search(query string) []*Template {
 	return results
 }
 
-// Combine combines multiple templates into one
+// Combine combines multiple templates into one. Patterns already written by an
+// earlier template are dropped; comments and blank lines are kept.
 func (m *Manager) Combine(names []string) (string, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	var combined strings.Builder
-	seen := make(map[string]bool)
-
-	// Add header
-	combined.WriteString(fmt.Sprintf("# Combined .gitignore\n# Generated: %s\n# Templates: %s\n\n",
-		filepath.Base(strings.Join(names, ", ")),
-		strings.Join(names, ", ")))
-
-	for _, name := range names {
-		key := strings.ToLower(name)
-		tmpl, exists := m.templates[key]
-		if !exists {
-			return "", fmt.Errorf("template not found: %s", name)
-		}
-
-		// Add template header
-		combined.WriteString(fmt.Sprintf("### %s ###\n", tmpl.Name))
-
-		// Add content with deduplication
-		lines := strings.Split(tmpl.Content, "\n")
-		for _, line := range lines {
-			trimmed := strings.TrimSpace(line)
-
-			// Skip empty lines and comments for deduplication check
-			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
-				combined.WriteString(line + "\n")
-				continue
-			}
-
-			// Deduplicate patterns
-			if !seen[trimmed] {
-				seen[trimmed] = true
-				combined.WriteString(line + "\n")
-			}
-		}
-
-		combined.WriteString("\n")
+	c, err := m.Compose(names)
+	if err != nil {
+		return "", err
 	}
-
-	return combined.String(), nil
+	return c.Render(names), nil
 }
 
 // Count returns the total number of templates
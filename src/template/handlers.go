@@ -7,21 +7,36 @@ import (
 )
 
 // writeJSONError writes the unified JSON error envelope (AI.md PART 9/14).
-func writeJSONError(w http.ResponseWriter, status int, code, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+// details is left out when nil.
+func writeJSONError(w http.ResponseWriter, status int, code, message string, details map[string]interface{}) {
+	body := map[string]interface{}{
 		"ok":      false,
 		"error":   code,
 		"message": message,
-	})
+	}
+	if details != nil {
+		body["details"] = details
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// notFoundDetails names an unknown template and the closest known names, so
+// clients can offer "did you mean" without another request.
+func (m *Manager) notFoundDetails(name string) map[string]interface{} {
+	suggestions := m.Suggest(name, 5)
+	if suggestions == nil {
+		suggestions = []string{}
+	}
+	return map[string]interface{}{"name": name, "suggestions": suggestions}
 }
 
 // HandleGetTemplate returns a specific template
 func (m *Manager) HandleGetTemplate(w http.ResponseWriter, r *http.Request, name string) {
 	tmpl, err := m.Get(name)
 	if err != nil {
-		writeJSONError(w, http.StatusNotFound, "NOT_FOUND", "template not found")
+		writeJSONError(w, http.StatusNotFound, "NOT_FOUND", "template not found", m.notFoundDetails(name))
 		return
 	}
 
@@ -76,12 +91,17 @@ func (m *Manager) HandleList(w http.ResponseWriter, r *http.Request) {
 func (m *Manager) HandleSearch(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	if query == "" {
-		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "query parameter 'q' is required")
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "query parameter 'q' is required", nil)
 		return
 	}
 
-	results := m.Search(query)
+	m.WriteSearchResults(w, r, query, m.Search(query))
+}
 
+// WriteSearchResults writes search results for query, negotiating JSON or a
+// plain-text name list like HandleSearch. It lets callers that time or count
+// the search itself reuse the response format.
+func (m *Manager) WriteSearchResults(w http.ResponseWriter, r *http.Request, query string, results []*Template) {
 	accept := r.Header.Get("Accept")
 
 	if strings.Contains(accept, "application/json") {
@@ -108,7 +128,7 @@ func (m *Manager) HandleSearch(w http.ResponseWriter, r *http.Request) {
 func (m *Manager) HandleCombine(w http.ResponseWriter, r *http.Request) {
 	templatesParam := r.URL.Query().Get("templates")
 	if templatesParam == "" {
-		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "query parameter 'templates' is required")
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", "query parameter 'templates' is required", nil)
 		return
 	}
 
@@ -118,9 +138,15 @@ func (m *Manager) HandleCombine(w http.ResponseWriter, r *http.Request) {
 		names[i] = strings.TrimSpace(name)
 	}
 
+	for _, name := range names {
+		if _, err := m.Get(name); err != nil {
+			writeJSONError(w, http.StatusNotFound, "NOT_FOUND", err.Error(), m.notFoundDetails(name))
+			return
+		}
+	}
 	combined, err := m.Combine(names)
 	if err != nil {
-		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		writeJSONError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error(), nil)
 		return
 	}
 
@@ -167,7 +193,7 @@ func (m *Manager) HandleCategoryTemplates(w http.ResponseWriter, r *http.Request
 	templates := m.GetByCategory(category)
 
 	if len(templates) == 0 {
-		writeJSONError(w, http.StatusNotFound, "NOT_FOUND", "category not found")
+		writeJSONError(w, http.StatusNotFound, "NOT_FOUND", "category not found", nil)
 		return
 	}
 
@@ -0,0 +1,120 @@
+package template
+
+import "strings"
+
+// Token kinds produced by Highlight. They name the part of gitignore(5) syntax
+// a span of text belongs to and double as CSS class suffixes.
+const (
+	TokenText    = "text"
+	TokenComment = "comment"
+	TokenNegate  = "negate"
+	TokenGlob    = "glob"
+	TokenDir     = "dir"
+	TokenEscape  = "escape"
+	TokenInvalid = "invalid"
+)
+
+// Token is one highlighted span of a line.
+type Token struct {
+	Kind string `json:"kind"`
+	Text string `json:"text"`
+}
+
+// HighlightedLine is one line of a template split into tokens. Number is
+// 1-based, matching the #L anchors on template pages.
+type HighlightedLine struct {
+	Number int     `json:"number"`
+	Tokens []Token `json:"tokens"`
+}
+
+// Highlight tokenizes content line by line for syntax highlighting: comments,
+// the "!" negation prefix, glob metacharacters (*, ?, [...]), backslash
+// escapes and the trailing "/" of directory-only rules. Lines ParseRule
+// rejects are a single invalid token. A trailing newline does not produce an
+// empty final line.
+func Highlight(content string) []HighlightedLine {
+	lines := splitLines(content)
+	out := make([]HighlightedLine, len(lines))
+	for i, line := range lines {
+		out[i] = HighlightedLine{Number: i + 1, Tokens: highlightLine(line, i+1)}
+	}
+	return out
+}
+
+// highlightLine tokenizes a single line.
+func highlightLine(line string, lineNo int) []Token {
+	rule, ok, err := ParseRule(line, lineNo)
+	switch {
+	case err != nil:
+		return []Token{{TokenInvalid, line}}
+	case !ok:
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			return []Token{{TokenComment, line}}
+		}
+		if line == "" {
+			return nil
+		}
+		return []Token{{TokenText, line}}
+	}
+
+	var tokens []Token
+	emit := func(kind, text string) {
+		if text == "" {
+			return
+		}
+		// Merge runs of the same kind so plain text stays one span.
+		if n := len(tokens); n > 0 && tokens[n-1].Kind == kind {
+			tokens[n-1].Text += text
+			return
+		}
+		tokens = append(tokens, Token{kind, text})
+	}
+
+	rest := line
+	if rule.Negate {
+		emit(TokenNegate, "!")
+		rest = rest[1:]
+	}
+	// Split off what ParseRule trimmed from the end (the directory slash and
+	// unescaped trailing spaces) so it can be marked separately.
+	body := rest
+	var suffix string
+	if rule.DirOnly {
+		if i := strings.LastIndex(rest, "/"); i >= 0 {
+			body, suffix = rest[:i], rest[i:]
+		}
+	}
+
+	for i := 0; i < len(body); i++ {
+		switch c := body[i]; c {
+		case '\\':
+			end := min(i+2, len(body))
+			emit(TokenEscape, body[i:end])
+			i = end - 1
+		case '*', '?':
+			emit(TokenGlob, string(c))
+		case '[':
+			end := strings.IndexByte(body[i+1:], ']')
+			// "[]...]" and "[!]...]" keep a leading "]" as a member.
+			if end == 0 || (end == 1 && body[i+1] == '!') {
+				if next := strings.IndexByte(body[i+end+2:], ']'); next >= 0 {
+					end += next + 1
+				}
+			}
+			if end < 0 {
+				emit(TokenText, body[i:])
+				i = len(body)
+				continue
+			}
+			emit(TokenGlob, body[i:i+end+2])
+			i += end + 1
+		default:
+			emit(TokenText, string(c))
+		}
+	}
+	if suffix != "" {
+		emit(TokenDir, "/")
+		emit(TokenText, suffix[1:])
+	}
+	return tokens
+}
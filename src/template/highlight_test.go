@@ -0,0 +1,27 @@
+package template
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	lines := Highlight("# Logs\n\n!build/\n*.py[cod]\n\\#file\nlogs/**/debug?.log\nfoo\\\n")
+	if len(lines) != 7 || lines[6].Number != 7 {
+		t.Fatalf("got %d lines", len(lines))
+	}
+	want := [][]Token{
+		{{TokenComment, "# Logs"}},
+		nil,
+		{{TokenNegate, "!"}, {TokenText, "build"}, {TokenDir, "/"}},
+		{{TokenGlob, "*"}, {TokenText, ".py"}, {TokenGlob, "[cod]"}},
+		{{TokenEscape, "\\#"}, {TokenText, "file"}},
+		{{TokenText, "logs/"}, {TokenGlob, "**"}, {TokenText, "/debug"}, {TokenGlob, "?"}, {TokenText, ".log"}},
+		{{TokenInvalid, "foo\\"}},
+	}
+	for i, w := range want {
+		if got := lines[i].Tokens; !reflect.DeepEqual(got, w) {
+			t.Errorf("line %d = %+v, want %+v", i+1, got, w)
+		}
+	}
+}
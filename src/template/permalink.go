@@ -0,0 +1,152 @@
+package template
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+	"sync"
+)
+
+// permalinkVersion is the encoding version byte leading every ID.
+const permalinkVersion = 1
+
+// Permalink flag bits.
+const (
+	// permalinkPinned marks a permalink carrying the dataset version it was
+	// created against.
+	permalinkPinned = 1 << iota
+)
+
+// ErrInvalidPermalink is returned for an ID that is malformed, fails its
+// checksum, or uses an unknown encoding version.
+var ErrInvalidPermalink = errors.New("invalid permalink")
+
+// Permalink is a composition encoded into a self-contained ID: the ordered
+// template names and, optionally, the dataset version they were combined
+// against. Nothing is stored server-side.
+type Permalink struct {
+	Templates []string `json:"templates"`
+	// Dataset is the pinned DatasetVersion, or "" when the link follows the
+	// current dataset.
+	Dataset string `json:"dataset,omitempty"`
+}
+
+// permalinkNames returns the embedded changelog's Names with their indexes.
+// The changelog only ever grows, so a name's index in this list never
+// changes, whatever the dataset becomes; changelog-gen refuses to write a
+// changelog that would move one, and TestPermalinkNamesStable pins them.
+var permalinkNames = sync.OnceValues(func() ([]string, map[string]int) {
+	var names []string
+	if cl, err := LoadChangelog(); err == nil {
+		names = cl.Names()
+	}
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+	return names, index
+})
+
+// Encode returns the permalink ID: URL-safe unpadded base64 of
+//
+//	version (1 byte) | flags (1 byte) | [dataset version (6 bytes)] |
+//	names | CRC-32 of everything before it (4 bytes)
+//
+// Each name is a uvarint: its index in permalinkNames plus one, or 0
+// followed by the uvarint length and bytes of a name the list lacks. A
+// three-template link is a few bytes of names rather than a few dozen.
+func (p Permalink) Encode() (string, error) {
+	buf := []byte{permalinkVersion, 0}
+	if p.Dataset != "" {
+		raw, err := hex.DecodeString(p.Dataset)
+		if err != nil || len(raw) != 6 {
+			return "", fmt.Errorf("dataset version %q is not 12 hex digits", p.Dataset)
+		}
+		buf[1] |= permalinkPinned
+		buf = append(buf, raw...)
+	}
+	_, index := permalinkNames()
+	for _, name := range p.Templates {
+		if name == "" || strings.Contains(name, ",") {
+			return "", fmt.Errorf("invalid template name %q", name)
+		}
+		if i, ok := index[name]; ok {
+			buf = binary.AppendUvarint(buf, uint64(i)+1)
+			continue
+		}
+		buf = binary.AppendUvarint(buf, 0)
+		buf = binary.AppendUvarint(buf, uint64(len(name)))
+		buf = append(buf, name...)
+	}
+	buf = binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// DecodePermalink parses an ID produced by Permalink.Encode.
+func DecodePermalink(id string) (Permalink, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil || len(buf) < 2+4 {
+		return Permalink{}, ErrInvalidPermalink
+	}
+	body, sum := buf[:len(buf)-4], binary.BigEndian.Uint32(buf[len(buf)-4:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return Permalink{}, fmt.Errorf("%w: checksum mismatch", ErrInvalidPermalink)
+	}
+	if version := body[0]; version != permalinkVersion {
+		return Permalink{}, fmt.Errorf("%w: unsupported version %d", ErrInvalidPermalink, version)
+	}
+
+	var p Permalink
+	flags, rest := body[1], body[2:]
+	if flags&permalinkPinned != 0 {
+		if len(rest) < 6 {
+			return Permalink{}, ErrInvalidPermalink
+		}
+		p.Dataset, rest = hex.EncodeToString(rest[:6]), rest[6:]
+	}
+	if len(rest) == 0 {
+		return Permalink{}, fmt.Errorf("%w: no templates", ErrInvalidPermalink)
+	}
+	if p.Templates, err = decodePermalinkNames(rest); err != nil {
+		return Permalink{}, err
+	}
+	return p, nil
+}
+
+// decodePermalinkNames reads the names of a permalink.
+func decodePermalinkNames(buf []byte) ([]string, error) {
+	names, _ := permalinkNames()
+	uvarint := func() (uint64, bool) {
+		v, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return 0, false
+		}
+		buf = buf[n:]
+		return v, true
+	}
+	var out []string
+	for len(buf) > 0 {
+		ref, ok := uvarint()
+		if !ok {
+			return nil, ErrInvalidPermalink
+		}
+		if ref > 0 {
+			if ref > uint64(len(names)) {
+				return nil, fmt.Errorf("%w: unknown name index %d", ErrInvalidPermalink, ref-1)
+			}
+			out = append(out, names[ref-1])
+			continue
+		}
+		n, ok := uvarint()
+		if !ok || n == 0 || n > uint64(len(buf)) {
+			return nil, ErrInvalidPermalink
+		}
+		out = append(out, string(buf[:n]))
+		buf = buf[n:]
+	}
+	return out, nil
+}
@@ -0,0 +1,82 @@
+package template
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPermalinkRoundTrip(t *testing.T) {
+	for _, p := range []Permalink{
+		{Templates: []string{"Go"}},
+		{Templates: []string{"Node", "macOS", "VisualStudioCode"}, Dataset: "85f3a840420f"},
+		{Templates: []string{"Go", "NotYetUpstream", "Node"}},
+	} {
+		id, err := p.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DecodePermalink(id)
+		if err != nil {
+			t.Fatalf("decode %s: %v", id, err)
+		}
+		if !reflect.DeepEqual(got, p) {
+			t.Errorf("round trip = %+v, want %+v", got, p)
+		}
+	}
+}
+
+// TestPermalinkCompact verifies that names are stored as indexes.
+func TestPermalinkCompact(t *testing.T) {
+	p := Permalink{Templates: []string{"Node", "macOS", "VisualStudioCode"}}
+	id, err := p.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// version, flags, three one- or two-byte indexes and the checksum.
+	if len(id) > 16 {
+		t.Errorf("ID %s is %d characters, want at most 16", id, len(id))
+	}
+}
+
+// TestPermalinkNamesStable pins the index of every name issued links may
+// carry: the first pinnedNames names of the embedded changelog must hash to
+// namesDigest. Recording a dataset only appends names, so this fails only if
+// changelog.json was rewritten in a way that breaks existing links.
+func TestPermalinkNamesStable(t *testing.T) {
+	const (
+		pinnedNames = 295
+		namesDigest = "20a2d3e7ffe6f375c518dca5f42cd9ab9155ed5adeef08edc76157061054b2c1"
+	)
+	names, _ := permalinkNames()
+	if len(names) < pinnedNames {
+		t.Fatalf("changelog records %d names, want at least %d", len(names), pinnedNames)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(names[:pinnedNames], "\n")))
+	if got := hex.EncodeToString(sum[:]); got != namesDigest {
+		t.Errorf("names digest = %s, want %s: a name's permalink index changed", got, namesDigest)
+	}
+}
+
+func TestPermalinkRejectsDamage(t *testing.T) {
+	id, err := Permalink{Templates: []string{"Go", "Node"}}.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	flipped := []byte(id)
+	flipped[3] ^= 1
+	for _, bad := range []string{"", "!!!", id[:len(id)-2], string(flipped)} {
+		if _, err := DecodePermalink(bad); !errors.Is(err, ErrInvalidPermalink) {
+			t.Errorf("DecodePermalink(%q) err = %v", bad, err)
+		}
+	}
+	if _, err := (Permalink{Templates: []string{"a,b"}}).Encode(); err == nil {
+		t.Error("Encode accepted a name containing a comma")
+	}
+	if _, err := (Permalink{Templates: []string{"Go"}, Dataset: "xyz"}).Encode(); err == nil {
+		t.Error("Encode accepted a malformed dataset version")
+	}
+}
@@ -0,0 +1,80 @@
+package template
+
+import (
+	"sort"
+	"strings"
+)
+
+// Related returns up to limit templates related to name, best first. A
+// candidate scores for each alias tag it shares with name (e.g. "js"), when
+// either's alias is the other's name, for sharing a category other than Root
+// (the catch-all for languages and frameworks), and for one name extending
+// the other (VisualStudio and VisualStudioCode). Templates that score nothing
+// are left out.
+func (m *Manager) Related(name string, limit int) []*Template {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tmpl, ok := m.templates[strings.ToLower(name)]
+	if !ok || limit <= 0 {
+		return nil
+	}
+	aliases := aliasTags(tmpl)
+	self := strings.ToLower(tmpl.Name)
+
+	type candidate struct {
+		tmpl  *Template
+		score int
+	}
+	var found []candidate
+	for key, other := range m.templates {
+		if key == self {
+			continue
+		}
+		score := 0
+		otherAliases := aliasTags(other)
+		for tag := range otherAliases {
+			if aliases[tag] {
+				score += 2
+			}
+		}
+		// One template's alias naming the other (Node's "npm" tag and an
+		// npm template) is as strong as a shared tag.
+		if aliases[key] || otherAliases[self] {
+			score += 2
+		}
+		if other.Category == tmpl.Category && tmpl.Category != "Root" {
+			score++
+		}
+		if len(self) >= 3 && len(key) >= 3 && (strings.HasPrefix(key, self) || strings.HasPrefix(self, key)) {
+			score += 2
+		}
+		if score > 0 {
+			found = append(found, candidate{other, score})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].score != found[j].score {
+			return found[i].score > found[j].score
+		}
+		return strings.ToLower(found[i].tmpl.Name) < strings.ToLower(found[j].tmpl.Name)
+	})
+
+	out := make([]*Template, 0, min(limit, len(found)))
+	for _, c := range found[:min(limit, len(found))] {
+		out = append(out, c.tmpl)
+	}
+	return out
+}
+
+// aliasTags returns t's tags other than its own name and category, which every
+// template has and so say nothing about relatedness.
+func aliasTags(t *Template) map[string]bool {
+	tags := make(map[string]bool)
+	for _, tag := range t.Tags {
+		if tag != strings.ToLower(t.Name) && tag != strings.ToLower(t.Category) {
+			tags[tag] = true
+		}
+	}
+	return tags
+}
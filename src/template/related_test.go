@@ -0,0 +1,31 @@
+package template
+
+import "testing"
+
+func TestRelated(t *testing.T) {
+	m := newTestManager(
+		&Template{Name: "VisualStudio", Category: "Root", Tags: []string{"visualstudio", "root", "vs"}},
+		&Template{Name: "VisualStudioCode", Category: "Global", Tags: []string{"visualstudiocode", "global", "vscode"}},
+		&Template{Name: "Vim", Category: "Global", Tags: []string{"vim", "global"}},
+		&Template{Name: "Go", Category: "Root", Tags: []string{"go", "root", "golang"}},
+		&Template{Name: "Golang", Category: "community", Tags: []string{"golang", "community"}},
+	)
+	related := m.Related("visualstudiocode", 5)
+	if len(related) != 2 || related[0].Name != "VisualStudio" || related[1].Name != "Vim" {
+		t.Errorf("Related(VisualStudioCode) = %v", names(related))
+	}
+	if related := m.Related("Go", 5); len(related) != 1 || related[0].Name != "Golang" {
+		t.Errorf("Related(Go) = %v", names(related))
+	}
+	if m.Related("nope", 5) != nil {
+		t.Error("Related(unknown) returned templates")
+	}
+}
+
+func names(templates []*Template) []string {
+	out := make([]string, len(templates))
+	for i, t := range templates {
+		out[i] = t.Name
+	}
+	return out
+}
@@ -0,0 +1,161 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rule is a single pattern line of a .gitignore file, split into the parts
+// gitignore(5) gives meaning to.
+type Rule struct {
+	// Line is the 1-based line number the rule came from.
+	Line int `json:"line"`
+	// Raw is the line exactly as written (without the line terminator).
+	Raw string `json:"raw"`
+	// Pattern is the glob with the negation prefix, trailing slash and
+	// unescaped trailing spaces removed.
+	Pattern string `json:"pattern"`
+	// Negate is true for "!pattern" rules that re-include a path.
+	Negate bool `json:"negate,omitempty"`
+	// DirOnly is true when the pattern ended in "/" and only matches
+	// directories.
+	DirOnly bool `json:"dir_only,omitempty"`
+	// Anchored is true when the pattern contains a slash other than a
+	// trailing one, so it matches relative to the .gitignore's directory
+	// rather than at any depth.
+	Anchored bool `json:"anchored,omitempty"`
+}
+
+// Text is the rule as git reads it: Raw without its unescaped trailing
+// spaces.
+func (r Rule) Text() string {
+	return trimTrailingSpaces(r.Raw)
+}
+
+// SyntaxError reports a line that git would reject or silently ignore.
+type SyntaxError struct {
+	Line   int
+	Text   string
+	Reason string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s: %q", e.Line, e.Reason, e.Text)
+}
+
+// ParseRule parses one line of a .gitignore file. ok is false for blank lines
+// and comments, which carry no rule.
+func ParseRule(line string, lineNo int) (rule Rule, ok bool, err error) {
+	raw := strings.TrimRight(line, "\r")
+	text := trimTrailingSpaces(raw)
+	if text == "" || strings.HasPrefix(text, "#") {
+		return Rule{}, false, nil
+	}
+
+	rule = Rule{Line: lineNo, Raw: raw}
+	switch {
+	case strings.HasPrefix(text, "!"):
+		rule.Negate = true
+		text = text[1:]
+	case strings.HasPrefix(text, `\#`), strings.HasPrefix(text, `\!`):
+		text = text[1:]
+	}
+
+	if strings.HasSuffix(text, "/") && !strings.HasSuffix(text, `\/`) {
+		rule.DirOnly = true
+		text = strings.TrimRight(text, "/")
+	}
+	if text == "" {
+		return Rule{}, false, &SyntaxError{Line: lineNo, Text: raw, Reason: "empty pattern"}
+	}
+	if endsWithEscape(text) {
+		return Rule{}, false, &SyntaxError{Line: lineNo, Text: raw, Reason: "pattern ends with an escape character"}
+	}
+	if !bracketsBalanced(text) {
+		return Rule{}, false, &SyntaxError{Line: lineNo, Text: raw, Reason: "unterminated bracket expression"}
+	}
+
+	rule.Pattern = text
+	rule.Anchored = strings.Contains(text, "/")
+	return rule, true, nil
+}
+
+// ParseRules parses every rule in content, stopping at the first invalid line.
+func ParseRules(content string) ([]Rule, error) {
+	var rules []Rule
+	for i, line := range strings.Split(content, "\n") {
+		rule, ok, err := ParseRule(line, i+1)
+		if err != nil {
+			return rules, err
+		}
+		if ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// Validate checks that content is a well-formed .gitignore file: valid UTF-8
+// text with no NUL bytes and no rule git would reject.
+func Validate(content []byte) error {
+	if !utf8.Valid(content) {
+		return fmt.Errorf("not valid UTF-8")
+	}
+	if strings.IndexByte(string(content), 0) >= 0 {
+		return fmt.Errorf("contains NUL bytes")
+	}
+	_, err := ParseRules(string(content))
+	return err
+}
+
+// trimTrailingSpaces removes trailing spaces unless they are escaped with a
+// backslash, as gitignore(5) specifies. Other whitespace, such as a tab, is
+// part of the pattern: git trims only spaces.
+func trimTrailingSpaces(s string) string {
+	for strings.HasSuffix(s, " ") {
+		trimmed := s[:len(s)-1]
+		if strings.HasSuffix(trimmed, `\`) && !endsWithEscape(trimmed[:len(trimmed)-1]) {
+			return s
+		}
+		s = trimmed
+	}
+	return s
+}
+
+// endsWithEscape reports whether s ends in an odd number of backslashes, i.e.
+// a dangling escape with nothing to escape.
+func endsWithEscape(s string) bool {
+	n := 0
+	for i := len(s) - 1; i >= 0 && s[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// bracketsBalanced reports whether every "[" that opens a bracket expression
+// is closed. A "]" directly after "[" or "[!" is a literal member.
+func bracketsBalanced(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			j := i + 1
+			if j < len(s) && (s[j] == '!' || s[j] == '^') {
+				j++
+			}
+			if j < len(s) && s[j] == ']' {
+				j++
+			}
+			for j < len(s) && s[j] != ']' {
+				j++
+			}
+			if j >= len(s) {
+				return false
+			}
+			i = j
+		}
+	}
+	return true
+}
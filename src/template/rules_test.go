@@ -0,0 +1,19 @@
+package template
+
+import "testing"
+
+// TestParseRuleTrailingWhitespace verifies that only unescaped trailing
+// spaces are trimmed, as git does: a trailing tab is part of the pattern.
+func TestParseRuleTrailingWhitespace(t *testing.T) {
+	for line, want := range map[string]string{
+		"foo   ":  "foo",
+		`foo\ `:   `foo\ `,
+		"foo\t":   "foo\t",
+		"foo\t  ": "foo\t",
+	} {
+		rule, ok, err := ParseRule(line, 1)
+		if !ok || err != nil || rule.Pattern != want {
+			t.Errorf("ParseRule(%q) = %q, %v, %v; want %q", line, rule.Pattern, ok, err, want)
+		}
+	}
+}
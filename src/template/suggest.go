@@ -0,0 +1,94 @@
+package template
+
+import (
+	"sort"
+	"strings"
+)
+
+// Suggest returns up to limit template names close to name, best first, for
+// "did you mean" hints on unknown names. A candidate matches when it is within
+// a small edit distance of name (scaled to its length) or when one is a prefix
+// of the other, compared case-insensitively.
+func (m *Manager) Suggest(name string, limit int) []string {
+	query := strings.ToLower(strings.TrimSpace(name))
+	if query == "" || limit <= 0 {
+		return nil
+	}
+	maxDist := max(1, min(3, len(query)/3))
+
+	type candidate struct {
+		name string
+		dist int
+	}
+	var found []candidate
+
+	m.mu.RLock()
+	for key, tmpl := range m.templates {
+		if key == query {
+			continue
+		}
+		d := editDistance(query, key)
+		if d > maxDist {
+			if len(query) < 3 || !(strings.HasPrefix(key, query) || strings.HasPrefix(query, key)) {
+				continue
+			}
+			// Prefix matches rank after every close edit.
+			d = maxDist + 1 + abs(len(key)-len(query))
+		}
+		found = append(found, candidate{tmpl.Name, d})
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].dist != found[j].dist {
+			return found[i].dist < found[j].dist
+		}
+		return found[i].name < found[j].name
+	})
+	var out []string
+	for _, c := range found {
+		if len(out) == limit {
+			break
+		}
+		out = append(out, c.name)
+	}
+	return out
+}
+
+// IsNearMiss reports whether name is not a template but is within suggestion
+// distance of one, which usually means a typo.
+func (m *Manager) IsNearMiss(name string) bool {
+	if _, err := m.Get(name); err == nil {
+		return false
+	}
+	return len(m.Suggest(name, 1)) > 0
+}
+
+// editDistance is the Levenshtein distance between a and b, byte-wise; template
+// names are ASCII.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,31 @@
+package template
+
+import "testing"
+
+func TestSuggest(t *testing.T) {
+	m, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name string
+		want string
+	}{
+		{"Pyhton", "Python"},
+		{"terrafrom", "Terraform"},
+		{"nod", "Node"},
+		{"VisualStudioCod", "VisualStudioCode"},
+	}
+	for _, c := range cases {
+		got := m.Suggest(c.name, 3)
+		if len(got) == 0 || got[0] != c.want {
+			t.Errorf("Suggest(%q) = %v, want %s first", c.name, got, c.want)
+		}
+	}
+	if got := m.Suggest("qqqqqqqqqq", 3); len(got) != 0 {
+		t.Errorf("Suggest(garbage) = %v, want none", got)
+	}
+	if m.IsNearMiss("Go") || !m.IsNearMiss("Goo") {
+		t.Error("IsNearMiss misclassified Go/Goo")
+	}
+}
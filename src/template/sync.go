@@ -0,0 +1,347 @@
+package template
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apimgr/gitignore/src/config"
+)
+
+// ErrUpToDate is returned by Syncer.Sync when the upstream revision is the one
+// already loaded, so there is nothing to stage.
+var ErrUpToDate = errors.New("template dataset already up to date")
+
+// syncStateFile is written into a staged dataset and records where it came
+// from, so a restart can reload it without contacting the mirror.
+const syncStateFile = ".sync.json"
+
+// SyncState describes the upstream source of a staged dataset.
+type SyncState struct {
+	URL      string    `json:"url"`
+	Ref      string    `json:"ref"`
+	Revision string    `json:"revision"`
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+// Changes lists template names that differ between two datasets.
+type Changes struct {
+	Added    []string `json:"added"`
+	Removed  []string `json:"removed"`
+	Modified []string `json:"modified"`
+}
+
+// Empty reports whether no template was added, removed or modified.
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// SyncResult is the outcome of a successful sync. Revision is the changelog
+// entry for the swap, dated at State.SyncedAt.
+type SyncResult struct {
+	State    SyncState
+	Count    int
+	Changes  Changes
+	Revision Revision
+}
+
+// Syncer pulls the github/gitignore dataset from a git URL (including file://
+// mirrors), syntax-checks it, stages it under the data directory and swaps it
+// into a Manager. The external git binary does the transfer, the same way the
+// tor package drives an external tor binary.
+type Syncer struct {
+	cfg config.TemplateSyncConfig
+	// dir is the staging root; the live dataset is dir/current.
+	dir string
+	// gitBin is the git executable; swappable in tests.
+	gitBin string
+}
+
+// NewSyncer builds a Syncer that stages datasets under {dataDir}/templates.
+func NewSyncer(cfg config.TemplateSyncConfig, dataDir string) *Syncer {
+	return &Syncer{
+		cfg:    cfg,
+		dir:    filepath.Join(dataDir, "templates"),
+		gitBin: "git",
+	}
+}
+
+// CurrentDir returns the directory holding the live synced dataset.
+func (s *Syncer) CurrentDir() string {
+	return filepath.Join(s.dir, "current")
+}
+
+// ref returns the git ref to fetch: the release pin when set, else the branch.
+func (s *Syncer) ref() string {
+	if s.cfg.Pin != "" {
+		return s.cfg.Pin
+	}
+	if s.cfg.Branch != "" {
+		return s.cfg.Branch
+	}
+	return "main"
+}
+
+// LoadStaged loads a previously synced dataset into m. It returns false when no
+// staged dataset exists or it no longer matches the configured release pin, in
+// which case m keeps the embedded snapshot until the next sync. A dataset
+// left in previous by a sync interrupted mid-promote is restored first.
+func (s *Syncer) LoadStaged(m *Manager) (bool, error) {
+	if err := s.restorePrevious(); err != nil {
+		return false, err
+	}
+	state, err := readSyncState(s.CurrentDir())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	if state.URL != s.cfg.URL || state.Ref != s.ref() {
+		return false, nil
+	}
+	if err := m.LoadDir(s.CurrentDir(), state.Revision); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Sync fetches the configured ref, validates every template, stages the
+// checkout and atomically replaces m's dataset. It returns ErrUpToDate when
+// the fetched revision is already loaded.
+func (s *Syncer) Sync(ctx context.Context, m *Manager) (*SyncResult, error) {
+	if s.cfg.URL == "" {
+		return nil, fmt.Errorf("templates.sync.url is not set")
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create templates dir: %w", err)
+	}
+
+	staging, err := os.MkdirTemp(s.dir, ".staging-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	ref := s.ref()
+	revision, err := s.fetch(ctx, staging, ref)
+	if err != nil {
+		return nil, err
+	}
+	if revision == m.Revision() {
+		return nil, ErrUpToDate
+	}
+
+	if err := validateTree(staging); err != nil {
+		return nil, err
+	}
+	if err := os.RemoveAll(filepath.Join(staging, ".git")); err != nil {
+		return nil, err
+	}
+
+	state := SyncState{URL: s.cfg.URL, Ref: ref, Revision: revision, SyncedAt: time.Now().UTC()}
+	if err := writeSyncState(staging, state); err != nil {
+		return nil, err
+	}
+
+	// Build the new dataset before touching the live directory so a load
+	// failure leaves both the disk and the in-memory snapshot untouched.
+	next := &Manager{}
+	if err := next.LoadDir(staging, revision); err != nil {
+		return nil, err
+	}
+	changes := diffDatasets(m.ListAll(), next.ListAll())
+	rev := NewRevision(m.ListAll(), next.ListAll(), state.SyncedAt, revision)
+
+	if err := s.promote(staging); err != nil {
+		return nil, err
+	}
+	if err := m.LoadDir(s.CurrentDir(), revision); err != nil {
+		return nil, err
+	}
+
+	return &SyncResult{State: state, Count: next.Count(), Changes: changes, Revision: rev}, nil
+}
+
+// fetch shallow-fetches ref from the configured URL into dir and returns the
+// resolved commit hash. Fetching into a fresh repository (rather than cloning
+// with --branch) lets ref be a branch, a tag, or a commit hash. The URL and
+// ref come from the config, so values git would parse as options are refused.
+func (s *Syncer) fetch(ctx context.Context, dir, ref string) (string, error) {
+	if strings.HasPrefix(s.cfg.URL, "-") {
+		return "", fmt.Errorf("templates.sync.url %q must not start with '-'", s.cfg.URL)
+	}
+	if strings.HasPrefix(ref, "-") {
+		return "", fmt.Errorf("templates.sync branch or pin %q must not start with '-'", ref)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	steps := [][]string{
+		{"init", "--quiet", dir},
+		{"-C", dir, "fetch", "--quiet", "--depth", "1", "--", s.cfg.URL, ref},
+		{"-C", dir, "checkout", "--quiet", "FETCH_HEAD"},
+	}
+	for _, args := range steps {
+		if _, err := s.git(ctx, args...); err != nil {
+			return "", err
+		}
+	}
+	out, err := s.git(ctx, "-C", dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// git runs one git command with prompts disabled and returns its stdout.
+func (s *Syncer) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, s.gitBin, args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[len(args)-1], msg)
+	}
+	return string(out), nil
+}
+
+// promote replaces the live dataset with staging in two renames: the current
+// dataset is moved aside to previous, then staging takes its place, and
+// previous is removed only after that succeeds. The swap is not atomic; a
+// crash between the renames leaves no current dataset, and LoadStaged puts
+// previous back.
+func (s *Syncer) promote(staging string) error {
+	current := s.CurrentDir()
+	previous := s.previousDir()
+	_ = os.RemoveAll(previous)
+
+	if _, err := os.Stat(current); err == nil {
+		if err := os.Rename(current, previous); err != nil {
+			return fmt.Errorf("retire current dataset: %w", err)
+		}
+	}
+	if err := os.Rename(staging, current); err != nil {
+		_ = os.Rename(previous, current)
+		return fmt.Errorf("promote staged dataset: %w", err)
+	}
+	_ = os.RemoveAll(previous)
+	return nil
+}
+
+// restorePrevious moves previous back to current when promote was cut short
+// between its renames.
+func (s *Syncer) restorePrevious() error {
+	if _, err := os.Stat(s.CurrentDir()); !errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if _, err := os.Stat(s.previousDir()); err != nil {
+		return nil
+	}
+	if err := os.Rename(s.previousDir(), s.CurrentDir()); err != nil {
+		return fmt.Errorf("restore previous dataset: %w", err)
+	}
+	return nil
+}
+
+// previousDir holds the retired dataset while promote swaps in a new one.
+func (s *Syncer) previousDir() string {
+	return filepath.Join(s.dir, "previous")
+}
+
+// validateTree syntax-checks every .gitignore file under dir and fails on the
+// first invalid one, naming the file and line.
+func validateTree(dir string) error {
+	count := 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".gitignore") {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := Validate(content); err != nil {
+			rel, _ := filepath.Rel(dir, path)
+			return fmt.Errorf("%s: %w", rel, err)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("no .gitignore templates found upstream")
+	}
+	return nil
+}
+
+// diffDatasets compares two template sets by name and content.
+func diffDatasets(prev, next []*Template) Changes {
+	before := make(map[string]*Template, len(prev))
+	for _, t := range prev {
+		before[strings.ToLower(t.Name)] = t
+	}
+	var c Changes
+	for _, t := range next {
+		key := strings.ToLower(t.Name)
+		old, ok := before[key]
+		switch {
+		case !ok:
+			c.Added = append(c.Added, t.Name)
+		case old.Content != t.Content:
+			c.Modified = append(c.Modified, t.Name)
+		}
+		delete(before, key)
+	}
+	for _, t := range before {
+		c.Removed = append(c.Removed, t.Name)
+	}
+	sort.Strings(c.Added)
+	sort.Strings(c.Removed)
+	sort.Strings(c.Modified)
+	return c
+}
+
+func readSyncState(dir string) (SyncState, error) {
+	var st SyncState
+	data, err := os.ReadFile(filepath.Join(dir, syncStateFile))
+	if err != nil {
+		return st, err
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return st, fmt.Errorf("parse %s: %w", syncStateFile, err)
+	}
+	return st, nil
+}
+
+func writeSyncState(dir string, st SyncState) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, syncStateFile), data, 0o644)
+}
@@ -0,0 +1,230 @@
+package template
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apimgr/gitignore/src/config"
+)
+
+// newMirror creates a local git repository laid out like github/gitignore and
+// returns its file:// URL plus a commit helper for follow-up revisions.
+func newMirror(t *testing.T, files map[string]string) (string, func(map[string]string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	commit := func(files map[string]string) {
+		t.Helper()
+		for name, content := range files {
+			path := filepath.Join(dir, name)
+			if content == "" {
+				_ = os.Remove(path)
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		run("add", "-A")
+		run("commit", "--quiet", "-m", "update")
+	}
+	run("init", "--quiet", "--initial-branch=main")
+	commit(files)
+	return "file://" + dir, commit
+}
+
+func TestSyncFromFileMirror(t *testing.T) {
+	url, commit := newMirror(t, map[string]string{
+		"Go.gitignore":           "# Go\n*.exe\n",
+		"Global/macOS.gitignore": "# macOS\n.DS_Store\n",
+	})
+	m, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSyncer(config.TemplateSyncConfig{URL: url, Branch: "main"}, t.TempDir())
+
+	res, err := s.Sync(context.Background(), m)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if m.Count() != 2 || res.Count != 2 {
+		t.Fatalf("count = %d/%d, want 2", m.Count(), res.Count)
+	}
+	if m.Revision() != res.State.Revision || m.Revision() == EmbeddedRevision {
+		t.Errorf("revision = %q, want synced commit %q", m.Revision(), res.State.Revision)
+	}
+	if tmpl, err := m.Get("macOS"); err != nil || tmpl.Category != "Global" {
+		t.Errorf("macOS = %+v, %v; want Global category", tmpl, err)
+	}
+	if len(res.Changes.Modified) != 2 {
+		t.Errorf("modified = %v, want Go and macOS", res.Changes.Modified)
+	}
+
+	if _, err := s.Sync(context.Background(), m); !errors.Is(err, ErrUpToDate) {
+		t.Errorf("second sync err = %v, want ErrUpToDate", err)
+	}
+
+	commit(map[string]string{"Go.gitignore": "# Go\n*.exe\n*.test\n", "Global/macOS.gitignore": "", "Zig.gitignore": "zig-out/\n"})
+	res, err = s.Sync(context.Background(), m)
+	if err != nil {
+		t.Fatalf("resync: %v", err)
+	}
+	got := res.Changes
+	if strings.Join(got.Added, ",") != "Zig" || strings.Join(got.Removed, ",") != "macOS" || strings.Join(got.Modified, ",") != "Go" {
+		t.Errorf("changes = %+v", got)
+	}
+	if rev := res.Revision; rev.Version != m.Version() || rev.Upstream != res.State.Revision || len(rev.Changes) != 3 {
+		t.Errorf("revision = %+v, want the changelog entry for dataset %s", rev, m.Version())
+	}
+
+	// A fresh manager picks the staged dataset back up, as on restart.
+	fresh, _ := New()
+	if ok, err := s.LoadStaged(fresh); !ok || err != nil {
+		t.Fatalf("LoadStaged = %v, %v", ok, err)
+	}
+	if fresh.Revision() != m.Revision() || fresh.Count() != 2 {
+		t.Errorf("reloaded %s/%d, want %s/2", fresh.Revision(), fresh.Count(), m.Revision())
+	}
+
+	// A crash between promote's renames leaves only previous; the restart
+	// restores it.
+	if err := os.Rename(s.CurrentDir(), s.previousDir()); err != nil {
+		t.Fatal(err)
+	}
+	fresh, _ = New()
+	if ok, err := s.LoadStaged(fresh); !ok || err != nil || fresh.Revision() != m.Revision() {
+		t.Fatalf("LoadStaged after an interrupted promote = %v, %v (revision %s)", ok, err, fresh.Revision())
+	}
+	if _, err := os.Stat(s.previousDir()); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("previous dataset was not moved back")
+	}
+}
+
+func TestSyncRejectsInvalidTemplate(t *testing.T) {
+	url, _ := newMirror(t, map[string]string{
+		"Go.gitignore":     "*.exe\n",
+		"Broken.gitignore": "build/[abc\n",
+	})
+	m, _ := New()
+	before := m.Count()
+	s := NewSyncer(config.TemplateSyncConfig{URL: url, Branch: "main"}, t.TempDir())
+
+	_, err := s.Sync(context.Background(), m)
+	if err == nil || !strings.Contains(err.Error(), "Broken.gitignore") {
+		t.Fatalf("err = %v, want Broken.gitignore syntax error", err)
+	}
+	if m.Count() != before || m.Revision() != EmbeddedRevision {
+		t.Error("embedded snapshot was replaced despite a failed sync")
+	}
+	if _, err := os.Stat(s.CurrentDir()); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("failed sync left a live dataset on disk")
+	}
+}
+
+func TestSyncRejectsOptionLikeValues(t *testing.T) {
+	url, _ := newMirror(t, map[string]string{"Go.gitignore": "*.exe\n"})
+	for _, cfg := range []config.TemplateSyncConfig{
+		{URL: "--upload-pack=touch /tmp/pwned", Branch: "main"},
+		{URL: url, Branch: "--upload-pack=touch /tmp/pwned"},
+		{URL: url, Branch: "main", Pin: "-v1"},
+	} {
+		m, _ := New()
+		s := NewSyncer(cfg, t.TempDir())
+		if _, err := s.Sync(context.Background(), m); err == nil || !strings.Contains(err.Error(), "must not start with '-'") {
+			t.Errorf("Sync(%+v) err = %v, want an option-like value refused", cfg, err)
+		}
+	}
+}
+
+func TestSyncHonorsPin(t *testing.T) {
+	url, commit := newMirror(t, map[string]string{"Go.gitignore": "*.exe\n"})
+	dir := strings.TrimPrefix(url, "file://")
+	if out, err := exec.Command("git", "-C", dir, "tag", "v1").CombinedOutput(); err != nil {
+		t.Fatalf("tag: %v\n%s", err, out)
+	}
+	commit(map[string]string{"Go.gitignore": "*.exe\n*.out\n"})
+
+	m, _ := New()
+	s := NewSyncer(config.TemplateSyncConfig{URL: url, Branch: "main", Pin: "v1"}, t.TempDir())
+	if _, err := s.Sync(context.Background(), m); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	tmpl, _ := m.Get("Go")
+	if strings.Contains(tmpl.Content, "*.out") {
+		t.Error("pinned sync followed the branch head")
+	}
+}
+
+func TestEmbeddedDatasetValidates(t *testing.T) {
+	m, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tmpl := range m.ListAll() {
+		if err := Validate([]byte(tmpl.Content)); err != nil {
+			t.Errorf("%s: %v", tmpl.Name, err)
+		}
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	cases := []struct {
+		line    string
+		ok      bool
+		want    Rule
+		wantErr bool
+	}{
+		{line: "# comment"},
+		{line: "   "},
+		{line: "!important.log", ok: true, want: Rule{Pattern: "important.log", Negate: true}},
+		{line: "node_modules/", ok: true, want: Rule{Pattern: "node_modules", DirOnly: true}},
+		{line: "/build", ok: true, want: Rule{Pattern: "/build", Anchored: true}},
+		{line: `\#file`, ok: true, want: Rule{Pattern: "#file"}},
+		{line: `trailing\ `, ok: true, want: Rule{Pattern: `trailing\ `}},
+		{line: "*.[oa]", ok: true, want: Rule{Pattern: "*.[oa]"}},
+		{line: "[]]x", ok: true, want: Rule{Pattern: "[]]x"}},
+		{line: "bad[", wantErr: true},
+		{line: `bad\`, wantErr: true},
+		{line: "!", wantErr: true},
+	}
+	for _, c := range cases {
+		rule, ok, err := ParseRule(c.line, 1)
+		if (err != nil) != c.wantErr {
+			t.Errorf("%q: err = %v, wantErr %v", c.line, err, c.wantErr)
+			continue
+		}
+		if ok != c.ok {
+			t.Errorf("%q: ok = %v, want %v", c.line, ok, c.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if rule.Pattern != c.want.Pattern || rule.Negate != c.want.Negate ||
+			rule.DirOnly != c.want.DirOnly || rule.Anchored != c.want.Anchored {
+			t.Errorf("%q: got %+v, want %+v", c.line, rule, c.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/apimgr/gitignore/src/config"
+	"github.com/apimgr/gitignore/src/db"
+	"github.com/apimgr/gitignore/src/scheduler"
+	"github.com/apimgr/gitignore/src/template"
+)
+
+// newTemplateSyncer builds the upstream dataset syncer, or returns nil when
+// templates.sync is disabled so the template_sync task skips.
+func newTemplateSyncer(cfg *config.Config, dataDir string) *template.Syncer {
+	if !cfg.Server.Templates.Sync.Enabled {
+		return nil
+	}
+	return template.NewSyncer(cfg.Server.Templates.Sync, dataDir)
+}
+
+// loadSyncedTemplates swaps in the last successfully synced dataset at startup
+// so a restart serves the same revision the scheduler last installed. Any
+// problem leaves the embedded snapshot in place; the next sync retries.
+func loadSyncedTemplates(tm *template.Manager, syncer *template.Syncer) {
+	if tm == nil || syncer == nil {
+		return
+	}
+	ok, err := syncer.LoadStaged(tm)
+	if err != nil {
+		log.Printf("templates: failed to load synced dataset, using embedded snapshot: %v", err)
+		return
+	}
+	if ok {
+		log.Printf("templates: loaded synced dataset %s (%d templates)", tm.Revision(), tm.Count())
+	}
+}
+
+// templateSyncHandler adapts Syncer.Sync to a scheduler handler for the
+// template_sync task. Each sync that changes the dataset records its revision
+// in server.db, where the running server's changelog picks it up; failures
+// surface through the scheduler status and the scheduler_error email like any
+// other task.
+func templateSyncHandler(tm *template.Manager, syncer *template.Syncer) scheduler.HandlerFunc {
+	return func(ctx context.Context) error {
+		res, err := syncer.Sync(ctx, tm)
+		if errors.Is(err, template.ErrUpToDate) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := recordTemplateRevision(res.Revision); err != nil {
+			return err
+		}
+		c := res.Changes
+		publishTemplatesUpdated(tm, c.Added, c.Removed, c.Modified)
+		log.Printf("templates: synced %s@%s (%d templates: %d added, %d removed, %d modified)",
+			res.State.Ref, res.State.Revision, res.Count, len(c.Added), len(c.Removed), len(c.Modified))
+		return nil
+	}
+}
+
+// recordTemplateRevision stores a synced revision and merges it into the
+// running server's changelog, if this process is serving.
+func recordTemplateRevision(rev template.Revision) error {
+	data, err := json.Marshal(rev)
+	if err != nil {
+		return err
+	}
+	if err := db.RecordTemplateRevision(db.TemplateRevision{Version: rev.Version, SyncedAt: rev.Date, Data: data}); err != nil {
+		return err
+	}
+	if eventServer != nil {
+		eventServer.ReloadChangelog()
+	}
+	return nil
+}
@@ -409,8 +409,16 @@ type TorManager struct {
 	serverPort int
 	ctx        context.Context
 	cancel     context.CancelFunc
+	onStatus   func(status, address string)
 }
 
+// Tor status values reported to an OnStatus hook.
+const (
+	TorStatusConnected    = "connected"
+	TorStatusDisconnected = "disconnected"
+	TorStatusFailed       = "failed"
+)
+
 // NewTorManager creates a Tor manager. serverPort is the server's HTTP port the
 // hidden service forwards to; configDir and dataDir locate the Tor files.
 func NewTorManager(ctx context.Context, serverPort int, configDir, dataDir string, cfg *config.TorConfig) *TorManager {
@@ -425,6 +433,22 @@ func NewTorManager(ctx context.Context, serverPort int, configDir, dataDir strin
 	}
 }
 
+// OnStatus sets a hook called whenever the hidden service connects (with its
+// address), loses its control connection, or fails to start. The hook runs
+// with the manager locked, so it must not call back into the manager.
+func (tm *TorManager) OnStatus(fn func(status, address string)) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.onStatus = fn
+}
+
+// reportLocked calls the status hook; the caller must hold tm.mu.
+func (tm *TorManager) reportLocked(status, address string) {
+	if tm.onStatus != nil {
+		tm.onStatus(status, address)
+	}
+}
+
 // Start initializes Tor. Callers should treat a returned error as non-fatal:
 // the server continues without Tor.
 func (tm *TorManager) Start() error {
@@ -437,9 +461,11 @@ func (tm *TorManager) Start() error {
 func (tm *TorManager) startLocked() error {
 	service, err := startDedicatedTor(tm.ctx, tm.serverPort, tm.configDir, tm.dataDir, tm.config)
 	if err != nil {
+		tm.reportLocked(TorStatusFailed, "")
 		return err
 	}
 	tm.service = service
+	tm.reportLocked(TorStatusConnected, service.OnionAddress())
 	return nil
 }
 
@@ -573,6 +599,9 @@ func (tm *TorManager) Monitor(ctx context.Context) {
 			}
 			if _, err := svc.tor.Control.GetInfo("version"); err != nil {
 				log.Printf("Tor: connection lost, reconnecting: %v", err)
+				tm.mu.Lock()
+				tm.reportLocked(TorStatusDisconnected, "")
+				tm.mu.Unlock()
 				if err := tm.Restart(); err != nil {
 					log.Printf("Tor: restart failed: %v", err)
 				}
@@ -29,6 +29,7 @@ func startTor(ctx context.Context, cfg *config.Config, configDir, dataDir string
 
 	torCfg := cfg.Server.Tor
 	mgr := tor.NewTorManager(ctx, serverPort, configDir, dataDir, &torCfg)
+	mgr.OnStatus(publishTorStatus)
 
 	go func() {
 		if err := mgr.Start(); err != nil {
@@ -11,6 +11,7 @@ import (
 
 	"github.com/apimgr/gitignore/src/config"
 	"github.com/apimgr/gitignore/src/scheduler"
+	"github.com/apimgr/gitignore/src/server"
 	"github.com/apimgr/gitignore/src/updater"
 )
 
@@ -107,6 +108,7 @@ func updateCheckHandler(cfg *config.Config, dataDir string) scheduler.HandlerFun
 		if lastNotifiedUpdate(dataDir) != rel.TagName {
 			log.Printf("update_check: update available: %s (current %s)", rel.TagName, Version)
 			emitUpdateAvailable(Version, rel.TagName)
+			publishUpdate(server.EventUpdateAvailable, Version, rel.TagName)
 			if err := recordNotifiedUpdate(dataDir, rel.TagName); err != nil {
 				log.Printf("update_check: failed to record notified version: %v", err)
 			}
@@ -121,6 +123,7 @@ func updateCheckHandler(cfg *config.Config, dataDir string) scheduler.HandlerFun
 			return err
 		}
 		emitUpdateInstalled(Version, rel.TagName)
+		publishUpdate(server.EventUpdateInstalled, Version, rel.TagName)
 		log.Printf("update_check: installed %s, re-executing", rel.TagName)
 		// Re-exec so the running server loads the new binary (AI.md PART 22
 		// "Restart service or re-exec"). Only returns on error.
@@ -153,3 +156,48 @@ func recordNotifiedUpdate(dataDir, tag string) error {
 	}
 	return os.WriteFile(path, []byte(tag+"\n"), 0o644)
 }
+
+// cliAssetName is the release-asset base name of the gitignore-cli builds.
+const cliAssetName = projectName + "-cli"
+
+// publishCLIRelease looks up the GitHub release of the running version and
+// publishes its gitignore-cli builds in /api/autodiscover cli_versions (AI.md
+// PART 32 "CLI Auto-Update"). A failed lookup is retried hourly; development
+// builds have no release and publish nothing.
+func publishCLIRelease(cfg *config.Config, srv *server.Server) {
+	if _, err := fmt.Sscanf(strings.TrimPrefix(Version, "v"), "%d.", new(int)); err != nil {
+		return
+	}
+	u := newUpdater(cfg)
+	for {
+		rel, err := lookupCLIRelease(u)
+		if err == nil && rel != nil {
+			srv.SetCLIRelease(rel)
+			log.Printf("Publishing gitignore-cli %s for %d platforms", rel.Version, len(rel.Binaries))
+			return
+		}
+		if err != nil {
+			log.Printf("cli_versions: release lookup failed: %v", err)
+		}
+		time.Sleep(time.Hour)
+	}
+}
+
+// lookupCLIRelease fetches the release tagged with the running version and
+// its checksums; nil when no such release exists.
+func lookupCLIRelease(u *updater.Updater) (*server.CLIRelease, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	rel, err := u.ReleaseByTag(ctx, "v"+strings.TrimPrefix(Version, "v"))
+	if err == nil && rel == nil {
+		rel, err = u.ReleaseByTag(ctx, strings.TrimPrefix(Version, "v"))
+	}
+	if err != nil || rel == nil {
+		return nil, err
+	}
+	sums, err := u.Checksums(ctx, rel)
+	if err != nil {
+		return nil, err
+	}
+	return server.CLIReleaseFrom(rel, cliAssetName, sums), nil
+}
@@ -17,6 +17,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -31,6 +32,10 @@ type Release struct {
 	Prerelease  bool      `json:"prerelease"`
 	PublishedAt time.Time `json:"published_at"`
 	Assets      []Asset   `json:"assets"`
+	// Checksums maps asset names to their SHA256 when the assets are
+	// downloaded from elsewhere (a server's cli_versions) and the release
+	// carries no checksums.txt asset of its own.
+	Checksums map[string]string `json:"-"`
 }
 
 // Asset is a single downloadable release artifact.
@@ -50,6 +55,9 @@ type Config struct {
 	APIBaseURL     string
 	UserAgent      string
 	Client         *http.Client
+	// Executable is the binary Install replaces; empty means the running
+	// one.
+	Executable string
 }
 
 // Updater performs update checks and installs against a single repo/channel.
@@ -87,6 +95,34 @@ func (u *Updater) Check(ctx context.Context) (*Release, error) {
 	return u.check(ctx, 0)
 }
 
+// ReleaseByTag returns the release tagged tag, or nil when there is none.
+func (u *Updater) ReleaseByTag(ctx context.Context, tag string) (*Release, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/tags/%s", u.cfg.APIBaseURL, u.cfg.Repo, tag)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", u.cfg.UserAgent)
+
+	resp, err := u.cfg.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API error: %d", resp.StatusCode)
+	}
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
 // CheckDeferred behaves like Check but ignores releases published fewer than
 // deferDays ago (AI.md PART 22 "Defer Semantics"). It selects the newest
 // eligible release. deferDays <= 0 disables the gate.
@@ -171,9 +207,11 @@ func (u *Updater) Install(ctx context.Context, release *Release) error {
 	}
 	defer os.Remove(tmpPath)
 
-	currentPath, err := os.Executable()
-	if err != nil {
-		return fmt.Errorf("failed to get executable path: %w", err)
+	currentPath := u.cfg.Executable
+	if currentPath == "" {
+		if currentPath, err = os.Executable(); err != nil {
+			return fmt.Errorf("failed to get executable path: %w", err)
+		}
 	}
 	currentPath, err = filepath.EvalSymlinks(currentPath)
 	if err != nil {
@@ -192,7 +230,7 @@ func (u *Updater) Install(ctx context.Context, release *Release) error {
 // 22). On success it returns the temp path (caller owns cleanup); on any failure
 // the temp file is removed before returning.
 func (u *Updater) downloadVerified(ctx context.Context, release *Release) (string, error) {
-	assetName := u.binaryAssetName()
+	assetName := u.AssetName()
 	var downloadURL string
 	for _, asset := range release.Assets {
 		if asset.Name == assetName {
@@ -261,19 +299,42 @@ func (u *Updater) downloadVerified(ctx context.Context, release *Release) (strin
 	return tmpPath, nil
 }
 
-// binaryAssetName returns the release-asset name for the running platform,
+// AssetName returns the release-asset name for the running platform,
 // matching the {name}-{os}-{arch} convention from `make build-all`.
-func (u *Updater) binaryAssetName() string {
-	name := u.cfg.BinaryName + "-" + runtime.GOOS + "-" + runtime.GOARCH
+func (u *Updater) AssetName() string {
+	name := u.cfg.BinaryName + "-" + Platform()
 	if runtime.GOOS == "windows" {
 		name += ".exe"
 	}
 	return name
 }
 
-// fetchExpectedChecksum downloads the release's checksums.txt asset and returns
-// the SHA256 recorded for assetName. Each line is "{sha256}  {filename}".
+// Platform returns the running platform as "{os}-{arch}".
+func Platform() string {
+	return runtime.GOOS + "-" + runtime.GOARCH
+}
+
+// fetchExpectedChecksum returns the SHA256 recorded for assetName: the
+// release's out-of-band checksum when it has one, else the entry in its
+// checksums.txt asset.
 func (u *Updater) fetchExpectedChecksum(ctx context.Context, release *Release, assetName string) (string, error) {
+	if sum, ok := release.Checksums[assetName]; ok {
+		return sum, nil
+	}
+	sums, err := u.Checksums(ctx, release)
+	if err != nil {
+		return "", err
+	}
+	sum, ok := sums[assetName]
+	if !ok {
+		return "", fmt.Errorf("no checksum entry for %s", assetName)
+	}
+	return sum, nil
+}
+
+// Checksums downloads the release's checksums.txt asset and returns the
+// SHA256 of each asset it lists. Each line is "{sha256}  {filename}".
+func (u *Updater) Checksums(ctx context.Context, release *Release) (map[string]string, error) {
 	var checksumsURL string
 	for _, asset := range release.Assets {
 		if asset.Name == "checksums.txt" {
@@ -282,34 +343,35 @@ func (u *Updater) fetchExpectedChecksum(ctx context.Context, release *Release, a
 		}
 	}
 	if checksumsURL == "" {
-		return "", fmt.Errorf("release has no checksums.txt asset")
+		return nil, fmt.Errorf("release has no checksums.txt asset")
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, checksumsURL, nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	req.Header.Set("User-Agent", u.cfg.UserAgent)
 	resp, err := u.cfg.Client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("checksum download failed: %d", resp.StatusCode)
+		return nil, fmt.Errorf("checksum download failed: %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	sums := make(map[string]string)
 	for _, line := range strings.Split(string(body), "\n") {
 		fields := strings.Fields(line)
-		if len(fields) == 2 && fields[1] == assetName {
-			return fields[0], nil
+		if len(fields) == 2 {
+			sums[fields[1]] = fields[0]
 		}
 	}
-	return "", fmt.Errorf("no checksum entry for %s", assetName)
+	return sums, nil
 }
 
 // verifyChecksum verifies the SHA256 of filePath against expectedHash.
@@ -350,3 +412,48 @@ func matchesBranch(r Release, branch string) bool {
 		return false
 	}
 }
+
+// Newer reports whether version candidate is newer than current. Versions
+// are compared as dotted numbers, ignoring a leading "v" and any "-suffix";
+// a version that does not parse (such as a "dev" build) differs from every
+// other, so any candidate counts as newer than it.
+func Newer(candidate, current string) bool {
+	a, okA := parseVersion(candidate)
+	b, okB := parseVersion(current)
+	if !okA || !okB {
+		return strings.TrimPrefix(candidate, "v") != strings.TrimPrefix(current, "v")
+	}
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+// parseVersion splits "v1.2.3-beta" into [1 2 3].
+func parseVersion(v string) ([]int, bool) {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		v = v[:i]
+	}
+	if v == "" {
+		return nil, false
+	}
+	var parts []int
+	for _, f := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
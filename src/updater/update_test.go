@@ -85,6 +85,19 @@ func newFixture(t *testing.T, latestTag string, releases []Release) *fixture {
 		json.NewEncoder(w).Encode(out)
 	})
 
+	mux.HandleFunc("/repos/apimgr/gitignore/releases/tags/", func(w http.ResponseWriter, r *http.Request) {
+		tag := r.URL.Path[len("/repos/apimgr/gitignore/releases/tags/"):]
+		for i := range releases {
+			if releases[i].TagName == tag {
+				rel := releases[i]
+				attach(&rel)
+				json.NewEncoder(w).Encode(rel)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+
 	fx.server = httptest.NewServer(mux)
 	t.Cleanup(fx.server.Close)
 	// Rewrite asset base once server URL is known: handlers close over fx.server.
@@ -332,3 +345,62 @@ func TestMatchesBranch(t *testing.T) {
 		t.Fatal("daily must not match beta channel")
 	}
 }
+
+func TestReleaseByTagAndChecksums(t *testing.T) {
+	fx := newFixture(t, "v2.0.0", []Release{{TagName: "v2.0.0"}, {TagName: "v1.0.0"}})
+	u := fx.updater("v2.0.0", "stable")
+	rel, err := u.ReleaseByTag(context.Background(), "v1.0.0")
+	if err != nil || rel == nil || rel.TagName != "v1.0.0" {
+		t.Fatalf("ReleaseByTag: rel=%+v err=%v", rel, err)
+	}
+	sums, err := u.Checksums(context.Background(), rel)
+	if err != nil {
+		t.Fatalf("Checksums: %v", err)
+	}
+	if sums[u.AssetName()] != fx.binarySHA {
+		t.Fatalf("checksum for %s = %q, want %q", u.AssetName(), sums[u.AssetName()], fx.binarySHA)
+	}
+	if rel, err := u.ReleaseByTag(context.Background(), "v9.9.9"); err != nil || rel != nil {
+		t.Fatalf("missing tag: rel=%+v err=%v, want nil, nil", rel, err)
+	}
+}
+
+func TestDownloadVerifiedOutOfBandChecksum(t *testing.T) {
+	fx := newFixture(t, "v2.0.0", []Release{{TagName: "v2.0.0"}})
+	u := fx.updater("v1.0.0", "stable")
+	rel := &Release{
+		TagName:   "v2.0.0",
+		Assets:    []Asset{{Name: u.AssetName(), BrowserDownloadURL: fx.server.URL + "/bin"}},
+		Checksums: map[string]string{u.AssetName(): fx.binarySHA},
+	}
+	tmpPath, err := u.downloadVerified(context.Background(), rel)
+	if err != nil {
+		t.Fatalf("downloadVerified: %v", err)
+	}
+	os.Remove(tmpPath)
+
+	rel.Checksums[u.AssetName()] = "00"
+	if _, err := u.downloadVerified(context.Background(), rel); err == nil {
+		t.Fatal("expected checksum mismatch against the out-of-band checksum")
+	}
+}
+
+func TestNewer(t *testing.T) {
+	cases := []struct {
+		candidate, current string
+		want               bool
+	}{
+		{"1.2.0", "1.1.9", true},
+		{"v1.10.0", "1.9.0", true},
+		{"1.2", "1.2.0", false},
+		{"1.2.0", "v1.2.0", false},
+		{"1.1.0", "1.2.0", false},
+		{"1.2.0", "dev", true},
+		{"dev", "dev", false},
+	}
+	for _, c := range cases {
+		if got := Newer(c.candidate, c.current); got != c.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", c.candidate, c.current, got, c.want)
+		}
+	}
+}